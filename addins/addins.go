@@ -22,7 +22,9 @@ package addins
 import (
 	"git.golaxy.org/framework/addins/broker"
 	"git.golaxy.org/framework/addins/broker/broker_nats"
+	"git.golaxy.org/framework/addins/catalog"
 	"git.golaxy.org/framework/addins/conf"
+	"git.golaxy.org/framework/addins/cpsync"
 	"git.golaxy.org/framework/addins/db/mongodb"
 	"git.golaxy.org/framework/addins/db/redisdb"
 	"git.golaxy.org/framework/addins/db/sqldb"
@@ -34,6 +36,7 @@ import (
 	"git.golaxy.org/framework/addins/dsync/dsync_etcd"
 	"git.golaxy.org/framework/addins/dsync/dsync_redis"
 	"git.golaxy.org/framework/addins/gate"
+	"git.golaxy.org/framework/addins/ingress"
 	"git.golaxy.org/framework/addins/log"
 	"git.golaxy.org/framework/addins/router"
 	"git.golaxy.org/framework/addins/rpc"
@@ -45,8 +48,11 @@ var (
 	Broker            = broker.AddIn
 	BrokerNats        = broker_nats.AddIn
 	BrokerNatsWith    = broker_nats.With
+	Catalog           = catalog.AddIn
 	Conf              = conf.AddIn
 	ConfWith          = conf.With
+	CallPathSync      = cpsync.AddIn
+	CallPathSyncWith  = cpsync.With
 	MongoDB           = mongodb.AddIn
 	MongoDBWith       = mongodb.With
 	RedisDB           = redisdb.AddIn
@@ -69,6 +75,8 @@ var (
 	DsyncRedisWith    = dsync_redis.With
 	Gate              = gate.AddIn
 	GateWith          = gate.With
+	Ingress           = ingress.AddIn
+	IngressWith       = ingress.With
 	Log               = log.AddIn
 	LogWith           = log.With
 	Router            = router.AddIn
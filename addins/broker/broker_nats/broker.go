@@ -102,10 +102,13 @@ func (b *_NatsBroker) Publish(ctx context.Context, topic string, data []byte) er
 	}
 
 	if err := b.client.Publish(topic, data); err != nil {
+		metricPublishFailures.With(b.svcCtx.Name(), "nats").Inc()
 		log.L(b.svcCtx).Error("publish topic failed", zap.String("topic", topic), zap.Error(err))
 		return fmt.Errorf("broker: %w", err)
 	}
 
+	metricPublished.With(b.svcCtx.Name(), "nats").Inc()
+	metricPublishedBytes.With(b.svcCtx.Name(), "nats").Add(float64(len(data)))
 	return nil
 }
 
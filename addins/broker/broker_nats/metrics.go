@@ -0,0 +1,31 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package broker_nats
+
+import "git.golaxy.org/framework/utils/metrics"
+
+var (
+	metricPublished = metrics.Default().Counter("golaxy_broker_published_total",
+		"Messages published to the broker.", "service", "broker")
+	metricPublishFailures = metrics.Default().Counter("golaxy_broker_publish_failures_total",
+		"Messages that failed to publish.", "service", "broker")
+	metricPublishedBytes = metrics.Default().Counter("golaxy_broker_published_bytes_total",
+		"Payload bytes published to the broker.", "service", "broker")
+)
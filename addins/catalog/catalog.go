@@ -0,0 +1,86 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/framework/addins/log"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidTarget 表示查询的调用目标类型无效。
+	ErrInvalidTarget = errors.New("catalog: invalid target")
+)
+
+// ICatalog 描述本服务可通过 RPC 调用的脚本与方法。
+type ICatalog interface {
+	// Scripts 返回本服务已登记的脚本。
+	Scripts() []Script
+	// Describe 以 JSON 返回本服务中目标类型为 target 的脚本，target 为空时返回全部脚本；
+	// target 可取 service、runtime 或 entity。结果包含全部导出方法，不应开放给客户端调用。
+	Describe(target string) (string, error)
+}
+
+func newCatalog(...any) ICatalog {
+	return &_Catalog{}
+}
+
+type _Catalog struct {
+	svcCtx service.Context
+}
+
+func (c *_Catalog) Init(svcCtx service.Context) {
+	log.L(svcCtx).Info("initializing add-in", zap.String("name", AddIn.Name))
+	c.svcCtx = svcCtx
+}
+
+func (c *_Catalog) Shut(svcCtx service.Context) {
+	log.L(svcCtx).Info("shutting down add-in", zap.String("name", AddIn.Name))
+}
+
+// Scripts 返回本服务已登记的脚本。
+func (c *_Catalog) Scripts() []Script {
+	return Lookup(c.svcCtx.Name())
+}
+
+// Describe 以 JSON 返回本服务中目标类型为 target 的脚本，target 为空时返回全部脚本。
+func (c *_Catalog) Describe(target string) (string, error) {
+	switch target {
+	case "", "service", "runtime", "entity":
+	default:
+		return "", ErrInvalidTarget
+	}
+
+	scripts := slices.DeleteFunc(c.Scripts(), func(script Script) bool {
+		return target != "" && script.Target != target
+	})
+
+	data, err := json.Marshal(scripts)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
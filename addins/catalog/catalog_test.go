@@ -0,0 +1,69 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+)
+
+type testSvcCtx struct {
+	service.Context
+	name string
+}
+
+func (ctx testSvcCtx) Name() string { return ctx.name }
+
+func TestDescribe(t *testing.T) {
+	const service = "catalog-test/describe"
+
+	rt := reflect.TypeFor[*testBag]()
+	Record(service, callpath.Service, "Bags", rt)
+	Record(service, callpath.Runtime, "World", rt)
+	Record(service, callpath.Entity, "Bag", rt)
+	Record("catalog-test/other", callpath.Entity, "Other", rt)
+
+	c := &_Catalog{svcCtx: testSvcCtx{name: service}}
+
+	cases := []struct {
+		target string
+		want   []string
+	}{
+		{"", []string{"Bag", "World", "Bags"}},
+		{"service", []string{"Bags"}},
+		{"runtime", []string{"World"}},
+		{"entity", []string{"Bag"}},
+	}
+
+	for _, tc := range cases {
+		t.Run("target="+tc.target, func(t *testing.T) {
+			data, err := c.Describe(tc.target)
+			if err != nil {
+				t.Fatalf("Describe failed: %v", err)
+			}
+
+			var scripts []Script
+			if err := json.Unmarshal([]byte(data), &scripts); err != nil {
+				t.Fatalf("unmarshal %q failed: %v", data, err)
+			}
+
+			var names []string
+			for _, script := range scripts {
+				if script.Service != service {
+					t.Fatalf("script of another service described: %+v", script)
+				}
+				names = append(names, script.Name)
+			}
+			if !reflect.DeepEqual(names, tc.want) {
+				t.Fatalf("scripts = %v, want %v", names, tc.want)
+			}
+		})
+	}
+
+	if _, err := c.Describe("client"); !errors.Is(err, ErrInvalidTarget) {
+		t.Fatalf("expected ErrInvalidTarget, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+// Package catalog 提供服务目录 add-in，描述当前节点可通过 RPC 调用的脚本与方法。
+//
+// 目录数据来自框架在服务构建、实体原型声明和运行时激活时收集的反射信息，
+// 可通过 RPC 调用 Describe 查询，也可由应用的管理 HTTP 服务导出并经命令行查看。
+//
+// 目录列出脚本的全部导出方法，不区分客户端是否有权调用，用于运维与服务间调试。
+// 导出目录的 HTTP 服务只应监听回环地址或管理网络，不得暴露到公网；也不要在客户端调用权限中声明 Describe。
+package catalog
@@ -0,0 +1,235 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package catalog
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"sync"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpcstack"
+)
+
+// Method 描述一个可通过 RPC 调用的方法。
+type Method struct {
+	Name    string   `json:"name"`            // Name 是方法名。
+	Params  []string `json:"params"`          // Params 是调用方需要提供的参数类型，不含自动注入的调用链。
+	Returns []string `json:"returns"`         // Returns 是方法的返回值类型。
+	Async   bool     `json:"async,omitempty"` // Async 表示方法返回 async.Future，结果在其完成后回传。
+}
+
+// Script 描述一个可作为 RPC 调用目标的脚本。
+type Script struct {
+	Service string   `json:"service"` // Service 是脚本所属的服务名称。
+	Target  string   `json:"target"`  // Target 是调用目标类型：service、runtime 或 entity。
+	Name    string   `json:"name"`    // Name 是脚本名，即插件名或组件名；为空表示目标对象本身。
+	Type    string   `json:"type"`    // Type 是脚本的 Go 类型。
+	Methods []Method `json:"methods"` // Methods 是按名称排序的可调用方法。
+}
+
+var (
+	callChainRT = reflect.TypeFor[rpcstack.CallChain]()
+	futureRT    = reflect.TypeFor[async.Future]()
+)
+
+type _ScriptKey struct {
+	service, target, name, typ string
+}
+
+type _Registry struct {
+	mutex   sync.RWMutex
+	scripts map[_ScriptKey]*Script
+	bases   []reflect.Type
+}
+
+var registry = &_Registry{
+	scripts: map[_ScriptKey]*Script{},
+}
+
+// IgnoreMethodsOf 登记框架基础行为类型；嵌入这些类型的脚本在目录中不再列出其提升的方法。
+func IgnoreMethodsOf(rts ...reflect.Type) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	for _, rt := range rts {
+		if rt == nil || slices.Contains(registry.bases, rt) {
+			continue
+		}
+		registry.bases = append(registry.bases, rt)
+	}
+}
+
+// Record 将服务 service 中类型为 rt 的脚本登记到进程级目录，同一脚本重复登记时忽略。
+func Record(service string, kind callpath.TargetKind, script string, rt reflect.Type) {
+	if rt == nil {
+		return
+	}
+
+	target := targetName(kind)
+	if target == "" {
+		return
+	}
+
+	key := _ScriptKey{service: service, target: target, name: script, typ: rt.String()}
+
+	registry.mutex.RLock()
+	_, ok := registry.scripts[key]
+	registry.mutex.RUnlock()
+	if ok {
+		return
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if _, ok := registry.scripts[key]; ok {
+		return
+	}
+
+	registry.scripts[key] = &Script{
+		Service: service,
+		Target:  target,
+		Name:    script,
+		Type:    key.typ,
+		Methods: describeMethods(rt, registry.ignoredMethods(rt)),
+	}
+}
+
+// All 返回进程内全部服务已登记的脚本，按服务、目标类型、脚本名和类型排序。
+func All() []Script {
+	return Lookup("")
+}
+
+// Lookup 返回服务 service 已登记的脚本；service 为空时返回全部服务的脚本。
+func Lookup(service string) []Script {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	scripts := make([]Script, 0, len(registry.scripts))
+	for _, script := range registry.scripts {
+		if service != "" && script.Service != service {
+			continue
+		}
+		scripts = append(scripts, *script)
+	}
+
+	slices.SortFunc(scripts, func(a, b Script) int {
+		return cmp.Or(
+			cmp.Compare(a.Service, b.Service),
+			cmp.Compare(a.Target, b.Target),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Type, b.Type),
+		)
+	})
+
+	return scripts
+}
+
+func (r *_Registry) ignoredMethods(rt reflect.Type) map[string]struct{} {
+	ignored := map[string]struct{}{}
+
+	for _, base := range r.bases {
+		if !embeds(rt, base) {
+			continue
+		}
+		for i := range base.NumMethod() {
+			ignored[base.Method(i).Name] = struct{}{}
+		}
+	}
+
+	return ignored
+}
+
+// embeds 判断 rt 是否（直接或间接）匿名嵌入了 base 指向的结构体。
+func embeds(rt, base reflect.Type) bool {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return false
+	}
+
+	baseElem := base
+	for baseElem.Kind() == reflect.Pointer {
+		baseElem = baseElem.Elem()
+	}
+	if rt == baseElem {
+		return true
+	}
+
+	field, ok := rt.FieldByName(baseElem.Name())
+	if !ok || !field.Anonymous {
+		return false
+	}
+
+	return field.Type == baseElem || field.Type == reflect.PointerTo(baseElem)
+}
+
+func describeMethods(rt reflect.Type, ignored map[string]struct{}) []Method {
+	methods := make([]Method, 0, rt.NumMethod())
+
+	for i := range rt.NumMethod() {
+		methodRT := rt.Method(i)
+		if _, ok := ignored[methodRT.Name]; ok {
+			continue
+		}
+
+		// 方法表中的第一个参数是接收者。
+		method := Method{
+			Name:    methodRT.Name,
+			Params:  []string{},
+			Returns: []string{},
+		}
+
+		for j := 1; j < methodRT.Type.NumIn(); j++ {
+			in := methodRT.Type.In(j)
+			if in == callChainRT {
+				continue
+			}
+			method.Params = append(method.Params, in.String())
+		}
+
+		for j := range methodRT.Type.NumOut() {
+			method.Returns = append(method.Returns, methodRT.Type.Out(j).String())
+		}
+
+		method.Async = methodRT.Type.NumOut() == 1 && methodRT.Type.Out(0) == futureRT
+
+		methods = append(methods, method)
+	}
+
+	return methods
+}
+
+func targetName(kind callpath.TargetKind) string {
+	switch kind {
+	case callpath.Service:
+		return "service"
+	case callpath.Runtime:
+		return "runtime"
+	case callpath.Entity:
+		return "entity"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,102 @@
+package catalog
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpcstack"
+)
+
+type testBag struct{}
+
+func (*testBag) Open(cc rpcstack.CallChain, id string, n int) (bool, error) { return false, nil }
+func (*testBag) close()                                                     {}
+
+func (*testBag) Load() async.Future {
+	var future async.Future
+	return future
+}
+
+type testBase struct{}
+
+func (*testBase) Start() {}
+func (*testBase) Stop()  {}
+
+type testEmbedded struct {
+	testBase
+}
+
+func (*testEmbedded) Use(item string) {}
+
+type testEmbeddedPtr struct {
+	*testBase
+}
+
+func (*testEmbeddedPtr) Drop(item string) {}
+
+func methodNames(script Script) []string {
+	var names []string
+	for _, method := range script.Methods {
+		names = append(names, method.Name)
+	}
+	return names
+}
+
+func TestRecord(t *testing.T) {
+	const service = "catalog-test/record"
+
+	rt := reflect.TypeFor[*testBag]()
+	Record(service, callpath.Entity, "Bag", rt)
+	Record(service, callpath.Entity, "Bag", rt)
+	Record(service, callpath.Client, "Bag", rt)
+	Record(service, callpath.Entity, "Nil", nil)
+
+	scripts := Lookup(service)
+	if len(scripts) != 1 {
+		t.Fatalf("expected duplicate and invalid records to be ignored, got %+v", scripts)
+	}
+
+	script := scripts[0]
+	if script.Service != service || script.Target != "entity" || script.Name != "Bag" || script.Type != rt.String() {
+		t.Fatalf("unexpected script %+v", script)
+	}
+
+	want := []Method{
+		{Name: "Load", Params: []string{}, Returns: []string{"async.Future"}, Async: true},
+		{Name: "Open", Params: []string{"string", "int"}, Returns: []string{"bool", "error"}},
+	}
+	if !reflect.DeepEqual(script.Methods, want) {
+		t.Fatalf("methods = %+v, want %+v", script.Methods, want)
+	}
+
+	if !slices.ContainsFunc(All(), func(s Script) bool { return s.Service == service }) {
+		t.Fatal("All does not include the recorded script")
+	}
+}
+
+func TestRecordIgnoresEmbeddedBase(t *testing.T) {
+	const service = "catalog-test/embed"
+
+	IgnoreMethodsOf(reflect.TypeFor[*testBase](), nil)
+
+	Record(service, callpath.Service, "Embedded", reflect.TypeFor[*testEmbedded]())
+	Record(service, callpath.Service, "EmbeddedPtr", reflect.TypeFor[*testEmbeddedPtr]())
+	Record(service, callpath.Service, "Plain", reflect.TypeFor[*testBag]())
+
+	got := map[string][]string{}
+	for _, script := range Lookup(service) {
+		got[script.Name] = methodNames(script)
+	}
+
+	want := map[string][]string{
+		"Embedded":    {"Use"},
+		"EmbeddedPtr": {"Drop"},
+		"Plain":       {"Load", "Open"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("methods = %v, want %v", got, want)
+	}
+}
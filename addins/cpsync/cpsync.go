@@ -0,0 +1,305 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package cpsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/option"
+	"git.golaxy.org/framework/addins/log"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	etcdv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// ICallPathSync 在集群内同步压缩调用路径表。
+type ICallPathSync interface {
+	// Publish 立即将本进程尚未发布的调用路径表条目发布到 ETCD。
+	Publish(ctx context.Context) error
+}
+
+func newCallPathSync(settings ...option.Setting[CallPathSyncOptions]) ICallPathSync {
+	return &_CallPathSync{
+		options:   option.New(With.Default(), settings...),
+		published: map[uint32]struct{}{},
+	}
+}
+
+type _CallPathSync struct {
+	svcCtx      service.Context
+	scope       *async.Scope
+	options     CallPathSyncOptions
+	client      *etcdv3.Client
+	publishMu   sync.Mutex
+	published   map[uint32]struct{}
+	publishedAt int
+}
+
+type _Value struct {
+	Script string `json:"script"`
+	Method string `json:"method"`
+}
+
+// Init 建立或复用 ETCD 客户端，合并集群中已发布的条目并发布本进程的条目，随后启动监听与周期发布。
+func (s *_CallPathSync) Init(svcCtx service.Context) {
+	log.L(svcCtx).Info("initializing add-in", zap.String("name", AddIn.Name))
+
+	s.svcCtx = svcCtx
+	s.scope = async.NewScope(nil)
+
+	if s.options.EtcdClient == nil {
+		cli, err := etcdv3.New(s.configure())
+		if err != nil {
+			log.L(svcCtx).Panic("new etcd client failed", log.JSON("config", s.configure()), zap.Error(err))
+		}
+		s.client = cli
+	} else {
+		s.client = s.options.EtcdClient
+	}
+
+	for _, ep := range s.client.Endpoints() {
+		func(endpoint string) {
+			ctx, cancel := context.WithTimeout(s.svcCtx, 3*time.Second)
+			defer cancel()
+
+			if _, err := s.client.Status(ctx, endpoint); err != nil {
+				log.L(svcCtx).Panic("status etcd failed", zap.String("endpoint", endpoint), zap.Error(err))
+			}
+		}(ep)
+	}
+
+	revision, err := s.load(s.svcCtx)
+	if err != nil {
+		log.L(svcCtx).Panic("load call path table failed", zap.String("key", s.options.KeyPrefix), zap.Error(err))
+	}
+
+	if err := s.Publish(s.svcCtx); err != nil {
+		log.L(svcCtx).Error("publish call path table failed", zap.String("key", s.options.KeyPrefix), zap.Error(err))
+	}
+
+	async.SpawnVoid(s.scope, func(ctx context.Context) {
+		s.watchingForEntries(ctx, revision+1)
+	})
+	async.SpawnVoid(s.scope, s.publishingLoop)
+}
+
+// Shut 停止监听与周期发布并等待退出；仅关闭由本 add-in 创建的 ETCD 客户端。
+func (s *_CallPathSync) Shut(svcCtx service.Context) {
+	log.L(svcCtx).Info("shutting down add-in", zap.String("name", AddIn.Name))
+
+	s.scope.Close()
+	<-s.scope.Completion().Done()
+
+	if s.options.EtcdClient == nil && s.client != nil {
+		s.client.Close()
+	}
+}
+
+// Publish 立即将本进程尚未发布的调用路径表条目发布到 ETCD。
+// 条目以不存在时才创建的方式写入，索引已被其他调用路径占用时返回冲突错误。
+func (s *_CallPathSync) Publish(ctx context.Context) error {
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+
+	count := callpath.Count()
+	if count == s.publishedAt {
+		return nil
+	}
+
+	var errs []error
+
+	for _, entry := range callpath.Entries() {
+		if _, ok := s.published[entry.Index]; ok {
+			continue
+		}
+
+		if err := s.put(ctx, entry); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		s.published[entry.Index] = struct{}{}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("cpsync: %w", errors.Join(errs...))
+	}
+
+	s.publishedAt = count
+	return nil
+}
+
+func (s *_CallPathSync) put(ctx context.Context, entry callpath.Entry) error {
+	key := s.entryKey(entry.Index)
+
+	value, err := json.Marshal(_Value{Script: entry.Script, Method: entry.Method})
+	if err != nil {
+		return err
+	}
+
+	rsp, err := s.client.Txn(ctx).
+		If(etcdv3.Compare(etcdv3.CreateRevision(key), "=", 0)).
+		Then(etcdv3.OpPut(key, string(value))).
+		Else(etcdv3.OpGet(key)).
+		Commit()
+	if err != nil {
+		return err
+	}
+
+	if rsp.Succeeded {
+		return nil
+	}
+
+	// 条目已由其他进程发布，仅校验是否与本进程的调用路径一致。
+	for _, kv := range rsp.Responses[0].GetResponseRange().GetKvs() {
+		exists, err := s.parseEntry(kv.Key, kv.Value)
+		if err != nil {
+			return err
+		}
+		if exists != entry {
+			return fmt.Errorf("cached index %d conflict: published %+v vs local %+v", entry.Index, exists, entry)
+		}
+	}
+
+	return nil
+}
+
+// load 合并 ETCD 中已发布的全部条目，返回读取时的存储修订号。
+func (s *_CallPathSync) load(ctx context.Context) (int64, error) {
+	rsp, err := s.client.Get(ctx, s.options.KeyPrefix, etcdv3.WithPrefix())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, kv := range rsp.Kvs {
+		s.merge(kv.Key, kv.Value)
+	}
+
+	log.L(s.svcCtx).Debug("call path table loaded",
+		zap.String("key", s.options.KeyPrefix),
+		zap.Int("entries", len(rsp.Kvs)),
+		zap.Int64("revision", rsp.Header.Revision))
+
+	return rsp.Header.Revision, nil
+}
+
+func (s *_CallPathSync) watchingForEntries(ctx context.Context, revision int64) {
+	log.L(s.svcCtx).Debug("watching for call path entries started", zap.String("key", s.options.KeyPrefix), zap.Int64("revision", revision))
+
+	for watchRsp := range s.client.Watch(ctx, s.options.KeyPrefix, etcdv3.WithPrefix(), etcdv3.WithRev(revision)) {
+		if watchRsp.Canceled {
+			log.L(s.svcCtx).Debug("watching for call path entries canceled",
+				zap.String("key", s.options.KeyPrefix),
+				zap.Int64("revision", revision),
+				zap.Error(watchRsp.Err()))
+			break
+		}
+		if watchRsp.Err() != nil {
+			log.L(s.svcCtx).Error("watching for call path entries unexpectedly interrupted",
+				zap.String("key", s.options.KeyPrefix),
+				zap.Int64("revision", revision),
+				zap.Error(watchRsp.Err()))
+			break
+		}
+
+		for _, event := range watchRsp.Events {
+			if event.Type != etcdv3.EventTypePut {
+				continue
+			}
+			s.merge(event.Kv.Key, event.Kv.Value)
+		}
+	}
+
+	log.L(s.svcCtx).Debug("watching for call path entries stopped", zap.String("key", s.options.KeyPrefix), zap.Int64("revision", revision))
+}
+
+func (s *_CallPathSync) publishingLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.options.PublishInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Publish(ctx); err != nil {
+				log.L(s.svcCtx).Error("publish call path table failed", zap.String("key", s.options.KeyPrefix), zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *_CallPathSync) merge(key, value []byte) {
+	entry, err := s.parseEntry(key, value)
+	if err != nil {
+		log.L(s.svcCtx).Warn("invalid call path entry", zap.ByteString("key", key), zap.Error(err))
+		return
+	}
+
+	if err := callpath.Merge(entry); err != nil {
+		log.L(s.svcCtx).Error("merge call path entry failed", zap.ByteString("key", key), zap.Error(err))
+	}
+}
+
+func (s *_CallPathSync) entryKey(idx uint32) string {
+	return s.options.KeyPrefix + fmt.Sprintf("%08x", idx)
+}
+
+func (s *_CallPathSync) parseEntry(key, value []byte) (callpath.Entry, error) {
+	idx, err := strconv.ParseUint(strings.TrimPrefix(string(key), s.options.KeyPrefix), 16, 32)
+	if err != nil {
+		return callpath.Entry{}, err
+	}
+
+	var v _Value
+	if err := json.Unmarshal(value, &v); err != nil {
+		return callpath.Entry{}, err
+	}
+
+	return callpath.Entry{Index: uint32(idx), Script: v.Script, Method: v.Method}, nil
+}
+
+func (s *_CallPathSync) configure() etcdv3.Config {
+	if s.options.EtcdConfig != nil {
+		return *s.options.EtcdConfig
+	}
+
+	config := etcdv3.Config{
+		Endpoints:   s.options.CustomAddresses,
+		Username:    s.options.CustomUsername,
+		Password:    s.options.CustomPassword,
+		DialTimeout: 3 * time.Second,
+	}
+
+	if s.options.CustomTLSConfig != nil {
+		config.TLS = s.options.CustomTLSConfig
+	}
+
+	return config
+}
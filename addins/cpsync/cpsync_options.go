@@ -0,0 +1,123 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package cpsync
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+	"time"
+
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/utils/exception"
+	"git.golaxy.org/core/utils/option"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// CallPathSyncOptions 配置调用路径表同步使用的 ETCD 客户端、键空间及发布周期。
+type CallPathSyncOptions struct {
+	EtcdClient      *clientv3.Client // EtcdClient 非 nil 时直接复用，停止时不会关闭它。
+	EtcdConfig      *clientv3.Config // EtcdConfig 在未提供客户端时优先于 Custom 字段。
+	KeyPrefix       string           // KeyPrefix 是调用路径表条目的键前缀。
+	PublishInterval time.Duration    // PublishInterval 是检查并发布本进程新增条目的周期。
+	CustomUsername  string           // CustomUsername 是自行构造客户端时使用的用户名。
+	CustomPassword  string           // CustomPassword 是自行构造客户端时使用的密码。
+	CustomAddresses []string         // CustomAddresses 是自行构造客户端时使用的端点。
+	CustomTLSConfig *tls.Config      // CustomTLSConfig 是自行构造客户端时使用的 TLS 配置。
+}
+
+// With 提供调用路径表同步 add-in 的 Option 构造方法。
+var With _CallPathSyncOption
+
+type _CallPathSyncOption struct{}
+
+// Default 返回使用本地 ETCD 端点、默认键前缀及 3 秒发布周期的设置。
+func (_CallPathSyncOption) Default() option.Setting[CallPathSyncOptions] {
+	return func(options *CallPathSyncOptions) {
+		With.EtcdClient(nil)(options)
+		With.EtcdConfig(nil)(options)
+		With.KeyPrefix("/golaxy/callpath/")(options)
+		With.PublishInterval(3 * time.Second)(options)
+		With.CustomAuth("", "")(options)
+		With.CustomAddresses("127.0.0.1:2379")(options)
+		With.CustomTLSConfig(nil)(options)
+	}
+}
+
+// EtcdClient 设置要复用的 ETCD 客户端，其优先级最高。
+func (_CallPathSyncOption) EtcdClient(cli *clientv3.Client) option.Setting[CallPathSyncOptions] {
+	return func(options *CallPathSyncOptions) {
+		options.EtcdClient = cli
+	}
+}
+
+// EtcdConfig 设置创建 ETCD 客户端时使用的完整配置，其优先级次于 EtcdClient。
+func (_CallPathSyncOption) EtcdConfig(config *clientv3.Config) option.Setting[CallPathSyncOptions] {
+	return func(options *CallPathSyncOptions) {
+		options.EtcdConfig = config
+	}
+}
+
+// KeyPrefix 设置调用路径表条目的键前缀；非空值会自动补充末尾斜杠。
+func (_CallPathSyncOption) KeyPrefix(prefix string) option.Setting[CallPathSyncOptions] {
+	return func(options *CallPathSyncOptions) {
+		if prefix != "" && !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+		options.KeyPrefix = prefix
+	}
+}
+
+// PublishInterval 设置检查并发布本进程新增条目的周期，必须大于 0。
+func (_CallPathSyncOption) PublishInterval(d time.Duration) option.Setting[CallPathSyncOptions] {
+	return func(options *CallPathSyncOptions) {
+		if d <= 0 {
+			exception.Panicf("cpsync: %w: option PublishInterval must be > 0", core.ErrArgs)
+		}
+		options.PublishInterval = d
+	}
+}
+
+// CustomAuth 设置自行构造 ETCD 客户端时使用的用户名和密码。
+func (_CallPathSyncOption) CustomAuth(username, password string) option.Setting[CallPathSyncOptions] {
+	return func(options *CallPathSyncOptions) {
+		options.CustomUsername = username
+		options.CustomPassword = password
+	}
+}
+
+// CustomAddresses 设置自行构造 ETCD 客户端时使用的端点，并校验 host:port 格式。
+func (_CallPathSyncOption) CustomAddresses(addrs ...string) option.Setting[CallPathSyncOptions] {
+	return func(options *CallPathSyncOptions) {
+		for _, addr := range addrs {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				exception.Panicf("cpsync: %w: %w", core.ErrArgs, err)
+			}
+		}
+		options.CustomAddresses = addrs
+	}
+}
+
+// CustomTLSConfig 设置自行构造 ETCD 客户端时使用的 TLS 配置。
+func (_CallPathSyncOption) CustomTLSConfig(conf *tls.Config) option.Setting[CallPathSyncOptions] {
+	return func(options *CallPathSyncOptions) {
+		options.CustomTLSConfig = conf
+	}
+}
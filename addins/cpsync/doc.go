@@ -0,0 +1,25 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+// Package cpsync 通过 ETCD 在集群内同步压缩调用路径表。
+//
+// 压缩调用路径只携带脚本名和方法名的哈希索引，接收方必须缓存过对应条目才能解析。
+// 该 add-in 将本进程缓存的条目发布到 ETCD，并监听其他进程发布的条目合并到本地，
+// 使网关、中转节点以及混合版本部署中的节点也能解析未在本地声明的调用路径。
+package cpsync
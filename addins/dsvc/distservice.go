@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unique"
 
@@ -75,10 +76,17 @@ type _DistService struct {
 	dsync       dsync.IDistSync
 	details     *NodeDetails
 	encoder     *codec.Encoder
+	fragEncoder *codec.Encoder
 	decoder     *codec.Decoder
 	correlation *correlation.Controller
 	bringUpOnce sync.Once
 	listeners   fanout.Broadcaster[MsgHandler, _BrokerMsg]
+	fragIDs     atomic.Uint64
+	reassembler *_Reassembler
+
+	authentication codec.IAuthentication
+	encryption     codec.IEncryption
+	nodeKeys       *_NodeKeys
 }
 
 // Init 获取服务发现、消息中间件和分布式同步依赖，并创建编解码器、请求关联控制器及节点地址。
@@ -98,15 +106,45 @@ func (d *_DistService) Init(svcCtx service.Context) {
 		log.L(svcCtx).Panic("broker delivery reliability must be at most once")
 	}
 
-	// 初始化 GAP 消息包编解码器。
-	d.decoder = codec.NewDecoder(d.options.MsgCreator)
-	d.encoder = codec.NewEncoder()
+	// 根据选项创建签名与加密模块。
+	d.initSecurity()
+
+	// 初始化 GAP 消息包编解码器；解码器总能还原其他节点按任一算法压缩的消息包。
+	// 分片编码器不再压缩已按需压缩的原始消息包，但同样加密和签名每个分片。
+	compression := codec.NewCompression(d.options.Compression)
+	d.decoder = &codec.Decoder{
+		MsgCreator:          d.options.MsgCreator,
+		Compression:         compression,
+		MaxUncompressedSize: d.options.MaxUncompressedSize,
+		Encryption:          d.encryption,
+		Authentication:      d.authentication,
+		AcceptUnsigned:      d.options.AcceptUnsigned,
+	}
+	d.encoder = &codec.Encoder{
+		Compression:          compression,
+		CompressionThreshold: d.options.CompressionThreshold,
+		Encryption:           d.encryption,
+		Authentication:       d.authentication,
+	}
+	d.fragEncoder = &codec.Encoder{
+		Encryption:     d.encryption,
+		Authentication: d.authentication,
+	}
+
+	// 分片组 ID 以启动时间为种子，避免节点重启后与对端尚未超时的旧分片组混淆。
+	d.fragIDs.Store(uint64(time.Now().UnixNano()))
+	d.reassembler = newReassembler(d.options.FragmentTimeout, d.options.MaxFragmentedMsgSize, d.options.ReassemblyMemoryLimit, d.onReassemblyExpired)
 
 	// 请求关联控制器随 add-in 的内部作用域一起停止。
 	d.correlation = correlation.New(d.scope.Context(), d.options.FutureTimeout)
 
 	// 根据 broker 分隔符和服务身份生成各级消息地址。
 	d.initNodeDetails()
+
+	// 在途请求数在采集时读取。
+	metricPendingCorrelations.With(svcCtx.Name(), svcCtx.ID().String()).SetFunc(func() float64 {
+		return float64(d.correlation.Pending())
+	})
 }
 
 // Shut 关闭内部作用域，拒绝新任务，并等待节点注销、消息退订及监听器退出。
@@ -119,6 +157,9 @@ func (d *_DistService) Shut(svcCtx service.Context) {
 	d.barrier.Wait()
 	<-d.correlation.Done().Done()
 	<-d.scope.Completion().Done()
+	d.reassembler.Close()
+
+	metricPendingCorrelations.Delete(svcCtx.Name(), svcCtx.ID().String())
 }
 
 // BringUp 仅执行一次：先订阅节点地址，再通过分布式锁检查并注册当前服务节点。
@@ -137,6 +178,11 @@ func (d *_DistService) BringUp() {
 			zap.String("node", svcCtx.ID().String()),
 			log.JSON("details", d.details))
 
+		// 启用 Ed25519 签名时，在订阅前同步其他节点的公钥，并持续监听节点变化。
+		if d.nodeKeys != nil {
+			d.watchNodeKeys()
+		}
+
 		// 在注册节点前订阅全部五类接收地址，避免上线后遗漏消息。
 		subs := []async.Signal{
 			// 全局广播与全局负载均衡地址。
@@ -167,12 +213,12 @@ func (d *_DistService) BringUp() {
 			log.L(svcCtx).Panic("checking service node failed", zap.String("service", svcCtx.Name()), zap.String("node", svcCtx.ID().String()), zap.Error(err))
 		}
 
-		// 发布节点单播地址及调用方配置的版本和元数据。
+		// 发布节点单播地址及调用方配置的版本和元数据；启用 Ed25519 签名时附带本节点公钥。
 		node := &discovery.Node{
 			ID:      svcCtx.ID(),
 			Address: d.details.LocalAddr,
 			Version: d.options.Version,
-			Meta:    d.options.Meta,
+			Meta:    d.nodeMeta(),
 		}
 
 		// 注册后持续续租，直到 add-in 的内部作用域关闭。
@@ -231,18 +277,17 @@ func (d *_DistService) Correlation() *correlation.Controller {
 	return d.correlation
 }
 
-// Send 将 msg 编码为 GAP 消息包并发布到 dst。
+// Send 将 msg 编码为 GAP 消息包并发布到 dst；超出 broker 单条负载上限时拆分为分片发布。
 func (d *_DistService) Send(dst string, msg gap.Msg) error {
 	if msg == nil {
 		return fmt.Errorf("dsvc: %w: msg is nil", core.ErrArgs)
 	}
 
-	mpBuf, err := d.encoder.Encode(
-		gap.Origin{Svc: d.svcCtx.Name(), Addr: d.details.LocalAddr, Timestamp: time.Now().UnixMilli()},
-		0,
-		msg,
-	)
+	src := gap.Origin{Svc: d.svcCtx.Name(), Addr: d.details.LocalAddr, Timestamp: time.Now().UnixMilli()}
+
+	mpBuf, err := d.encoder.EncodeTo(dst, src, 0, msg)
 	if err != nil {
+		metricSendFailures.With(d.svcCtx.Name()).Inc()
 		log.L(d.svcCtx).Error("encode message failed",
 			zap.String("dst", dst),
 			zap.Uint32("msg", msg.MsgID()),
@@ -251,8 +296,13 @@ func (d *_DistService) Send(dst string, msg gap.Msg) error {
 	}
 	defer mpBuf.Release()
 
-	err = d.broker.Publish(d.scope.Context(), dst, mpBuf.Payload())
+	if maxPayload := d.broker.MaxPayload(); maxPayload > 0 && int64(len(mpBuf.Payload())) > maxPayload {
+		err = d.publishFragments(dst, src, mpBuf.Payload(), maxPayload)
+	} else {
+		err = d.broker.Publish(d.scope.Context(), dst, mpBuf.Payload())
+	}
 	if err != nil {
+		metricSendFailures.With(d.svcCtx.Name()).Inc()
 		log.L(d.svcCtx).Error("publish message failed",
 			zap.String("dst", dst),
 			zap.Uint32("msg", msg.MsgID()),
@@ -260,6 +310,7 @@ func (d *_DistService) Send(dst string, msg gap.Msg) error {
 		return fmt.Errorf("dsvc: %w", err)
 	}
 
+	metricMessagesSent.With(d.svcCtx.Name()).Inc()
 	return nil
 }
 
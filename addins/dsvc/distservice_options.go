@@ -20,23 +20,40 @@
 package dsvc
 
 import (
+	"crypto/ed25519"
 	"time"
 
 	"git.golaxy.org/core"
 	"git.golaxy.org/core/utils/exception"
 	"git.golaxy.org/core/utils/option"
 	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/codec"
+	"git.golaxy.org/framework/net/gtp"
+	"git.golaxy.org/framework/net/gtp/method"
 )
 
 // DistServiceOptions 配置分布式服务的节点信息、地址空间及消息处理容量。
 type DistServiceOptions struct {
-	Version           string            // Version 是对外发布的服务版本。
-	Meta              map[string]string // Meta 是对外发布的服务节点元数据。
-	DomainRoot        string            // DomainRoot 是消息地址空间的根域。
-	RegistrationTTL   time.Duration     // RegistrationTTL 是服务发现注册租约的有效期。
-	FutureTimeout     time.Duration     // FutureTimeout 是请求等待响应的默认超时。
-	ListenerInboxSize int               // ListenerInboxSize 是每个消息监听器的收件箱容量。
-	MsgCreator        gap.IMsgCreator   // MsgCreator 用于按消息 ID 创建解码目标。
+	Version               string             // Version 是对外发布的服务版本。
+	Meta                  map[string]string  // Meta 是对外发布的服务节点元数据。
+	DomainRoot            string             // DomainRoot 是消息地址空间的根域。
+	RegistrationTTL       time.Duration      // RegistrationTTL 是服务发现注册租约的有效期。
+	FutureTimeout         time.Duration      // FutureTimeout 是请求等待响应的默认超时。
+	ListenerInboxSize     int                // ListenerInboxSize 是每个消息监听器的收件箱容量。
+	MsgCreator            gap.IMsgCreator    // MsgCreator 用于按消息 ID 创建解码目标。
+	FragmentTimeout       time.Duration      // FragmentTimeout 是分片消息从首个分片到达起完成重组的时限。
+	MaxFragmentedMsgSize  int                // MaxFragmentedMsgSize 是允许分片收发的单条消息包字节上限。
+	ReassemblyMemoryLimit int                // ReassemblyMemoryLimit 是所有重组中消息占用的字节总上限。
+	Compression           gtp.Compression    // Compression 是发送消息包使用的压缩算法。
+	CompressionThreshold  int                // CompressionThreshold 是启用压缩的消息体字节阈值；小于等于 0 时禁用。
+	MaxUncompressedSize   int                // MaxUncompressedSize 限制解压后的消息体，防御压缩炸弹。
+	HMACHash              gtp.Hash           // HMACHash 是 HMAC 签名使用的摘要算法。
+	HMACKeyring           *codec.Keyring     // HMACKeyring 是集群共享的 HMAC 签名密钥环；nil 表示不使用 HMAC 签名。
+	Ed25519KeyID          string             // Ed25519KeyID 是本节点签名密钥的 ID。
+	Ed25519PrivateKey     ed25519.PrivateKey // Ed25519PrivateKey 是本节点签名私钥；nil 表示不使用 Ed25519 签名。
+	EncryptionKeyring     *codec.Keyring     // EncryptionKeyring 是集群共享的 AES-GCM 加密密钥环；nil 表示不加密。
+	AcceptUnsigned        bool               // AcceptUnsigned 允许启用签名后仍接收未签名的消息包。
+	MaxClockSkew          time.Duration      // MaxClockSkew 是签名消息来源时间戳与本地时间的最大偏差。
 }
 
 // With 提供分布式服务 add-in 的 Option 构造方法。
@@ -44,7 +61,9 @@ var With _DistServiceOption
 
 type _DistServiceOption struct{}
 
-// Default 返回 svc 根域、30 秒注册租约、5 秒 Future 超时和默认 GAP 消息构建器。
+// Default 返回 svc 根域、30 秒注册租约、5 秒 Future 超时、默认 GAP 消息构建器，
+// 30 秒分片重组时限、64MB 单条分片消息上限、256MB 重组内存上限，
+// 不压缩发送、4KB 压缩阈值、128MB 解压上限，以及不签名、不加密和 5 分钟签名时钟偏差。
 func (_DistServiceOption) Default() option.Setting[DistServiceOptions] {
 	return func(options *DistServiceOptions) {
 		With.Version("").Apply(options)
@@ -54,6 +73,17 @@ func (_DistServiceOption) Default() option.Setting[DistServiceOptions] {
 		With.FutureTimeout(5 * time.Second).Apply(options)
 		With.ListenerInboxSize(256 * 1024).Apply(options)
 		With.MsgCreator(gap.DefaultMsgCreator()).Apply(options)
+		With.FragmentTimeout(30 * time.Second).Apply(options)
+		With.MaxFragmentedMsgSize(64 * 1024 * 1024).Apply(options)
+		With.ReassemblyMemoryLimit(256 * 1024 * 1024).Apply(options)
+		With.Compression(gtp.Compression_None).Apply(options)
+		With.CompressionThreshold(4 * 1024).Apply(options)
+		With.MaxUncompressedSize(128 * 1024 * 1024).Apply(options)
+		With.HMACSigning(gtp.Hash_None, nil).Apply(options)
+		With.Ed25519Signing("", nil).Apply(options)
+		With.Encryption(nil).Apply(options)
+		With.AcceptUnsigned(false).Apply(options)
+		With.MaxClockSkew(5 * time.Minute).Apply(options)
 	}
 }
 
@@ -108,7 +138,7 @@ func (_DistServiceOption) ListenerInboxSize(size int) option.Setting[DistService
 	}
 }
 
-// MsgCreator 设置 GAP 消息构建器，不得为 nil。
+// MsgCreator 设置 GAP 消息构建器，不得为 nil；需声明 gap.MsgFragment 才能接收分片消息。
 func (_DistServiceOption) MsgCreator(mc gap.IMsgCreator) option.Setting[DistServiceOptions] {
 	return func(options *DistServiceOptions) {
 		if mc == nil {
@@ -117,3 +147,112 @@ func (_DistServiceOption) MsgCreator(mc gap.IMsgCreator) option.Setting[DistServ
 		options.MsgCreator = mc
 	}
 }
+
+// FragmentTimeout 设置分片消息的重组时限，必须不少于 1 秒；超时未到齐的分片会被丢弃。
+func (_DistServiceOption) FragmentTimeout(d time.Duration) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		if d < time.Second {
+			exception.Panicf("dsvc: %w: option FragmentTimeout must be >= 1 second", core.ErrArgs)
+		}
+		options.FragmentTimeout = d
+	}
+}
+
+// MaxFragmentedMsgSize 设置允许分片收发的单条消息包字节上限，必须大于 0；发送和重组时均会检查。
+func (_DistServiceOption) MaxFragmentedMsgSize(size int) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		if size <= 0 {
+			exception.Panicf("dsvc: %w: option MaxFragmentedMsgSize must be > 0", core.ErrArgs)
+		}
+		options.MaxFragmentedMsgSize = size
+	}
+}
+
+// ReassemblyMemoryLimit 设置所有重组中消息占用的字节总上限，必须大于 0；超出时新的分片组会被拒绝。
+func (_DistServiceOption) ReassemblyMemoryLimit(size int) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		if size <= 0 {
+			exception.Panicf("dsvc: %w: option ReassemblyMemoryLimit must be > 0", core.ErrArgs)
+		}
+		options.ReassemblyMemoryLimit = size
+	}
+}
+
+// Compression 设置发送消息包使用的压缩算法；Compression_None 表示不压缩，接收端始终可还原任一算法压缩的消息包。
+func (_DistServiceOption) Compression(c gtp.Compression) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		if c != gtp.Compression_None {
+			if _, err := method.NewCompressionStream(c); err != nil {
+				exception.Panicf("dsvc: %w: option Compression is invalid, %w", core.ErrArgs, err)
+			}
+		}
+		options.Compression = c
+	}
+}
+
+// CompressionThreshold 设置启用压缩的消息体字节阈值；小于等于 0 时禁用压缩。
+func (_DistServiceOption) CompressionThreshold(threshold int) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		options.CompressionThreshold = threshold
+	}
+}
+
+// MaxUncompressedSize 设置解压后消息体的字节上限，必须大于 0，用于防御压缩炸弹。
+func (_DistServiceOption) MaxUncompressedSize(size int) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		if size <= 0 {
+			exception.Panicf("dsvc: %w: option MaxUncompressedSize must be > 0", core.ErrArgs)
+		}
+		options.MaxUncompressedSize = size
+	}
+}
+
+// HMACSigning 设置以集群共享密钥环进行 HMAC 签名；keyring 为 nil 时不使用 HMAC 签名，不可与 Ed25519 签名同时启用。
+// HMAC 只能证明发送方持有集群密钥，无法防止持有密钥的节点伪造其他节点的来源。
+func (_DistServiceOption) HMACSigning(h gtp.Hash, keyring *codec.Keyring) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		if keyring != nil {
+			if _, err := method.NewHMAC(h, []byte{0}); err != nil {
+				exception.Panicf("dsvc: %w: option HMACSigning hash is invalid, %w", core.ErrArgs, err)
+			}
+		}
+		options.HMACHash = h
+		options.HMACKeyring = keyring
+	}
+}
+
+// Ed25519Signing 设置以本节点 Ed25519 私钥签名；公钥随节点注册发布到服务发现元数据，接收方据此验证消息来源。
+// privateKey 为 nil 时不使用 Ed25519 签名，不可与 HMAC 签名同时启用；更换密钥需以新密钥重启节点。
+func (_DistServiceOption) Ed25519Signing(keyID string, privateKey ed25519.PrivateKey) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		if privateKey != nil && (keyID == "" || len(privateKey) != ed25519.PrivateKeySize) {
+			exception.Panicf("dsvc: %w: option Ed25519Signing key is invalid", core.ErrArgs)
+		}
+		options.Ed25519KeyID = keyID
+		options.Ed25519PrivateKey = privateKey
+	}
+}
+
+// Encryption 设置以集群共享密钥环进行 AES-GCM 加密；keyring 为 nil 时不加密，接收端仍需配置密钥环才能解密。
+func (_DistServiceOption) Encryption(keyring *codec.Keyring) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		options.EncryptionKeyring = keyring
+	}
+}
+
+// AcceptUnsigned 设置启用签名后是否仍接收未签名的消息包，用于在集群中逐步启用签名。
+func (_DistServiceOption) AcceptUnsigned(b bool) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		options.AcceptUnsigned = b
+	}
+}
+
+// MaxClockSkew 设置签名消息来源时间戳与本地时间的最大偏差，必须大于 0；超出时视为重放并丢弃。
+func (_DistServiceOption) MaxClockSkew(d time.Duration) option.Setting[DistServiceOptions] {
+	return func(options *DistServiceOptions) {
+		if d <= 0 {
+			exception.Panicf("dsvc: %w: option MaxClockSkew must be > 0", core.ErrArgs)
+		}
+		options.MaxClockSkew = d
+	}
+}
@@ -0,0 +1,315 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package dsvc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"git.golaxy.org/framework/addins/broker"
+	"git.golaxy.org/framework/addins/log"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/utils/binaryutil"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrFragmentTooLarge 表示消息包超出允许分片收发的字节上限。
+	ErrFragmentTooLarge = errors.New("dsvc: fragmented message too large")
+	// ErrReassemblyMemoryLimit 表示重组中消息占用的内存已达上限。
+	ErrReassemblyMemoryLimit = errors.New("dsvc: reassembly memory limit reached")
+	// ErrMalformedFragment 表示分片字段相互矛盾或与同组分片不一致。
+	ErrMalformedFragment = errors.New("dsvc: malformed fragment")
+)
+
+// publishFragments 将已编码的消息包按 broker 单条负载上限拆分为分片并依次发布。
+// 负载均衡地址的每条消息可能投递到不同节点，分片无法在同一节点重组，因此拒绝发布。
+func (d *_DistService) publishFragments(dst string, src gap.Origin, data []byte, maxPayload int64) error {
+	if len(data) > d.options.MaxFragmentedMsgSize {
+		return fmt.Errorf("%w (%d > %d)", ErrFragmentTooLarge, len(data), d.options.MaxFragmentedMsgSize)
+	}
+
+	if d.details.DomainBalance.Equal(dst) || d.details.DomainBalance.Contains(dst) {
+		return fmt.Errorf("dsvc: message size %d exceeds broker max payload %d and can't be fragmented to balance address", len(data), maxPayload)
+	}
+
+	fragID := d.fragIDs.Add(1)
+
+	// 以空片段实际编码一次，测得分片除 Data 外的开销（含加密和签名包装），据此计算每个分片可携带的最大片段长度；
+	// 片段、密文和签名三层包装的长度前缀会随 Data 增长，按负载上限预留。
+	probeBuf, err := d.fragEncoder.EncodeTo(dst, src, 0, gap.MsgFragment{FragID: fragID})
+	if err != nil {
+		return err
+	}
+	overhead := int64(len(probeBuf.Payload())) + 3*int64(binaryutil.SizeofUvarint(uint64(maxPayload)))
+	probeBuf.Release()
+
+	chunkSize := maxPayload - overhead
+	if chunkSize <= 0 {
+		return fmt.Errorf("dsvc: broker max payload %d too small to carry fragments", maxPayload)
+	}
+
+	total := (int64(len(data)) + chunkSize - 1) / chunkSize
+
+	for i := int64(0); i < total; i++ {
+		offset := i * chunkSize
+		end := min(offset+chunkSize, int64(len(data)))
+
+		fragBuf, err := d.fragEncoder.EncodeTo(dst, src, 0, gap.MsgFragment{
+			FragID:   fragID,
+			Index:    uint32(i),
+			Total:    uint32(total),
+			Offset:   uint32(offset),
+			TotalLen: uint32(len(data)),
+			Data:     data[offset:end],
+		})
+		if err != nil {
+			return err
+		}
+
+		err = d.broker.Publish(d.scope.Context(), dst, fragBuf.Payload())
+		fragBuf.Release()
+		if err != nil {
+			return err
+		}
+	}
+
+	metricFragmentedMessages.With(d.svcCtx.Name()).Inc()
+	return nil
+}
+
+// reassemble 将分片交给重组器；全部分片到齐时解码并返回原始消息包。
+func (d *_DistService) reassemble(e broker.Event, mp gap.MsgPacket) (gap.MsgPacket, bool) {
+	frag, ok := mp.Body.(*gap.MsgFragment)
+	if !ok {
+		metricDecodeFailures.With(d.svcCtx.Name()).Inc()
+		log.L(d.svcCtx).Error("unexpected fragment message type",
+			zap.String("topic", e.Topic),
+			zap.String("queue", e.Queue),
+			zap.String("type", fmt.Sprintf("%T", mp.Body)))
+		return gap.MsgPacket{}, false
+	}
+
+	src := mp.Head.Src.Addr
+
+	data, err := d.reassembler.Push(src, frag)
+	if err != nil {
+		metricReassemblyFailures.With(d.svcCtx.Name()).Inc()
+		log.L(d.svcCtx).Error("reassemble fragment failed",
+			zap.String("topic", e.Topic),
+			zap.String("queue", e.Queue),
+			zap.String("src", src),
+			zap.Uint64("frag_id", frag.FragID),
+			zap.Uint32("index", frag.Index),
+			zap.Uint32("total", frag.Total),
+			zap.Error(err))
+		return gap.MsgPacket{}, false
+	}
+	if data == nil {
+		return gap.MsgPacket{}, false
+	}
+
+	mp, err = d.decoder.DecodeFor(e.Topic, data)
+	if err == nil && mp.Head.MsgID == gap.MsgID_Fragment {
+		err = fmt.Errorf("%w: nested fragment", ErrMalformedFragment)
+	}
+	if err == nil {
+		err = d.checkFreshness(mp)
+	}
+	if err != nil {
+		d.countDecodeFailure(err)
+		log.L(d.svcCtx).Error("decode reassembled broker message failed",
+			zap.String("topic", e.Topic),
+			zap.String("queue", e.Queue),
+			zap.String("src", src),
+			zap.Error(err))
+		return gap.MsgPacket{}, false
+	}
+
+	return mp, true
+}
+
+func (d *_DistService) onReassemblyExpired(src string, fragID uint64, received, total uint32) {
+	metricReassemblyFailures.With(d.svcCtx.Name()).Inc()
+	log.L(d.svcCtx).Error("fragmented message expired before reassembly completed",
+		zap.String("src", src),
+		zap.Uint64("frag_id", fragID),
+		zap.Uint32("received", received),
+		zap.Uint32("total", total))
+}
+
+type _FragKey struct {
+	src    string
+	fragID uint64
+}
+
+type _Reassembly struct {
+	buf      []byte
+	received []bool
+	count    uint32
+	chunk    uint32
+	mem      int
+	timer    *time.Timer
+}
+
+// _Reassembler 按来源地址和分片组 ID 重组分片，并限制单条消息大小、内存总量及重组时限。
+type _Reassembler struct {
+	mutex     sync.Mutex
+	timeout   time.Duration
+	maxSize   int
+	memLimit  int
+	memUsed   int
+	pending   map[_FragKey]*_Reassembly
+	closed    bool
+	onExpired func(src string, fragID uint64, received, total uint32)
+}
+
+func newReassembler(timeout time.Duration, maxSize, memLimit int, onExpired func(src string, fragID uint64, received, total uint32)) *_Reassembler {
+	return &_Reassembler{
+		timeout:   timeout,
+		maxSize:   maxSize,
+		memLimit:  memLimit,
+		pending:   map[_FragKey]*_Reassembly{},
+		onExpired: onExpired,
+	}
+}
+
+// Push 复制分片内容到对应的重组缓冲区；全部分片到齐时返回完整消息包，否则返回 nil。
+// 重复分片会被忽略；分片布局错误或与同组分片不一致时丢弃整个分片组。
+// 每个分片至少携带 1 字节，分片数因此不超过消息包字节数，重组缓冲区与分片到达标记一并计入内存上限。
+func (r *_Reassembler) Push(src string, frag *gap.MsgFragment) ([]byte, error) {
+	if frag.Total == 0 || frag.Index >= frag.Total || frag.TotalLen == 0 || frag.Total > frag.TotalLen || len(frag.Data) <= 0 ||
+		uint64(frag.Offset)+uint64(len(frag.Data)) > uint64(frag.TotalLen) {
+		return nil, ErrMalformedFragment
+	}
+	if int64(frag.TotalLen) > int64(r.maxSize) {
+		return nil, fmt.Errorf("%w (%d > %d)", ErrFragmentTooLarge, frag.TotalLen, r.maxSize)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return nil, nil
+	}
+
+	key := _FragKey{src: src, fragID: frag.FragID}
+	ra, ok := r.pending[key]
+
+	chunk, laid := fragmentChunk(frag)
+	if !laid {
+		if ok {
+			r.drop(key, ra)
+		}
+		return nil, fmt.Errorf("%w: fragment %d/%d at offset %d with %d bytes breaks the layout", ErrMalformedFragment, frag.Index, frag.Total, frag.Offset, len(frag.Data))
+	}
+
+	if !ok {
+		mem := int(frag.TotalLen) + int(frag.Total)
+		if r.memUsed+mem > r.memLimit {
+			return nil, fmt.Errorf("%w (%d + %d > %d)", ErrReassemblyMemoryLimit, r.memUsed, mem, r.memLimit)
+		}
+		ra = &_Reassembly{
+			buf:      make([]byte, frag.TotalLen),
+			received: make([]bool, frag.Total),
+			chunk:    chunk,
+			mem:      mem,
+		}
+		ra.timer = time.AfterFunc(r.timeout, func() { r.expire(key, ra) })
+		r.pending[key] = ra
+		r.memUsed += ra.mem
+	} else if len(ra.buf) != int(frag.TotalLen) || len(ra.received) != int(frag.Total) || ra.chunk != chunk {
+		r.drop(key, ra)
+		return nil, fmt.Errorf("%w: inconsistent total or chunk size", ErrMalformedFragment)
+	}
+
+	if ra.received[frag.Index] {
+		return nil, nil
+	}
+
+	copy(ra.buf[frag.Offset:], frag.Data)
+	ra.received[frag.Index] = true
+	ra.count++
+
+	if ra.count < uint32(len(ra.received)) {
+		return nil, nil
+	}
+
+	r.drop(key, ra)
+
+	return ra.buf, nil
+}
+
+// fragmentChunk 按 publishFragments 的分片布局推算分片组的片段长度：除最后一个分片外各片段等长且依序首尾相接，
+// 最后一个分片止于消息包末尾。同组分片推算出的片段长度一致时，片段恰好无重叠、无空隙地覆盖整个消息包。
+func fragmentChunk(frag *gap.MsgFragment) (uint32, bool) {
+	n := uint64(len(frag.Data))
+
+	if frag.Index < frag.Total-1 {
+		// 非最后一个分片的长度即片段长度，分片数须与按该长度切分的结果一致。
+		return uint32(n), uint64(frag.Offset) == uint64(frag.Index)*n &&
+			(uint64(frag.TotalLen)+n-1)/n == uint64(frag.Total)
+	}
+
+	if uint64(frag.Offset)+n != uint64(frag.TotalLen) {
+		return 0, false
+	}
+	if frag.Index == 0 {
+		return uint32(n), frag.Offset == 0
+	}
+	if frag.Offset%frag.Index != 0 {
+		return 0, false
+	}
+	chunk := frag.Offset / frag.Index
+	return chunk, n <= uint64(chunk)
+}
+
+// Close 停止全部重组计时器并丢弃未完成的分片组。
+func (r *_Reassembler) Close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, ra := range r.pending {
+		r.drop(key, ra)
+	}
+	r.closed = true
+}
+
+func (r *_Reassembler) expire(key _FragKey, ra *_Reassembly) {
+	r.mutex.Lock()
+	if r.pending[key] != ra {
+		r.mutex.Unlock()
+		return
+	}
+	r.drop(key, ra)
+	r.mutex.Unlock()
+
+	if r.onExpired != nil {
+		r.onExpired(key.src, key.fragID, ra.count, uint32(len(ra.received)))
+	}
+}
+
+func (r *_Reassembler) drop(key _FragKey, ra *_Reassembly) {
+	ra.timer.Stop()
+	delete(r.pending, key)
+	r.memUsed -= ra.mem
+}
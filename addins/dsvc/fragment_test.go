@@ -0,0 +1,240 @@
+package dsvc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"git.golaxy.org/framework/net/gap"
+)
+
+// split 按 publishFragments 的布局将 data 切分为 chunk 字节的分片。
+func split(fragID uint64, data []byte, chunk int) []*gap.MsgFragment {
+	total := (len(data) + chunk - 1) / chunk
+	frags := make([]*gap.MsgFragment, 0, total)
+	for i := range total {
+		offset := i * chunk
+		frags = append(frags, &gap.MsgFragment{
+			FragID:   fragID,
+			Index:    uint32(i),
+			Total:    uint32(total),
+			Offset:   uint32(offset),
+			TotalLen: uint32(len(data)),
+			Data:     data[offset:min(offset+chunk, len(data))],
+		})
+	}
+	return frags
+}
+
+func TestReassemblerPush(t *testing.T) {
+	data := []byte("0123456789")
+
+	cases := []struct {
+		name  string
+		frags func() []*gap.MsgFragment
+		err   error // 最后一个分片的错误；为 nil 时期望重组出 data
+	}{
+		{"in order", func() []*gap.MsgFragment { return split(1, data, 4) }, nil},
+		{"out of order", func() []*gap.MsgFragment {
+			frags := split(1, data, 4)
+			return []*gap.MsgFragment{frags[2], frags[0], frags[1]}
+		}, nil},
+		{"single", func() []*gap.MsgFragment { return split(1, data, len(data)) }, nil},
+		{"duplicate", func() []*gap.MsgFragment {
+			frags := split(1, data, 4)
+			return []*gap.MsgFragment{frags[0], frags[0], frags[1], frags[1], frags[2]}
+		}, nil},
+		{"overlap", func() []*gap.MsgFragment {
+			frags := split(1, data, 4)
+			frags[1].Offset = 3
+			return frags[:2]
+		}, ErrMalformedFragment},
+		{"gap", func() []*gap.MsgFragment {
+			frags := split(1, data, 4)
+			frags[1].Offset = 5
+			frags[1].Data = data[5:8]
+			return frags[:2]
+		}, ErrMalformedFragment},
+		{"overlap and gap cancel out", func() []*gap.MsgFragment {
+			// 片段 1 声称 [2,6) 而不是 [4,8)：字节数之和不变，但 [8,10) 无法被覆盖。
+			frags := split(1, data, 4)
+			frags[1].Offset = 2
+			frags[1].Data = data[2:6]
+			return frags[:2]
+		}, ErrMalformedFragment},
+		{"inconsistent chunk", func() []*gap.MsgFragment {
+			frags := split(1, data, 4)
+			frags[2].Offset = 9
+			frags[2].Data = data[9:]
+			return []*gap.MsgFragment{frags[0], frags[2]}
+		}, ErrMalformedFragment},
+		{"inconsistent total", func() []*gap.MsgFragment {
+			frags := split(1, data, 4)
+			frags[1].TotalLen = 11
+			return frags[:2]
+		}, ErrMalformedFragment},
+		{"more fragments than bytes", func() []*gap.MsgFragment {
+			return []*gap.MsgFragment{{FragID: 1, Index: 0, Total: 1 << 30, TotalLen: 4, Data: data[:1]}}
+		}, ErrMalformedFragment},
+		{"empty data", func() []*gap.MsgFragment {
+			return []*gap.MsgFragment{{FragID: 1, Index: 0, Total: 1, TotalLen: 4}}
+		}, ErrMalformedFragment},
+		{"index out of range", func() []*gap.MsgFragment {
+			return []*gap.MsgFragment{{FragID: 1, Index: 1, Total: 1, TotalLen: 1, Data: data[:1]}}
+		}, ErrMalformedFragment},
+		{"data past end", func() []*gap.MsgFragment {
+			return []*gap.MsgFragment{{FragID: 1, Index: 0, Total: 1, Offset: 1, TotalLen: 1, Data: data[:1]}}
+		}, ErrMalformedFragment},
+		{"too large", func() []*gap.MsgFragment { return split(1, make([]byte, 2048), 1024)[:1] }, ErrFragmentTooLarge},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := newReassembler(time.Minute, 1024, 1024, nil)
+			defer r.Close()
+
+			frags := c.frags()
+
+			var got []byte
+			var err error
+			for i, frag := range frags {
+				got, err = r.Push("node-a", frag)
+				if i < len(frags)-1 && (err != nil || got != nil) {
+					t.Fatalf("fragment %d: unexpected result %q, %v", i, got, err)
+				}
+			}
+
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("expected %v, got %q, %v", c.err, got, err)
+				}
+				if len(r.pending) != 0 || r.memUsed != 0 {
+					t.Fatalf("rejected group not released: %d pending, %d bytes", len(r.pending), r.memUsed)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, data) {
+				t.Fatalf("reassembled %q, %v, want %q", got, err, data)
+			}
+			if len(r.pending) != 0 || r.memUsed != 0 {
+				t.Fatalf("completed group not released: %d pending, %d bytes", len(r.pending), r.memUsed)
+			}
+		})
+	}
+}
+
+func TestReassemblerSeparatesSources(t *testing.T) {
+	r := newReassembler(time.Minute, 1024, 1024, nil)
+	defer r.Close()
+
+	a, b := split(1, []byte("aaaa"), 2), split(1, []byte("bbbb"), 2)
+
+	for _, push := range []struct {
+		src  string
+		frag *gap.MsgFragment
+	}{{"node-a", a[0]}, {"node-b", b[0]}, {"node-b", b[1]}} {
+		if got, err := r.Push(push.src, push.frag); err != nil || (got != nil && string(got) != "bbbb") {
+			t.Fatalf("push from %s: %q, %v", push.src, got, err)
+		}
+	}
+
+	if got, err := r.Push("node-a", a[1]); err != nil || string(got) != "aaaa" {
+		t.Fatalf("reassembled %q, %v", got, err)
+	}
+}
+
+func TestReassemblerMemoryLimit(t *testing.T) {
+	// 每组消耗 8 字节缓冲区与 2 个分片到达标记。
+	r := newReassembler(time.Minute, 1024, 20, nil)
+	defer r.Close()
+
+	first, second, third := split(1, make([]byte, 8), 4), split(2, make([]byte, 8), 4), split(3, make([]byte, 8), 4)
+
+	if _, err := r.Push("node-a", first[0]); err != nil {
+		t.Fatalf("first group: %v", err)
+	}
+	if _, err := r.Push("node-a", second[0]); err != nil {
+		t.Fatalf("second group: %v", err)
+	}
+	if r.memUsed != 20 {
+		t.Fatalf("memory used = %d, want 20", r.memUsed)
+	}
+	if _, err := r.Push("node-a", third[0]); !errors.Is(err, ErrReassemblyMemoryLimit) {
+		t.Fatalf("expected ErrReassemblyMemoryLimit, got %v", err)
+	}
+
+	if _, err := r.Push("node-a", first[1]); err != nil {
+		t.Fatalf("completing first group: %v", err)
+	}
+	if _, err := r.Push("node-a", third[0]); err != nil {
+		t.Fatalf("memory not released after completion: %v", err)
+	}
+
+	// 分片到达标记同样计入上限：10 字节的消息拆成 10 个分片需要 20 字节。
+	small := newReassembler(time.Minute, 1024, 19, nil)
+	defer small.Close()
+
+	if _, err := small.Push("node-a", split(4, make([]byte, 10), 1)[0]); !errors.Is(err, ErrReassemblyMemoryLimit) {
+		t.Fatalf("expected ErrReassemblyMemoryLimit for fragment bookkeeping, got %v", err)
+	}
+}
+
+func TestReassemblerExpiry(t *testing.T) {
+	type expired struct {
+		src      string
+		fragID   uint64
+		received uint32
+		total    uint32
+	}
+	ch := make(chan expired, 2)
+
+	r := newReassembler(20*time.Millisecond, 1024, 1024, func(src string, fragID uint64, received, total uint32) {
+		ch <- expired{src, fragID, received, total}
+	})
+	defer r.Close()
+
+	frags := split(7, []byte("0123456789"), 4)
+	if _, err := r.Push("node-a", frags[0]); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	select {
+	case got := <-ch:
+		if got != (expired{"node-a", 7, 1, 3}) {
+			t.Fatalf("unexpected expiry %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("group did not expire")
+	}
+
+	r.mutex.Lock()
+	pending, memUsed := len(r.pending), r.memUsed
+	r.mutex.Unlock()
+	if pending != 0 || memUsed != 0 {
+		t.Fatalf("expired group not released: %d pending, %d bytes", pending, memUsed)
+	}
+
+	// 过期后迟到的分片开始新的分片组，不会与已丢弃的分片拼接。
+	if got, err := r.Push("node-a", frags[1]); err != nil || got != nil {
+		t.Fatalf("late fragment: %q, %v", got, err)
+	}
+}
+
+func TestReassemblerClose(t *testing.T) {
+	r := newReassembler(time.Minute, 1024, 1024, nil)
+
+	frags := split(1, []byte("0123"), 2)
+	if _, err := r.Push("node-a", frags[0]); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	r.Close()
+
+	if got, err := r.Push("node-a", frags[1]); err != nil || got != nil {
+		t.Fatalf("push after Close: %q, %v", got, err)
+	}
+	if len(r.pending) != 0 || r.memUsed != 0 {
+		t.Fatalf("Close did not release groups: %d pending, %d bytes", len(r.pending), r.memUsed)
+	}
+}
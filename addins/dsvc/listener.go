@@ -28,6 +28,7 @@ import (
 	"git.golaxy.org/framework/addins/broker"
 	"git.golaxy.org/framework/addins/log"
 	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/codec"
 	"go.uber.org/zap"
 )
 
@@ -103,8 +104,12 @@ func (d *_DistService) addListener(ctx context.Context, handler MsgHandler) (asy
 }
 
 func (d *_DistService) handleEvent(e broker.Event) {
-	mp, err := d.decoder.Decode(e.Message)
+	mp, err := d.decoder.DecodeFor(e.Topic, e.Message)
+	if err == nil {
+		err = d.checkFreshness(mp)
+	}
 	if err != nil {
+		d.countDecodeFailure(err)
 		log.L(d.svcCtx).Error("decode broker message failed",
 			zap.String("topic", e.Topic),
 			zap.String("queue", e.Queue),
@@ -112,17 +117,37 @@ func (d *_DistService) handleEvent(e broker.Event) {
 		return
 	}
 
+	// 分片在全部到齐后还原为原始消息包再分发。
+	if mp.Head.MsgID == gap.MsgID_Fragment {
+		var ok bool
+		if mp, ok = d.reassemble(e, mp); !ok {
+			return
+		}
+	}
+
 	msg := _BrokerMsg{
 		topic:     e.Topic,
 		queue:     e.Queue,
 		msgPacket: mp,
 	}
 
+	metricMessagesReceived.With(d.svcCtx.Name()).Inc()
+
 	dropped := d.listeners.Broadcast(msg)
 	if dropped > 0 {
+		metricDroppedDeliveries.With(d.svcCtx.Name()).Add(float64(dropped))
 		log.L(d.svcCtx).Error("broker message deliveries dropped due to listener backpressure",
 			zap.String("topic", e.Topic),
 			zap.String("queue", e.Queue),
 			zap.Int("dropped", dropped))
 	}
 }
+
+// countDecodeFailure 按原因统计解码失败；签名校验失败与过期的签名消息单独计数。
+func (d *_DistService) countDecodeFailure(err error) {
+	if errors.Is(err, codec.ErrAuthenticate) || errors.Is(err, ErrStaleMsg) {
+		metricAuthFailures.With(d.svcCtx.Name()).Inc()
+		return
+	}
+	metricDecodeFailures.With(d.svcCtx.Name()).Inc()
+}
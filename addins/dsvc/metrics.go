@@ -0,0 +1,43 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package dsvc
+
+import "git.golaxy.org/framework/utils/metrics"
+
+var (
+	metricPendingCorrelations = metrics.Default().Gauge("golaxy_dsvc_pending_correlations",
+		"Requests awaiting a correlated reply.", "service", "node")
+	metricMessagesSent = metrics.Default().Counter("golaxy_dsvc_messages_sent_total",
+		"GAP messages published to the broker.", "service")
+	metricSendFailures = metrics.Default().Counter("golaxy_dsvc_send_failures_total",
+		"GAP messages that failed to encode or publish.", "service")
+	metricMessagesReceived = metrics.Default().Counter("golaxy_dsvc_messages_received_total",
+		"GAP messages received from the broker.", "service")
+	metricDecodeFailures = metrics.Default().Counter("golaxy_dsvc_decode_failures_total",
+		"Broker messages that failed to decode as GAP.", "service")
+	metricDroppedDeliveries = metrics.Default().Counter("golaxy_dsvc_dropped_deliveries_total",
+		"Received messages dropped due to listener backpressure.", "service")
+	metricFragmentedMessages = metrics.Default().Counter("golaxy_dsvc_fragmented_messages_total",
+		"GAP messages split into fragments because they exceed the broker max payload.", "service")
+	metricAuthFailures = metrics.Default().Counter("golaxy_dsvc_auth_failures_total",
+		"Broker messages rejected for a missing or invalid signature or a stale timestamp.", "service")
+	metricReassemblyFailures = metrics.Default().Counter("golaxy_dsvc_reassembly_failures_total",
+		"Fragmented messages rejected or expired before reassembly completed.", "service")
+)
@@ -0,0 +1,309 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package dsvc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/framework/addins/discovery"
+	"git.golaxy.org/framework/addins/log"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/codec"
+	"go.uber.org/zap"
+)
+
+// NodeMetaEd25519PublicKey 是服务发现节点元数据中发布 Ed25519 签名公钥的键，值格式为 "<keyID>:<base64 公钥>"。
+const NodeMetaEd25519PublicKey = "gap.ed25519"
+
+var (
+	// ErrStaleMsg 表示签名消息的来源时间戳超出允许的时钟偏差，可能是重放。
+	ErrStaleMsg = errors.New("dsvc: stale signed message")
+)
+
+// nodeKeyResyncBackoff 是节点公钥监听中断后重新同步服务发现的间隔。
+const nodeKeyResyncBackoff = time.Second
+
+// initSecurity 根据选项创建签名与加密模块，并配置到编解码器。
+func (d *_DistService) initSecurity() {
+	switch {
+	case d.options.HMACKeyring != nil && d.options.Ed25519PrivateKey != nil:
+		log.L(d.svcCtx).Panic("HMAC signing and Ed25519 signing are mutually exclusive")
+	case d.options.HMACKeyring != nil:
+		d.authentication = codec.NewHMACAuthentication(d.options.HMACHash, d.options.HMACKeyring)
+	case d.options.Ed25519PrivateKey != nil:
+		d.nodeKeys = &_NodeKeys{}
+		d.authentication = codec.NewEd25519Authentication(d.options.Ed25519KeyID, d.options.Ed25519PrivateKey, d.resolveNodeKey)
+	}
+
+	if d.options.EncryptionKeyring != nil {
+		d.encryption = codec.NewAESGCMEncryption(d.options.EncryptionKeyring)
+	}
+}
+
+// nodeMeta 返回注册到服务发现的节点元数据；启用 Ed25519 签名时附带本节点公钥，不修改选项中的 map。
+func (d *_DistService) nodeMeta() map[string]string {
+	if d.options.Ed25519PrivateKey == nil {
+		return d.options.Meta
+	}
+	meta := maps.Clone(d.options.Meta)
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	publicKey := d.options.Ed25519PrivateKey.Public().(ed25519.PublicKey)
+	meta[NodeMetaEd25519PublicKey] = d.options.Ed25519KeyID + ":" + base64.StdEncoding.EncodeToString(publicKey)
+	return meta
+}
+
+// checkFreshness 拒绝来源时间戳超出允许时钟偏差的签名消息，限制被截获消息的重放窗口。
+func (d *_DistService) checkFreshness(mp gap.MsgPacket) error {
+	if !mp.Head.Flags.Is(gap.Flag_Signed) {
+		return nil
+	}
+	skew := time.Since(time.UnixMilli(mp.Head.Src.Timestamp))
+	if skew > d.options.MaxClockSkew || skew < -d.options.MaxClockSkew {
+		return fmt.Errorf("%w (skew %s)", ErrStaleMsg, skew)
+	}
+	return nil
+}
+
+type _NodeKey struct {
+	svc       string
+	keyID     string
+	publicKey ed25519.PublicKey
+}
+
+// errNoNodeKey 表示节点未发布 Ed25519 公钥。
+var errNoNodeKey = errors.New("node has no published Ed25519 public key")
+
+// parseNodeKey 解析 svc 下节点在元数据中发布的 Ed25519 公钥。
+func parseNodeKey(svc string, meta map[string]string) (_NodeKey, error) {
+	value, ok := meta[NodeMetaEd25519PublicKey]
+	if !ok {
+		return _NodeKey{}, errNoNodeKey
+	}
+
+	keyID, encoded, ok := strings.Cut(value, ":")
+	if !ok || keyID == "" {
+		return _NodeKey{}, errors.New("node published a malformed Ed25519 public key")
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return _NodeKey{}, errors.New("node published an invalid Ed25519 public key")
+	}
+
+	return _NodeKey{svc: svc, keyID: keyID, publicKey: publicKey}, nil
+}
+
+// _NodeKeys 保存从服务发现同步的节点公钥，按节点单播地址索引；并发安全。
+// 验证签名时只查表，不在解码路径上查询服务发现。
+type _NodeKeys struct {
+	mutex sync.RWMutex
+	keys  map[string]_NodeKey
+}
+
+// resolve 返回 src 所声明节点发布的 Ed25519 公钥；公钥必须属于 src.Svc 下单播地址为 src.Addr 的节点。
+// 尚未同步到公钥的节点返回错误，其消息被丢弃。
+func (k *_NodeKeys) resolve(src gap.Origin, keyID string) (ed25519.PublicKey, error) {
+	k.mutex.RLock()
+	key, ok := k.keys[src.Addr]
+	k.mutex.RUnlock()
+
+	if !ok {
+		return nil, errors.New("node key not found in discovery")
+	}
+	if key.svc != src.Svc {
+		return nil, fmt.Errorf("node belongs to service %q", key.svc)
+	}
+	if key.keyID != keyID {
+		return nil, fmt.Errorf("node published key %q", key.keyID)
+	}
+	return key.publicKey, nil
+}
+
+// reset 以全量快照替换公钥表。
+func (k *_NodeKeys) reset(keys map[string]_NodeKey) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.keys = keys
+}
+
+// store 记录 addr 处节点的公钥，替换该节点此前发布的公钥。
+func (k *_NodeKeys) store(addr string, key _NodeKey) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if k.keys == nil {
+		k.keys = map[string]_NodeKey{}
+	}
+	k.keys[addr] = key
+}
+
+// remove 移除 addr 处属于 svc 的节点公钥。
+func (k *_NodeKeys) remove(addr, svc string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if key, ok := k.keys[addr]; ok && key.svc == svc {
+		delete(k.keys, addr)
+	}
+}
+
+// resolveNodeKey 返回 src 所声明节点的 Ed25519 公钥；本节点直接使用自身私钥对应的公钥，其他节点查询公钥表。
+func (d *_DistService) resolveNodeKey(src gap.Origin, keyID string) (ed25519.PublicKey, error) {
+	if src.Addr == d.details.LocalAddr {
+		if src.Svc != d.svcCtx.Name() || keyID != d.options.Ed25519KeyID {
+			return nil, errors.New("origin doesn't match local node")
+		}
+		return d.options.Ed25519PrivateKey.Public().(ed25519.PublicKey), nil
+	}
+	return d.nodeKeys.resolve(src, keyID)
+}
+
+// watchNodeKeys 同步全部节点公钥并持续监听节点变化；监听中断后重新同步，直到 add-in 的内部作用域关闭。
+// 首次同步在调用方同步完成，使订阅前已知的节点发来的消息可立即验证。
+func (d *_DistService) watchNodeKeys() {
+	stopped, err := d.syncNodeKeys(d.scope.Context())
+	if err != nil {
+		log.L(d.svcCtx).Error("synchronizing node keys failed", zap.Error(err))
+	}
+
+	future := async.SpawnVoid(d.scope, func(ctx context.Context) {
+		for {
+			if err == nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-stopped.Done():
+				}
+				log.L(d.svcCtx).Warn("watching node keys stopped, resynchronizing")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(nodeKeyResyncBackoff):
+			}
+
+			stopped, err = d.syncNodeKeys(ctx)
+			if err != nil {
+				log.L(d.svcCtx).Error("synchronizing node keys failed", zap.Error(err))
+			}
+		}
+	})
+	future.OnComplete(func(ret async.Result) {
+		if ret.Error != nil && !errors.Is(ret.Error, async.ErrScopeClosed) {
+			log.L(d.svcCtx).Error("watching node keys task failed", zap.Error(ret.Error))
+		}
+	})
+}
+
+// syncNodeKeys 以服务发现中全部节点的快照替换公钥表，并从快照的下一修订号开始监听节点变化，
+// 避免遗漏查询与监听之间的变化；返回的 Signal 在监听结束后完成。
+func (d *_DistService) syncNodeKeys(ctx context.Context) (stopped async.Signal, err error) {
+	services, err := d.registry.List(ctx)
+	if err != nil {
+		return stopped, err
+	}
+
+	var revision int64
+	keys := map[string]_NodeKey{}
+
+	for _, svc := range services {
+		revision = max(revision, svc.Revision)
+
+		for i := range svc.Nodes {
+			addr, key, err := d.nodeKey(svc.Name, &svc.Nodes[i])
+			if err != nil {
+				continue
+			}
+			keys[addr] = key
+		}
+	}
+
+	d.nodeKeys.reset(keys)
+
+	var revisions []int64
+	if revision > 0 {
+		revisions = append(revisions, revision+1)
+	}
+
+	stopped, err = d.registry.WatchHandler(ctx, "", generic.CastDelegateVoid1(d.handleNodeKeyEvent), revisions...)
+	if err != nil {
+		return stopped, err
+	}
+
+	log.L(d.svcCtx).Debug("watching node keys started",
+		zap.Int("nodes", len(keys)),
+		zap.Int64("revision", revision))
+	return stopped, nil
+}
+
+// handleNodeKeyEvent 按节点注册、更新和注销事件更新公钥表；节点更新后不再发布公钥时移除其公钥。
+func (d *_DistService) handleNodeKeyEvent(event discovery.Event) {
+	if event.Type == discovery.EventType_Error {
+		log.L(d.svcCtx).Warn("watching node keys interrupted", zap.Error(event.Error))
+		return
+	}
+
+	if event.Service == nil {
+		return
+	}
+
+	for i := range event.Service.Nodes {
+		node := &event.Service.Nodes[i]
+
+		addr, key, err := d.nodeKey(event.Service.Name, node)
+		if event.Type == discovery.EventType_Delete || err != nil {
+			if addr != "" {
+				d.nodeKeys.remove(addr, event.Service.Name)
+			}
+			continue
+		}
+
+		d.nodeKeys.store(addr, key)
+	}
+}
+
+// nodeKey 返回节点的单播地址及其发布的公钥；节点公钥无效时记录警告。
+// 单播地址由节点 ID 推算，而非采用节点自行发布的地址，因此节点无法为其他节点的地址发布公钥。
+func (d *_DistService) nodeKey(svc string, node *discovery.Node) (string, _NodeKey, error) {
+	addr, err := d.details.MakeNodeAddr(node.ID)
+	if err != nil {
+		return "", _NodeKey{}, err
+	}
+
+	key, err := parseNodeKey(svc, node.Meta)
+	if err != nil && !errors.Is(err, errNoNodeKey) {
+		log.L(d.svcCtx).Warn("ignoring node key",
+			zap.String("service", svc),
+			zap.String("node", node.ID.String()),
+			zap.Error(err))
+	}
+	return addr, key, err
+}
@@ -0,0 +1,128 @@
+package dsvc
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"git.golaxy.org/framework/net/gap"
+)
+
+func publishedKey(keyID string, publicKey ed25519.PublicKey) map[string]string {
+	return map[string]string{NodeMetaEd25519PublicKey: keyID + ":" + base64.StdEncoding.EncodeToString(publicKey)}
+}
+
+func TestParseNodeKey(t *testing.T) {
+	publicKey, _, _ := ed25519.GenerateKey(nil)
+
+	cases := []struct {
+		name string
+		meta map[string]string
+		err  bool
+	}{
+		{"valid", publishedKey("k1", publicKey), false},
+		{"not published", map[string]string{"other": "value"}, true},
+		{"nil meta", nil, true},
+		{"no separator", map[string]string{NodeMetaEd25519PublicKey: base64.StdEncoding.EncodeToString(publicKey)}, true},
+		{"empty key id", publishedKey("", publicKey), true},
+		{"bad base64", map[string]string{NodeMetaEd25519PublicKey: "k1:!!"}, true},
+		{"short key", publishedKey("k1", publicKey[:16]), true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			key, err := parseNodeKey("svc", c.meta)
+			if c.err {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", key)
+				}
+				return
+			}
+			if err != nil || key.svc != "svc" || key.keyID != "k1" || !bytes.Equal(key.publicKey, publicKey) {
+				t.Fatalf("parseNodeKey = %+v, %v", key, err)
+			}
+		})
+	}
+}
+
+func TestNodeKeysResolve(t *testing.T) {
+	publicKey, _, _ := ed25519.GenerateKey(nil)
+
+	var keys _NodeKeys
+	keys.reset(map[string]_NodeKey{"node-a": {svc: "svc", keyID: "k1", publicKey: publicKey}})
+
+	cases := []struct {
+		name  string
+		src   gap.Origin
+		keyID string
+		err   bool
+	}{
+		{"known", gap.Origin{Svc: "svc", Addr: "node-a"}, "k1", false},
+		{"unknown node", gap.Origin{Svc: "svc", Addr: "node-b"}, "k1", true},
+		{"other service", gap.Origin{Svc: "other", Addr: "node-a"}, "k1", true},
+		{"other key id", gap.Origin{Svc: "svc", Addr: "node-a"}, "k2", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := keys.resolve(c.src, c.keyID)
+			if c.err {
+				if err == nil {
+					t.Fatalf("expected error, got %x", got)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, publicKey) {
+				t.Fatalf("resolve = %x, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestNodeKeysUpdates(t *testing.T) {
+	oldKey, _, _ := ed25519.GenerateKey(nil)
+	newKey, _, _ := ed25519.GenerateKey(nil)
+	src := gap.Origin{Svc: "svc", Addr: "node-a"}
+
+	var keys _NodeKeys
+
+	if _, err := keys.resolve(src, "k1"); err == nil {
+		t.Fatal("empty table resolved a key")
+	}
+
+	keys.store("node-a", _NodeKey{svc: "svc", keyID: "k1", publicKey: oldKey})
+	if got, err := keys.resolve(src, "k1"); err != nil || !bytes.Equal(got, oldKey) {
+		t.Fatalf("resolve = %x, %v", got, err)
+	}
+
+	// 节点以新密钥重启后，旧密钥 ID 不再被接受。
+	keys.store("node-a", _NodeKey{svc: "svc", keyID: "k2", publicKey: newKey})
+	if _, err := keys.resolve(src, "k1"); err == nil {
+		t.Fatal("rotated key still accepted")
+	}
+	if got, err := keys.resolve(src, "k2"); err != nil || !bytes.Equal(got, newKey) {
+		t.Fatalf("resolve = %x, %v", got, err)
+	}
+
+	// 其他服务的注销事件不移除同一地址上的公钥。
+	keys.remove("node-a", "other")
+	if _, err := keys.resolve(src, "k2"); err != nil {
+		t.Fatalf("remove for another service dropped the key: %v", err)
+	}
+
+	keys.remove("node-a", "svc")
+	if _, err := keys.resolve(src, "k2"); err == nil {
+		t.Fatal("removed key still accepted")
+	}
+
+	// 重新同步以快照替换整张表。
+	keys.store("node-b", _NodeKey{svc: "svc", keyID: "k1", publicKey: oldKey})
+	keys.reset(map[string]_NodeKey{"node-a": {svc: "svc", keyID: "k1", publicKey: oldKey}})
+	if _, err := keys.resolve(gap.Origin{Svc: "svc", Addr: "node-b"}, "k1"); err == nil {
+		t.Fatal("reset kept a key missing from the snapshot")
+	}
+	if _, err := keys.resolve(src, "k1"); err != nil {
+		t.Fatalf("resolve after reset failed: %v", err)
+	}
+}
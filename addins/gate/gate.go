@@ -167,6 +167,10 @@ func (g *_Gate) Init(svcCtx service.Context) {
 	if g.tcpListener == nil && g.wsListener == nil {
 		log.L(svcCtx).Panic("no address need to listen")
 	}
+
+	metricSessions.With(svcCtx.Name(), svcCtx.ID().String()).SetFunc(func() float64 {
+		return float64(g.Count())
+	})
 }
 
 // Shut 以服务关闭原因为全部会话发起终止，等待受管任务退出后关闭监听器。
@@ -186,6 +190,8 @@ func (g *_Gate) Shut(svcCtx service.Context) {
 	if g.wsListener != nil {
 		g.wsListener.Close()
 	}
+
+	metricSessions.Delete(svcCtx.Name(), svcCtx.ID().String())
 }
 
 // Get 按会话 ID 查询当前尚未过期的会话。
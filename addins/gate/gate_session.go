@@ -77,8 +77,11 @@ func (g *_Gate) establishSession(conn net.Conn) (*_Session, bool) {
 		zap.String("local", conn.LocalAddr().String()),
 		zap.String("remote", conn.RemoteAddr().String()))
 
+	metricSessionsEstablished.With(g.svcCtx.Name()).Inc()
+
 	dropped := g.sessionWatcher.Broadcast(session)
 	if dropped > 0 {
+		metricDroppedDeliveries.With(g.svcCtx.Name(), "session_watcher").Add(float64(dropped))
 		log.L(g.svcCtx).Error("session established deliveries dropped due to watcher backpressure",
 			zap.String("session_id", session.ID().String()),
 			zap.String("user_id", session.UserID()),
@@ -146,6 +146,7 @@ loop:
 func (io *_SessionIO) handlePayload(event transport.Event[*gtp.MsgPayload]) {
 	dropped := io.dataListeners.Broadcast(event.Msg.Data)
 	if dropped > 0 {
+		metricDroppedDeliveries.With(io.session.gate.svcCtx.Name(), "payload").Add(float64(dropped))
 		log.L(io.session.gate.svcCtx).Error("received payload deliveries dropped due to listener backpressure",
 			zap.String("session_id", io.session.ID().String()),
 			zap.Uint32("seq", event.Seq),
@@ -157,6 +158,7 @@ func (io *_SessionIO) handlePayload(event transport.Event[*gtp.MsgPayload]) {
 func (io *_SessionIO) handleEvent(event transport.IEvent) {
 	dropped := io.eventListeners.Broadcast(event)
 	if dropped > 0 {
+		metricDroppedDeliveries.With(io.session.gate.svcCtx.Name(), "event").Add(float64(dropped))
 		log.L(io.session.gate.svcCtx).Error("received event deliveries dropped due to listener backpressure",
 			zap.String("session_id", io.session.ID().String()),
 			zap.Uint32("seq", event.Seq),
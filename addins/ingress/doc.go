@@ -0,0 +1,27 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+// Package ingress 提供 HTTP/JSON 入口 add-in，将 HTTP 请求映射为服务或实体 RPC。
+//
+// 路由 POST /rpc/{service}/{addIn}/{method} 调用服务插件方法，
+// POST /entity/{id}/{comp}/{method} 调用实体组件方法；请求体为参数的 JSON 数组，
+// 响应体以 JSON 返回结果。调用前先经可插拔的 Authenticator 鉴权，再校验目标服务在
+// Services 允许列表中（默认仅 ingress 所在服务），最后使用与 rpcpcsr 相同的 PermissionValidator
+// 校验权限，HTTP 调用方在调用链中视为客户端。
+package ingress
@@ -0,0 +1,112 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package ingress
+
+import (
+	"errors"
+	"net/http"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/option"
+	"git.golaxy.org/framework/addins/log"
+	"go.uber.org/zap"
+)
+
+// IIngress 提供 HTTP/JSON 入口的请求处理器。
+type IIngress interface {
+	// Handler 返回处理 RPC 路由的 http.Handler，可挂载到外部 HTTP 服务。
+	Handler() http.Handler
+}
+
+func newIngress(settings ...option.Setting[IngressOptions]) IIngress {
+	return &_Ingress{
+		options: option.New(With.Default(), settings...),
+	}
+}
+
+type _Ingress struct {
+	svcCtx   service.Context
+	options  IngressOptions
+	logger   *zap.Logger
+	mux      *http.ServeMux
+	listener *http.Server
+}
+
+// Init 注册 RPC 路由；配置了监听地址时启动 HTTP 服务，监听失败会 panic。
+func (ig *_Ingress) Init(svcCtx service.Context) {
+	log.L(svcCtx).Info("initializing add-in", zap.String("name", AddIn.Name))
+
+	ig.svcCtx = svcCtx
+	ig.logger = log.L(svcCtx)
+	ig.mux = ig.routes()
+
+	if ig.options.Address == "" {
+		return
+	}
+
+	if ig.options.Authenticator == nil {
+		ig.logger.Warn("listener(http) accepts anonymous requests, set an authenticator to identify callers", zap.String("address", ig.options.Address))
+	}
+
+	listener := &http.Server{
+		Addr:              ig.options.Address,
+		Handler:           ig.mux,
+		TLSConfig:         ig.options.TLSConfig,
+		ReadHeaderTimeout: ig.options.ReadHeaderTimeout,
+		ReadTimeout:       ig.options.ReadTimeout,
+	}
+	ig.listener = listener
+
+	ig.logger.Info("listener(http) started", zap.String("address", listener.Addr))
+
+	go func() {
+		var err error
+		if listener.TLSConfig != nil {
+			err = listener.ListenAndServeTLS("", "")
+		} else {
+			err = listener.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ig.logger.Panic("listener(http) was interrupted", zap.String("address", listener.Addr), zap.Error(err))
+		}
+	}()
+}
+
+// Shut 关闭 HTTP 服务。
+func (ig *_Ingress) Shut(svcCtx service.Context) {
+	log.L(svcCtx).Info("shutting down add-in", zap.String("name", AddIn.Name))
+
+	if ig.listener != nil {
+		ig.listener.Close()
+	}
+}
+
+// Handler 返回处理 RPC 路由的 http.Handler，可挂载到外部 HTTP 服务。
+func (ig *_Ingress) Handler() http.Handler {
+	return ig.mux
+}
+
+// routes 创建注册了 RPC 路由的 ServeMux。
+func (ig *_Ingress) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+ig.options.PathPrefix+"/rpc/{service}/{addIn}/{method}", ig.handleServiceRPC)
+	mux.HandleFunc("POST "+ig.options.PathPrefix+"/entity/{id}/{comp}/{method}", ig.handleEntityRPC)
+	return mux
+}
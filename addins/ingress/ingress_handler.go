@@ -0,0 +1,308 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/discovery"
+	"git.golaxy.org/framework/addins/gate"
+	"git.golaxy.org/framework/addins/rpc"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap/variant"
+	"go.uber.org/zap"
+)
+
+// ErrUnauthorized 表示 HTTP 请求未通过 Authenticator 鉴权。
+var ErrUnauthorized = errors.New("ingress: unauthorized")
+
+// _Request 是解析后的一次 HTTP RPC 请求。
+type _Request struct {
+	args   []any
+	typed  bool
+	oneway bool
+}
+
+// handleServiceRPC 处理 POST /rpc/{service}/{addIn}/{method}；查询参数 node 指定目标节点，否则负载均衡选择节点。
+// 指定的节点必须属于 service。
+func (ig *_Ingress) handleServiceRPC(w http.ResponseWriter, r *http.Request) {
+	service, addIn, method := r.PathValue("service"), r.PathValue("addIn"), r.PathValue("method")
+
+	cp := callpath.CallPath{
+		TargetKind: callpath.Service,
+		Script:     addIn,
+		Method:     method,
+	}
+
+	req, ok := ig.parseRequest(w, r, service, cp)
+	if !ok {
+		return
+	}
+
+	proxied := rpc.ProxyService(ig.svcCtx)
+	nodeID := uid.From(r.URL.Query().Get("node"))
+
+	// 节点地址不区分服务，须确认节点属于已校验的服务
+	if nodeID != uid.Nil {
+		if _, err := discovery.AddIn.Require(ig.svcCtx).GetNode(r.Context(), service, nodeID); err != nil {
+			ig.replyError(w, r, http.StatusNotFound, fmt.Errorf("%w: %w", rpcpcsr.ErrServiceNodeNotFound, err))
+			return
+		}
+	}
+
+	if req.oneway {
+		var err error
+		if nodeID == uid.Nil {
+			err = proxied.BalanceOnewayRPC(service, addIn, method, req.args...)
+		} else {
+			err = proxied.OnewayRPC(nodeID, addIn, method, req.args...)
+		}
+		ig.replyOneway(w, r, err)
+		return
+	}
+
+	var future async.Future
+	if nodeID == uid.Nil {
+		future = proxied.BalanceRPC(service, addIn, method, req.args...)
+	} else {
+		future = proxied.RPC(nodeID, addIn, method, req.args...)
+	}
+	ig.replyFuture(w, r, req, future)
+}
+
+// handleEntityRPC 处理 POST /entity/{id}/{comp}/{method}；查询参数 service 指定实体所在服务，默认为当前服务。
+func (ig *_Ingress) handleEntityRPC(w http.ResponseWriter, r *http.Request) {
+	id, comp, method := uid.From(r.PathValue("id")), r.PathValue("comp"), r.PathValue("method")
+	if id == uid.Nil {
+		ig.replyError(w, r, http.StatusBadRequest, fmt.Errorf("ingress: invalid entity id %q", r.PathValue("id")))
+		return
+	}
+
+	service := r.URL.Query().Get("service")
+	if service == "" {
+		service = ig.svcCtx.Name()
+	}
+
+	cp := callpath.CallPath{
+		TargetKind: callpath.Entity,
+		ID:         id,
+		Script:     comp,
+		Method:     method,
+	}
+
+	req, ok := ig.parseRequest(w, r, service, cp)
+	if !ok {
+		return
+	}
+
+	proxied := rpc.ProxyEntity(ig.svcCtx, id)
+
+	if req.oneway {
+		ig.replyOneway(w, r, proxied.OnewayRPC(service, comp, method, req.args...))
+		return
+	}
+
+	ig.replyFuture(w, r, req, proxied.RPC(service, comp, method, req.args...))
+}
+
+// parseRequest 依次完成鉴权、目标服务与权限校验以及参数解析；失败时已写入错误响应并返回 false。
+func (ig *_Ingress) parseRequest(w http.ResponseWriter, r *http.Request, service string, cp callpath.CallPath) (*_Request, bool) {
+	principal := AnonymousPrincipal
+	if ig.options.Authenticator != nil {
+		var err error
+		principal, err = ig.options.Authenticator(r)
+		if err != nil {
+			ig.replyError(w, r, http.StatusUnauthorized, fmt.Errorf("%w: %w", ErrUnauthorized, err))
+			return nil, false
+		}
+	}
+
+	// HTTP 调用方视为客户端，使权限校验器按客户端规则处理
+	cc := rpcstack.CallChain{{
+		Addr:      gate.ClientDetails.DomainUnicast.Join(principal),
+		Timestamp: time.Now(),
+	}}
+
+	if err := ig.verifyService(service); err != nil {
+		ig.replyError(w, r, http.StatusForbidden, err)
+		return nil, false
+	}
+
+	if err := ig.verifyPermission(cc, cp); err != nil {
+		ig.replyError(w, r, http.StatusForbidden, err)
+		return nil, false
+	}
+
+	query := r.URL.Query()
+
+	req := &_Request{}
+	req.typed, _ = strconv.ParseBool(query.Get("typed"))
+	req.oneway, _ = strconv.ParseBool(query.Get("oneway"))
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, ig.options.MaxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ig.replyError(w, r, http.StatusRequestEntityTooLarge, err)
+		} else {
+			ig.replyError(w, r, http.StatusBadRequest, err)
+		}
+		return nil, false
+	}
+
+	req.args, err = decodeArgs(body, req.typed)
+	if err != nil {
+		ig.replyError(w, r, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	return req, true
+}
+
+// verifyService 校验目标服务在 Services 允许列表中；未设置列表时仅允许当前服务。
+func (ig *_Ingress) verifyService(service string) error {
+	if len(ig.options.Services) <= 0 {
+		if service == ig.svcCtx.Name() {
+			return nil
+		}
+	} else if slices.Contains(ig.options.Services, service) {
+		return nil
+	}
+	return fmt.Errorf("%w: service %q not allowed", rpcpcsr.ErrPermissionDenied, service)
+}
+
+// verifyPermission 使用与 rpcpcsr 相同的规则校验调用；未配置校验器时放行。
+func (ig *_Ingress) verifyPermission(cc rpcstack.CallChain, cp callpath.CallPath) error {
+	if len(ig.options.PermissionValidator) <= 0 {
+		return nil
+	}
+
+	passed, err := ig.options.PermissionValidator.SafeCall(func(passed bool, err error) bool {
+		return !passed || err != nil
+	}, cc, cp)
+	if err != nil {
+		return fmt.Errorf("%w: %w", rpcpcsr.ErrPermissionDenied, err)
+	}
+	if !passed {
+		return rpcpcsr.ErrPermissionDenied
+	}
+	return nil
+}
+
+// replyFuture 在 RequestTimeout 内等待 RPC 结果并写入响应。
+func (ig *_Ingress) replyFuture(w http.ResponseWriter, r *http.Request, req *_Request, future async.Future) {
+	ctx, cancel := context.WithTimeout(r.Context(), ig.options.RequestTimeout)
+	defer cancel()
+
+	ret := future.Wait(ctx)
+	if !ret.OK() {
+		ig.replyError(w, r, statusOf(ret.Error), ret.Error)
+		return
+	}
+
+	var rets variant.Array
+	if ret.Value != nil {
+		rets, _ = ret.Value.(variant.Array)
+	}
+
+	body, err := encodeRets(rets, req.typed)
+	if err != nil {
+		ig.replyError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	ig.reply(w, r, http.StatusOK, body)
+}
+
+// replyOneway 写入单向调用的响应；投递成功时返回 202。
+func (ig *_Ingress) replyOneway(w http.ResponseWriter, r *http.Request, err error) {
+	if err != nil {
+		ig.replyError(w, r, statusOf(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// replyError 以 {"error":{"code":...,"message":...}} 形式写入错误响应。
+func (ig *_Ingress) replyError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	ig.logger.Debug("ingress request failed",
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.Int("status", status),
+		zap.Error(err))
+
+	varErr := variant.NewError(err)
+
+	body, _ := json.Marshal(_ErrorReply{
+		Error: _ErrorBody{
+			Code:    varErr.Code,
+			Message: varErr.Message,
+		},
+	})
+
+	ig.reply(w, r, status, body)
+}
+
+func (ig *_Ingress) reply(w http.ResponseWriter, r *http.Request, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		ig.logger.Debug("ingress write response failed", zap.String("path", r.URL.Path), zap.Error(err))
+	}
+}
+
+// statusOf 将 RPC 错误映射为 HTTP 状态码。
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, rpcpcsr.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, rpcpcsr.ErrAddInNotFound),
+		errors.Is(err, rpcpcsr.ErrMethodNotFound),
+		errors.Is(err, rpcpcsr.ErrComponentNotFound),
+		errors.Is(err, rpcpcsr.ErrEntityNotFound),
+		errors.Is(err, rpcpcsr.ErrDistEntityNotFound),
+		errors.Is(err, rpcpcsr.ErrDistEntityNodeNotFound),
+		errors.Is(err, rpcpcsr.ErrServiceNodeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, rpcpcsr.ErrMethodParameterCountMismatch),
+		errors.Is(err, rpcpcsr.ErrMethodParameterTypeMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, rpcpcsr.ErrThrottled):
+		return http.StatusTooManyRequests
+	case errors.Is(err, rpcpcsr.ErrCircuitOpen):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
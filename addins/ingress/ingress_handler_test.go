@@ -0,0 +1,270 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/core/utils/option"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap/variant"
+	"go.uber.org/zap"
+)
+
+// fakeServiceContext 仅实现 handler 在发起 RPC 前用到的方法。
+type fakeServiceContext struct {
+	service.Context
+}
+
+func (ctx *fakeServiceContext) Name() string {
+	return "test"
+}
+
+func newTestIngress(settings ...option.Setting[IngressOptions]) *_Ingress {
+	ig := newIngress(settings...).(*_Ingress)
+	ig.svcCtx = &fakeServiceContext{}
+	ig.logger = zap.NewNop()
+	ig.mux = ig.routes()
+	return ig
+}
+
+func allowAll(cc rpcstack.CallChain, cp callpath.CallPath) bool {
+	return true
+}
+
+func serve(ig *_Ingress, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	ig.Handler().ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func assertErrorReply(t *testing.T, rec *httptest.ResponseRecorder, status int) _ErrorBody {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d, body %s", rec.Code, status, rec.Body)
+	}
+
+	var reply _ErrorReply
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("decode error reply failed: %v, body %s", err, rec.Body)
+	}
+	if reply.Error.Message == "" {
+		t.Fatalf("error reply without message: %s", rec.Body)
+	}
+	return reply.Error
+}
+
+func TestDefaultDoesNotListen(t *testing.T) {
+	options := option.New(With.Default())
+
+	if options.Address != "" {
+		t.Fatalf("default address = %q, want no listener", options.Address)
+	}
+	if options.ReadHeaderTimeout <= 0 || options.ReadTimeout <= 0 {
+		t.Fatalf("default read timeouts not set: %+v", options)
+	}
+}
+
+func TestHandlerRejectsBeforeRPC(t *testing.T) {
+	denyAuth := func(r *http.Request) (string, error) {
+		return "", errors.New("missing token")
+	}
+
+	cases := []struct {
+		name     string
+		settings []option.Setting[IngressOptions]
+		method   string
+		target   string
+		body     string
+		status   int
+	}{
+		{"unauthorized", []option.Setting[IngressOptions]{With.Authenticator(denyAuth)}, http.MethodPost, "/rpc/lobby/Bag/Open", "[]", http.StatusUnauthorized},
+		{"default permission", nil, http.MethodPost, "/rpc/lobby/Bag/Open", "[]", http.StatusForbidden},
+		{"entity default permission", nil, http.MethodPost, "/entity/e1/Bag/Open", "[]", http.StatusForbidden},
+		{"malformed args", []option.Setting[IngressOptions]{With.PermissionValidator(generic.CastDelegate2(allowAll))}, http.MethodPost, "/rpc/test/Bag/Open", "{", http.StatusBadRequest},
+		{"malformed typed args", []option.Setting[IngressOptions]{With.PermissionValidator(generic.CastDelegate2(allowAll))}, http.MethodPost, "/rpc/test/Bag/Open?typed=true", `[{"type":"nope","value":1}]`, http.StatusBadRequest},
+		{"body too large", []option.Setting[IngressOptions]{With.PermissionValidator(generic.CastDelegate2(allowAll)), With.MaxBodySize(4)}, http.MethodPost, "/rpc/test/Bag/Open", "[1,2,3]", http.StatusRequestEntityTooLarge},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ig := newTestIngress(c.settings...)
+			assertErrorReply(t, serve(ig, c.method, c.target, c.body), c.status)
+		})
+	}
+}
+
+func TestHandlerRoutes(t *testing.T) {
+	ig := newTestIngress(With.PathPrefix("/api"))
+
+	if rec := serve(ig, http.MethodGet, "/api/rpc/lobby/Bag/Open", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if rec := serve(ig, http.MethodPost, "/rpc/lobby/Bag/Open", "[]"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unprefixed status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	assertErrorReply(t, serve(ig, http.MethodPost, "/api/rpc/lobby/Bag/Open", "[]"), http.StatusForbidden)
+}
+
+func TestHandlerRejectsCrossServiceCalls(t *testing.T) {
+	allow := With.PermissionValidator(generic.CastDelegate2(allowAll))
+
+	cases := []struct {
+		name     string
+		settings []option.Setting[IngressOptions]
+		target   string
+		status   int
+	}{
+		{"other service", nil, "/rpc/lobby/Bag/Open", http.StatusForbidden},
+		{"entity in other service", nil, "/entity/e1/Bag/Open?service=lobby", http.StatusForbidden},
+		{"service not listed", []option.Setting[IngressOptions]{With.Services("lobby")}, "/rpc/match/Bag/Open", http.StatusForbidden},
+		{"own service not listed", []option.Setting[IngressOptions]{With.Services("lobby")}, "/rpc/test/Bag/Open", http.StatusForbidden},
+		{"entity service not listed", []option.Setting[IngressOptions]{With.Services("lobby")}, "/entity/e1/Bag/Open?service=match", http.StatusForbidden},
+		// 允许的服务通过校验后才解析参数，以格式错误的参数确认请求未被目标服务校验拒绝。
+		{"listed service", []option.Setting[IngressOptions]{With.Services("lobby")}, "/rpc/lobby/Bag/Open", http.StatusBadRequest},
+		{"own service by default", nil, "/entity/e1/Bag/Open", http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ig := newTestIngress(append([]option.Setting[IngressOptions]{allow}, c.settings...)...)
+
+			body := assertErrorReply(t, serve(ig, http.MethodPost, c.target, "{"), c.status)
+			if c.status == http.StatusForbidden && body.Code != rpcpcsr.ErrCodePermissionDenied {
+				t.Fatalf("error code = %d, want %d", body.Code, rpcpcsr.ErrCodePermissionDenied)
+			}
+		})
+	}
+}
+
+func TestHandlerPassesPrincipalToValidator(t *testing.T) {
+	var got callpath.CallPath
+	var caller string
+
+	validator := func(cc rpcstack.CallChain, cp callpath.CallPath) bool {
+		got, caller = cp, cc[len(cc)-1].Addr
+		return false
+	}
+	auth := func(r *http.Request) (string, error) {
+		return r.Header.Get("X-User"), nil
+	}
+
+	ig := newTestIngress(With.Authenticator(auth), With.PermissionValidator(generic.CastDelegate2(validator)))
+
+	req := httptest.NewRequest(http.MethodPost, "/entity/e1/Bag/Open", strings.NewReader("[]"))
+	req.Header.Set("X-User", "alice")
+	rec := httptest.NewRecorder()
+	ig.Handler().ServeHTTP(rec, req)
+
+	body := assertErrorReply(t, rec, http.StatusForbidden)
+	if body.Code != rpcpcsr.ErrCodePermissionDenied {
+		t.Fatalf("error code = %d, want %d", body.Code, rpcpcsr.ErrCodePermissionDenied)
+	}
+	if got.TargetKind != callpath.Entity || got.ID != uid.From("e1") || got.Script != "Bag" || got.Method != "Open" {
+		t.Fatalf("unexpected call path: %+v", got)
+	}
+	if !strings.HasSuffix(caller, "alice") {
+		t.Fatalf("caller %q does not carry the principal", caller)
+	}
+}
+
+func TestReplyFuture(t *testing.T) {
+	ig := newTestIngress(With.RequestTimeout(20 * time.Millisecond))
+
+	resolved := func(ret async.Result) async.Future {
+		promise, future := async.NewPromise()
+		promise.Resolve(ret)
+		return future
+	}
+
+	rets, err := variant.NewArray([]any{1, "a"})
+	if err != nil {
+		t.Fatalf("NewArray failed: %v", err)
+	}
+
+	t.Run("rets", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ig.replyFuture(rec, httptest.NewRequest(http.MethodPost, "/", nil), &_Request{}, resolved(async.NewResult(rets, nil)))
+
+		if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"rets":[1,"a"]}` {
+			t.Fatalf("unexpected reply %d %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("no rets", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ig.replyFuture(rec, httptest.NewRequest(http.MethodPost, "/", nil), &_Request{}, resolved(async.NewResult(nil, nil)))
+
+		if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"rets":[]}` {
+			t.Fatalf("unexpected reply %d %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ig.replyFuture(rec, httptest.NewRequest(http.MethodPost, "/", nil), &_Request{}, resolved(async.NewResult(nil, rpcpcsr.ErrMethodNotFound)))
+
+		body := assertErrorReply(t, rec, http.StatusNotFound)
+		if body.Code != rpcpcsr.ErrCodeMethodNotFound {
+			t.Fatalf("error code = %d, want %d", body.Code, rpcpcsr.ErrCodeMethodNotFound)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, pending := async.NewPromise()
+
+		rec := httptest.NewRecorder()
+		ig.replyFuture(rec, httptest.NewRequest(http.MethodPost, "/", nil), &_Request{}, pending)
+
+		assertErrorReply(t, rec, http.StatusGatewayTimeout)
+	})
+}
+
+func TestReplyOneway(t *testing.T) {
+	ig := newTestIngress()
+
+	rec := httptest.NewRecorder()
+	ig.replyOneway(rec, httptest.NewRequest(http.MethodPost, "/", nil), nil)
+	if rec.Code != http.StatusAccepted || rec.Body.Len() != 0 {
+		t.Fatalf("unexpected reply %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	ig.replyOneway(rec, httptest.NewRequest(http.MethodPost, "/", nil), rpcpcsr.ErrThrottled)
+	assertErrorReply(t, rec, http.StatusTooManyRequests)
+}
+
+func TestStatusOf(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{rpcpcsr.ErrPermissionDenied, http.StatusForbidden},
+		{fmt.Errorf("wrapped: %w", rpcpcsr.ErrEntityNotFound), http.StatusNotFound},
+		{rpcpcsr.ErrServiceNodeNotFound, http.StatusNotFound},
+		{rpcpcsr.ErrMethodParameterCountMismatch, http.StatusBadRequest},
+		{rpcpcsr.ErrThrottled, http.StatusTooManyRequests},
+		{rpcpcsr.ErrCircuitOpen, http.StatusServiceUnavailable},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{errors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		if got := statusOf(c.err); got != c.status {
+			t.Fatalf("statusOf(%v) = %d, want %d", c.err, got, c.status)
+		}
+	}
+}
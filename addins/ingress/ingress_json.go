@@ -0,0 +1,250 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package ingress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/framework/net/gap/variant"
+)
+
+type _ErrorBody struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+type _ErrorReply struct {
+	Error _ErrorBody `json:"error"`
+}
+
+type _RetsReply struct {
+	Rets any `json:"rets"`
+}
+
+// decodeArgs 将请求体 JSON 数组解析为调用参数；typed 为 true 时按 variant JSON 格式解析，
+// 否则将普通 JSON 值转换为最接近的动态值。空请求体表示无参数。
+func decodeArgs(data []byte, typed bool) ([]any, error) {
+	if len(bytes.TrimSpace(data)) <= 0 {
+		return nil, nil
+	}
+
+	if typed {
+		var arr variant.Array
+		if err := json.Unmarshal(data, &arr); err != nil {
+			return nil, fmt.Errorf("ingress: decode typed args failed: %w", err)
+		}
+
+		args := make([]any, len(arr.Items))
+		for i := range arr.Items {
+			args[i] = arr.Items[i]
+		}
+		return args, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var raw []any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("ingress: decode args failed: %w", err)
+	}
+
+	args := make([]any, len(raw))
+	for i := range raw {
+		v, err := jsonToVariant(raw[i])
+		if err != nil {
+			return nil, fmt.Errorf("ingress: decode arg %d failed: %w", i, err)
+		}
+		args[i] = v
+	}
+	return args, nil
+}
+
+// jsonToVariant 将普通 JSON 值转换为动态值：整数选用能容纳的最小有符号整型，超出 int64 时使用 Uint64，
+// 其余数字使用 Double；对象转换为按键排序的字符串键 Map。
+func jsonToVariant(a any) (variant.Variant, error) {
+	switch v := a.(type) {
+	case nil:
+		return variant.NewVariant(variant.Null{})
+	case bool:
+		return variant.NewVariant(variant.Bool(v))
+	case string:
+		return variant.NewVariant(variant.String(v))
+	case json.Number:
+		return jsonNumberToVariant(v)
+	case []any:
+		arr := variant.Array{Items: make([]variant.Variant, 0, len(v))}
+		for i := range v {
+			item, err := jsonToVariant(v[i])
+			if err != nil {
+				return variant.Variant{}, err
+			}
+			arr.Items = append(arr.Items, item)
+		}
+		return variant.NewVariant(arr)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		m := variant.Map{Entries: make(generic.UnorderedSliceMap[variant.Variant, variant.Variant], 0, len(v))}
+		for _, k := range keys {
+			varK, err := variant.NewVariant(variant.String(k))
+			if err != nil {
+				return variant.Variant{}, err
+			}
+			varV, err := jsonToVariant(v[k])
+			if err != nil {
+				return variant.Variant{}, err
+			}
+			m.Entries = append(m.Entries, generic.UnorderedKV[variant.Variant, variant.Variant]{K: varK, V: varV})
+		}
+		return variant.NewVariant(m)
+	default:
+		return variant.Variant{}, fmt.Errorf("unsupported json value %T", a)
+	}
+}
+
+func jsonNumberToVariant(n json.Number) (variant.Variant, error) {
+	if i, err := n.Int64(); err == nil {
+		switch {
+		case i >= math.MinInt8 && i <= math.MaxInt8:
+			return variant.NewVariant(variant.Int8(i))
+		case i >= math.MinInt16 && i <= math.MaxInt16:
+			return variant.NewVariant(variant.Int16(i))
+		case i >= math.MinInt32 && i <= math.MaxInt32:
+			return variant.NewVariant(variant.Int32(i))
+		default:
+			return variant.NewVariant(variant.Int64(i))
+		}
+	}
+
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return variant.NewVariant(variant.Uint64(u))
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return variant.Variant{}, err
+	}
+	return variant.NewVariant(variant.Double(f))
+}
+
+// encodeRets 将 RPC 返回值编码为 {"rets":[...]}；typed 为 true 时按 variant JSON 格式编码，
+// 否则输出各返回值的普通 JSON 形式。
+func encodeRets(rets variant.Array, typed bool) ([]byte, error) {
+	items, err := arrayItems(rets)
+	if err != nil {
+		return nil, err
+	}
+
+	if typed {
+		return json.Marshal(_RetsReply{Rets: items})
+	}
+
+	plain := make([]any, len(items))
+	for i := range items {
+		plain[i], err = variantToJSON(items[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(_RetsReply{Rets: plain})
+}
+
+// variantToJSON 将动态值转换为可直接 JSON 编码的普通值；非字符串键的 Map 编码为键值对数组。
+func variantToJSON(v variant.Variant) (any, error) {
+	switch v.TypeID {
+	case variant.TypeID_Null:
+		return nil, nil
+	case variant.TypeID_Array:
+		arr, ok := v.Value.Indirect().(variant.Array)
+		if !ok {
+			return v.Value.Indirect(), nil
+		}
+		items, err := arrayItems(arr)
+		if err != nil {
+			return nil, err
+		}
+		ret := make([]any, len(items))
+		for i := range items {
+			if ret[i], err = variantToJSON(items[i]); err != nil {
+				return nil, err
+			}
+		}
+		return ret, nil
+	case variant.TypeID_Map:
+		m, ok := v.Value.Indirect().(variant.Map)
+		if !ok {
+			return v.Value.Indirect(), nil
+		}
+		obj := make(map[string]any, len(m.Entries))
+		for _, kv := range m.Entries {
+			k, ok := kv.K.Value.Indirect().(string)
+			if !ok {
+				return m, nil
+			}
+			val, err := variantToJSON(kv.V)
+			if err != nil {
+				return nil, err
+			}
+			obj[k] = val
+		}
+		return obj, nil
+	case variant.TypeID_Error:
+		if err, ok := v.Value.Indirect().(*variant.Error); ok && err != nil {
+			return _ErrorBody{Code: err.Code, Message: err.Message}, nil
+		}
+		return v.Value.Indirect(), nil
+	default:
+		val := v.Value.Indirect()
+		if f, ok := val.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			return v, nil
+		}
+		if f, ok := val.(float32); ok && (math.IsNaN(float64(f)) || math.IsInf(float64(f), 0)) {
+			return v, nil
+		}
+		return val, nil
+	}
+}
+
+// arrayItems 返回数组项；快照形态先解码。
+func arrayItems(arr variant.Array) ([]variant.Variant, error) {
+	if !arr.IsSnapshot {
+		if arr.Items == nil {
+			return []variant.Variant{}, nil
+		}
+		return arr.Items, nil
+	}
+
+	var decoded variant.Array
+	if _, err := decoded.Write(arr.SnapshotBytes.Payload()); err != nil {
+		return nil, err
+	}
+	return decoded.Items, nil
+}
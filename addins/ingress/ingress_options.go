@@ -0,0 +1,169 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package ingress
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/utils/exception"
+	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/core/utils/option"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+)
+
+// Authenticator 校验 HTTP 请求并返回调用方标识；返回错误时以 401 拒绝请求。
+type Authenticator = func(r *http.Request) (principal string, err error)
+
+// IngressOptions 配置 HTTP 监听、鉴权、权限校验和请求限制。
+type IngressOptions struct {
+	Address             string                      // Address 是 HTTP 监听地址；空字符串表示不监听，仅通过 Handler 挂载。
+	TLSConfig           *tls.Config                 // TLSConfig 非 nil 时启用 HTTPS。
+	ReadHeaderTimeout   time.Duration               // ReadHeaderTimeout 是监听时读取请求头的最长时间。
+	ReadTimeout         time.Duration               // ReadTimeout 是监听时读取整个请求（含请求体）的最长时间。
+	PathPrefix          string                      // PathPrefix 是路由前缀，例如 "/api"。
+	Authenticator       Authenticator               // Authenticator 校验请求身份；nil 表示匿名访问，调用方标识为 AnonymousPrincipal。
+	PermissionValidator rpcpcsr.PermissionValidator // PermissionValidator 校验调用方是否有权访问调用路径；为空时放行。
+	Services            []string                    // Services 是允许调用的目标服务；为空时仅允许 ingress 所在服务。
+	RequestTimeout      time.Duration               // RequestTimeout 是等待 RPC 结果的最长时间。
+	MaxBodySize         int64                       // MaxBodySize 限制请求体字节数。
+}
+
+// AnonymousPrincipal 是未配置 Authenticator 时的调用方标识。
+const AnonymousPrincipal = "anonymous"
+
+// With 提供 ingress add-in 的 Option 构造方法。
+var With _IngressOption
+
+type _IngressOption struct{}
+
+// Default 返回不监听端口、仅允许调用当前服务中 rpcpcsr.DefaultCliPermissions 所声明方法的默认设置；
+// 需要独立监听时应同时设置 Address 与 Authenticator，否则只能通过 Handler 挂载到已有的 HTTP 服务。
+func (_IngressOption) Default() option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		With.Address("")(options)
+		With.TLSConfig(nil)(options)
+		With.ReadHeaderTimeout(5 * time.Second)(options)
+		With.ReadTimeout(30 * time.Second)(options)
+		With.PathPrefix("")(options)
+		With.Authenticator(nil)(options)
+		With.PermissionValidator(generic.CastDelegate2(rpcpcsr.DefaultValidateCliPermission))(options)
+		With.Services()(options)
+		With.RequestTimeout(10 * time.Second)(options)
+		With.MaxBodySize(1024 * 1024)(options)
+	}
+}
+
+// Address 设置 HTTP 监听地址并校验 host:port 格式；空字符串表示不监听。
+func (_IngressOption) Address(addr string) option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		if addr != "" {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				exception.Panicf("ingress: %w: %w", core.ErrArgs, err)
+			}
+		}
+		options.Address = addr
+	}
+}
+
+// TLSConfig 设置 HTTPS 配置；nil 表示使用 HTTP。
+func (_IngressOption) TLSConfig(config *tls.Config) option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		options.TLSConfig = config
+	}
+}
+
+// ReadHeaderTimeout 设置监听时读取请求头的最长时间，必须大于 0。
+func (_IngressOption) ReadHeaderTimeout(d time.Duration) option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		if d <= 0 {
+			exception.Panicf("ingress: %w: option ReadHeaderTimeout can't be set to a value less than or equal to 0", core.ErrArgs)
+		}
+		options.ReadHeaderTimeout = d
+	}
+}
+
+// ReadTimeout 设置监听时读取整个请求的最长时间，必须大于 0。
+func (_IngressOption) ReadTimeout(d time.Duration) option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		if d <= 0 {
+			exception.Panicf("ingress: %w: option ReadTimeout can't be set to a value less than or equal to 0", core.ErrArgs)
+		}
+		options.ReadTimeout = d
+	}
+}
+
+// PathPrefix 设置路由前缀，去除末尾的 "/"。
+func (_IngressOption) PathPrefix(prefix string) option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if prefix != "" && !strings.HasPrefix(prefix, "/") {
+			exception.Panicf("ingress: %w: path prefix must start with '/'", core.ErrArgs)
+		}
+		options.PathPrefix = prefix
+	}
+}
+
+// Authenticator 设置请求鉴权函数；nil 表示匿名访问。
+func (_IngressOption) Authenticator(fn Authenticator) option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		options.Authenticator = fn
+	}
+}
+
+// PermissionValidator 设置调用权限校验器；为空时放行所有调用。
+func (_IngressOption) PermissionValidator(validator rpcpcsr.PermissionValidator) option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		options.PermissionValidator = validator
+	}
+}
+
+// Services 设置允许调用的目标服务；不设置时仅允许 ingress 所在服务。
+// 权限校验器只看到调用路径，无法区分目标服务，调用其他服务的方法必须在此显式列出该服务。
+func (_IngressOption) Services(services ...string) option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		options.Services = slices.Clone(services)
+	}
+}
+
+// RequestTimeout 设置等待 RPC 结果的最长时间，必须大于 0。
+func (_IngressOption) RequestTimeout(d time.Duration) option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		if d <= 0 {
+			exception.Panicf("ingress: %w: option RequestTimeout can't be set to a value less than or equal to 0", core.ErrArgs)
+		}
+		options.RequestTimeout = d
+	}
+}
+
+// MaxBodySize 设置请求体字节数上限，必须大于 0。
+func (_IngressOption) MaxBodySize(size int64) option.Setting[IngressOptions] {
+	return func(options *IngressOptions) {
+		if size <= 0 {
+			exception.Panicf("ingress: %w: option MaxBodySize can't be set to a value less than or equal to 0", core.ErrArgs)
+		}
+		options.MaxBodySize = size
+	}
+}
@@ -20,10 +20,16 @@
 package callpath
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
 	"hash/fnv"
 	"maps"
 	"runtime"
+	"slices"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"git.golaxy.org/core/utils/exception"
 	"git.golaxy.org/core/utils/types"
@@ -34,30 +40,158 @@ type _Cached struct {
 	Method string
 }
 
+// Entry 是压缩调用路径表中的一个条目。
+type Entry struct {
+	Index  uint32 // Index 是脚本名和方法名的 FNV-1a 索引。
+	Script string // Script 是脚本名。
+	Method string // Method 是方法名。
+}
+
+// DemoteTTL 是 Demote 降级调用路径的有效期，到期后恢复压缩编码，对端届时仍无法解析时会再次降级。
+const DemoteTTL = 5 * time.Minute
+
 var (
-	cache atomic.Pointer[map[uint32]*_Cached]
+	cache   atomic.Pointer[map[uint32]*_Cached] // 本进程声明的条目。
+	merged  atomic.Pointer[map[uint32]*_Cached] // 其他进程发布的条目，解析时优先级低于本进程声明的条目。
+	demoted sync.Map                            // 索引到降级到期时间的映射，零值表示因索引冲突永久降级。
 )
 
 // Cache 缓存脚本名和方法名，并返回用于压缩调用路径的 FNV-1a 索引。
-// 同一索引对应不同调用路径时 panic；调用双方必须预先缓存一致的条目。
+// 同一索引对应本进程声明的不同调用路径时 panic；调用双方必须预先缓存一致的条目。
+// 索引已被其他进程发布的不同调用路径占用时，本进程声明的条目优先，该索引永久降级为完整形式编码。
 func Cache(script, method string) uint32 {
-	hash := fnv.New32a()
+	idx, err := store(&cache, script, method)
+	if err != nil {
+		exception.Panicf("%s; rename the script or method to change the generated call path id", err)
+	}
+	if remote := lookup(&merged, idx); remote != nil && (remote.Script != script || remote.Method != method) {
+		demoteIndex(idx, time.Time{})
+	}
+	return idx
+}
 
-	sep := [1]byte{0}
-	hash.Write(types.String2Bytes(script))
-	hash.Write(sep[:])
-	hash.Write(types.String2Bytes(method))
+// Merge 合并其他进程发布的压缩调用路径表条目，已存在的条目被忽略。合并的条目单独保存，不计入 Entries 与 Count，
+// 解析压缩调用路径时优先使用本进程声明的条目。
+// 索引与脚本名和方法名不匹配的条目不会被合并；与已缓存条目冲突的条目同样不会被合并，且该索引永久降级为完整形式编码。
+// 以上条目均以错误返回。
+func Merge(entries ...Entry) error {
+	var errs []error
+
+	for _, entry := range entries {
+		if reduce(entry.Script, entry.Method) != entry.Index {
+			errs = append(errs, fmt.Errorf("rpc: cached index %d mismatch: %+v", entry.Index, _Cached{Script: entry.Script, Method: entry.Method}))
+			continue
+		}
 
-	idx := hash.Sum32()
+		if local := lookup(&cache, entry.Index); local != nil {
+			if local.Script != entry.Script || local.Method != entry.Method {
+				demoteIndex(entry.Index, time.Time{})
+				errs = append(errs, fmt.Errorf("rpc: cached index %d conflict: existing %+v vs new %+v", entry.Index, *local, _Cached{Script: entry.Script, Method: entry.Method}))
+			}
+			continue
+		}
+
+		if _, err := store(&merged, entry.Script, entry.Method); err != nil {
+			demoteIndex(entry.Index, time.Time{})
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Entries 返回本进程通过 Cache 声明的全部压缩调用路径表条目，按索引排序。
+func Entries() []Entry {
+	m := cache.Load()
+	if m == nil {
+		return nil
+	}
+
+	entries := make([]Entry, 0, len(*m))
+	for idx, cached := range *m {
+		entries = append(entries, Entry{Index: idx, Script: cached.Script, Method: cached.Method})
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+
+	return entries
+}
+
+// Count 返回本进程通过 Cache 声明的压缩调用路径表条目数；条目只增不减，可用于判断表是否变化。
+func Count() int {
+	m := cache.Load()
+	if m == nil {
+		return 0
+	}
+	return len(*m)
+}
+
+// Demote 标记调用路径在本进程内 DemoteTTL 时长内不再压缩编码，用于对端无法解析其压缩索引时回退到完整形式。
+// 因索引冲突而永久降级的调用路径不受影响。
+func Demote(script, method string) {
+	demoteIndex(reduce(script, method), time.Now().Add(DemoteTTL))
+}
+
+// ResetDemoted 清除全部由 Demote 标记的临时降级，使调用路径立即恢复压缩编码；因索引冲突的永久降级保留。
+func ResetDemoted() {
+	demoted.Range(func(key, value any) bool {
+		if !value.(time.Time).IsZero() {
+			demoted.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+func demoteIndex(idx uint32, expiry time.Time) {
+	for {
+		old, loaded := demoted.LoadOrStore(idx, expiry)
+		if !loaded {
+			return
+		}
+		oldExpiry := old.(time.Time)
+		if oldExpiry.IsZero() || (!expiry.IsZero() && !expiry.After(oldExpiry)) {
+			return
+		}
+		if demoted.CompareAndSwap(idx, old, expiry) {
+			return
+		}
+	}
+}
+
+func isDemoted(idx uint32) bool {
+	v, ok := demoted.Load(idx)
+	if !ok {
+		return false
+	}
+	expiry := v.(time.Time)
+	if expiry.IsZero() || time.Now().Before(expiry) {
+		return true
+	}
+	demoted.CompareAndDelete(idx, v)
+	return false
+}
+
+func lookup(table *atomic.Pointer[map[uint32]*_Cached], idx uint32) *_Cached {
+	m := table.Load()
+	if m == nil {
+		return nil
+	}
+	return (*m)[idx]
+}
+
+func store(table *atomic.Pointer[map[uint32]*_Cached], script, method string) (uint32, error) {
+	idx := reduce(script, method)
 
 	for {
-		old := cache.Load()
+		old := table.Load()
 		if old != nil {
 			if exists, ok := (*old)[idx]; ok {
 				if exists.Script == script && exists.Method == method {
-					return idx
+					return idx, nil
 				}
-				exception.Panicf("rpc: cached index %d conflict: existing %+v vs new %+v; rename the script or method to change the generated call path id", idx, *exists, _Cached{Script: script, Method: method})
+				return idx, fmt.Errorf("rpc: cached index %d conflict: existing %+v vs new %+v", idx, *exists, _Cached{Script: script, Method: method})
 			}
 		}
 
@@ -75,8 +209,8 @@ func Cache(script, method string) uint32 {
 
 		next[idx] = cached
 
-		if cache.CompareAndSwap(old, &next) {
-			return idx
+		if table.CompareAndSwap(old, &next) {
+			return idx, nil
 		}
 
 		runtime.Gosched()
@@ -95,9 +229,8 @@ func reduce(script, method string) uint32 {
 }
 
 func inflate(idx uint32) *_Cached {
-	m := cache.Load()
-	if m == nil {
-		return nil
+	if cached := lookup(&cache, idx); cached != nil {
+		return cached
 	}
-	return (*m)[idx]
+	return lookup(&merged, idx)
 }
@@ -0,0 +1,161 @@
+package callpath
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 以下方法名在脚本 Collide 下两两生成相同的索引，每个用例使用独立的一组以免共享全局表相互影响。
+var collisions = [][2]string{
+	{"M127628", "M1320222"},
+	{"M127629", "M1320223"},
+	{"M127622", "M1320228"},
+}
+
+func encodedShort(t *testing.T, cp CallPath) bool {
+	t.Helper()
+
+	data, err := cp.Encode(true)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	return data[1]&1 != 0
+}
+
+func TestMergeRejectsMismatchedIndex(t *testing.T) {
+	err := Merge(Entry{Index: reduce("Merge", "Mismatch") + 1, Script: "Merge", Method: "Mismatch"})
+	if err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Fatalf("expected mismatch error, got %v", err)
+	}
+	if inflate(reduce("Merge", "Mismatch")+1) != nil {
+		t.Fatalf("mismatched entry merged")
+	}
+}
+
+func TestMergeKeepsRemoteEntriesSeparate(t *testing.T) {
+	idx := reduce("Merge", "Remote")
+	count := Count()
+
+	if err := Merge(Entry{Index: idx, Script: "Merge", Method: "Remote"}); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if err := Merge(Entry{Index: idx, Script: "Merge", Method: "Remote"}); err != nil {
+		t.Fatalf("merging the same entry twice failed: %v", err)
+	}
+
+	if cached := inflate(idx); cached == nil || cached.Method != "Remote" {
+		t.Fatalf("merged entry not resolvable: %+v", cached)
+	}
+	if Count() != count || slices.ContainsFunc(Entries(), func(e Entry) bool { return e.Index == idx }) {
+		t.Fatalf("merged entry reported as a local entry")
+	}
+}
+
+func TestCacheOverridesConflictingMergedEntry(t *testing.T) {
+	remote, local := collisions[0][0], collisions[0][1]
+	idx := reduce("Collide", local)
+
+	if err := Merge(Entry{Index: idx, Script: "Collide", Method: remote}); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	func() {
+		defer func() {
+			if panicInfo := recover(); panicInfo != nil {
+				t.Fatalf("Cache panicked on a merged entry: %v", panicInfo)
+			}
+		}()
+		Cache("Collide", local)
+	}()
+
+	if cached := inflate(idx); cached == nil || cached.Method != local {
+		t.Fatalf("local entry did not override the merged one: %+v", cached)
+	}
+	if encodedShort(t, CallPath{TargetKind: Service, Script: "Collide", Method: local}) {
+		t.Fatalf("conflicting index still encoded in short form")
+	}
+}
+
+func TestMergeConflictWithLocalEntry(t *testing.T) {
+	local, remote := collisions[1][0], collisions[1][1]
+	idx := Cache("Collide", local)
+
+	if err := Merge(Entry{Index: idx, Script: "Collide", Method: remote}); err == nil {
+		t.Fatalf("expected conflict error")
+	}
+
+	if cached := inflate(idx); cached == nil || cached.Method != local {
+		t.Fatalf("merged entry replaced the local one: %+v", cached)
+	}
+	if encodedShort(t, CallPath{TargetKind: Service, Script: "Collide", Method: local}) {
+		t.Fatalf("conflicting index still encoded in short form")
+	}
+}
+
+func TestMergeConflictBetweenRemoteEntries(t *testing.T) {
+	first, second := collisions[2][0], collisions[2][1]
+	idx := reduce("Collide", first)
+
+	if err := Merge(Entry{Index: idx, Script: "Collide", Method: first}); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if err := Merge(Entry{Index: idx, Script: "Collide", Method: second}); err == nil {
+		t.Fatalf("expected conflict error")
+	}
+
+	if cached := inflate(idx); cached == nil || cached.Method != first {
+		t.Fatalf("first merged entry replaced: %+v", cached)
+	}
+	if !isDemoted(idx) {
+		t.Fatalf("conflicting index not demoted")
+	}
+
+	ResetDemoted()
+	if !isDemoted(idx) {
+		t.Fatalf("ResetDemoted cleared a conflict demotion")
+	}
+}
+
+func TestDemote(t *testing.T) {
+	cp := CallPath{TargetKind: Service, Script: "Demote", Method: "Call"}
+	Cache(cp.Script, cp.Method)
+
+	if !encodedShort(t, cp) {
+		t.Fatalf("expected short form before Demote")
+	}
+
+	Demote(cp.Script, cp.Method)
+	if encodedShort(t, cp) {
+		t.Fatalf("expected long form after Demote")
+	}
+
+	ResetDemoted()
+	if !encodedShort(t, cp) {
+		t.Fatalf("expected short form after ResetDemoted")
+	}
+}
+
+func TestDemoteExpires(t *testing.T) {
+	idx := reduce("Demote", "Expired")
+
+	demoteIndex(idx, time.Now().Add(-time.Second))
+	if isDemoted(idx) {
+		t.Fatalf("expired demotion still effective")
+	}
+
+	// 延长有效期生效，缩短有效期被忽略。
+	demoteIndex(idx, time.Now().Add(time.Minute))
+	demoteIndex(idx, time.Now().Add(-time.Second))
+	if !isDemoted(idx) {
+		t.Fatalf("shorter demotion replaced a longer one")
+	}
+
+	demoteIndex(idx, time.Time{})
+	demoteIndex(idx, time.Now().Add(time.Minute))
+	ResetDemoted()
+	if !isDemoted(idx) {
+		t.Fatalf("temporary demotion replaced a permanent one")
+	}
+}
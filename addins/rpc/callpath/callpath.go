@@ -27,8 +27,19 @@ import (
 
 	"git.golaxy.org/core/utils/types"
 	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/net/gap/variant"
 )
 
+// ErrUnknownIndex 表示压缩调用路径的索引未在本进程缓存，发送方应改用完整形式重发。
+var ErrUnknownIndex = errors.New("rpc: inflate cached index failed")
+
+// ErrCodeUnknownIndex 是 ErrUnknownIndex 的远端错误码，属于框架保留区间。
+const ErrCodeUnknownIndex int32 = 117
+
+func init() {
+	variant.ErrorRegistry().Declare(ErrCodeUnknownIndex, ErrUnknownIndex)
+}
+
 // TargetKind 标识 RPC 调用路径指向的对象类型。
 type TargetKind uint8
 
@@ -57,10 +68,17 @@ type CallPath struct {
 	Method string
 }
 
-// Encode 编码调用路径。short 为 true 时使用进程内缓存索引压缩脚本名和方法名。
+// Encode 编码调用路径。short 为 true 时使用进程内缓存索引压缩脚本名和方法名；
+// 已通过 Demote 降级的调用路径始终使用完整形式。
 func (cp CallPath) Encode(short bool) ([]byte, error) {
 	var sb bytes.Buffer
 
+	var idx uint32
+	if short {
+		idx = reduce(cp.Script, cp.Method)
+		short = !isDemoted(idx)
+	}
+
 	sb.WriteByte(byte(cp.TargetKind))
 	sb.WriteByte(types.Bool2Int[uint8](short)<<0 + types.Bool2Int[uint8](cp.ExcludeSrc)<<1)
 
@@ -76,7 +94,7 @@ func (cp CallPath) Encode(short bool) ([]byte, error) {
 
 	if short {
 		var buff [4]byte
-		binary.LittleEndian.PutUint32(buff[:], reduce(cp.Script, cp.Method))
+		binary.LittleEndian.PutUint32(buff[:], idx)
 		sb.Write(buff[:])
 	} else {
 		sb.WriteString(cp.Script)
@@ -103,7 +121,7 @@ func (cp CallPath) String() string {
 	return ""
 }
 
-// Parse 解码调用路径；压缩路径要求本进程已缓存对应的脚本和方法。
+// Parse 解码调用路径；压缩路径要求本进程已缓存对应的脚本和方法，否则返回 ErrUnknownIndex。
 func Parse(data []byte) (CallPath, error) {
 	if len(data) < 2 {
 		return CallPath{}, errors.New("rpc: invalid call path data format")
@@ -158,7 +176,7 @@ func Parse(data []byte) (CallPath, error) {
 
 		cached := inflate(binary.LittleEndian.Uint32(data[offset:]))
 		if cached == nil {
-			return CallPath{}, errors.New("rpc: inflate cached index failed")
+			return CallPath{}, ErrUnknownIndex
 		}
 
 		cp.Script = cached.Script
@@ -1,5 +1,6 @@
 // Package callpath 负责 RPC 目标路径的编码与解码。
 //
 // 它描述 RPC 请求的目标类型、脚本名和方法名，并提供 Parse 与 Cache 等
-// 辅助能力以支持更高效的传输表示。
+// 辅助能力以支持更高效的传输表示。压缩表可通过 Entries 与 Merge 在进程间同步，
+// 对端无法解析的调用路径可通过 Demote 在 DemoteTTL 内回退到完整形式。
 package callpath
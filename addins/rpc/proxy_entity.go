@@ -34,7 +34,7 @@ import (
 	"git.golaxy.org/framework/addins/gate"
 	"git.golaxy.org/framework/addins/rpc/callpath"
 	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
-	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
 )
 
 // ProxyEntity 使用 provider 所在的服务上下文创建实体 id 的 RPC 代理。
@@ -63,34 +63,57 @@ type EntityProxied struct {
 	svcCtx service.Context
 	rtCtx  runtime.Context
 	id     uid.ID
+	retry  *RetryPolicy
+	codec  *gap.ArgsCodec
+}
+
+// WithRetry 返回使用重试策略的代理副本，该策略优先于插件配置的方法级重试策略；仅作用于需要响应的请求，
+// 广播请求与单向通知不重试。
+func (p EntityProxied) WithRetry(policy RetryPolicy) EntityProxied {
+	p.retry = &policy
+	return p
+}
+
+// WithArgsCodec 返回使用参数编解码器的代理副本，该设置优先于插件配置的默认编解码器；
+// 分组与批量请求不支持其他编解码器，仍使用 variant 格式。
+func (p EntityProxied) WithArgsCodec(codec gap.ArgsCodec) EntityProxied {
+	p.codec = &codec
+	return p
 }
 
 // RPC 向承载实体的首个指定服务节点发起 RPC；查询失败时返回已携带错误的 Future。
 func (p EntityProxied) RPC(service, comp, method string, args ...any) async.Future {
+	return p.Call(service, comp, method, args...).RPC()
+}
+
+// Call 构造向承载实体的首个指定服务节点发起的请求，不立即发送；可调用 ProxyCall.RPC 发起或加入 Batch 合并发送。
+func (p EntityProxied) Call(service, comp, method string, args ...any) ProxyCall {
 	if p.svcCtx == nil {
 		exception.Panic("rpc: svcCtx is nil")
 	}
 
-	// 查询分布式实体信息
-	distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.id)
-	if !ok {
-		return async.Rejected(rpcpcsr.ErrDistEntityNotFound)
-	}
+	// 目标地址，每次尝试时重新查询以跟随实体迁移
+	resolve := func() (string, error) {
+		// 查询分布式实体信息
+		distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.id)
+		if !ok {
+			return "", rpcpcsr.ErrDistEntityNotFound
+		}
 
-	// 查询分布式实体目标服务节点
-	nodeIdx := slices.IndexFunc(distEntity.Nodes, func(node dent.Node) bool {
-		return node.Service == service
-	})
-	if nodeIdx < 0 {
-		return async.Rejected(rpcpcsr.ErrDistEntityNodeNotFound)
-	}
+		// 查询分布式实体目标服务节点
+		nodeIdx := slices.IndexFunc(distEntity.Nodes, func(node dent.Node) bool {
+			return node.Service == service
+		})
+		if nodeIdx < 0 {
+			return "", rpcpcsr.ErrDistEntityNodeNotFound
+		}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
+		return distEntity.Nodes[nodeIdx].RemoteAddr, nil
 	}
 
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
 	// 调用路径
 	cp := callpath.CallPath{
 		TargetKind: callpath.Entity,
@@ -99,7 +122,16 @@ func (p EntityProxied) RPC(service, comp, method string, args ...any) async.Futu
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).RPC(distEntity.Nodes[nodeIdx].RemoteAddr, cc, cp, args...)
+	return ProxyCall{
+		svcCtx:  p.svcCtx,
+		retry:   p.retry,
+		codec:   p.codec,
+		resolve: resolve,
+		cc:      cc,
+		sc:      sc,
+		cp:      cp,
+		args:    args,
+	}
 }
 
 // BalanceRPC 从承载实体且服务名匹配的节点中随机选择一个发起 RPC。
@@ -108,43 +140,45 @@ func (p EntityProxied) BalanceRPC(service, comp, method string, args ...any) asy
 		exception.Panic("rpc: svcCtx is nil")
 	}
 
-	// 查询分布式实体信息
-	distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.id)
-	if !ok {
-		return async.Rejected(rpcpcsr.ErrDistEntityNotFound)
-	}
-
-	// 统计节点数量
-	var count int
-	for i := range distEntity.Nodes {
-		if distEntity.Nodes[i].Service == service {
-			count++
+	// 目标地址，每次尝试时重新查询以跟随实体迁移
+	resolve := func() (string, error) {
+		// 查询分布式实体信息
+		distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.id)
+		if !ok {
+			return "", rpcpcsr.ErrDistEntityNotFound
 		}
-	}
-	if count <= 0 {
-		return async.Rejected(rpcpcsr.ErrDistEntityNodeNotFound)
-	}
 
-	// 随机目标节点
-	var dst string
-	offset := rand.Intn(count)
+		// 统计节点数量
+		var count int
+		for i := range distEntity.Nodes {
+			if distEntity.Nodes[i].Service == service {
+				count++
+			}
+		}
+		if count <= 0 {
+			return "", rpcpcsr.ErrDistEntityNodeNotFound
+		}
 
-	for i := range distEntity.Nodes {
-		if distEntity.Nodes[i].Service == service {
-			if offset <= 0 {
-				dst = distEntity.Nodes[i].RemoteAddr
-				break
+		// 随机目标节点
+		var dst string
+		offset := rand.Intn(count)
+
+		for i := range distEntity.Nodes {
+			if distEntity.Nodes[i].Service == service {
+				if offset <= 0 {
+					dst = distEntity.Nodes[i].RemoteAddr
+					break
+				}
+				offset--
 			}
-			offset--
 		}
-	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
+		return dst, nil
 	}
 
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
 	// 调用路径
 	cp := callpath.CallPath{
 		TargetKind: callpath.Entity,
@@ -153,7 +187,7 @@ func (p EntityProxied) BalanceRPC(service, comp, method string, args ...any) asy
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).RPC(dst, cc, cp, args...)
+	return invokeRPC(p.svcCtx, p.retry, p.codec, resolve, cc, sc, cp, args)
 }
 
 // GlobalBalanceRPC 从承载实体的全部节点中随机选择一个发起 RPC；excludeSelf 为 true 时排除本节点。
@@ -162,42 +196,43 @@ func (p EntityProxied) GlobalBalanceRPC(excludeSelf bool, comp, method string, a
 		exception.Panic("rpc: svcCtx is nil")
 	}
 
-	// 查询分布式实体信息
-	distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.id)
-	if !ok {
-		return async.Rejected(rpcpcsr.ErrDistEntityNotFound)
-	}
-
-	// 随机目标节点
-	var dst string
-
-	if excludeSelf {
-		if len(distEntity.Nodes) <= 1 {
-			return async.Rejected(rpcpcsr.ErrDistEntityNodeNotFound)
+	// 目标地址，每次尝试时重新查询以跟随实体迁移
+	resolve := func() (string, error) {
+		// 查询分布式实体信息
+		distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.id)
+		if !ok {
+			return "", rpcpcsr.ErrDistEntityNotFound
 		}
 
-		localAddr := dsvc.AddIn.Require(p.svcCtx).NodeDetails().LocalAddr
-		idx := rand.Intn(len(distEntity.Nodes))
+		// 随机目标节点
+		var dst string
 
-		if distEntity.Nodes[idx].RemoteAddr == localAddr {
-			idx = (idx + 1) % len(distEntity.Nodes)
-		}
+		if excludeSelf {
+			if len(distEntity.Nodes) <= 1 {
+				return "", rpcpcsr.ErrDistEntityNodeNotFound
+			}
 
-		dst = distEntity.Nodes[idx].RemoteAddr
+			localAddr := dsvc.AddIn.Require(p.svcCtx).NodeDetails().LocalAddr
+			idx := rand.Intn(len(distEntity.Nodes))
 
-	} else {
-		if len(distEntity.Nodes) <= 0 {
-			return async.Rejected(rpcpcsr.ErrDistEntityNodeNotFound)
+			if distEntity.Nodes[idx].RemoteAddr == localAddr {
+				idx = (idx + 1) % len(distEntity.Nodes)
+			}
+
+			dst = distEntity.Nodes[idx].RemoteAddr
+		} else {
+			if len(distEntity.Nodes) <= 0 {
+				return "", rpcpcsr.ErrDistEntityNodeNotFound
+			}
+			dst = distEntity.Nodes[rand.Intn(len(distEntity.Nodes))].RemoteAddr
 		}
-		dst = distEntity.Nodes[rand.Intn(len(distEntity.Nodes))].RemoteAddr
-	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
+		return dst, nil
 	}
 
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
 	// 调用路径
 	cp := callpath.CallPath{
 		TargetKind: callpath.Entity,
@@ -206,7 +241,7 @@ func (p EntityProxied) GlobalBalanceRPC(excludeSelf bool, comp, method string, a
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).RPC(dst, cc, cp, args...)
+	return invokeRPC(p.svcCtx, p.retry, p.codec, resolve, cc, sc, cp, args)
 }
 
 // OnewayRPC 向承载实体的首个指定服务节点发起单向 RPC。
@@ -229,11 +264,8 @@ func (p EntityProxied) OnewayRPC(service, comp, method string, args ...any) erro
 		return rpcpcsr.ErrDistEntityNodeNotFound
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -243,7 +275,7 @@ func (p EntityProxied) OnewayRPC(service, comp, method string, args ...any) erro
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(distEntity.Nodes[nodeIdx].RemoteAddr, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(distEntity.Nodes[nodeIdx].RemoteAddr, cc, sc, cp, p.codec, args)
 }
 
 // BalanceOnewayRPC 从承载实体且服务名匹配的节点中随机选择一个发起单向 RPC。
@@ -283,11 +315,8 @@ func (p EntityProxied) BalanceOnewayRPC(service, comp, method string, args ...an
 		}
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -297,7 +326,7 @@ func (p EntityProxied) BalanceOnewayRPC(service, comp, method string, args ...an
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
 }
 
 // GlobalBalanceOnewayRPC 从承载实体的全部节点中随机选择一个发起单向 RPC；excludeSelf 为 true 时排除本节点。
@@ -336,11 +365,8 @@ func (p EntityProxied) GlobalBalanceOnewayRPC(excludeSelf bool, comp, method str
 		dst = distEntity.Nodes[rand.Intn(len(distEntity.Nodes))].RemoteAddr
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -350,7 +376,7 @@ func (p EntityProxied) GlobalBalanceOnewayRPC(excludeSelf bool, comp, method str
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
 }
 
 // BroadcastOnewayRPC 向指定服务中承载该实体的节点广播单向 RPC；excludeSelf 为 true 时排除源节点。
@@ -373,11 +399,8 @@ func (p EntityProxied) BroadcastOnewayRPC(excludeSelf bool, service, comp, metho
 		return rpcpcsr.ErrDistEntityNodeNotFound
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -388,7 +411,7 @@ func (p EntityProxied) BroadcastOnewayRPC(excludeSelf bool, service, comp, metho
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(distEntity.Nodes[nodeIdx].BroadcastAddr, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(distEntity.Nodes[nodeIdx].BroadcastAddr, cc, sc, cp, p.codec, args)
 }
 
 // GlobalBroadcastOnewayRPC 向所有服务中承载该实体的节点广播单向 RPC；excludeSelf 为 true 时排除源节点。
@@ -400,11 +423,8 @@ func (p EntityProxied) GlobalBroadcastOnewayRPC(excludeSelf bool, comp, method s
 	// 全局广播地址
 	dst := dsvc.AddIn.Require(p.svcCtx).NodeDetails().GlobalBroadcastAddr
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -415,7 +435,61 @@ func (p EntityProxied) GlobalBroadcastOnewayRPC(excludeSelf bool, comp, method s
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
+}
+
+// BroadcastRPC 向指定服务中承载该实体的全部节点逐一发起 RPC，返回以节点 ID 为键汇总各节点结果的 Future；
+// service 为空时等同于 GlobalBroadcastRPC，excludeSelf 为 true 时排除本节点。Future 的值为 map[uid.ID]ResultValues，可使用 BroadcastResults 解析。
+func (p EntityProxied) BroadcastRPC(excludeSelf bool, service, comp, method string, args ...any) async.Future {
+	if p.svcCtx == nil {
+		exception.Panic("rpc: svcCtx is nil")
+	}
+
+	// 目标节点
+	targets, err := distEntityTargets(p.svcCtx, p.id, service, excludeSelf)
+	if err != nil {
+		return async.Rejected(err)
+	}
+
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
+	// 调用路径
+	cp := callpath.CallPath{
+		TargetKind: callpath.Entity,
+		ID:         p.id,
+		Script:     comp,
+		Method:     method,
+	}
+
+	return gatherRPC(requireRPC(p.svcCtx), targets, cc, sc, cp, p.codec, args)
+}
+
+// GlobalBroadcastRPC 向所有服务中承载该实体的节点逐一发起 RPC，返回以节点 ID 为键汇总各节点结果的 Future；
+// excludeSelf 为 true 时排除本节点。Future 的值为 map[uid.ID]ResultValues，可使用 BroadcastResults 解析。
+func (p EntityProxied) GlobalBroadcastRPC(excludeSelf bool, comp, method string, args ...any) async.Future {
+	if p.svcCtx == nil {
+		exception.Panic("rpc: svcCtx is nil")
+	}
+
+	// 目标节点
+	targets, err := distEntityTargets(p.svcCtx, p.id, "", excludeSelf)
+	if err != nil {
+		return async.Rejected(err)
+	}
+
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
+	// 调用路径
+	cp := callpath.CallPath{
+		TargetKind: callpath.Entity,
+		ID:         p.id,
+		Script:     comp,
+		Method:     method,
+	}
+
+	return gatherRPC(requireRPC(p.svcCtx), targets, cc, sc, cp, p.codec, args)
 }
 
 // CliRPC 向实体 ID 对应的客户端单播地址发起 RPC。
@@ -427,11 +501,8 @@ func (p EntityProxied) CliRPC(script, method string, args ...any) async.Future {
 	// 客户端地址
 	dst := gate.ClientDetails.DomainUnicast.Join(p.id.String())
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -440,7 +511,7 @@ func (p EntityProxied) CliRPC(script, method string, args ...any) async.Future {
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).RPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedRPC(dst, cc, sc, cp, "", p.codec, args)
 }
 
 // CliOnewayRPC 向实体 ID 对应的客户端单播地址发起单向 RPC。
@@ -452,11 +523,8 @@ func (p EntityProxied) CliOnewayRPC(script, method string, args ...any) error {
 	// 客户端地址
 	dst := gate.ClientDetails.DomainUnicast.Join(p.id.String())
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -465,5 +533,5 @@ func (p EntityProxied) CliOnewayRPC(script, method string, args ...any) error {
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
 }
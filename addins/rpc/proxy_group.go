@@ -20,22 +20,31 @@
 package rpc
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+
 	"git.golaxy.org/core"
 	"git.golaxy.org/core/runtime"
 	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
 	"git.golaxy.org/core/utils/exception"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/dent"
 	"git.golaxy.org/framework/addins/gate"
+	"git.golaxy.org/framework/addins/router"
 	"git.golaxy.org/framework/addins/rpc/callpath"
-	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
 )
 
-// ProxyGroup 使用 provider 所在的服务上下文创建客户端组 name 的 RPC 代理。
+// ProxyGroup 使用 provider 所在的服务上下文创建路由组 name 的 RPC 代理。
 // provider 必须是 service.Context 或实现 runtime.CurrentContextProvider，否则 panic。
 func ProxyGroup(provider any, name string) GroupProxied {
 	if provider == nil {
 		exception.Panicf("rpc: %w: provider is nil", core.ErrArgs)
 	}
 	p := GroupProxied{
+		name: name,
 		addr: gate.ClientDetails.DomainMulticast.Join(name),
 	}
 	switch x := provider.(type) {
@@ -50,24 +59,87 @@ func ProxyGroup(provider any, name string) GroupProxied {
 	return p
 }
 
-// GroupProxied 绑定一个客户端组播地址。
+// GroupProxied 绑定一个路由组，可向组内客户端组播，也可按成员实体在服务端扇出调用。
 type GroupProxied struct {
 	svcCtx service.Context
 	rtCtx  runtime.Context
+	name   string
 	addr   string
 }
 
+// RPC 向组内全部成员实体发起 RPC：通过 dent 查询承载各成员实体的首个指定服务节点，按节点合并为批量请求发送；
+// 返回的 Future 在所有节点响应、失败或超时后，以 map[uid.ID]ResultValues 完成，键为成员实体 ID。
+// 组不存在时返回已携带 ErrGroupNotFound 的 Future。
+func (p GroupProxied) RPC(service, comp, method string, args ...any) async.Future {
+	if p.svcCtx == nil {
+		exception.Panic("rpc: svcCtx is nil")
+	}
+
+	// 按承载节点划分成员实体
+	batches, failed, err := p.partition(service)
+	if err != nil {
+		return async.Rejected(err)
+	}
+
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
+	// 调用路径，实体 ID 由批量消息携带
+	cp := callpath.CallPath{
+		TargetKind: callpath.Entity,
+		Script:     comp,
+		Method:     method,
+	}
+
+	return gatherGroupRPC(requireRPC(p.svcCtx), batches, failed, cc, sc, cp, args)
+}
+
+// OnewayRPC 向组内全部成员实体发起单向 RPC，按承载成员实体的首个指定服务节点合并为批量通知发送。
+// 无法路由的成员与发送失败的批次不影响其余成员，其错误合并后返回。
+func (p GroupProxied) OnewayRPC(service, comp, method string, args ...any) error {
+	if p.svcCtx == nil {
+		exception.Panic("rpc: svcCtx is nil")
+	}
+
+	// 按承载节点划分成员实体
+	batches, failed, err := p.partition(service)
+	if err != nil {
+		return err
+	}
+
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
+	// 调用路径，实体 ID 由批量消息携带
+	cp := callpath.CallPath{
+		TargetKind: callpath.Entity,
+		Script:     comp,
+		Method:     method,
+	}
+
+	var errs []error
+	for id, err := range failed {
+		errs = append(errs, fmt.Errorf("entity %s: %w", id, err))
+	}
+
+	r := requireRPC(p.svcCtx)
+	for dst, entityIDs := range batches {
+		if err := r.tracedGroupOnewayRPC(dst, cc, sc, cp, entityIDs, args); err != nil {
+			errs = append(errs, fmt.Errorf("node %s: %w", dst, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 // CliOnewayRPC 向组内客户端广播单向 RPC。
 func (p GroupProxied) CliOnewayRPC(script, method string, args ...any) error {
 	if p.svcCtx == nil {
 		exception.Panic("rpc: svcCtx is nil")
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -76,5 +148,44 @@ func (p GroupProxied) CliOnewayRPC(script, method string, args ...any) error {
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(p.addr, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(p.addr, cc, sc, cp, nil, args)
+}
+
+// partition 查询组成员，并通过 dent 按承载成员实体的首个指定服务节点地址分组；无法路由的成员及其原因记入 failed。
+func (p GroupProxied) partition(service string) (batches map[string][]uid.ID, failed map[uid.ID]error, err error) {
+	group, ok := router.AddIn.Require(p.svcCtx).GetGroupByName(p.svcCtx, p.name)
+	if !ok {
+		return nil, nil, rpcpcsr.ErrGroupNotFound
+	}
+
+	batches, failed = partitionMembers(dent.QuerierAddIn.Require(p.svcCtx), group.List(), service)
+	return batches, failed, nil
+}
+
+// partitionMembers 按承载成员实体的首个指定服务节点地址分组；实体不存在记为 ErrDistEntityNotFound，
+// 实体不在指定服务中记为 ErrDistEntityNodeNotFound。
+func partitionMembers(querier dent.IDistEntityQuerier, members []uid.ID, service string) (batches map[string][]uid.ID, failed map[uid.ID]error) {
+	batches = map[string][]uid.ID{}
+	failed = map[uid.ID]error{}
+
+	for _, id := range members {
+		distEntity, ok := querier.GetDistEntity(id)
+		if !ok {
+			failed[id] = rpcpcsr.ErrDistEntityNotFound
+			continue
+		}
+
+		nodeIdx := slices.IndexFunc(distEntity.Nodes, func(node dent.Node) bool {
+			return node.Service == service
+		})
+		if nodeIdx < 0 {
+			failed[id] = rpcpcsr.ErrDistEntityNodeNotFound
+			continue
+		}
+
+		addr := distEntity.Nodes[nodeIdx].RemoteAddr
+		batches[addr] = append(batches[addr], id)
+	}
+
+	return batches, failed
 }
@@ -0,0 +1,145 @@
+package rpc
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/dent"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/utils/tracing"
+)
+
+type fakeQuerier map[uid.ID]*dent.DistEntity
+
+func (q fakeQuerier) GetDistEntity(id uid.ID) (*dent.DistEntity, bool) {
+	distEntity, ok := q[id]
+	return distEntity, ok
+}
+
+func TestPartitionMembers(t *testing.T) {
+	querier := fakeQuerier{
+		uid.From("e1"): {Nodes: []dent.Node{{Service: "gate", RemoteAddr: "gate-1"}, {Service: "lobby", RemoteAddr: "lobby-1"}}},
+		uid.From("e2"): {Nodes: []dent.Node{{Service: "lobby", RemoteAddr: "lobby-2"}, {Service: "lobby", RemoteAddr: "lobby-1"}}},
+		uid.From("e3"): {Nodes: []dent.Node{{Service: "lobby", RemoteAddr: "lobby-1"}}},
+		uid.From("e4"): {Nodes: []dent.Node{{Service: "gate", RemoteAddr: "gate-1"}}},
+	}
+
+	members := []uid.ID{uid.From("e1"), uid.From("e2"), uid.From("e3"), uid.From("e4"), uid.From("e5")}
+
+	batches, failed := partitionMembers(querier, members, "lobby")
+
+	if got := batches["lobby-1"]; !slices.Equal(got, []uid.ID{uid.From("e1"), uid.From("e3")}) {
+		t.Fatalf("lobby-1 batch = %v", got)
+	}
+	if got := batches["lobby-2"]; !slices.Equal(got, []uid.ID{uid.From("e2")}) {
+		t.Fatalf("lobby-2 batch = %v", got)
+	}
+	if len(batches) != 2 {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+
+	if !errors.Is(failed[uid.From("e4")], rpcpcsr.ErrDistEntityNodeNotFound) {
+		t.Fatalf("e4 error = %v, want ErrDistEntityNodeNotFound", failed[uid.From("e4")])
+	}
+	if !errors.Is(failed[uid.From("e5")], rpcpcsr.ErrDistEntityNotFound) {
+		t.Fatalf("e5 error = %v, want ErrDistEntityNotFound", failed[uid.From("e5")])
+	}
+	if len(failed) != 2 {
+		t.Fatalf("unexpected failed members: %v", failed)
+	}
+}
+
+type fakeGroupCaller struct {
+	mutex sync.Mutex
+	calls map[string][]uid.ID
+	reply func(dst string, entityIDs []uid.ID) async.Result
+}
+
+func (c *fakeGroupCaller) tracedGroupRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, entityIDs []uid.ID, args []any) async.Future {
+	c.mutex.Lock()
+	if c.calls == nil {
+		c.calls = map[string][]uid.ID{}
+	}
+	c.calls[dst] = entityIDs
+	c.mutex.Unlock()
+
+	promise, future := async.NewPromise()
+	go promise.Resolve(c.reply(dst, entityIDs))
+	return future
+}
+
+func TestGatherGroupRPC(t *testing.T) {
+	batchErr := errors.New("node down")
+	entityErr := errors.New("entity failed")
+
+	caller := &fakeGroupCaller{
+		reply: func(dst string, entityIDs []uid.ID) async.Result {
+			switch dst {
+			case "node-a":
+				return async.NewResult(map[uid.ID]async.Result{
+					uid.From("e1"): async.NewResult(mustResultArray(t, "ok"), nil),
+					uid.From("e2"): async.NewResult(nil, entityErr),
+					// e3 缺失
+				}, nil)
+			default:
+				return async.NewResult(nil, batchErr)
+			}
+		},
+	}
+
+	batches := map[string][]uid.ID{
+		"node-a": {uid.From("e1"), uid.From("e2"), uid.From("e3")},
+		"node-b": {uid.From("e4"), uid.From("e5")},
+	}
+	failed := map[uid.ID]error{
+		uid.From("e6"): rpcpcsr.ErrDistEntityNotFound,
+	}
+
+	rets := AssertBroadcast(gatherGroupRPC(caller, batches, failed, rpcstack.EmptyCallChain, tracing.SpanContext{}, callpath.CallPath{TargetKind: callpath.Entity}, nil))
+
+	if len(caller.calls) != len(batches) {
+		t.Fatalf("expected %d batch calls, got %v", len(batches), caller.calls)
+	}
+	if len(rets) != 6 {
+		t.Fatalf("expected 6 results, got %d: %+v", len(rets), rets)
+	}
+
+	if rvs := rets[uid.From("e1")]; rvs.Error != nil || len(rvs.Values) != 1 || rvs.Values[0] != "ok" {
+		t.Fatalf("unexpected e1 result: %+v", rvs)
+	}
+
+	want := map[string]error{
+		"e2": entityErr,
+		"e3": rpcpcsr.ErrUndeliverable,
+		"e4": batchErr,
+		"e5": batchErr,
+		"e6": rpcpcsr.ErrDistEntityNotFound,
+	}
+	for id, err := range want {
+		if got := rets[uid.From(id)].Error; !errors.Is(got, err) {
+			t.Errorf("%s error = %v, want %v", id, got, err)
+		}
+	}
+}
+
+func TestGatherGroupRPCOnlyFailed(t *testing.T) {
+	caller := &fakeGroupCaller{
+		reply: func(string, []uid.ID) async.Result {
+			t.Fatal("unexpected call")
+			return async.Result{}
+		},
+	}
+
+	failed := map[uid.ID]error{uid.From("e1"): rpcpcsr.ErrDistEntityNodeNotFound}
+
+	rets := AssertBroadcast(gatherGroupRPC(caller, nil, failed, rpcstack.EmptyCallChain, tracing.SpanContext{}, callpath.CallPath{TargetKind: callpath.Entity}, nil))
+	if len(rets) != 1 || !errors.Is(rets[uid.From("e1")].Error, rpcpcsr.ErrDistEntityNodeNotFound) {
+		t.Fatalf("unexpected results: %+v", rets)
+	}
+}
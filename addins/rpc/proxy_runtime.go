@@ -33,7 +33,7 @@ import (
 	"git.golaxy.org/framework/addins/dsvc"
 	"git.golaxy.org/framework/addins/rpc/callpath"
 	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
-	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
 )
 
 // ProxyRuntime 使用 provider 所在的服务上下文创建实体 entityID 的运行时 RPC 代理。
@@ -62,34 +62,57 @@ type RuntimeProxied struct {
 	svcCtx   service.Context
 	rtCtx    runtime.Context
 	entityID uid.ID
+	retry    *RetryPolicy
+	codec    *gap.ArgsCodec
+}
+
+// WithRetry 返回使用重试策略的代理副本，该策略优先于插件配置的方法级重试策略；仅作用于需要响应的请求，
+// 广播请求与单向通知不重试。
+func (p RuntimeProxied) WithRetry(policy RetryPolicy) RuntimeProxied {
+	p.retry = &policy
+	return p
+}
+
+// WithArgsCodec 返回使用参数编解码器的代理副本，该设置优先于插件配置的默认编解码器；
+// 分组与批量请求不支持其他编解码器，仍使用 variant 格式。
+func (p RuntimeProxied) WithArgsCodec(codec gap.ArgsCodec) RuntimeProxied {
+	p.codec = &codec
+	return p
 }
 
 // RPC 向承载实体的首个指定服务节点发起运行时插件 RPC；查询失败时返回已携带错误的 Future。
 func (p RuntimeProxied) RPC(service, addIn, method string, args ...any) async.Future {
+	return p.Call(service, addIn, method, args...).RPC()
+}
+
+// Call 构造向承载实体的首个指定服务节点运行时发起的请求，不立即发送；可调用 ProxyCall.RPC 发起或加入 Batch 合并发送。
+func (p RuntimeProxied) Call(service, addIn, method string, args ...any) ProxyCall {
 	if p.svcCtx == nil {
 		exception.Panic("rpc: svcCtx is nil")
 	}
 
-	// 查询分布式实体信息
-	distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.entityID)
-	if !ok {
-		return async.Rejected(rpcpcsr.ErrDistEntityNotFound)
-	}
+	// 目标地址，每次尝试时重新查询以跟随实体迁移
+	resolve := func() (string, error) {
+		// 查询分布式实体信息
+		distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.entityID)
+		if !ok {
+			return "", rpcpcsr.ErrDistEntityNotFound
+		}
 
-	// 查询分布式实体目标服务节点
-	nodeIdx := slices.IndexFunc(distEntity.Nodes, func(node dent.Node) bool {
-		return node.Service == service
-	})
-	if nodeIdx < 0 {
-		return async.Rejected(rpcpcsr.ErrDistEntityNodeNotFound)
-	}
+		// 查询分布式实体目标服务节点
+		nodeIdx := slices.IndexFunc(distEntity.Nodes, func(node dent.Node) bool {
+			return node.Service == service
+		})
+		if nodeIdx < 0 {
+			return "", rpcpcsr.ErrDistEntityNodeNotFound
+		}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
+		return distEntity.Nodes[nodeIdx].RemoteAddr, nil
 	}
 
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
 	// 调用路径
 	cp := callpath.CallPath{
 		TargetKind: callpath.Runtime,
@@ -98,7 +121,16 @@ func (p RuntimeProxied) RPC(service, addIn, method string, args ...any) async.Fu
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).RPC(distEntity.Nodes[nodeIdx].RemoteAddr, cc, cp, args...)
+	return ProxyCall{
+		svcCtx:  p.svcCtx,
+		retry:   p.retry,
+		codec:   p.codec,
+		resolve: resolve,
+		cc:      cc,
+		sc:      sc,
+		cp:      cp,
+		args:    args,
+	}
 }
 
 // BalanceRPC 从承载实体且服务名匹配的节点中随机选择一个发起运行时插件 RPC。
@@ -107,43 +139,45 @@ func (p RuntimeProxied) BalanceRPC(service, addIn, method string, args ...any) a
 		exception.Panic("rpc: svcCtx is nil")
 	}
 
-	// 查询分布式实体信息
-	distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.entityID)
-	if !ok {
-		return async.Rejected(rpcpcsr.ErrDistEntityNotFound)
-	}
-
-	// 统计节点数量
-	var count int
-	for i := range distEntity.Nodes {
-		if distEntity.Nodes[i].Service == service {
-			count++
+	// 目标地址，每次尝试时重新查询以跟随实体迁移
+	resolve := func() (string, error) {
+		// 查询分布式实体信息
+		distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.entityID)
+		if !ok {
+			return "", rpcpcsr.ErrDistEntityNotFound
 		}
-	}
-	if count <= 0 {
-		return async.Rejected(rpcpcsr.ErrDistEntityNodeNotFound)
-	}
 
-	// 随机目标节点
-	var dst string
-	offset := rand.Intn(count)
+		// 统计节点数量
+		var count int
+		for i := range distEntity.Nodes {
+			if distEntity.Nodes[i].Service == service {
+				count++
+			}
+		}
+		if count <= 0 {
+			return "", rpcpcsr.ErrDistEntityNodeNotFound
+		}
 
-	for i := range distEntity.Nodes {
-		if distEntity.Nodes[i].Service == service {
-			if offset <= 0 {
-				dst = distEntity.Nodes[i].RemoteAddr
-				break
+		// 随机目标节点
+		var dst string
+		offset := rand.Intn(count)
+
+		for i := range distEntity.Nodes {
+			if distEntity.Nodes[i].Service == service {
+				if offset <= 0 {
+					dst = distEntity.Nodes[i].RemoteAddr
+					break
+				}
+				offset--
 			}
-			offset--
 		}
-	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
+		return dst, nil
 	}
 
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
 	// 调用路径
 	cp := callpath.CallPath{
 		TargetKind: callpath.Runtime,
@@ -152,7 +186,7 @@ func (p RuntimeProxied) BalanceRPC(service, addIn, method string, args ...any) a
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).RPC(dst, cc, cp, args...)
+	return invokeRPC(p.svcCtx, p.retry, p.codec, resolve, cc, sc, cp, args)
 }
 
 // GlobalBalanceRPC 从承载实体的全部节点中随机选择一个发起运行时插件 RPC；excludeSelf 为 true 时排除本节点。
@@ -161,42 +195,43 @@ func (p RuntimeProxied) GlobalBalanceRPC(excludeSelf bool, addIn, method string,
 		exception.Panic("rpc: svcCtx is nil")
 	}
 
-	// 查询分布式实体信息
-	distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.entityID)
-	if !ok {
-		return async.Rejected(rpcpcsr.ErrDistEntityNotFound)
-	}
-
-	// 随机目标节点
-	var dst string
-
-	if excludeSelf {
-		if len(distEntity.Nodes) <= 1 {
-			return async.Rejected(rpcpcsr.ErrDistEntityNodeNotFound)
+	// 目标地址，每次尝试时重新查询以跟随实体迁移
+	resolve := func() (string, error) {
+		// 查询分布式实体信息
+		distEntity, ok := dent.QuerierAddIn.Require(p.svcCtx).GetDistEntity(p.entityID)
+		if !ok {
+			return "", rpcpcsr.ErrDistEntityNotFound
 		}
 
-		localAddr := dsvc.AddIn.Require(p.svcCtx).NodeDetails().LocalAddr
-		idx := rand.Intn(len(distEntity.Nodes))
+		// 随机目标节点
+		var dst string
 
-		if distEntity.Nodes[idx].RemoteAddr == localAddr {
-			idx = (idx + 1) % len(distEntity.Nodes)
-		}
+		if excludeSelf {
+			if len(distEntity.Nodes) <= 1 {
+				return "", rpcpcsr.ErrDistEntityNodeNotFound
+			}
 
-		dst = distEntity.Nodes[idx].RemoteAddr
+			localAddr := dsvc.AddIn.Require(p.svcCtx).NodeDetails().LocalAddr
+			idx := rand.Intn(len(distEntity.Nodes))
 
-	} else {
-		if len(distEntity.Nodes) <= 0 {
-			return async.Rejected(rpcpcsr.ErrDistEntityNodeNotFound)
+			if distEntity.Nodes[idx].RemoteAddr == localAddr {
+				idx = (idx + 1) % len(distEntity.Nodes)
+			}
+
+			dst = distEntity.Nodes[idx].RemoteAddr
+		} else {
+			if len(distEntity.Nodes) <= 0 {
+				return "", rpcpcsr.ErrDistEntityNodeNotFound
+			}
+			dst = distEntity.Nodes[rand.Intn(len(distEntity.Nodes))].RemoteAddr
 		}
-		dst = distEntity.Nodes[rand.Intn(len(distEntity.Nodes))].RemoteAddr
-	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
+		return dst, nil
 	}
 
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
 	// 调用路径
 	cp := callpath.CallPath{
 		TargetKind: callpath.Runtime,
@@ -205,7 +240,7 @@ func (p RuntimeProxied) GlobalBalanceRPC(excludeSelf bool, addIn, method string,
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).RPC(dst, cc, cp, args...)
+	return invokeRPC(p.svcCtx, p.retry, p.codec, resolve, cc, sc, cp, args)
 }
 
 // OnewayRPC 向承载实体的首个指定服务节点发起运行时插件单向 RPC。
@@ -228,11 +263,8 @@ func (p RuntimeProxied) OnewayRPC(service, addIn, method string, args ...any) er
 		return rpcpcsr.ErrDistEntityNodeNotFound
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -242,7 +274,7 @@ func (p RuntimeProxied) OnewayRPC(service, addIn, method string, args ...any) er
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(distEntity.Nodes[nodeIdx].RemoteAddr, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(distEntity.Nodes[nodeIdx].RemoteAddr, cc, sc, cp, p.codec, args)
 }
 
 // BalanceOnewayRPC 从承载实体且服务名匹配的节点中随机选择一个发起运行时插件单向 RPC。
@@ -282,11 +314,8 @@ func (p RuntimeProxied) BalanceOnewayRPC(service, addIn, method string, args ...
 		}
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -296,7 +325,7 @@ func (p RuntimeProxied) BalanceOnewayRPC(service, addIn, method string, args ...
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
 }
 
 // GlobalBalanceOnewayRPC 从承载实体的全部节点中随机选择一个发起运行时插件单向 RPC；excludeSelf 为 true 时排除本节点。
@@ -335,11 +364,8 @@ func (p RuntimeProxied) GlobalBalanceOnewayRPC(excludeSelf bool, addIn, method s
 		dst = distEntity.Nodes[rand.Intn(len(distEntity.Nodes))].RemoteAddr
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -349,7 +375,7 @@ func (p RuntimeProxied) GlobalBalanceOnewayRPC(excludeSelf bool, addIn, method s
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
 }
 
 // BroadcastOnewayRPC 向指定服务中承载该实体的运行时广播单向 RPC；excludeSelf 为 true 时排除源节点。
@@ -372,11 +398,8 @@ func (p RuntimeProxied) BroadcastOnewayRPC(excludeSelf bool, service, addIn, met
 		return rpcpcsr.ErrDistEntityNodeNotFound
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -387,7 +410,7 @@ func (p RuntimeProxied) BroadcastOnewayRPC(excludeSelf bool, service, addIn, met
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(distEntity.Nodes[nodeIdx].BroadcastAddr, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(distEntity.Nodes[nodeIdx].BroadcastAddr, cc, sc, cp, p.codec, args)
 }
 
 // GlobalBroadcastOnewayRPC 向所有承载该实体的运行时广播单向 RPC；excludeSelf 为 true 时排除源节点。
@@ -399,11 +422,8 @@ func (p RuntimeProxied) GlobalBroadcastOnewayRPC(excludeSelf bool, addIn, method
 	// 全局广播地址
 	dst := dsvc.AddIn.Require(p.svcCtx).NodeDetails().GlobalBroadcastAddr
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -414,5 +434,59 @@ func (p RuntimeProxied) GlobalBroadcastOnewayRPC(excludeSelf bool, addIn, method
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
+}
+
+// BroadcastRPC 向指定服务中承载该实体的全部运行时逐一发起 RPC，返回以节点 ID 为键汇总各节点结果的 Future；
+// service 为空时等同于 GlobalBroadcastRPC，excludeSelf 为 true 时排除本节点。Future 的值为 map[uid.ID]ResultValues，可使用 BroadcastResults 解析。
+func (p RuntimeProxied) BroadcastRPC(excludeSelf bool, service, addIn, method string, args ...any) async.Future {
+	if p.svcCtx == nil {
+		exception.Panic("rpc: svcCtx is nil")
+	}
+
+	// 目标节点
+	targets, err := distEntityTargets(p.svcCtx, p.entityID, service, excludeSelf)
+	if err != nil {
+		return async.Rejected(err)
+	}
+
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
+	// 调用路径
+	cp := callpath.CallPath{
+		TargetKind: callpath.Runtime,
+		ID:         p.entityID,
+		Script:     addIn,
+		Method:     method,
+	}
+
+	return gatherRPC(requireRPC(p.svcCtx), targets, cc, sc, cp, p.codec, args)
+}
+
+// GlobalBroadcastRPC 向所有承载该实体的运行时逐一发起 RPC，返回以节点 ID 为键汇总各节点结果的 Future；
+// excludeSelf 为 true 时排除本节点。Future 的值为 map[uid.ID]ResultValues，可使用 BroadcastResults 解析。
+func (p RuntimeProxied) GlobalBroadcastRPC(excludeSelf bool, addIn, method string, args ...any) async.Future {
+	if p.svcCtx == nil {
+		exception.Panic("rpc: svcCtx is nil")
+	}
+
+	// 目标节点
+	targets, err := distEntityTargets(p.svcCtx, p.entityID, "", excludeSelf)
+	if err != nil {
+		return async.Rejected(err)
+	}
+
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
+	// 调用路径
+	cp := callpath.CallPath{
+		TargetKind: callpath.Runtime,
+		ID:         p.entityID,
+		Script:     addIn,
+		Method:     method,
+	}
+
+	return gatherRPC(requireRPC(p.svcCtx), targets, cc, sc, cp, p.codec, args)
 }
@@ -20,15 +20,20 @@
 package rpc
 
 import (
+	"errors"
+	"fmt"
+
 	"git.golaxy.org/core"
 	"git.golaxy.org/core/runtime"
 	"git.golaxy.org/core/service"
 	"git.golaxy.org/core/utils/async"
 	"git.golaxy.org/core/utils/exception"
 	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/discovery"
 	"git.golaxy.org/framework/addins/dsvc"
 	"git.golaxy.org/framework/addins/rpc/callpath"
-	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/net/gap"
 )
 
 // ProxyService 使用 provider 所在的服务上下文创建服务 RPC 代理。
@@ -54,25 +59,50 @@ func ProxyService(provider any) ServiceProxied {
 type ServiceProxied struct {
 	svcCtx service.Context
 	rtCtx  runtime.Context
+	route  *RoutePolicy
+	retry  *RetryPolicy
+	codec  *gap.ArgsCodec
+}
+
+// WithRetry 返回使用重试策略的代理副本，该策略优先于插件配置的方法级重试策略；仅作用于需要响应的请求，
+// 广播请求与单向通知不重试。
+func (p ServiceProxied) WithRetry(policy RetryPolicy) ServiceProxied {
+	p.retry = &policy
+	return p
+}
+
+// WithArgsCodec 返回使用参数编解码器的代理副本，该设置优先于插件配置的默认编解码器；
+// 分组与批量请求不支持其他编解码器，仍使用 variant 格式。
+func (p ServiceProxied) WithArgsCodec(codec gap.ArgsCodec) ServiceProxied {
+	p.codec = &codec
+	return p
+}
+
+// WithRoute 返回使用路由策略的代理副本。设置策略后，BalanceRPC 与 BalanceOnewayRPC 改为按策略从服务发现节点中
+// 随机选择节点并单播投递，HashRPC 与 HashOnewayRPC 仅在满足策略的节点中哈希选择，BroadcastRPC 仅向满足过滤条件的节点广播。
+func (p ServiceProxied) WithRoute(policy RoutePolicy) ServiceProxied {
+	p.route = &policy
+	return p
 }
 
 // RPC 向 nodeID 标识的服务节点发起 RPC；地址构造失败时返回已携带错误的 Future。
 func (p ServiceProxied) RPC(nodeID uid.ID, addIn, method string, args ...any) async.Future {
+	return p.Call(nodeID, addIn, method, args...).RPC()
+}
+
+// Call 构造向 nodeID 标识的服务节点发起的请求，不立即发送；可调用 ProxyCall.RPC 发起或加入 Batch 合并发送。
+func (p ServiceProxied) Call(nodeID uid.ID, addIn, method string, args ...any) ProxyCall {
 	if p.svcCtx == nil {
 		exception.Panic("rpc: svcCtx is nil")
 	}
 
-	// 目标地址
-	dst, err := dsvc.AddIn.Require(p.svcCtx).NodeDetails().MakeNodeAddr(nodeID)
-	if err != nil {
-		return async.Rejected(err)
+	// 目标地址，每次尝试时重新解析
+	resolve := func() (string, error) {
+		return dsvc.AddIn.Require(p.svcCtx).NodeDetails().MakeNodeAddr(nodeID)
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -81,30 +111,59 @@ func (p ServiceProxied) RPC(nodeID uid.ID, addIn, method string, args ...any) as
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).RPC(dst, cc, cp, args...)
+	return ProxyCall{
+		svcCtx:  p.svcCtx,
+		retry:   p.retry,
+		codec:   p.codec,
+		resolve: resolve,
+		cc:      cc,
+		sc:      sc,
+		cp:      cp,
+		args:    args,
+	}
 }
 
 // BalanceRPC 向指定服务名的负载均衡地址发起 RPC；service 为空时使用全局负载均衡地址。
+// 代理设置了路由策略时，改为按策略选择节点并单播投递，此时 service 不得为空；
+// 存在熔断打开的节点时，指定服务的调用改为在可用节点中随机选择并单播投递。
 func (p ServiceProxied) BalanceRPC(service, addIn, method string, args ...any) async.Future {
 	if p.svcCtx == nil {
 		exception.Panic("rpc: svcCtx is nil")
 	}
 
-	// 目标地址
-	var dst string
+	// 目标地址，每次尝试时重新解析
+	resolve := func() (string, error) {
+		return p.balanceAddr(service)
+	}
 
-	if service != "" {
-		dst = dsvc.AddIn.Require(p.svcCtx).NodeDetails().MakeBalanceAddr(service)
-	} else {
-		dst = dsvc.AddIn.Require(p.svcCtx).NodeDetails().GlobalBalanceAddr
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
+	// 调用路径
+	cp := callpath.CallPath{
+		TargetKind: callpath.Service,
+		Script:     addIn,
+		Method:     method,
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
+	return invokeRPC(p.svcCtx, p.retry, p.codec, resolve, cc, sc, cp, args)
+}
+
+// HashRPC 按 key 在指定服务节点组成的一致性哈希环上选择节点并发起 RPC；相同 key 在节点集合不变时总会路由到同一节点，
+// 节点增减时仅迁移少量 key。节点列表来自服务发现并通过监听保持更新；代理设置了路由策略时仅选择满足策略的节点。
+func (p ServiceProxied) HashRPC(service, key, addIn, method string, args ...any) async.Future {
+	if p.svcCtx == nil {
+		exception.Panic("rpc: svcCtx is nil")
 	}
 
+	// 目标地址，每次尝试时重新解析
+	resolve := func() (string, error) {
+		return p.hashNodeAddr(service, key)
+	}
+
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
 	// 调用路径
 	cp := callpath.CallPath{
 		TargetKind: callpath.Service,
@@ -112,7 +171,7 @@ func (p ServiceProxied) BalanceRPC(service, addIn, method string, args ...any) a
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).RPC(dst, cc, cp, args...)
+	return invokeRPC(p.svcCtx, p.retry, p.codec, resolve, cc, sc, cp, args)
 }
 
 // OnewayRPC 向 nodeID 标识的服务节点发起单向 RPC。
@@ -127,11 +186,8 @@ func (p ServiceProxied) OnewayRPC(nodeID uid.ID, addIn, method string, args ...a
 		return err
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -140,30 +196,51 @@ func (p ServiceProxied) OnewayRPC(nodeID uid.ID, addIn, method string, args ...a
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
 }
 
 // BalanceOnewayRPC 向指定服务名的负载均衡地址发起单向 RPC；service 为空时使用全局负载均衡地址。
+// 代理设置了路由策略时，改为按策略选择节点并单播投递，此时 service 不得为空；
+// 存在熔断打开的节点时，指定服务的调用改为在可用节点中随机选择并单播投递。
 func (p ServiceProxied) BalanceOnewayRPC(service, addIn, method string, args ...any) error {
 	if p.svcCtx == nil {
 		exception.Panic("rpc: svcCtx is nil")
 	}
 
 	// 目标地址
-	var dst string
+	dst, err := p.balanceAddr(service)
+	if err != nil {
+		return err
+	}
 
-	if service != "" {
-		dst = dsvc.AddIn.Require(p.svcCtx).NodeDetails().MakeBalanceAddr(service)
-	} else {
-		dst = dsvc.AddIn.Require(p.svcCtx).NodeDetails().GlobalBalanceAddr
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
+	// 调用路径
+	cp := callpath.CallPath{
+		TargetKind: callpath.Service,
+		Script:     addIn,
+		Method:     method,
+	}
+
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
+}
+
+// HashOnewayRPC 按 key 在指定服务节点组成的一致性哈希环上选择节点并发起单向 RPC。
+func (p ServiceProxied) HashOnewayRPC(service, key, addIn, method string, args ...any) error {
+	if p.svcCtx == nil {
+		exception.Panic("rpc: svcCtx is nil")
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
+	// 目标地址
+	dst, err := p.hashNodeAddr(service, key)
+	if err != nil {
+		return err
 	}
 
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
 	// 调用路径
 	cp := callpath.CallPath{
 		TargetKind: callpath.Service,
@@ -171,7 +248,7 @@ func (p ServiceProxied) BalanceOnewayRPC(service, addIn, method string, args ...
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
 }
 
 // BroadcastOnewayRPC 向指定服务名广播单向 RPC；service 为空时全局广播，excludeSelf 为 true 时排除源节点。
@@ -189,11 +266,8 @@ func (p ServiceProxied) BroadcastOnewayRPC(excludeSelf bool, service, addIn, met
 		dst = dsvc.AddIn.Require(p.svcCtx).NodeDetails().GlobalBroadcastAddr
 	}
 
-	// 调用链
-	cc := rpcstack.EmptyCallChain
-	if p.rtCtx != nil {
-		cc = rpcstack.AddIn.Require(p.rtCtx).CallChain()
-	}
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
 
 	// 调用路径
 	cp := callpath.CallPath{
@@ -203,5 +277,129 @@ func (p ServiceProxied) BroadcastOnewayRPC(excludeSelf bool, service, addIn, met
 		Method:     method,
 	}
 
-	return AddIn.Require(p.svcCtx).OnewayRPC(dst, cc, cp, args...)
+	return requireRPC(p.svcCtx).tracedOnewayRPC(dst, cc, sc, cp, p.codec, args)
+}
+
+// BroadcastRPC 从服务节点缓存中取得指定服务名的全部节点（设置了路由策略时仅限满足过滤条件的节点）并逐一发起 RPC，返回以节点 ID 为键汇总各节点结果的 Future；
+// service 为空时取全部服务的节点，excludeSelf 为 true 时排除本节点；服务不存在或没有可调用的节点时返回 ErrServiceNodeNotFound。
+// Future 的值为 map[uid.ID]ResultValues，可使用 BroadcastResults 解析。
+func (p ServiceProxied) BroadcastRPC(excludeSelf bool, service, addIn, method string, args ...any) async.Future {
+	if p.svcCtx == nil {
+		exception.Panic("rpc: svcCtx is nil")
+	}
+
+	// 从监听维护的节点缓存读取服务节点，不逐次查询服务发现
+	nodes, err := requireRPC(p.svcCtx).serviceNodes(service)
+	if err != nil {
+		return async.Rejected(err)
+	}
+
+	// 目标节点
+	details := dsvc.AddIn.Require(p.svcCtx).NodeDetails()
+	var targets []gatherTarget
+
+	for _, node := range p.route.filter(nodes.nodes) {
+		dst, err := details.MakeNodeAddr(node.ID)
+		if err != nil {
+			continue
+		}
+		if excludeSelf && dst == details.LocalAddr {
+			continue
+		}
+		targets = append(targets, gatherTarget{nodeID: node.ID, addr: dst})
+	}
+	if len(targets) <= 0 {
+		return async.Rejected(rpcpcsr.ErrServiceNodeNotFound)
+	}
+
+	// 调用链与追踪上下文
+	cc, sc := callContext(p.rtCtx)
+
+	// 调用路径
+	cp := callpath.CallPath{
+		TargetKind: callpath.Service,
+		Script:     addIn,
+		Method:     method,
+	}
+
+	return gatherRPC(requireRPC(p.svcCtx), targets, cc, sc, cp, p.codec, args)
+}
+
+func (p ServiceProxied) hashNodeAddr(service, key string) (string, error) {
+	if service == "" {
+		return "", fmt.Errorf("rpc: %w: service is empty", core.ErrArgs)
+	}
+
+	nodes, err := requireRPC(p.svcCtx).serviceNodes(service)
+	if err != nil {
+		return "", err
+	}
+
+	node, ok := p.route.selectHash(nodes, key, p.healthyFilter())
+	if !ok {
+		if _, ok := p.route.selectHash(nodes, key, nil); ok {
+			return "", rpcpcsr.ErrCircuitOpen
+		}
+		return "", rpcpcsr.ErrServiceNodeNotFound
+	}
+
+	return dsvc.AddIn.Require(p.svcCtx).NodeDetails().MakeNodeAddr(node.ID)
+}
+
+func (p ServiceProxied) routeNodeAddr(service string) (string, error) {
+	if service == "" {
+		return "", fmt.Errorf("rpc: %w: service is empty, route policy requires a named service", core.ErrArgs)
+	}
+
+	nodes, err := requireRPC(p.svcCtx).serviceNodes(service)
+	if err != nil {
+		return "", err
+	}
+
+	node, ok := p.route.selectRandom(nodes, p.healthyFilter())
+	if !ok {
+		if _, ok := p.route.selectRandom(nodes, nil); ok {
+			return "", rpcpcsr.ErrCircuitOpen
+		}
+		return "", rpcpcsr.ErrServiceNodeNotFound
+	}
+
+	return dsvc.AddIn.Require(p.svcCtx).NodeDetails().MakeNodeAddr(node.ID)
+}
+
+func (p ServiceProxied) balanceAddr(service string) (string, error) {
+	if p.route != nil {
+		return p.routeNodeAddr(service)
+	}
+
+	details := dsvc.AddIn.Require(p.svcCtx).NodeDetails()
+
+	if service == "" {
+		return details.GlobalBalanceAddr, nil
+	}
+
+	// 存在熔断节点时改为在可用节点中选择，避免经负载均衡地址投递到被隔离的节点；节点列表不可用时退回负载均衡地址
+	if requireRPC(p.svcCtx).ejecting() {
+		dst, err := p.routeNodeAddr(service)
+		if err == nil || errors.Is(err, rpcpcsr.ErrCircuitOpen) {
+			return dst, err
+		}
+	}
+
+	return details.MakeBalanceAddr(service), nil
+}
+
+// healthyFilter 返回跳过熔断打开节点的过滤器；没有熔断节点时返回 nil。
+func (p ServiceProxied) healthyFilter() NodeFilter {
+	rpc := requireRPC(p.svcCtx)
+	if !rpc.ejecting() {
+		return nil
+	}
+
+	details := dsvc.AddIn.Require(p.svcCtx).NodeDetails()
+
+	return func(node *discovery.Node) bool {
+		addr, err := details.MakeNodeAddr(node.ID)
+		return err == nil && rpc.nodeAvailable(addr)
+	}
 }
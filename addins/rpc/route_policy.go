@@ -0,0 +1,272 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpc
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"git.golaxy.org/framework/addins/discovery"
+	"git.golaxy.org/framework/utils/hashring"
+)
+
+// NodeFilter 判断服务发现中的节点是否可作为路由目标。
+type NodeFilter = func(node *discovery.Node) bool
+
+// RouteWeight 将一部分流量分配给满足 Filter 的节点，Weight 为相对权重。
+type RouteWeight struct {
+	Weight int        // Weight 是相对权重，小于等于 0 时该组不参与路由。
+	Filter NodeFilter // Filter 选择该组节点；nil 表示全部节点。
+}
+
+// RoutePolicy 描述基于服务发现节点 Version 与 Meta 的路由策略。
+//
+// 候选节点必须满足全部 Filters；Weights 非空时，先按权重在至少有一个候选节点的分组中选择分组，
+// 再在分组内选择节点。随机路由逐次按权重抽样；哈希路由根据 key 确定分组，同一 key 的分组选择保持稳定。
+type RoutePolicy struct {
+	Filters []NodeFilter  // Filters 是节点必须全部满足的过滤条件。
+	Weights []RouteWeight // Weights 是可选的加权分组，常用于金丝雀发布。
+}
+
+// VersionAtLeast 返回选择版本不低于 version 的节点的过滤器。
+func VersionAtLeast(version string) NodeFilter {
+	return func(node *discovery.Node) bool {
+		return CompareVersion(node.Version, version) >= 0
+	}
+}
+
+// VersionBelow 返回选择版本低于 version 的节点的过滤器。
+func VersionBelow(version string) NodeFilter {
+	return func(node *discovery.Node) bool {
+		return CompareVersion(node.Version, version) < 0
+	}
+}
+
+// MetaEquals 返回选择元数据 key 等于 value 的节点的过滤器。
+func MetaEquals(key, value string) NodeFilter {
+	return func(node *discovery.Node) bool {
+		v, ok := node.Meta[key]
+		return ok && v == value
+	}
+}
+
+// CompareVersion 按语义化版本的优先级比较 a 与 b，忽略前缀 v 及 + 之后的构建元数据。
+// 主版本部分按点分段比较，数字段按数值比较，其他段按字典序比较，缺失段视为 0；
+// 主版本部分相同时，带 - 预发布后缀的版本低于正式版本，两者都带后缀时按点分标识逐个比较，
+// 数字标识按数值比较且低于非数字标识，其他标识按字典序比较，标识较少且前缀相同的一方较低。
+// a 小于、等于、大于 b 时分别返回 -1、0、1。
+func CompareVersion(a, b string) int {
+	ar, ap := splitVersion(a)
+	br, bp := splitVersion(b)
+
+	for i := range max(len(ar), len(br)) {
+		var x, y string
+		if i < len(ar) {
+			x = ar[i]
+		}
+		if i < len(br) {
+			y = br[i]
+		}
+		if c := compareVersionPart(orZero(x), orZero(y), false); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case ap == nil && bp == nil:
+		return 0
+	case ap == nil:
+		return 1
+	case bp == nil:
+		return -1
+	}
+
+	for i := range min(len(ap), len(bp)) {
+		if c := compareVersionPart(ap[i], bp[i], true); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(ap) < len(bp):
+		return -1
+	case len(ap) > len(bp):
+		return 1
+	}
+	return 0
+}
+
+// compareVersionPart 比较两个版本段；两者都是数字时按数值比较，否则按字典序比较。
+// numericFirst 为 true 时数字段低于非数字段，用于比较预发布标识。
+func compareVersionPart(x, y string, numericFirst bool) int {
+	xn, xerr := strconv.ParseUint(x, 10, 64)
+	yn, yerr := strconv.ParseUint(y, 10, 64)
+
+	switch {
+	case xerr == nil && yerr == nil:
+		switch {
+		case xn < yn:
+			return -1
+		case xn > yn:
+			return 1
+		}
+		return 0
+	case numericFirst && xerr == nil:
+		return -1
+	case numericFirst && yerr == nil:
+		return 1
+	}
+
+	return strings.Compare(x, y)
+}
+
+// splitVersion 拆分版本的主版本段与预发布标识；没有预发布后缀时 pre 为 nil。
+func splitVersion(v string) (release, pre []string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		pre = strings.Split(v[i+1:], ".")
+		v = v[:i]
+	}
+	if v != "" {
+		release = strings.Split(v, ".")
+	}
+	return release, pre
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// match 报告节点是否满足全部过滤条件。
+func (rp *RoutePolicy) match(node *discovery.Node) bool {
+	if rp == nil {
+		return true
+	}
+	for _, filter := range rp.Filters {
+		if filter != nil && !filter(node) {
+			return false
+		}
+	}
+	return true
+}
+
+// candidates 返回满足全部过滤条件、分组过滤条件且可用的节点；healthy 为 nil 时视为全部可用。
+func (rp *RoutePolicy) candidates(sn *_ServiceNodes, group, healthy NodeFilter) []*discovery.Node {
+	var nodes []*discovery.Node
+	for i := range sn.nodes {
+		node := &sn.nodes[i]
+		if !rp.match(node) {
+			continue
+		}
+		if group != nil && !group(node) {
+			continue
+		}
+		if healthy != nil && !healthy(node) {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// groups 返回至少有一个候选节点的加权分组及其权重总和。
+func (rp *RoutePolicy) groups(sn *_ServiceNodes, healthy NodeFilter) ([]RouteWeight, int) {
+	var groups []RouteWeight
+	total := 0
+
+	for _, weight := range rp.Weights {
+		if weight.Weight <= 0 || len(rp.candidates(sn, weight.Filter, healthy)) <= 0 {
+			continue
+		}
+		groups = append(groups, weight)
+		total += weight.Weight
+	}
+
+	return groups, total
+}
+
+// pickGroup 按 n 在 [0, total) 中的位置选择分组。
+func pickGroup(groups []RouteWeight, n int) NodeFilter {
+	for _, group := range groups {
+		if n < group.Weight {
+			return group.Filter
+		}
+		n -= group.Weight
+	}
+	return groups[len(groups)-1].Filter
+}
+
+// selectRandom 按策略在可用节点中随机选择一个节点。
+func (rp *RoutePolicy) selectRandom(sn *_ServiceNodes, healthy NodeFilter) (*discovery.Node, bool) {
+	var group NodeFilter
+
+	if rp != nil && len(rp.Weights) > 0 {
+		groups, total := rp.groups(sn, healthy)
+		if total <= 0 {
+			return nil, false
+		}
+		group = pickGroup(groups, rand.Intn(total))
+	}
+
+	nodes := rp.candidates(sn, group, healthy)
+	if len(nodes) <= 0 {
+		return nil, false
+	}
+
+	return nodes[rand.Intn(len(nodes))], true
+}
+
+// selectHash 按策略在一致性哈希环上为 key 选择节点；不满足条件或不可用的节点按环序溢出到后继节点。
+func (rp *RoutePolicy) selectHash(sn *_ServiceNodes, key string, healthy NodeFilter) (*discovery.Node, bool) {
+	var group NodeFilter
+
+	if rp != nil && len(rp.Weights) > 0 {
+		groups, total := rp.groups(sn, healthy)
+		if total <= 0 {
+			return nil, false
+		}
+		group = pickGroup(groups, int(hashring.Hash(key)%uint64(total)))
+	}
+
+	return sn.hashFunc(key, func(node *discovery.Node) bool {
+		return rp.match(node) && (group == nil || group(node)) && (healthy == nil || healthy(node))
+	})
+}
+
+// filter 返回满足全部过滤条件的节点；策略为 nil 时返回全部节点。
+func (rp *RoutePolicy) filter(nodes []discovery.Node) []discovery.Node {
+	if rp == nil {
+		return nodes
+	}
+	var ret []discovery.Node
+	for i := range nodes {
+		if rp.match(&nodes[i]) {
+			ret = append(ret, nodes[i])
+		}
+	}
+	return ret
+}
@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	"fmt"
+	"testing"
+
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/discovery"
+)
+
+func TestCompareVersion(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.2", "1.2.0", 0},
+		{"1.10", "1.9", 1},
+		{"1.2.3", "1.3", -1},
+		{"", "0.0", 0},
+		{"1.0.0+build.5", "1.0.0", 0},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-rc.1+build.7", "1.0.0-rc.1", 0},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta", 1},
+		{"2.0.0-alpha", "1.9.9", 1},
+	}
+
+	for _, c := range cases {
+		if got := CompareVersion(c.a, c.b); got != c.want {
+			t.Errorf("CompareVersion(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+		if got := CompareVersion(c.b, c.a); got != -c.want {
+			t.Errorf("CompareVersion(%q, %q) = %d, want %d", c.b, c.a, got, -c.want)
+		}
+	}
+}
+
+func newTestNodes(versions ...string) []discovery.Node {
+	nodes := make([]discovery.Node, len(versions))
+	for i, version := range versions {
+		nodes[i] = discovery.Node{
+			ID:      uid.From(fmt.Sprintf("node-%d", i)),
+			Version: version,
+			Meta:    map[string]string{"zone": fmt.Sprintf("z%d", i%2)},
+		}
+	}
+	return nodes
+}
+
+func nodeIDs(nodes []discovery.Node) []string {
+	ids := make([]string, len(nodes))
+	for i := range nodes {
+		ids[i] = nodes[i].ID.String()
+	}
+	return ids
+}
+
+func TestRoutePolicyFilters(t *testing.T) {
+	nodes := newTestNodes("1.0.0", "1.1.0-rc.1", "1.1.0", "2.0.0")
+
+	cases := []struct {
+		name   string
+		policy *RoutePolicy
+		want   []string
+	}{
+		{"nil", nil, []string{"node-0", "node-1", "node-2", "node-3"}},
+		{"at least", &RoutePolicy{Filters: []NodeFilter{VersionAtLeast("1.1.0")}}, []string{"node-2", "node-3"}},
+		{"below", &RoutePolicy{Filters: []NodeFilter{VersionBelow("1.1.0")}}, []string{"node-0", "node-1"}},
+		{"meta", &RoutePolicy{Filters: []NodeFilter{MetaEquals("zone", "z1")}}, []string{"node-1", "node-3"}},
+		{"combined", &RoutePolicy{Filters: []NodeFilter{VersionAtLeast("1.1.0-rc.1"), MetaEquals("zone", "z0")}}, []string{"node-2"}},
+		{"missing meta", &RoutePolicy{Filters: []NodeFilter{MetaEquals("region", "")}}, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := nodeIDs(c.policy.filter(nodes))
+			if fmt.Sprint(got) != fmt.Sprint(c.want) {
+				t.Fatalf("filter = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRoutePolicyWeightedRandom(t *testing.T) {
+	sn := newServiceNodes("svc", newTestNodes("1.0.0", "1.0.0", "2.0.0"), 1, 16)
+
+	policy := &RoutePolicy{
+		Weights: []RouteWeight{
+			{Weight: 9, Filter: VersionBelow("2.0.0")},
+			{Weight: 1, Filter: VersionAtLeast("2.0.0")},
+			{Weight: 0, Filter: nil},
+		},
+	}
+
+	const n = 10000
+	canary := 0
+
+	for range n {
+		node, ok := policy.selectRandom(sn, nil)
+		if !ok {
+			t.Fatal("selectRandom found no node")
+		}
+		if node.Version == "2.0.0" {
+			canary++
+		}
+	}
+
+	if canary < n/20 || canary > n/5 {
+		t.Fatalf("canary group received %d of %d requests, want about 10%%", canary, n)
+	}
+}
+
+func TestRoutePolicyWeightedSkipsEmptyGroups(t *testing.T) {
+	sn := newServiceNodes("svc", newTestNodes("1.0.0", "1.0.0"), 1, 16)
+
+	policy := &RoutePolicy{
+		Weights: []RouteWeight{
+			{Weight: 1, Filter: VersionBelow("2.0.0")},
+			{Weight: 100, Filter: VersionAtLeast("2.0.0")},
+		},
+	}
+
+	for range 100 {
+		node, ok := policy.selectRandom(sn, nil)
+		if !ok || node.Version != "1.0.0" {
+			t.Fatalf("unexpected selection: %v, %v", node, ok)
+		}
+	}
+
+	unhealthy := func(*discovery.Node) bool { return false }
+	if _, ok := policy.selectRandom(sn, unhealthy); ok {
+		t.Fatal("selectRandom succeeded without healthy nodes")
+	}
+}
+
+func TestRoutePolicyWeightedHashIsStable(t *testing.T) {
+	sn := newServiceNodes("svc", newTestNodes("1.0.0", "1.0.0", "2.0.0", "2.0.0"), 1, 16)
+
+	policy := &RoutePolicy{
+		Weights: []RouteWeight{
+			{Weight: 1, Filter: VersionBelow("2.0.0")},
+			{Weight: 1, Filter: VersionAtLeast("2.0.0")},
+		},
+	}
+
+	for i := range 100 {
+		key := fmt.Sprintf("key-%d", i)
+
+		first, ok := policy.selectHash(sn, key, nil)
+		if !ok {
+			t.Fatalf("selectHash found no node for %q", key)
+		}
+		for range 3 {
+			node, ok := policy.selectHash(sn, key, nil)
+			if !ok || node.ID != first.ID {
+				t.Fatalf("selectHash is not stable for %q: %v then %v", key, first.ID, node)
+			}
+		}
+	}
+}
@@ -20,14 +20,20 @@
 package rpc
 
 import (
+	"git.golaxy.org/core"
 	"git.golaxy.org/core/service"
 	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/exception"
 	"git.golaxy.org/core/utils/generic"
 	"git.golaxy.org/core/utils/option"
+	"git.golaxy.org/core/utils/uid"
 	"git.golaxy.org/framework/addins/log"
 	"git.golaxy.org/framework/addins/rpc/callpath"
 	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
 	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/utils/circuit"
+	"git.golaxy.org/framework/utils/tracing"
 	"go.uber.org/zap"
 )
 
@@ -39,6 +45,28 @@ type IRPC interface {
 	OnewayRPC(dst string, cc rpcstack.CallChain, cp callpath.CallPath, args ...any) error
 }
 
+// iRPC 是代理与批量调用依赖的内部能力，仅由内置实现 *_RPC 提供，不属于 IRPC 的对外契约。
+type iRPC interface {
+	serviceNodes(service string) (*_ServiceNodes, error)
+	nodeAvailable(addr string) bool
+	ejecting() bool
+	retryPolicy(script, method string) *RetryPolicy
+	tracedRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, idemKey string, codec *gap.ArgsCodec, args []any) async.Future
+	tracedOnewayRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, codec *gap.ArgsCodec, args []any) error
+	tracedGroupRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, entityIDs []uid.ID, args []any) async.Future
+	tracedGroupOnewayRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, entityIDs []uid.ID, args []any) error
+	tracedBatchRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, calls []rpcpcsr.BatchCall) []async.Future
+}
+
+// requireRPC 返回服务上下文中的 RPC 插件内部实现；插件不是内置实现时 panic。
+func requireRPC(svcCtx service.Context) iRPC {
+	r, ok := AddIn.Require(svcCtx).(iRPC)
+	if !ok {
+		exception.Panicf("rpc: %w: proxy calls require the builtin RPC add-in", core.ErrArgs)
+	}
+	return r
+}
+
 func newRPC(settings ...option.Setting[RPCOptions]) IRPC {
 	return &_RPC{
 		options: option.New(With.Default(), settings...),
@@ -50,6 +78,8 @@ type _RPC struct {
 	options    RPCOptions
 	barrier    generic.Barrier
 	deliverers []rpcpcsr.IDeliverer
+	nodeCache  *_ServiceNodeCache
+	breakers   *circuit.Group[string]
 }
 
 // Init 按配置顺序缓存可投递处理器，再依次调用处理器的 LifecycleInit。
@@ -57,6 +87,11 @@ func (r *_RPC) Init(svcCtx service.Context) {
 	log.L(svcCtx).Info("initializing add-in", zap.String("name", AddIn.Name))
 
 	r.svcCtx = svcCtx
+	r.nodeCache = newServiceNodeCache(svcCtx, r.options.HashVirtualNodes, r.forgetNode)
+
+	if r.options.CircuitBreaker {
+		r.breakers = circuit.NewGroup[string](r.options.CircuitBreakerOptions)
+	}
 
 	for _, p := range r.options.Processors {
 		if deliverer, ok := p.(rpcpcsr.IDeliverer); ok {
@@ -71,7 +106,7 @@ func (r *_RPC) Init(svcCtx service.Context) {
 	}
 }
 
-// Shut 停止接收新调用，等待在途调用离开后关闭处理器。
+// Shut 停止接收新调用，等待在途调用离开后关闭处理器及服务节点缓存。
 func (r *_RPC) Shut(svcCtx service.Context) {
 	log.L(svcCtx).Info("shutting down add-in", zap.String("name", AddIn.Name))
 
@@ -83,10 +118,18 @@ func (r *_RPC) Shut(svcCtx service.Context) {
 			cb.Shut(r.svcCtx)
 		}
 	}
+
+	r.nodeCache.close()
 }
 
-// RPC 依次选择首个匹配的投递器发起请求。
+// RPC 依次选择首个匹配的投递器发起请求；目标为单播节点地址且该节点熔断打开时快速失败。
+// 未携带父追踪上下文，调用作为新链路的根 Span 记录。
 func (r *_RPC) RPC(dst string, cc rpcstack.CallChain, cp callpath.CallPath, args ...any) async.Future {
+	return r.tracedRPC(dst, cc, tracing.SpanContext{}, cp, "", nil, args)
+}
+
+// tracedRPC 与 RPC 相同，但以 sc 为父上下文记录调用方 Span 与调用指标，并在投递器支持时为请求附带幂等键、追踪上下文与参数编解码器。
+func (r *_RPC) tracedRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, idemKey string, codec *gap.ArgsCodec, args []any) async.Future {
 	if !r.barrier.Join(1) {
 		return async.Rejected(rpcpcsr.ErrTerminated)
 	}
@@ -103,14 +146,41 @@ func (r *_RPC) RPC(dst string, cc rpcstack.CallChain, cp callpath.CallPath, args
 			continue
 		}
 
-		return deliverer.Request(r.svcCtx, dst, cc, cp, args)
+		call := r.beginCall(sc, dst, cp, false)
+
+		breaker, ticket, err := r.admit(dst)
+		if err != nil {
+			call.end(err)
+			return async.Rejected(err)
+		}
+
+		ext := rpcpcsr.CallExt{
+			IdemKey:     idemKey,
+			TraceParent: call.traceParent(),
+			ArgsCodec:   r.argsCodec(codec),
+		}
+
+		var future async.Future
+		if extDeliverer, ok := deliverer.(rpcpcsr.IExtDeliverer); ok && ext != (rpcpcsr.CallExt{}) {
+			future = extDeliverer.RequestExt(r.svcCtx, dst, cc, cp, ext, args)
+		} else {
+			future = deliverer.Request(r.svcCtx, dst, cc, cp, args)
+		}
+
+		return call.endOnComplete(settleFuture(breaker, ticket, future))
 	}
 
 	return async.Rejected(rpcpcsr.ErrUndeliverable)
 }
 
-// OnewayRPC 依次选择首个匹配的投递器发送通知。
+// OnewayRPC 依次选择首个匹配的投递器发送通知；目标为单播节点地址且该节点熔断打开时快速失败。
+// 未携带父追踪上下文，调用作为新链路的根 Span 记录。
 func (r *_RPC) OnewayRPC(dst string, cc rpcstack.CallChain, cp callpath.CallPath, args ...any) error {
+	return r.tracedOnewayRPC(dst, cc, tracing.SpanContext{}, cp, nil, args)
+}
+
+// tracedOnewayRPC 与 OnewayRPC 相同，但以 sc 为父上下文记录调用方 Span 与调用指标，并在投递器支持时为通知附带追踪上下文与参数编解码器。
+func (r *_RPC) tracedOnewayRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, codec *gap.ArgsCodec, args []any) error {
 	if !r.barrier.Join(1) {
 		return rpcpcsr.ErrTerminated
 	}
@@ -127,8 +197,58 @@ func (r *_RPC) OnewayRPC(dst string, cc rpcstack.CallChain, cp callpath.CallPath
 			continue
 		}
 
-		return deliverer.Notify(r.svcCtx, dst, cc, cp, args)
+		call := r.beginCall(sc, dst, cp, true)
+
+		breaker, ticket, err := r.admit(dst)
+		if err != nil {
+			call.end(err)
+			return err
+		}
+
+		ext := rpcpcsr.CallExt{
+			TraceParent: call.traceParent(),
+			ArgsCodec:   r.argsCodec(codec),
+		}
+
+		if extDeliverer, ok := deliverer.(rpcpcsr.IExtDeliverer); ok && ext != (rpcpcsr.CallExt{}) {
+			err = extDeliverer.NotifyExt(r.svcCtx, dst, cc, cp, ext, args)
+		} else {
+			err = deliverer.Notify(r.svcCtx, dst, cc, cp, args)
+		}
+		call.end(err)
+		if breaker != nil {
+			// 单向通知无法观测远端是否处理，仅归还放行名额
+			breaker.Cancel(ticket)
+		}
+		return err
 	}
 
 	return rpcpcsr.ErrUndeliverable
 }
+
+func (r *_RPC) serviceNodes(service string) (*_ServiceNodes, error) {
+	if !r.barrier.Join(1) {
+		return nil, rpcpcsr.ErrTerminated
+	}
+	defer r.barrier.Done()
+
+	return r.nodeCache.get(service)
+}
+
+func (r *_RPC) retryPolicy(script, method string) *RetryPolicy {
+	if policy, ok := r.options.RetryPolicies[methodKey(script, method)]; ok {
+		return &policy
+	}
+	if policy, ok := r.options.RetryPolicies[methodKey(script, "")]; ok {
+		return &policy
+	}
+	return nil
+}
+
+// argsCodec 返回调用使用的参数编解码器；调用方未指定时使用插件配置的默认编解码器。
+func (r *_RPC) argsCodec(codec *gap.ArgsCodec) gap.ArgsCodec {
+	if codec != nil {
+		return *codec
+	}
+	return r.options.ArgsCodec
+}
@@ -0,0 +1,215 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpc
+
+import (
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/utils/tracing"
+)
+
+// batchCallPath 是批量请求在调用方 Span 与调用指标中使用的调用路径。
+var batchCallPath = callpath.CallPath{TargetKind: callpath.Service, Method: "batch"}
+
+// ProxyCall 描述一次由代理 Call 方法构造、尚未发起的请求，可直接调用 RPC 发起，或加入 Batch 合并发送。
+// 批量消息不携带幂等键与参数编解码器，因此配置了重试策略或参数编解码器的请求加入 Batch 后仍单独发送。
+type ProxyCall struct {
+	svcCtx  service.Context
+	retry   *RetryPolicy
+	codec   *gap.ArgsCodec
+	resolve func() (string, error)
+	cc      rpcstack.CallChain
+	sc      tracing.SpanContext
+	cp      callpath.CallPath
+	args    []any
+}
+
+// RPC 按代理的重试策略发起请求，与代理上对应的 RPC 方法相同。
+func (c ProxyCall) RPC() async.Future {
+	return invokeRPC(c.svcCtx, c.retry, c.codec, c.resolve, c.cc, c.sc, c.cp, c.args)
+}
+
+// batchable 判断请求能否合并发送：请求不会按重试策略发起（未指定策略时使用 RPC 插件按方法配置的策略），
+// 且参数使用默认的 variant 编码。
+func (c ProxyCall) batchable() bool {
+	if c.codec != nil && *c.codec != gap.ArgsCodec_Variant {
+		return false
+	}
+	policy := c.retry
+	if policy == nil {
+		policy = requireRPC(c.svcCtx).retryPolicy(c.cp.Script, c.cp.Method)
+	}
+	return policy == nil || policy.MaxAttempts <= 1
+}
+
+// Batch 创建空的批量请求。
+func Batch() *RPCBatch {
+	return &RPCBatch{}
+}
+
+// RPCBatch 收集多个请求，发送时将目标地址相同的请求合并为一条 GAP 批量消息，只占用一次消息代理发布与一个关联 ID。
+type RPCBatch struct {
+	calls []ProxyCall
+}
+
+// Add 按顺序追加请求。
+func (b *RPCBatch) Add(calls ...ProxyCall) *RPCBatch {
+	b.calls = append(b.calls, calls...)
+	return b
+}
+
+// Send 解析各请求的目标地址并合并发送，返回与 Add 顺序一一对应的 Future；目标地址解析失败的请求返回已携带错误的 Future。
+// 合并后的请求共用首个请求的调用链与追踪上下文，被调方按顺序调度，同一实体的调用保持添加顺序；
+// 目标地址仅有一个请求、或首个匹配的投递器不支持批量投递时按普通请求发送。
+// 配置了重试策略或参数编解码器的请求不参与合并，与直接调用 RPC 相同地单独发送。
+func (b *RPCBatch) Send() []async.Future {
+	type batchKey struct {
+		svcCtx service.Context
+		dst    string
+	}
+
+	futures := make([]async.Future, len(b.calls))
+	groups := map[batchKey][]int{}
+	var keys []batchKey
+
+	for i := range b.calls {
+		call := &b.calls[i]
+
+		if !call.batchable() {
+			futures[i] = call.RPC()
+			continue
+		}
+
+		dst, err := call.resolve()
+		if err != nil {
+			futures[i] = async.Rejected(err)
+			continue
+		}
+
+		key := batchKey{svcCtx: call.svcCtx, dst: dst}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	for _, key := range keys {
+		idxs := groups[key]
+		r := requireRPC(key.svcCtx)
+
+		if len(idxs) == 1 {
+			call := &b.calls[idxs[0]]
+			futures[idxs[0]] = r.tracedRPC(key.dst, call.cc, call.sc, call.cp, "", call.codec, call.args)
+			continue
+		}
+
+		calls := make([]rpcpcsr.BatchCall, len(idxs))
+		for j, idx := range idxs {
+			calls[j] = rpcpcsr.BatchCall{CallPath: b.calls[idx].cp, Args: b.calls[idx].args}
+		}
+
+		first := &b.calls[idxs[0]]
+		for j, future := range r.tracedBatchRPC(key.dst, first.cc, first.sc, calls) {
+			futures[idxs[j]] = future
+		}
+	}
+
+	return futures
+}
+
+// tracedBatchRPC 选择首个匹配的投递器，将发往 dst 的多个请求合并为一条批量请求发起，返回与 calls 一一对应的 Future；
+// 该投递器不支持批量投递时逐个发起普通请求。熔断与调用指标按整批请求计。
+// calls 中的请求均使用默认的 variant 参数编码，见 ProxyCall.batchable。
+func (r *_RPC) tracedBatchRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, calls []rpcpcsr.BatchCall) []async.Future {
+	futures := make([]async.Future, len(calls))
+
+	reject := func(err error) []async.Future {
+		for i := range futures {
+			futures[i] = async.Rejected(err)
+		}
+		return futures
+	}
+
+	if !r.barrier.Join(1) {
+		return reject(rpcpcsr.ErrTerminated)
+	}
+	defer r.barrier.Done()
+
+	if cc == nil {
+		cc = rpcstack.EmptyCallChain
+	}
+
+	for i := range r.deliverers {
+		deliverer := r.deliverers[i]
+
+		if !deliverer.Match(r.svcCtx, dst, cc, calls[0].CallPath, false) {
+			continue
+		}
+
+		batchDeliverer, ok := deliverer.(rpcpcsr.IBatchDeliverer)
+		if !ok {
+			for j := range calls {
+				futures[j] = r.tracedRPC(dst, cc, sc, calls[j].CallPath, "", nil, calls[j].Args)
+			}
+			return futures
+		}
+
+		call := r.beginCall(sc, dst, batchCallPath, false)
+
+		breaker, ticket, err := r.admit(dst)
+		if err != nil {
+			call.end(err)
+			return reject(err)
+		}
+
+		ext := rpcpcsr.CallExt{
+			TraceParent: call.traceParent(),
+		}
+
+		resolves := make([]func(async.Result), len(calls))
+		for j := range calls {
+			promise, future := async.NewPromise()
+			resolves[j], futures[j] = promise.Resolve, future
+		}
+
+		batchFuture := call.endOnComplete(settleFuture(breaker, ticket, batchDeliverer.RequestBatch(r.svcCtx, dst, cc, calls, ext)))
+		batchFuture.OnComplete(func(ret async.Result) {
+			results, _ := ret.Value.([]async.Result)
+			for j, resolve := range resolves {
+				switch {
+				case !ret.OK():
+					resolve(ret)
+				case j < len(results):
+					resolve(results[j])
+				default:
+					resolve(async.NewResult(nil, rpcpcsr.ErrUndeliverable))
+				}
+			}
+		})
+
+		return futures
+	}
+
+	return reject(rpcpcsr.ErrUndeliverable)
+}
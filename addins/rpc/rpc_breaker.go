@@ -0,0 +1,111 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/framework/addins/discovery"
+	"git.golaxy.org/framework/addins/dsvc"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/circuit"
+	"git.golaxy.org/framework/utils/correlation"
+)
+
+// admit 为发往单播节点地址的调用申请熔断器放行；返回 nil 熔断器表示该调用不受熔断控制。
+func (r *_RPC) admit(dst string) (*circuit.Breaker, circuit.Ticket, error) {
+	if r.breakers == nil || !dsvc.AddIn.Require(r.svcCtx).NodeDetails().DomainUnicast.Contains(dst) {
+		return nil, circuit.Ticket{}, nil
+	}
+
+	breaker := r.breakers.Get(dst)
+
+	ticket, ok := breaker.Allow()
+	if !ok {
+		return nil, circuit.Ticket{}, rpcpcsr.ErrCircuitOpen
+	}
+
+	return breaker, ticket, nil
+}
+
+// settle 按调用结果更新熔断器：收到响应（包括远端返回的错误）视为成功，超时视为失败，其他本地错误不计入统计。
+func settle(breaker *circuit.Breaker, ticket circuit.Ticket, err error) {
+	if breaker == nil {
+		return
+	}
+
+	switch {
+	case err == nil || isRemoteError(err):
+		breaker.Done(ticket, true)
+	case errors.Is(err, correlation.ErrTimeout) || errors.Is(err, context.DeadlineExceeded):
+		breaker.Done(ticket, false)
+	default:
+		breaker.Cancel(ticket)
+	}
+}
+
+func isRemoteError(err error) bool {
+	var ptr *variant.Error
+	if errors.As(err, &ptr) {
+		return true
+	}
+	var val variant.Error
+	return errors.As(err, &val)
+}
+
+// settleFuture 在 Future 完成时更新熔断器。
+func settleFuture(breaker *circuit.Breaker, ticket circuit.Ticket, future async.Future) async.Future {
+	if breaker == nil {
+		return future
+	}
+	future.OnComplete(func(ret async.Result) {
+		settle(breaker, ticket, ret.Error)
+	})
+	return future
+}
+
+func (r *_RPC) nodeAvailable(addr string) bool {
+	if r.breakers == nil {
+		return true
+	}
+	return r.breakers.Available(addr)
+}
+
+// forgetNode 在节点从服务发现中下线时回收其熔断器。
+func (r *_RPC) forgetNode(node discovery.Node) {
+	if r.breakers == nil {
+		return
+	}
+	addr, err := dsvc.AddIn.Require(r.svcCtx).NodeDetails().MakeNodeAddr(node.ID)
+	if err != nil {
+		return
+	}
+	r.breakers.Remove(addr)
+}
+
+func (r *_RPC) ejecting() bool {
+	if r.breakers == nil {
+		return false
+	}
+	return r.breakers.Tripped() > 0
+}
@@ -0,0 +1,160 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpc
+
+import (
+	"sync"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/dent"
+	"git.golaxy.org/framework/addins/dsvc"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/utils/tracing"
+)
+
+// gatherTarget 描述一次聚合 RPC 中的单个目标节点。
+type gatherTarget struct {
+	nodeID uid.ID // nodeID 是目标节点 ID，作为结果键。
+	addr   string // addr 是目标节点的单播地址。
+}
+
+// gatherCaller 是聚合 RPC 向单个目标节点发起请求所需的能力，由 *_RPC 实现。
+type gatherCaller interface {
+	tracedRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, idemKey string, codec *gap.ArgsCodec, args []any) async.Future
+}
+
+// groupCaller 是分组聚合 RPC 向单个节点发起批量请求所需的能力，由 *_RPC 实现。
+type groupCaller interface {
+	tracedGroupRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, entityIDs []uid.ID, args []any) async.Future
+}
+
+// distEntityTargets 返回承载分布式实体的目标节点；service 为空时选择全部服务中的节点，excludeSelf 为 true 时排除本节点。
+// 实体不存在时返回 ErrDistEntityNotFound，没有符合条件的节点时返回 ErrDistEntityNodeNotFound。
+func distEntityTargets(svcCtx service.Context, entityID uid.ID, service string, excludeSelf bool) ([]gatherTarget, error) {
+	distEntity, ok := dent.QuerierAddIn.Require(svcCtx).GetDistEntity(entityID)
+	if !ok {
+		return nil, rpcpcsr.ErrDistEntityNotFound
+	}
+
+	localAddr := dsvc.AddIn.Require(svcCtx).NodeDetails().LocalAddr
+	var targets []gatherTarget
+
+	for i := range distEntity.Nodes {
+		node := &distEntity.Nodes[i]
+		if service != "" && node.Service != service {
+			continue
+		}
+		if excludeSelf && node.RemoteAddr == localAddr {
+			continue
+		}
+		targets = append(targets, gatherTarget{nodeID: node.ID, addr: node.RemoteAddr})
+	}
+	if len(targets) <= 0 {
+		return nil, rpcpcsr.ErrDistEntityNodeNotFound
+	}
+
+	return targets, nil
+}
+
+// gatherRPC 向全部目标节点分别发起 RPC，并在所有节点响应、失败或超时后，以 map[uid.ID]ResultValues 完成返回的 Future。
+// 单个节点的超时由分布式服务的 Future 超时控制，不会阻塞其他节点的结果。
+func gatherRPC(r gatherCaller, targets []gatherTarget, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, codec *gap.ArgsCodec, args []any) async.Future {
+	promise, future := async.NewPromise()
+
+	if len(targets) <= 0 {
+		promise.Resolve(async.NewResult(map[uid.ID]ResultValues{}, nil))
+		return future
+	}
+
+	var mutex sync.Mutex
+	rets := make(map[uid.ID]ResultValues, len(targets))
+	remaining := len(targets)
+
+	for _, target := range targets {
+		r.tracedRPC(target.addr, cc, sc, cp, "", codec, args).OnComplete(func(ret async.Result) {
+			rvs := ParseResults(ret)
+
+			mutex.Lock()
+			rets[target.nodeID] = rvs
+			remaining--
+			done := remaining <= 0
+			mutex.Unlock()
+
+			if done {
+				promise.Resolve(async.NewResult(rets, nil))
+			}
+		})
+	}
+
+	return future
+}
+
+// gatherGroupRPC 按节点批量发起实体调用，并在所有批次响应、失败或超时后，以 map[uid.ID]ResultValues 完成返回的 Future，
+// 键为成员实体 ID。failed 中无法路由的实体直接以对应错误记入结果；批次整体失败时，批内全部实体记为该错误，
+// 批次结果中缺失的实体记为 ErrUndeliverable。分组消息不携带参数编解码器，参数始终以 variant 格式编码。
+func gatherGroupRPC(r groupCaller, batches map[string][]uid.ID, failed map[uid.ID]error, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, args []any) async.Future {
+	promise, future := async.NewPromise()
+
+	rets := make(map[uid.ID]ResultValues, len(failed))
+	for id, err := range failed {
+		rets[id] = ParseResults(async.NewResult(nil, err))
+	}
+
+	if len(batches) <= 0 {
+		promise.Resolve(async.NewResult(rets, nil))
+		return future
+	}
+
+	var mutex sync.Mutex
+	remaining := len(batches)
+
+	for dst, entityIDs := range batches {
+		r.tracedGroupRPC(dst, cc, sc, cp, entityIDs, args).OnComplete(func(ret async.Result) {
+			results, _ := ret.Value.(map[uid.ID]async.Result)
+
+			mutex.Lock()
+			for _, id := range entityIDs {
+				if !ret.OK() {
+					rets[id] = ParseResults(ret)
+					continue
+				}
+				entityRet, ok := results[id]
+				if !ok {
+					entityRet = async.NewResult(nil, rpcpcsr.ErrUndeliverable)
+				}
+				rets[id] = ParseResults(entityRet)
+			}
+			remaining--
+			done := remaining <= 0
+			mutex.Unlock()
+
+			if done {
+				promise.Resolve(async.NewResult(rets, nil))
+			}
+		})
+	}
+
+	return future
+}
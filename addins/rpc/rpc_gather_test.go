@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/tracing"
+)
+
+type fakeGatherCaller struct {
+	mutex sync.Mutex
+	dsts  []string
+	reply func(dst string) async.Result
+}
+
+func (c *fakeGatherCaller) tracedRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, idemKey string, codec *gap.ArgsCodec, args []any) async.Future {
+	c.mutex.Lock()
+	c.dsts = append(c.dsts, dst)
+	c.mutex.Unlock()
+
+	promise, future := async.NewPromise()
+	go promise.Resolve(c.reply(dst))
+	return future
+}
+
+func mustResultArray(t *testing.T, values ...any) variant.Array {
+	t.Helper()
+
+	arr, err := variant.NewArray(values)
+	if err != nil {
+		t.Fatalf("NewArray failed: %v", err)
+	}
+	return arr
+}
+
+func TestGatherRPCCollectsPerNodeResults(t *testing.T) {
+	cause := errors.New("node failed")
+
+	caller := &fakeGatherCaller{
+		reply: func(dst string) async.Result {
+			switch dst {
+			case "addr-a":
+				return async.NewResult(mustResultArray(t, "a"), nil)
+			case "addr-b":
+				return async.NewResult(nil, cause)
+			default:
+				return async.NewResult(mustResultArray(t, 3), nil)
+			}
+		},
+	}
+
+	targets := []gatherTarget{
+		{nodeID: uid.From("node-a"), addr: "addr-a"},
+		{nodeID: uid.From("node-b"), addr: "addr-b"},
+		{nodeID: uid.From("node-c"), addr: "addr-c"},
+	}
+
+	rets := AssertBroadcast(gatherRPC(caller, targets, rpcstack.EmptyCallChain, tracing.SpanContext{}, callpath.CallPath{}, nil, nil))
+
+	if len(caller.dsts) != len(targets) {
+		t.Fatalf("expected %d calls, got %d", len(targets), len(caller.dsts))
+	}
+	if len(rets) != len(targets) {
+		t.Fatalf("expected %d results, got %d", len(targets), len(rets))
+	}
+
+	if rvs := rets[uid.From("node-a")]; rvs.Error != nil || len(rvs.Values) != 1 || rvs.Values[0] != "a" {
+		t.Fatalf("unexpected node-a result: %+v", rvs)
+	}
+	if rvs := rets[uid.From("node-b")]; !errors.Is(rvs.Error, cause) {
+		t.Fatalf("expected node-b error %v, got %+v", cause, rvs)
+	}
+	if rvs := rets[uid.From("node-c")]; rvs.Error != nil || len(rvs.Values) != 1 || rvs.Values[0] != 3 {
+		t.Fatalf("unexpected node-c result: %+v", rvs)
+	}
+}
+
+func TestGatherRPCNoTargets(t *testing.T) {
+	caller := &fakeGatherCaller{
+		reply: func(string) async.Result {
+			t.Fatal("unexpected call")
+			return async.Result{}
+		},
+	}
+
+	brvs := BroadcastResults(gatherRPC(caller, nil, rpcstack.EmptyCallChain, tracing.SpanContext{}, callpath.CallPath{}, nil, nil))
+	if brvs.Error != nil {
+		t.Fatalf("unexpected error: %v", brvs.Error)
+	}
+	if brvs.Values == nil || len(brvs.Values) != 0 {
+		t.Fatalf("expected empty result map, got %+v", brvs.Values)
+	}
+}
+
+func TestParseBroadcastResults(t *testing.T) {
+	cause := errors.New("rejected")
+
+	brvs := ParseBroadcastResults(async.NewResult(nil, cause))
+	if !errors.Is(brvs.Error, cause) || brvs.Values != nil {
+		t.Fatalf("unexpected result for failed broadcast: %+v", brvs)
+	}
+
+	brvs = ParseBroadcastResults(async.NewResult("not a map", nil))
+	if !errors.Is(brvs.Error, ErrMethodResultTypeMismatch) {
+		t.Fatalf("expected ErrMethodResultTypeMismatch, got %v", brvs.Error)
+	}
+
+	want := map[uid.ID]ResultValues{
+		uid.From("node-a"): {Values: []any{"a"}},
+		uid.From("node-b"): {Error: cause},
+	}
+
+	brvs = ParseBroadcastResults(async.NewResult(want, nil))
+	if brvs.Error != nil {
+		t.Fatalf("unexpected error: %v", brvs.Error)
+	}
+	if len(brvs.Values) != len(want) || !errors.Is(brvs.Values[uid.From("node-b")].Error, cause) {
+		t.Fatalf("unexpected results: %+v", brvs.Values)
+	}
+}
+
+func TestAssertBroadcast(t *testing.T) {
+	want := map[uid.ID]ResultValues{
+		uid.From("node-a"): {Error: errors.New("node error is kept")},
+	}
+
+	promise, future := async.NewPromise()
+	promise.Resolve(async.NewResult(want, nil))
+
+	if got := AssertBroadcast(future); len(got) != 1 || got[uid.From("node-a")].Error == nil {
+		t.Fatalf("unexpected results: %+v", got)
+	}
+
+	cause := errors.New("broadcast failed")
+
+	defer func() {
+		if panicInfo := recover(); panicInfo == nil {
+			t.Fatal("expected AssertBroadcast to panic")
+		}
+	}()
+	AssertBroadcast(async.Rejected(cause))
+}
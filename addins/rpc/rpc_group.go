@@ -0,0 +1,111 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpc
+
+import (
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/utils/tracing"
+)
+
+// tracedGroupRPC 选择首个匹配且支持批量投递的投递器，将节点 dst 上多个实体的同一调用合并为一条请求发起；
+// 返回的 Future 以 map[uid.ID]async.Result 完成。熔断与调用指标按整批请求计。
+func (r *_RPC) tracedGroupRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, entityIDs []uid.ID, args []any) async.Future {
+	if !r.barrier.Join(1) {
+		return async.Rejected(rpcpcsr.ErrTerminated)
+	}
+	defer r.barrier.Done()
+
+	if cc == nil {
+		cc = rpcstack.EmptyCallChain
+	}
+
+	for i := range r.deliverers {
+		deliverer := r.deliverers[i]
+
+		groupDeliverer, ok := deliverer.(rpcpcsr.IGroupDeliverer)
+		if !ok || !deliverer.Match(r.svcCtx, dst, cc, cp, false) {
+			continue
+		}
+
+		call := r.beginCall(sc, dst, cp, false)
+
+		breaker, ticket, err := r.admit(dst)
+		if err != nil {
+			call.end(err)
+			return async.Rejected(err)
+		}
+
+		ext := rpcpcsr.CallExt{
+			TraceParent: call.traceParent(),
+		}
+
+		future := groupDeliverer.RequestGroup(r.svcCtx, dst, cc, cp, entityIDs, ext, args)
+		return call.endOnComplete(settleFuture(breaker, ticket, future))
+	}
+
+	return async.Rejected(rpcpcsr.ErrUndeliverable)
+}
+
+// tracedGroupOnewayRPC 与 tracedGroupRPC 相同，但发送无需响应的批量通知。
+func (r *_RPC) tracedGroupOnewayRPC(dst string, cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, entityIDs []uid.ID, args []any) error {
+	if !r.barrier.Join(1) {
+		return rpcpcsr.ErrTerminated
+	}
+	defer r.barrier.Done()
+
+	if cc == nil {
+		cc = rpcstack.EmptyCallChain
+	}
+
+	for i := range r.deliverers {
+		deliverer := r.deliverers[i]
+
+		groupDeliverer, ok := deliverer.(rpcpcsr.IGroupDeliverer)
+		if !ok || !deliverer.Match(r.svcCtx, dst, cc, cp, true) {
+			continue
+		}
+
+		call := r.beginCall(sc, dst, cp, true)
+
+		breaker, ticket, err := r.admit(dst)
+		if err != nil {
+			call.end(err)
+			return err
+		}
+
+		ext := rpcpcsr.CallExt{
+			TraceParent: call.traceParent(),
+		}
+
+		err = groupDeliverer.NotifyGroup(r.svcCtx, dst, cc, cp, entityIDs, ext, args)
+		call.end(err)
+		if breaker != nil {
+			// 单向通知无法观测远端是否处理，仅归还放行名额
+			breaker.Cancel(ticket)
+		}
+		return err
+	}
+
+	return rpcpcsr.ErrUndeliverable
+}
@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/utils/tracing"
+)
+
+type fakeServiceContext struct {
+	service.Context
+	name string
+}
+
+func (ctx *fakeServiceContext) Name() string {
+	return ctx.name
+}
+
+// fakeDeliverer 是仅支持单个调用的投递器。
+type fakeDeliverer struct {
+	match bool
+}
+
+func (d *fakeDeliverer) Match(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, oneway bool) bool {
+	return d.match
+}
+
+func (d *fakeDeliverer) Request(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, args []any) async.Future {
+	return async.Rejected(errors.New("unexpected request"))
+}
+
+func (d *fakeDeliverer) Notify(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, args []any) error {
+	return errors.New("unexpected notify")
+}
+
+// fakeGroupDeliverer 记录分组调用，并以 reply 完成请求。
+type fakeGroupDeliverer struct {
+	fakeDeliverer
+	dst       string
+	entityIDs []uid.ID
+	ext       rpcpcsr.CallExt
+	reply     async.Result
+	notified  bool
+}
+
+func (d *fakeGroupDeliverer) RequestGroup(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, entityIDs []uid.ID, ext rpcpcsr.CallExt, args []any) async.Future {
+	d.dst, d.entityIDs, d.ext = dst, entityIDs, ext
+
+	promise, future := async.NewPromise()
+	promise.Resolve(d.reply)
+	return future
+}
+
+func (d *fakeGroupDeliverer) NotifyGroup(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, entityIDs []uid.ID, ext rpcpcsr.CallExt, args []any) error {
+	d.dst, d.entityIDs, d.ext, d.notified = dst, entityIDs, ext, true
+	return d.reply.Error
+}
+
+func newTestRPC(deliverers ...rpcpcsr.IDeliverer) *_RPC {
+	return &_RPC{
+		svcCtx:     &fakeServiceContext{name: "test"},
+		deliverers: deliverers,
+	}
+}
+
+func TestTracedGroupRPCSelectsGroupDeliverer(t *testing.T) {
+	want := map[uid.ID]async.Result{uid.From("e1"): async.NewResult(nil, nil)}
+
+	skipped := &fakeGroupDeliverer{fakeDeliverer: fakeDeliverer{match: false}}
+	single := &fakeDeliverer{match: true}
+	group := &fakeGroupDeliverer{fakeDeliverer: fakeDeliverer{match: true}, reply: async.NewResult(want, nil)}
+
+	r := newTestRPC(skipped, single, group)
+
+	entityIDs := []uid.ID{uid.From("e1")}
+	cp := callpath.CallPath{TargetKind: callpath.Entity, Script: "Bag", Method: "Open"}
+
+	ret := r.tracedGroupRPC("node-a", nil, tracing.SpanContext{}, cp, entityIDs, nil).Wait(t.Context())
+	if !ret.OK() {
+		t.Fatalf("unexpected error: %v", ret.Error)
+	}
+	if got, ok := ret.Value.(map[uid.ID]async.Result); !ok || len(got) != 1 {
+		t.Fatalf("unexpected result: %+v", ret.Value)
+	}
+	if skipped.dst != "" {
+		t.Fatal("unmatched deliverer was used")
+	}
+	if group.dst != "node-a" || !slices.Equal(group.entityIDs, entityIDs) {
+		t.Fatalf("group deliverer got dst %q, entities %v", group.dst, group.entityIDs)
+	}
+
+	if err := r.tracedGroupOnewayRPC("node-a", nil, tracing.SpanContext{}, cp, entityIDs, nil); err != nil {
+		t.Fatalf("unexpected notify error: %v", err)
+	}
+	if !group.notified {
+		t.Fatal("group notify was not delivered")
+	}
+}
+
+func TestTracedGroupRPCUndeliverable(t *testing.T) {
+	r := newTestRPC(&fakeDeliverer{match: true})
+
+	cp := callpath.CallPath{TargetKind: callpath.Entity, Script: "Bag", Method: "Open"}
+
+	ret := r.tracedGroupRPC("node-a", nil, tracing.SpanContext{}, cp, []uid.ID{uid.From("e1")}, nil).Wait(t.Context())
+	if !errors.Is(ret.Error, rpcpcsr.ErrUndeliverable) {
+		t.Fatalf("expected ErrUndeliverable, got %v", ret.Error)
+	}
+	if err := r.tracedGroupOnewayRPC("node-a", nil, tracing.SpanContext{}, cp, []uid.ID{uid.From("e1")}, nil); !errors.Is(err, rpcpcsr.ErrUndeliverable) {
+		t.Fatalf("expected ErrUndeliverable, got %v", err)
+	}
+}
+
+func TestTracedGroupRPCPropagatesBatchError(t *testing.T) {
+	cause := errors.New("send failed")
+	group := &fakeGroupDeliverer{fakeDeliverer: fakeDeliverer{match: true}, reply: async.NewResult(nil, cause)}
+
+	r := newTestRPC(group)
+
+	cp := callpath.CallPath{TargetKind: callpath.Entity, Script: "Bag", Method: "Open"}
+
+	ret := r.tracedGroupRPC("node-a", nil, tracing.SpanContext{}, cp, []uid.ID{uid.From("e1")}, nil).Wait(t.Context())
+	if !errors.Is(ret.Error, cause) {
+		t.Fatalf("expected %v, got %v", cause, ret.Error)
+	}
+}
@@ -0,0 +1,250 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/framework/addins/discovery"
+	"git.golaxy.org/framework/addins/log"
+	"git.golaxy.org/framework/utils/hashring"
+	"go.uber.org/zap"
+)
+
+// _ServiceNodes 是指定服务节点的只读快照，附带以节点 ID 为成员的一致性哈希环。
+type _ServiceNodes struct {
+	service  string
+	nodes    []discovery.Node
+	index    map[string]int
+	ring     *hashring.Ring
+	revision int64
+}
+
+func newServiceNodes(service string, nodes []discovery.Node, revision int64, virtualNodes int) *_ServiceNodes {
+	sn := &_ServiceNodes{
+		service:  service,
+		nodes:    nodes,
+		index:    make(map[string]int, len(nodes)),
+		revision: revision,
+	}
+
+	members := make([]string, 0, len(nodes))
+	for i := range nodes {
+		id := nodes[i].ID.String()
+		sn.index[id] = i
+		members = append(members, id)
+	}
+	sn.ring = hashring.New(virtualNodes, members...)
+
+	return sn
+}
+
+// hashFunc 返回 key 在一致性哈希环上顺时针方向首个被 accept 接受的节点；accept 为 nil 时接受全部节点。
+func (sn *_ServiceNodes) hashFunc(key string, accept func(node *discovery.Node) bool) (*discovery.Node, bool) {
+	var acceptMember func(member string) bool
+	if accept != nil {
+		acceptMember = func(member string) bool {
+			return accept(&sn.nodes[sn.index[member]])
+		}
+	}
+
+	member, ok := sn.ring.GetFunc(key, acceptMember)
+	if !ok {
+		return nil, false
+	}
+	return &sn.nodes[sn.index[member]], true
+}
+
+type _ServiceNodeEntry struct {
+	once     sync.Once
+	err      error
+	snapshot atomic.Pointer[_ServiceNodes]
+}
+
+// _ServiceNodeCache 按需查询服务节点，并通过服务发现监听保持快照更新。
+// 监听出错或结束后缓存项失效，下次访问时重新查询。
+type _ServiceNodeCache struct {
+	svcCtx       service.Context
+	scope        *async.Scope
+	virtualNodes int
+	onRemoved    func(node discovery.Node)
+	mutex        sync.Mutex
+	entries      map[string]*_ServiceNodeEntry
+}
+
+// newServiceNodeCache 创建服务节点缓存；onRemoved 不为 nil 时，在监听到节点下线后调用。
+func newServiceNodeCache(svcCtx service.Context, virtualNodes int, onRemoved func(node discovery.Node)) *_ServiceNodeCache {
+	return &_ServiceNodeCache{
+		svcCtx:       svcCtx,
+		scope:        async.NewScope(nil),
+		virtualNodes: virtualNodes,
+		onRemoved:    onRemoved,
+		entries:      make(map[string]*_ServiceNodeEntry),
+	}
+}
+
+// close 停止全部监听并等待后台任务退出。
+func (c *_ServiceNodeCache) close() {
+	c.scope.Close()
+	<-c.scope.Completion().Done()
+}
+
+// get 返回指定服务当前的节点快照，service 为空时返回全部服务的节点；首次访问时同步查询服务发现并开始监听。
+func (c *_ServiceNodeCache) get(service string) (*_ServiceNodes, error) {
+	c.mutex.Lock()
+	entry, ok := c.entries[service]
+	if !ok {
+		entry = &_ServiceNodeEntry{}
+		c.entries[service] = entry
+	}
+	c.mutex.Unlock()
+
+	entry.once.Do(func() {
+		entry.err = c.load(service, entry)
+	})
+	if entry.err != nil {
+		c.invalidate(service, entry)
+		return nil, entry.err
+	}
+
+	return entry.snapshot.Load(), nil
+}
+
+func (c *_ServiceNodeCache) load(service string, entry *_ServiceNodeEntry) error {
+	registry := discovery.AddIn.Require(c.svcCtx)
+
+	var nodes []discovery.Node
+	var revision int64
+
+	if service != "" {
+		svc, err := registry.Get(c.scope.Context(), service)
+		if err != nil {
+			if !errors.Is(err, discovery.ErrRegistrationNotFound) {
+				return err
+			}
+		} else {
+			nodes = svc.Nodes
+			revision = svc.Revision
+		}
+	} else {
+		// 服务名为空时合并全部服务的节点；节点 ID 全局唯一，单播地址仅由节点 ID 决定。
+		svcs, err := registry.List(c.scope.Context())
+		if err != nil {
+			return err
+		}
+		for _, svc := range svcs {
+			nodes = append(nodes, svc.Nodes...)
+			revision = max(revision, svc.Revision)
+		}
+	}
+
+	entry.snapshot.Store(newServiceNodes(service, nodes, revision, c.virtualNodes))
+
+	// 从快照的下一修订号开始监听，避免遗漏查询与监听之间的变化。
+	var revisions []int64
+	if revision > 0 {
+		revisions = append(revisions, revision+1)
+	}
+
+	stopped, err := registry.WatchHandler(c.scope.Context(), service, generic.CastDelegateVoid1(func(event discovery.Event) {
+		c.handleEvent(service, entry, event)
+	}), revisions...)
+	if err != nil {
+		return err
+	}
+
+	async.SpawnVoid(c.scope, func(ctx context.Context) {
+		select {
+		case <-ctx.Done():
+		case <-stopped.Done():
+			c.invalidate(service, entry)
+		}
+	})
+
+	log.L(c.svcCtx).Debug("watching service nodes started",
+		zap.String("service", service),
+		zap.Int("nodes", len(nodes)),
+		zap.Int64("revision", revision))
+	return nil
+}
+
+func (c *_ServiceNodeCache) handleEvent(service string, entry *_ServiceNodeEntry, event discovery.Event) {
+	if event.Type == discovery.EventType_Error {
+		log.L(c.svcCtx).Warn("watching service nodes interrupted, invalidate cache",
+			zap.String("service", service),
+			zap.Error(event.Error))
+		c.invalidate(service, entry)
+		return
+	}
+
+	// 服务键前缀监听可能收到名称以 service 开头的其他服务；服务名为空时监听全部服务。
+	if event.Service == nil || (service != "" && event.Service.Name != service) {
+		return
+	}
+
+	old := entry.snapshot.Load()
+	nodes := slices.Clone(old.nodes)
+
+	for _, node := range event.Service.Nodes {
+		idx := slices.IndexFunc(nodes, func(n discovery.Node) bool {
+			return n.ID == node.ID
+		})
+
+		switch event.Type {
+		case discovery.EventType_Create, discovery.EventType_Update:
+			if idx < 0 {
+				nodes = append(nodes, node)
+			} else {
+				nodes[idx] = node
+			}
+		case discovery.EventType_Delete:
+			if idx >= 0 {
+				nodes = slices.Delete(nodes, idx, idx+1)
+			}
+			if c.onRemoved != nil {
+				c.onRemoved(node)
+			}
+		}
+	}
+
+	entry.snapshot.Store(newServiceNodes(service, nodes, event.Service.Revision, c.virtualNodes))
+
+	log.L(c.svcCtx).Debug("service nodes changed",
+		zap.String("service", service),
+		zap.String("type", event.Type.String()),
+		zap.Int("nodes", len(nodes)),
+		zap.Int64("revision", event.Service.Revision))
+}
+
+func (c *_ServiceNodeCache) invalidate(service string, entry *_ServiceNodeEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.entries[service] == entry {
+		delete(c.entries, service)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpc
+
+import (
+	"time"
+
+	"git.golaxy.org/core/runtime"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/utils/metrics"
+	"git.golaxy.org/framework/utils/tracing"
+)
+
+var (
+	metricClientCalls = metrics.Default().Counter("golaxy_rpc_client_calls_total",
+		"RPC requests and notifications issued, by result code.", "service", "method", "kind", "code")
+	metricClientDuration = metrics.Default().Histogram("golaxy_rpc_client_duration_seconds",
+		"Time from issuing an RPC request until its reply, error or timeout.", nil, "service", "method")
+)
+
+// callContext 返回运行时正在处理的调用链与追踪上下文；rtCtx 为 nil 时返回空调用链与零值追踪上下文。
+func callContext(rtCtx runtime.Context) (rpcstack.CallChain, tracing.SpanContext) {
+	if rtCtx == nil {
+		return rpcstack.EmptyCallChain, tracing.SpanContext{}
+	}
+	stack := rpcstack.AddIn.Require(rtCtx)
+	return stack.CallChain(), stack.SpanContext()
+}
+
+// _ClientCall 记录一次调用方调用的 Span 与指标。
+type _ClientCall struct {
+	svcName string
+	method  string
+	kind    string
+	span    *tracing.ActiveSpan
+	start   time.Time
+}
+
+// beginCall 以 sc 为父上下文开始调用方 Span，并开始计时；未设置默认 Tracer 时只传播 sc。
+func (r *_RPC) beginCall(sc tracing.SpanContext, dst string, cp callpath.CallPath, oneway bool) *_ClientCall {
+	method := cp.Method
+	if cp.Script != "" {
+		method = cp.Script + "." + cp.Method
+	}
+
+	kind, spanKind := "request", tracing.SpanKind_Client
+	if oneway {
+		kind, spanKind = "notify", tracing.SpanKind_Producer
+	}
+
+	return &_ClientCall{
+		svcName: r.svcCtx.Name(),
+		method:  method,
+		kind:    kind,
+		span: tracing.Default().Start(sc, r.svcCtx.Name(), method, spanKind,
+			tracing.Attr("rpc.system", "golaxy"),
+			tracing.Attr("rpc.dst", dst),
+			tracing.Attr("rpc.call_path", cp.String())),
+		start: time.Now(),
+	}
+}
+
+// traceParent 返回随消息传播的追踪上下文。
+func (c *_ClientCall) traceParent() string {
+	return c.span.Context().TraceParent()
+}
+
+// end 结束 Span 并记录调用结果；请求还会记录往返耗时。
+func (c *_ClientCall) end(err error) {
+	c.span.End(err)
+	metricClientCalls.With(c.svcName, c.method, c.kind, rpcpcsr.ResultCode(err)).Inc()
+	if c.kind == "request" {
+		metricClientDuration.With(c.svcName, c.method).Observe(time.Since(c.start).Seconds())
+	}
+}
+
+// endOnComplete 在 Future 完成时结束调用。
+func (c *_ClientCall) endOnComplete(future async.Future) async.Future {
+	future.OnComplete(func(ret async.Result) {
+		c.end(ret.Error)
+	})
+	return future
+}
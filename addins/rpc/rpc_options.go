@@ -20,14 +20,28 @@
 package rpc
 
 import (
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/utils/exception"
 	"git.golaxy.org/core/utils/option"
 	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/utils/circuit"
 )
 
-// RPCOptions 定义 RPC 插件使用的处理器链。
+// RPCOptions 定义 RPC 插件使用的处理器链及节点路由参数。
 type RPCOptions struct {
 	// Processors 按顺序保存处理器；实现 IDeliverer 的处理器也按此顺序参与投递匹配。
 	Processors []any
+	// HashVirtualNodes 是一致性哈希路由中每个服务节点的虚拟节点数。
+	HashVirtualNodes int
+	// CircuitBreaker 控制是否按目标节点熔断；熔断打开的节点会被快速拒绝，并在负载均衡与哈希路由中被跳过。
+	CircuitBreaker bool
+	// CircuitBreakerOptions 是按目标节点熔断的阈值与时间参数。
+	CircuitBreakerOptions circuit.Options
+	// RetryPolicies 是方法级重试策略，键为 "脚本名.方法名"，方法名为空的键匹配脚本的全部方法；代理上设置的策略优先。
+	RetryPolicies map[string]RetryPolicy
+	// ArgsCodec 是请求与通知参数的默认编解码器；代理上设置的编解码器优先。
+	ArgsCodec gap.ArgsCodec
 }
 
 // With 提供 RPCOptions 的设置项。
@@ -35,10 +49,15 @@ var With _Option
 
 type _Option struct{}
 
-// Default 返回默认设置，默认仅安装服务内 RPC 处理器并启用调用路径压缩。
+// Default 返回默认设置，默认仅安装服务内 RPC 处理器并启用调用路径压缩，一致性哈希路由使用 160 个虚拟节点，
+// 被调方对携带幂等键的请求按 30 秒窗口去重。按目标节点熔断默认关闭，
+// 可使用 With.CircuitBreaker(true, circuit.DefaultOptions()) 启用。
 func (_Option) Default() option.Setting[RPCOptions] {
 	return func(options *RPCOptions) {
-		With.Processors(rpcpcsr.NewServiceProcessor(nil, true))(options)
+		With.Processors(rpcpcsr.NewServiceProcessorWith())(options)
+		With.HashVirtualNodes(160)(options)
+		With.CircuitBreaker(false, circuit.DefaultOptions())(options)
+		With.ArgsCodec(gap.ArgsCodec_Variant)(options)
 	}
 }
 
@@ -48,3 +67,56 @@ func (_Option) Processors(processors ...any) option.Setting[RPCOptions] {
 		options.Processors = processors
 	}
 }
+
+// HashVirtualNodes 设置一致性哈希路由中每个服务节点的虚拟节点数，必须大于 0。
+func (_Option) HashVirtualNodes(n int) option.Setting[RPCOptions] {
+	return func(options *RPCOptions) {
+		if n <= 0 {
+			exception.Panicf("rpc: %w: option HashVirtualNodes must be > 0", core.ErrArgs)
+		}
+		options.HashVirtualNodes = n
+	}
+}
+
+// CircuitBreaker 设置是否按目标节点熔断及熔断参数；启用时 Window 与 OpenTimeout 必须大于 0。
+// 节点从服务发现中下线时回收其熔断器，breakerOptions.IdleTimeout 大于 0 时空闲的熔断器也会被回收。
+func (_Option) CircuitBreaker(enable bool, breakerOptions circuit.Options) option.Setting[RPCOptions] {
+	return func(options *RPCOptions) {
+		if enable {
+			if breakerOptions.Window <= 0 {
+				exception.Panicf("rpc: %w: option CircuitBreakerOptions.Window must be > 0", core.ErrArgs)
+			}
+			if breakerOptions.OpenTimeout <= 0 {
+				exception.Panicf("rpc: %w: option CircuitBreakerOptions.OpenTimeout must be > 0", core.ErrArgs)
+			}
+		}
+		options.CircuitBreaker = enable
+		options.CircuitBreakerOptions = breakerOptions
+	}
+}
+
+// RetryPolicy 设置脚本（服务插件、运行时插件或实体组件）方法的重试策略；method 为空时作用于脚本的全部方法。
+func (_Option) RetryPolicy(script, method string, policy RetryPolicy) option.Setting[RPCOptions] {
+	return func(options *RPCOptions) {
+		if script == "" {
+			exception.Panicf("rpc: %w: option RetryPolicy script is empty", core.ErrArgs)
+		}
+		if options.RetryPolicies == nil {
+			options.RetryPolicies = map[string]RetryPolicy{}
+		}
+		options.RetryPolicies[methodKey(script, method)] = policy
+	}
+}
+
+// ArgsCodec 设置请求与通知参数的默认编解码器，编解码器须已通过 gap.DeclareArgsCodec 注册。
+// 被调方须能识别该编解码器，非 variant 格式仅用于单个请求与通知，分组与批量请求仍使用 variant 格式。
+func (_Option) ArgsCodec(codec gap.ArgsCodec) option.Setting[RPCOptions] {
+	return func(options *RPCOptions) {
+		if codec != gap.ArgsCodec_Variant {
+			if _, err := gap.LookupArgsCodec(codec); err != nil {
+				exception.Panicf("rpc: %w: option ArgsCodec: %w", core.ErrArgs, err)
+			}
+		}
+		options.ArgsCodec = codec
+	}
+}
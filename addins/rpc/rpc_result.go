@@ -24,6 +24,7 @@ import (
 
 	"git.golaxy.org/core/utils/async"
 	"git.golaxy.org/core/utils/exception"
+	"git.golaxy.org/core/utils/uid"
 )
 
 // ResultValues 保存未指定返回值类型的 RPC 结果及错误。
@@ -54,6 +55,34 @@ func Results(future async.Future) (rvs ResultValues) {
 	return ParseResults(future.Wait(context.Background()))
 }
 
+// BroadcastResultValues 保存广播 RPC 中各节点的结果；单个节点的失败记录在对应 ResultValues.Error 中。
+type BroadcastResultValues struct {
+	Values map[uid.ID]ResultValues
+	Error  error
+}
+
+// Extract 返回各节点结果和整体错误。
+func (brvs BroadcastResultValues) Extract() (map[uid.ID]ResultValues, error) {
+	return brvs.Values, brvs.Error
+}
+
+// Ensure 返回各节点结果；整体结果包含错误时 panic。
+func (brvs BroadcastResultValues) Ensure() map[uid.ID]ResultValues {
+	return brvs.ensure(2)
+}
+
+func (brvs BroadcastResultValues) ensure(skip int) map[uid.ID]ResultValues {
+	if brvs.Error != nil {
+		exception.PanicSkip(skip, brvs.Error)
+	}
+	return brvs.Values
+}
+
+// BroadcastResults 等待广播 RPC 的 Future 完成，并将其解析为各节点的结果。
+func BroadcastResults(future async.Future) (brvs BroadcastResultValues) {
+	return ParseBroadcastResults(future.Wait(context.Background()))
+}
+
 // ResultTupleVoid 保存无返回值 RPC 的错误。
 type ResultTupleVoid struct {
 	Error error
@@ -19,13 +19,21 @@
 
 package rpc
 
-import "git.golaxy.org/core/utils/async"
+import (
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+)
 
 // Asserts 等待并返回未指定类型的 RPC 结果；结果包含错误时 panic。
 func Asserts(future async.Future) []any {
 	return Results(future).ensure(3)
 }
 
+// AssertBroadcast 等待并返回广播 RPC 各节点的结果；整体结果包含错误时 panic，单个节点的错误保留在对应结果中。
+func AssertBroadcast(future async.Future) map[uid.ID]ResultValues {
+	return BroadcastResults(future).ensure(3)
+}
+
 // AssertVoid 等待无返回值 RPC 完成；结果包含错误时 panic。
 func AssertVoid(future async.Future) {
 	ResultVoid(future).ensure(3)
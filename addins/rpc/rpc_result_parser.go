@@ -25,6 +25,7 @@ import (
 
 	"git.golaxy.org/core/utils/async"
 	"git.golaxy.org/core/utils/types"
+	"git.golaxy.org/core/utils/uid"
 	"git.golaxy.org/framework/net/gap/variant"
 )
 
@@ -84,6 +85,23 @@ func ParseResults(ret async.Result) (rvs ResultValues) {
 	return
 }
 
+// ParseBroadcastResults 从广播 RPC 的异步结果中解析各节点的结果。
+func ParseBroadcastResults(ret async.Result) (brvs BroadcastResultValues) {
+	if !ret.OK() {
+		brvs.Error = ret.Error
+		return
+	}
+
+	rets, ok := ret.Value.(map[uid.ID]ResultValues)
+	if !ok {
+		brvs.Error = ErrMethodResultTypeMismatch
+		return
+	}
+
+	brvs.Values = rets
+	return
+}
+
 // ParseVoid 将异步结果解析为无返回值结果。
 func ParseVoid(ret async.Result) (rtp ResultTupleVoid) {
 	if !ret.OK() {
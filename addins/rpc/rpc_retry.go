@@ -0,0 +1,140 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpc
+
+import (
+	"errors"
+	"time"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/correlation"
+	"git.golaxy.org/framework/utils/tracing"
+)
+
+// DefaultRetryableErrors 是 RetryPolicy 未指定 Retryable 时使用的可重试错误，覆盖节点重启、实体迁移等瞬时故障。
+var DefaultRetryableErrors = []error{
+	rpcpcsr.ErrUndeliverable,
+	rpcpcsr.ErrDistEntityNodeNotFound,
+	rpcpcsr.ErrServiceNodeNotFound,
+	rpcpcsr.ErrCircuitOpen,
+	correlation.ErrTimeout,
+}
+
+// RetryPolicy 定义 RPC 请求的重试策略。启用重试的请求携带幂等键，被调方在去重窗口内不会重复执行同一请求。
+type RetryPolicy struct {
+	MaxAttempts int           // MaxAttempts 是包括首次在内的最大尝试次数，小于等于 1 时不重试。
+	Backoff     time.Duration // Backoff 是首次重试前的等待时间。
+	MaxBackoff  time.Duration // MaxBackoff 是等待时间上限，小于等于 0 时不限制。
+	Multiplier  float64       // Multiplier 是每次重试后等待时间的增长倍数，小于 1 时视为 1。
+	Retryable   []error       // Retryable 是可重试的错误，为空时使用 DefaultRetryableErrors。
+}
+
+// backoff 返回第 attempt 次尝试失败后的等待时间。
+func (rp *RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(rp.Backoff)
+	for range attempt - 1 {
+		d *= max(rp.Multiplier, 1)
+		if rp.MaxBackoff > 0 && d >= float64(rp.MaxBackoff) {
+			break
+		}
+	}
+	if rp.MaxBackoff > 0 {
+		d = min(d, float64(rp.MaxBackoff))
+	}
+	return time.Duration(d)
+}
+
+// retryable 报告错误是否可重试。远端返回的错误先经 variant.ErrorRegistry 关联回注册的本地错误，再按 errors.Is 匹配，
+// 因此自定义的可重试错误需要注册错误码才能匹配远端返回的同类错误。
+func (rp *RetryPolicy) retryable(err error) bool {
+	retryable := rp.Retryable
+	if len(retryable) <= 0 {
+		retryable = DefaultRetryableErrors
+	}
+
+	var remoteErr *variant.Error
+	if errors.As(err, &remoteErr) {
+		variant.ErrorRegistry().Decode(remoteErr)
+	}
+
+	for _, target := range retryable {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// methodKey 返回方法级重试策略的键。
+func methodKey(script, method string) string {
+	return script + "." + method
+}
+
+// invokeRPC 按重试策略发起请求；调用方未指定策略时使用方法级策略。每次尝试都会调用 resolve 重新解析目标地址，
+// 以便跟随实体迁移或避开熔断节点。
+func invokeRPC(svcCtx service.Context, policy *RetryPolicy, codec *gap.ArgsCodec, resolve func() (string, error), cc rpcstack.CallChain, sc tracing.SpanContext, cp callpath.CallPath, args []any) async.Future {
+	r := requireRPC(svcCtx)
+
+	if policy == nil {
+		policy = r.retryPolicy(cp.Script, cp.Method)
+	}
+
+	if policy == nil || policy.MaxAttempts <= 1 {
+		dst, err := resolve()
+		if err != nil {
+			return async.Rejected(err)
+		}
+		return r.tracedRPC(dst, cc, sc, cp, "", codec, args)
+	}
+
+	promise, future := async.NewPromise()
+	idemKey := uid.New().String()
+
+	var attempt func(n int)
+	attempt = func(n int) {
+		var f async.Future
+
+		dst, err := resolve()
+		if err != nil {
+			f = async.Rejected(err)
+		} else {
+			f = r.tracedRPC(dst, cc, sc, cp, idemKey, codec, args)
+		}
+
+		f.OnComplete(func(ret async.Result) {
+			if ret.Error == nil || n >= policy.MaxAttempts || !policy.retryable(ret.Error) {
+				promise.Resolve(ret)
+				return
+			}
+			time.AfterFunc(policy.backoff(n), func() { attempt(n + 1) })
+		})
+	}
+	attempt(1)
+
+	return future
+}
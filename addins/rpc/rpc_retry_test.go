@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"git.golaxy.org/framework/addins/rpc/rpcpcsr"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/correlation"
+)
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	custom := errors.New("custom transient")
+
+	cases := []struct {
+		name      string
+		retryable []error
+		err       error
+		want      bool
+	}{
+		{"local default", nil, rpcpcsr.ErrUndeliverable, true},
+		{"wrapped local default", nil, fmt.Errorf("send: %w", correlation.ErrTimeout), true},
+		{"local non retryable", nil, rpcpcsr.ErrMethodNotFound, false},
+		{"remote registered", nil, variant.Errorln(rpcpcsr.ErrCodeCircuitOpen, rpcpcsr.ErrCircuitOpen.Error()), true},
+		{"remote encoded", nil, variant.NewError(rpcpcsr.ErrServiceNodeNotFound), true},
+		{"remote non retryable", nil, variant.Errorln(rpcpcsr.ErrCodeMethodNotFound, rpcpcsr.ErrMethodNotFound.Error()), false},
+		{"remote unregistered with matching text", nil, variant.Errorln(-1, "wrapped: "+rpcpcsr.ErrUndeliverable.Error()), false},
+		{"custom local", []error{custom}, custom, true},
+		{"custom replaces defaults", []error{custom}, rpcpcsr.ErrUndeliverable, false},
+		{"custom remote unregistered", []error{custom}, variant.Errorln(-1, custom.Error()), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			policy := &RetryPolicy{MaxAttempts: 3, Retryable: c.retryable}
+			if got := policy.retryable(c.err); got != c.want {
+				t.Fatalf("retryable(%v) = %v, want %v", c.err, got, c.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, Multiplier: 2}
+
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	for i, d := range want {
+		if got := policy.backoff(i + 1); got != d {
+			t.Fatalf("backoff(%d) = %v, want %v", i+1, got, d)
+		}
+	}
+
+	constant := &RetryPolicy{Backoff: 10 * time.Millisecond}
+	if got := constant.backoff(5); got != 10*time.Millisecond {
+		t.Fatalf("constant backoff = %v, want 10ms", got)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"git.golaxy.org/framework/net/gap"
 	"git.golaxy.org/framework/net/gap/codec"
 	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/tracing"
 	"go.uber.org/zap"
 )
 
@@ -51,6 +52,7 @@ type RPCli struct {
 	decoder        *codec.Decoder
 	remoteClock    cli.TimeSample
 	reduceCallPath bool
+	shortConfirmed sync.Map
 	scriptsMu      sync.RWMutex
 	scripts        generic.SliceMap[string, IScript]
 }
@@ -61,7 +63,44 @@ func (c *RPCli) RemoteClock() cli.TimeSample {
 }
 
 // RPC 向服务的实体目标发起请求，并返回用于接收响应的 Future。
+// 设置了默认 Tracer 时，每次请求作为新链路的根 Span 记录，追踪上下文随请求传播到服务端。
+// 服务端无法解析压缩调用路径时，降级该调用路径并以完整形式重发一次；压缩形式的请求成功后，该调用路径的通知也改用压缩形式。
 func (c *RPCli) RPC(service, comp, method string, args ...any) async.Future {
+	span := startSpan(service, comp, method, tracing.SpanKind_Client)
+
+	cp := callpath.CallPath{
+		TargetKind: callpath.Entity,
+		Script:     comp,
+		Method:     method,
+	}
+
+	future := c.request(service, cp, span.Context().TraceParent(), args)
+
+	if c.reduceCallPath {
+		promise, fallback := async.NewPromise()
+		future.OnComplete(func(ret async.Result) {
+			if !errors.Is(ret.Error, callpath.ErrUnknownIndex) {
+				if ret.Error == nil {
+					c.shortConfirmed.Store(_CallPathKey{Script: cp.Script, Method: cp.Method}, struct{}{})
+				}
+				promise.Resolve(ret)
+				return
+			}
+			callpath.Demote(cp.Script, cp.Method)
+			c.shortConfirmed.Delete(_CallPathKey{Script: cp.Script, Method: cp.Method})
+			c.request(service, cp, span.Context().TraceParent(), args).OnComplete(promise.Resolve)
+		})
+		future = fallback
+	}
+
+	if span.IsRecording() {
+		future.OnComplete(func(ret async.Result) { span.End(ret.Error) })
+	}
+
+	return future
+}
+
+func (c *RPCli) request(service string, cp callpath.CallPath, traceParent string, args []any) async.Future {
 	controller := c.Correlation()
 	corrID, future, err := controller.Begin()
 	if err != nil {
@@ -74,12 +113,6 @@ func (c *RPCli) RPC(service, comp, method string, args ...any) async.Future {
 		return future
 	}
 
-	cp := callpath.CallPath{
-		TargetKind: callpath.Entity,
-		Script:     comp,
-		Method:     method,
-	}
-
 	cpBuf, err := cp.Encode(c.reduceCallPath)
 	if err != nil {
 		controller.Cancel(corrID, err)
@@ -87,9 +120,10 @@ func (c *RPCli) RPC(service, comp, method string, args ...any) async.Future {
 	}
 
 	msg := &gap.MsgRPCRequest{
-		CorrID: corrID,
-		Path:   cpBuf,
-		Args:   vargs,
+		CorrID:      corrID,
+		Path:        cpBuf,
+		Args:        vargs,
+		TraceParent: traceParent,
 	}
 
 	msgBuf, err := gap.Marshal(msg)
@@ -129,7 +163,11 @@ func (c *RPCli) RPC(service, comp, method string, args ...any) async.Future {
 }
 
 // OnewayRPC 向服务的实体目标发送无需响应的通知。
-func (c *RPCli) OnewayRPC(service, comp, method string, args ...any) error {
+// 通知无法在对端解析失败时回退，因此仅当同一调用路径的压缩形式请求已成功时才压缩调用路径，否则使用完整形式。
+func (c *RPCli) OnewayRPC(service, comp, method string, args ...any) (err error) {
+	span := startSpan(service, comp, method, tracing.SpanKind_Producer)
+	defer func() { span.End(err) }()
+
 	vargs, err := variant.NewArray(args)
 	if err != nil {
 		return err
@@ -141,14 +179,15 @@ func (c *RPCli) OnewayRPC(service, comp, method string, args ...any) error {
 		Method:     method,
 	}
 
-	cpBuf, err := cp.Encode(c.reduceCallPath)
+	cpBuf, err := cp.Encode(c.reduceCallPath && c.confirmedShort(cp))
 	if err != nil {
 		return err
 	}
 
 	msg := &gap.MsgOnewayRPC{
-		Path: cpBuf,
-		Args: vargs,
+		Path:        cpBuf,
+		Args:        vargs,
+		TraceParent: span.Context().TraceParent(),
 	}
 
 	msgBuf, err := gap.Marshal(msg)
@@ -181,3 +220,26 @@ func (c *RPCli) OnewayRPC(service, comp, method string, args ...any) error {
 		zap.String("call_path", cp.String()))
 	return nil
 }
+
+// _CallPathKey 标识脚本的方法，用于记录已确认可被对端解析的压缩调用路径。
+type _CallPathKey struct {
+	Script string
+	Method string
+}
+
+// confirmedShort 报告调用路径的压缩形式是否已被对端成功解析。
+func (c *RPCli) confirmedShort(cp callpath.CallPath) bool {
+	_, ok := c.shortConfirmed.Load(_CallPathKey{Script: cp.Script, Method: cp.Method})
+	return ok
+}
+
+// startSpan 开始客户端调用方 Span；未设置默认 Tracer 时返回不记录的 Span。
+func startSpan(service, comp, method string, kind tracing.SpanKind) *tracing.ActiveSpan {
+	name := method
+	if comp != "" {
+		name = comp + "." + method
+	}
+	return tracing.Default().Start(tracing.SpanContext{}, "rpcli", name, kind,
+		tracing.Attr("rpc.system", "golaxy"),
+		tracing.Attr("rpc.dst", service))
+}
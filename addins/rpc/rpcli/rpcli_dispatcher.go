@@ -30,6 +30,7 @@ import (
 	"git.golaxy.org/framework/net/gap"
 	"git.golaxy.org/framework/net/gap/variant"
 	"git.golaxy.org/framework/utils/correlation"
+	"git.golaxy.org/framework/utils/tracing"
 	"go.uber.org/zap"
 )
 
@@ -82,7 +83,7 @@ func (c *RPCli) acceptNotify(src gap.Origin, req *gap.MsgOnewayRPC) {
 
 	switch cp.TargetKind {
 	case callpath.Client:
-		_, err := c.callScript(cc, cp.Script, cp.Method, req.Args)
+		_, err := c.tracedCallScript(req.TraceParent, tracing.SpanKind_Consumer, cc, cp.Script, cp.Method, req.Args)
 		if err != nil {
 			c.L().Error("accept rpc notify failed",
 				zap.String("session_id", c.SessionID().String()),
@@ -129,7 +130,7 @@ func (c *RPCli) acceptRequest(src gap.Origin, req *gap.MsgRPCRequest) {
 
 	switch cp.TargetKind {
 	case callpath.Client:
-		rets, err := c.callScript(cc, cp.Script, cp.Method, req.Args)
+		rets, err := c.tracedCallScript(req.TraceParent, tracing.SpanKind_Server, cc, cp.Script, cp.Method, req.Args)
 		if err != nil {
 			c.L().Error("accept rpc request failed",
 				zap.String("session_id", c.SessionID().String()),
@@ -163,7 +164,7 @@ func (c *RPCli) resolveReply(reply *gap.MsgRPCReply) {
 			ret.Value = reply.Rets
 		}
 	} else {
-		ret.Error = &reply.Error
+		ret.Error = variant.ErrorRegistry().Decode(&reply.Error)
 	}
 
 	if !c.Correlation().Resolve(reply.CorrID, ret) {
@@ -245,6 +246,19 @@ func (c *RPCli) reply(src gap.Origin, corrID correlation.ID, rets variant.Array,
 		zap.Uint64("corr_id", uint64(corrID)))
 }
 
+// tracedCallScript 以 traceParent 为父上下文记录被调方 Span，并调用本地客户端脚本。
+func (c *RPCli) tracedCallScript(traceParent string, kind tracing.SpanKind, cc rpcstack.CallChain, script, method string, args variant.Array) (variant.Array, error) {
+	sc, _ := tracing.ParseTraceParent(traceParent)
+	span := tracing.Default().Start(sc, "rpcli", script+"."+method, kind,
+		tracing.Attr("rpc.system", "golaxy"),
+		tracing.Attr("rpc.script", script),
+		tracing.Attr("rpc.method", method))
+
+	rets, err := c.callScript(cc, script, method, args)
+	span.End(err)
+	return rets, err
+}
+
 // callScript 查找已注册脚本和导出方法，转换参数后通过反射同步调用。
 func (c *RPCli) callScript(cc rpcstack.CallChain, script, method string, args variant.Array) (rets variant.Array, err error) {
 	scr, ok := c.GetScript(script)
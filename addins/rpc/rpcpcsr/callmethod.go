@@ -34,6 +34,7 @@ import (
 	"git.golaxy.org/core/utils/uid"
 	"git.golaxy.org/framework/addins/rpcstack"
 	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/tracing"
 )
 
 // ICallee 允许对象按名称动态提供可调用方法。
@@ -47,11 +48,14 @@ var (
 )
 
 // CallService 在当前服务或其运行中的插件上同步调用方法，并将 panic 转换为错误。
-func CallService(svcCtx service.Context, cc rpcstack.CallChain, addIn, method string, args variant.Array) (_ variant.Array, err error) {
+// sc 为调用方的追踪上下文，调用期间会以其为父上下文记录被调方 Span，并记录调用次数与耗时指标。
+func CallService(svcCtx service.Context, cc rpcstack.CallChain, sc tracing.SpanContext, addIn, method string, args variant.Array) (_ variant.Array, err error) {
+	scope := beginCall(svcCtx, sc, "service", uid.Nil, addIn, method)
 	defer func() {
 		if panicErr := types.Panic2Err(recover()); panicErr != nil {
 			err = fmt.Errorf("rpc: %w: %w", core.ErrPanicked, panicErr)
 		}
+		scope.end(err)
 	}()
 
 	var scriptRV reflect.Value
@@ -93,8 +97,11 @@ func CallService(svcCtx service.Context, cc rpcstack.CallChain, addIn, method st
 }
 
 // CallRuntime 将方法调用调度到实体所在的运行时；addIn 为空时调用运行时本身。
-func CallRuntime(svcCtx service.Context, cc rpcstack.CallChain, entityID uid.ID, addIn, method string, args variant.Array) (_ async.Future, err error) {
-	return svcCtx.Submit(entityID, func(entity ec.Entity, _ ...any) async.Result {
+// 方法执行期间 sc 派生的被调方 Span 会压入运行时 RPC 栈，方法内发起的 RPC 将成为其子 Span。
+func CallRuntime(svcCtx service.Context, cc rpcstack.CallChain, sc tracing.SpanContext, entityID uid.ID, addIn, method string, args variant.Array) (_ async.Future, err error) {
+	scope := beginCall(svcCtx, sc, "runtime", entityID, addIn, method)
+
+	future := svcCtx.Submit(entityID, func(entity ec.Entity, _ ...any) async.Result {
 		var scriptRV reflect.Value
 
 		if addIn == "" {
@@ -131,7 +138,7 @@ func CallRuntime(svcCtx service.Context, cc rpcstack.CallChain, entityID uid.ID,
 		}
 
 		stack := rpcstack.AddIn.Require(runtime.Current(entity))
-		rpcstack.UnsafeRPCStack(stack).PushCallChain(cc)
+		rpcstack.UnsafeRPCStack(stack).PushCallChain(cc, scope.spanContext())
 		defer rpcstack.UnsafeRPCStack(stack).PopCallChain()
 
 		retsRV := methodRV.Call(argsRV)
@@ -147,12 +154,18 @@ func CallRuntime(svcCtx service.Context, cc rpcstack.CallChain, entityID uid.ID,
 		}
 
 		return async.NewResult(rets.Snapshot(true))
-	}), nil
+	})
+
+	scope.endOnComplete(future)
+	return future, nil
 }
 
 // CallEntity 将方法调用调度到实体；component 为空时调用实体本身。
-func CallEntity(svcCtx service.Context, cc rpcstack.CallChain, entityID uid.ID, component, method string, args variant.Array) (_ async.Future, err error) {
-	return svcCtx.Submit(entityID, func(entity ec.Entity, _ ...any) async.Result {
+// 追踪上下文的处理与 CallRuntime 相同。
+func CallEntity(svcCtx service.Context, cc rpcstack.CallChain, sc tracing.SpanContext, entityID uid.ID, component, method string, args variant.Array) (_ async.Future, err error) {
+	scope := beginCall(svcCtx, sc, "entity", entityID, component, method)
+
+	future := svcCtx.Submit(entityID, func(entity ec.Entity, _ ...any) async.Result {
 		var scriptRV reflect.Value
 
 		if component == "" {
@@ -184,7 +197,7 @@ func CallEntity(svcCtx service.Context, cc rpcstack.CallChain, entityID uid.ID,
 		}
 
 		stack := rpcstack.AddIn.Require(runtime.Current(entity))
-		rpcstack.UnsafeRPCStack(stack).PushCallChain(cc)
+		rpcstack.UnsafeRPCStack(stack).PushCallChain(cc, scope.spanContext())
 		defer rpcstack.UnsafeRPCStack(stack).PopCallChain()
 
 		retsRV := methodRV.Call(argsRV)
@@ -200,7 +213,10 @@ func CallEntity(svcCtx service.Context, cc rpcstack.CallChain, entityID uid.ID,
 		}
 
 		return async.NewResult(rets.Snapshot(true))
-	}), nil
+	})
+
+	scope.endOnComplete(future)
+	return future, nil
 }
 
 func parseArgs(methodRV reflect.Value, cc rpcstack.CallChain, args variant.Array) ([]reflect.Value, error) {
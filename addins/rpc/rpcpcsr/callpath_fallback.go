@@ -0,0 +1,122 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"errors"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+)
+
+// fallbackLongCallPath 在对端无法解析压缩调用路径而拒绝请求时，降级该调用路径并以完整形式重发一次。
+// 降级对本进程后续的全部调用生效；short 为 false 时直接返回 future。
+func fallbackLongCallPath(short bool, cp callpath.CallPath, future async.Future, resend func() async.Future) async.Future {
+	if !short {
+		return future
+	}
+
+	promise, fallback := async.NewPromise()
+
+	future.OnComplete(func(ret async.Result) {
+		if !errors.Is(ret.Error, callpath.ErrUnknownIndex) {
+			promise.Resolve(ret)
+			return
+		}
+		callpath.Demote(cp.Script, cp.Method)
+		resend().OnComplete(promise.Resolve)
+	})
+
+	return fallback
+}
+
+// fallbackLongGroupCallPath 与 fallbackLongCallPath 相同，用于结果按实体汇总的批量请求；
+// 对端无法解析调用路径时全部实体的结果均为该错误，因此任一实体命中即整体重发。
+func fallbackLongGroupCallPath(short bool, cp callpath.CallPath, future async.Future, resend func() async.Future) async.Future {
+	if !short {
+		return future
+	}
+
+	promise, fallback := async.NewPromise()
+
+	future.OnComplete(func(ret async.Result) {
+		results, _ := ret.Value.(map[uid.ID]async.Result)
+		for _, result := range results {
+			if errors.Is(result.Error, callpath.ErrUnknownIndex) {
+				callpath.Demote(cp.Script, cp.Method)
+				resend().OnComplete(promise.Resolve)
+				return
+			}
+		}
+		promise.Resolve(ret)
+	})
+
+	return fallback
+}
+
+// fallbackLongBatchCallPaths 在批量请求中部分调用路径无法被对端解析时，降级这些调用路径并仅重发对应的调用，
+// 重发结果按原顺序回填。
+func fallbackLongBatchCallPaths(short bool, calls []BatchCall, future async.Future, resend func(calls []BatchCall) async.Future) async.Future {
+	if !short {
+		return future
+	}
+
+	promise, fallback := async.NewPromise()
+
+	future.OnComplete(func(ret async.Result) {
+		results, ok := ret.Value.([]async.Result)
+		if !ok {
+			promise.Resolve(ret)
+			return
+		}
+
+		var idxs []int
+		var retries []BatchCall
+
+		for i := range results {
+			if i < len(calls) && errors.Is(results[i].Error, callpath.ErrUnknownIndex) {
+				callpath.Demote(calls[i].CallPath.Script, calls[i].CallPath.Method)
+				idxs = append(idxs, i)
+				retries = append(retries, calls[i])
+			}
+		}
+
+		if len(retries) <= 0 {
+			promise.Resolve(ret)
+			return
+		}
+
+		resend(retries).OnComplete(func(retryRet async.Result) {
+			retryResults, _ := retryRet.Value.([]async.Result)
+			for j, i := range idxs {
+				switch {
+				case retryRet.Error != nil:
+					results[i] = async.NewResult(nil, retryRet.Error)
+				case j < len(retryResults):
+					results[i] = retryResults[j]
+				}
+			}
+			promise.Resolve(async.NewResult(results, nil))
+		})
+	})
+
+	return fallback
+}
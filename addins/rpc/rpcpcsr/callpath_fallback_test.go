@@ -0,0 +1,147 @@
+package rpcpcsr
+
+import (
+	"errors"
+	"testing"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+)
+
+func resolved(ret async.Result) async.Future {
+	promise, future := async.NewPromise()
+	promise.Resolve(ret)
+	return future
+}
+
+// assertLongForm 校验调用路径已被降级，随后清除临时降级以免影响其他用例。
+func assertLongForm(t *testing.T, cp callpath.CallPath) {
+	t.Helper()
+	defer callpath.ResetDemoted()
+
+	data, err := cp.Encode(true)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if data[1]&1 != 0 {
+		t.Fatalf("call path %s not demoted", cp)
+	}
+}
+
+func TestFallbackLongCallPath(t *testing.T) {
+	cp := callpath.CallPath{TargetKind: callpath.Service, Script: "Fallback", Method: "Single"}
+
+	t.Run("long form", func(t *testing.T) {
+		future := resolved(async.NewResult(nil, callpath.ErrUnknownIndex))
+		ret := fallbackLongCallPath(false, cp, future, func() async.Future {
+			t.Fatalf("unexpected resend")
+			return async.Rejected(ErrTerminated)
+		}).Wait(t.Context())
+		if !errors.Is(ret.Error, callpath.ErrUnknownIndex) {
+			t.Fatalf("unexpected result: %+v", ret)
+		}
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		future := resolved(async.NewResult(nil, ErrPermissionDenied))
+		ret := fallbackLongCallPath(true, cp, future, func() async.Future {
+			t.Fatalf("unexpected resend")
+			return async.Rejected(ErrTerminated)
+		}).Wait(t.Context())
+		if !errors.Is(ret.Error, ErrPermissionDenied) {
+			t.Fatalf("unexpected result: %+v", ret)
+		}
+	})
+
+	t.Run("unknown index", func(t *testing.T) {
+		resends := 0
+		future := resolved(async.NewResult(nil, callpath.ErrUnknownIndex))
+		ret := fallbackLongCallPath(true, cp, future, func() async.Future {
+			resends++
+			return resolved(async.NewResult("long", nil))
+		}).Wait(t.Context())
+		if resends != 1 || ret.Value != "long" {
+			t.Fatalf("resends = %d, result = %+v", resends, ret)
+		}
+		assertLongForm(t, cp)
+	})
+}
+
+func TestFallbackLongGroupCallPath(t *testing.T) {
+	cp := callpath.CallPath{TargetKind: callpath.Entity, Script: "Fallback", Method: "Group"}
+
+	results := map[uid.ID]async.Result{
+		uid.From("e1"): async.NewResult(nil, callpath.ErrUnknownIndex),
+		uid.From("e2"): async.NewResult(nil, callpath.ErrUnknownIndex),
+	}
+	retried := map[uid.ID]async.Result{
+		uid.From("e1"): {},
+		uid.From("e2"): {},
+	}
+
+	resends := 0
+	ret := fallbackLongGroupCallPath(true, cp, resolved(async.NewResult(results, nil)), func() async.Future {
+		resends++
+		return resolved(async.NewResult(retried, nil))
+	}).Wait(t.Context())
+
+	got, _ := ret.Value.(map[uid.ID]async.Result)
+	if resends != 1 || len(got) != 2 || !got[uid.From("e1")].OK() {
+		t.Fatalf("resends = %d, result = %+v", resends, ret)
+	}
+	assertLongForm(t, cp)
+}
+
+func TestFallbackLongBatchCallPaths(t *testing.T) {
+	calls := []BatchCall{
+		{CallPath: callpath.CallPath{TargetKind: callpath.Service, Script: "Fallback", Method: "Batch0"}},
+		{CallPath: callpath.CallPath{TargetKind: callpath.Service, Script: "Fallback", Method: "Batch1"}},
+		{CallPath: callpath.CallPath{TargetKind: callpath.Service, Script: "Fallback", Method: "Batch2"}},
+		{CallPath: callpath.CallPath{TargetKind: callpath.Service, Script: "Fallback", Method: "Batch3"}},
+	}
+
+	first := func() async.Future {
+		return resolved(async.NewResult([]async.Result{
+			async.NewResult(0, nil),
+			async.NewResult(nil, callpath.ErrUnknownIndex),
+			async.NewResult(2, nil),
+			async.NewResult(nil, callpath.ErrUnknownIndex),
+		}, nil))
+	}
+
+	t.Run("resends unknown calls", func(t *testing.T) {
+		var resent []BatchCall
+		ret := fallbackLongBatchCallPaths(true, calls, first(), func(retries []BatchCall) async.Future {
+			resent = retries
+			return resolved(async.NewResult([]async.Result{async.NewResult(1, nil), async.NewResult(3, nil)}, nil))
+		}).Wait(t.Context())
+
+		if len(resent) != 2 || resent[0].CallPath != calls[1].CallPath || resent[1].CallPath != calls[3].CallPath {
+			t.Fatalf("resent calls = %+v", resent)
+		}
+
+		results, _ := ret.Value.([]async.Result)
+		if len(results) != len(calls) {
+			t.Fatalf("unexpected result: %+v", ret)
+		}
+		for i := range results {
+			if !results[i].OK() || results[i].Value != i {
+				t.Fatalf("result %d = %+v", i, results[i])
+			}
+		}
+		assertLongForm(t, calls[1].CallPath)
+	})
+
+	t.Run("resend failed", func(t *testing.T) {
+		ret := fallbackLongBatchCallPaths(true, calls, first(), func(retries []BatchCall) async.Future {
+			return async.Rejected(ErrTerminated)
+		}).Wait(t.Context())
+
+		results, _ := ret.Value.([]async.Result)
+		if len(results) != len(calls) || results[0].Value != 0 || !errors.Is(results[1].Error, ErrTerminated) || !errors.Is(results[3].Error, ErrTerminated) {
+			t.Fatalf("unexpected result: %+v", ret)
+		}
+		callpath.ResetDemoted()
+	})
+}
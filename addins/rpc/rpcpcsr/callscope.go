@@ -0,0 +1,100 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"time"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/utils/tracing"
+)
+
+// parseSpanContext 解析消息中的 traceparent；格式非法时丢弃并开始新链路，不影响调用本身。
+func parseSpanContext(traceParent string) tracing.SpanContext {
+	sc, _ := tracing.ParseTraceParent(traceParent)
+	return sc
+}
+
+// spanName 返回调用目标的 Span 名称，同时用作指标的方法标签。
+func spanName(script, method string) string {
+	if script == "" {
+		return method
+	}
+	return script + "." + method
+}
+
+// _CallScope 记录一次被调方调用的 Span 与指标。
+type _CallScope struct {
+	svcName string
+	target  string
+	method  string
+	span    *tracing.ActiveSpan
+	start   time.Time
+}
+
+// beginCall 以 sc 为父上下文开始被调方 Span，并开始计时。
+func beginCall(svcCtx service.Context, sc tracing.SpanContext, target string, entityID uid.ID, script, method string) *_CallScope {
+	attrs := []tracing.Attribute{
+		tracing.Attr("rpc.system", "golaxy"),
+		tracing.Attr("rpc.target", target),
+		tracing.Attr("rpc.script", script),
+		tracing.Attr("rpc.method", method),
+	}
+	if entityID != uid.Nil {
+		attrs = append(attrs, tracing.Attr("rpc.entity_id", entityID.String()))
+	}
+
+	name := spanName(script, method)
+
+	return &_CallScope{
+		svcName: svcCtx.Name(),
+		target:  target,
+		method:  name,
+		span:    tracing.Default().Start(sc, svcCtx.Name(), name, tracing.SpanKind_Server, attrs...),
+		start:   time.Now(),
+	}
+}
+
+// spanContext 返回被调方 Span 的追踪上下文。
+func (cs *_CallScope) spanContext() tracing.SpanContext {
+	return cs.span.Context()
+}
+
+// end 结束 Span 并记录调用结果与耗时。
+func (cs *_CallScope) end(err error) {
+	cs.span.End(err)
+	metricServerCalls.With(cs.svcName, cs.target, cs.method, ResultCode(err)).Inc()
+	metricServerDuration.With(cs.svcName, cs.target, cs.method).Observe(time.Since(cs.start).Seconds())
+}
+
+// endOnComplete 在 Future 及其返回的异步结果全部完成后结束调用。
+func (cs *_CallScope) endOnComplete(future async.Future) {
+	future.OnComplete(func(ret async.Result) {
+		if ret.OK() {
+			if inner, ok := ret.Value.(async.Future); ok && !inner.IsNil() {
+				cs.endOnComplete(inner)
+				return
+			}
+		}
+		cs.end(ret.Error)
+	})
+}
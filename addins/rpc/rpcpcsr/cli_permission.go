@@ -0,0 +1,197 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/framework/addins/gate"
+	"git.golaxy.org/framework/addins/log"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"go.uber.org/zap"
+)
+
+// CliPermissionValidator 在网关按客户端会话身份校验其是否有权访问调用路径。
+type CliPermissionValidator = generic.Delegate2[gate.ISession, callpath.CallPath, bool]
+
+// CliRoleResolver 根据网关会话的身份（用户 ID、扩展数据等）解析客户端拥有的角色。
+type CliRoleResolver = func(session gate.ISession) []string
+
+// CliMethod 声明一个客户端可调用的方法。
+type CliMethod struct {
+	Method string   // Method 是方法名。
+	Roles  []string // Roles 是允许调用的角色，满足其一即可；为空表示任意客户端均可调用。
+}
+
+// ICliMethods 由组件或插件实现，声明其允许客户端调用的方法。
+type ICliMethods interface {
+	// CliMethods 返回允许客户端调用的方法声明。
+	CliMethods() []CliMethod
+}
+
+// DefaultCliFallbackPrefix 是未声明方法的默认回退前缀，兼容以 C_ 前缀标记客户端可调用方法的旧规则。
+const DefaultCliFallbackPrefix = "C_"
+
+// CliPermissions 保存按脚本（组件或插件名）和方法声明的客户端调用权限，可并发使用。
+// 未声明的方法仅在方法名带有回退前缀时允许任意客户端调用，否则拒绝；回退前缀默认为 DefaultCliFallbackPrefix。
+type CliPermissions struct {
+	mutex          sync.RWMutex
+	scripts        map[string]map[string][]string
+	roleResolver   CliRoleResolver
+	fallbackPrefix string
+}
+
+// DefaultCliPermissions 是默认的客户端调用权限表，供 DefaultValidateCliPermission 与 DefaultValidateCliSessionPermission 使用。
+var DefaultCliPermissions = NewCliPermissions()
+
+// NewCliPermissions 创建空的客户端调用权限表。
+func NewCliPermissions() *CliPermissions {
+	return &CliPermissions{
+		scripts:        map[string]map[string][]string{},
+		fallbackPrefix: DefaultCliFallbackPrefix,
+	}
+}
+
+// Declare 声明脚本的方法允许客户端调用；roles 为空表示任意客户端均可调用。重复声明会覆盖之前的角色。
+func (ps *CliPermissions) Declare(script, method string, roles ...string) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	methods, ok := ps.scripts[script]
+	if !ok {
+		methods = map[string][]string{}
+		ps.scripts[script] = methods
+	}
+	methods[method] = slices.Clone(roles)
+}
+
+// DeclareScript 按 ICliMethods 的声明登记脚本允许客户端调用的方法。
+func (ps *CliPermissions) DeclareScript(script string, methods ICliMethods) {
+	for _, m := range methods.CliMethods() {
+		ps.Declare(script, m.Method, m.Roles...)
+	}
+}
+
+// SetRoleResolver 设置会话角色解析器；未设置时会话不具有任何角色，只能调用未限定角色的方法。
+func (ps *CliPermissions) SetRoleResolver(resolver CliRoleResolver) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.roleResolver = resolver
+}
+
+// SetFallbackPrefix 设置未声明方法的回退前缀；为空时关闭回退，未声明的方法一律拒绝客户端调用。
+func (ps *CliPermissions) SetFallbackPrefix(prefix string) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.fallbackPrefix = prefix
+}
+
+// Declared 报告方法是否声明为允许客户端调用。
+func (ps *CliPermissions) Declared(script, method string) bool {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	_, ok := ps.scripts[script][method]
+	return ok
+}
+
+// Permitted 报告方法是否声明为允许客户端调用，或未声明但方法名带有回退前缀；不校验角色。
+func (ps *CliPermissions) Permitted(script, method string) bool {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	if _, ok := ps.scripts[script][method]; ok {
+		return true
+	}
+	return ps.fallback(method)
+}
+
+// Allow 报告具有 roles 的客户端是否可以调用方法；未声明的方法按回退前缀判断。
+func (ps *CliPermissions) Allow(script, method string, roles []string) bool {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	required, ok := ps.scripts[script][method]
+	if !ok {
+		return ps.fallback(method)
+	}
+	if len(required) <= 0 {
+		return true
+	}
+
+	for _, role := range roles {
+		if slices.Contains(required, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// fallback 报告未声明的方法名是否带有回退前缀，调用方需持有读锁。
+func (ps *CliPermissions) fallback(method string) bool {
+	return ps.fallbackPrefix != "" && strings.HasPrefix(method, ps.fallbackPrefix)
+}
+
+// ValidateSession 按会话角色校验客户端是否可以访问调用路径，可作为 CliPermissionValidator 使用。
+func (ps *CliPermissions) ValidateSession(session gate.ISession, cp callpath.CallPath) bool {
+	ps.mutex.RLock()
+	resolver := ps.roleResolver
+	ps.mutex.RUnlock()
+
+	var roles []string
+	if resolver != nil {
+		roles = resolver(session)
+	}
+
+	return ps.Allow(cp.Script, cp.Method, roles)
+}
+
+// DeclareCliMethod 在 DefaultCliPermissions 中声明脚本的方法允许客户端调用。
+func DeclareCliMethod(script, method string, roles ...string) {
+	DefaultCliPermissions.Declare(script, method, roles...)
+}
+
+// DeclareCliScript 在 DefaultCliPermissions 中按 ICliMethods 的声明登记脚本允许客户端调用的方法。
+func DeclareCliScript(script string, methods ICliMethods) {
+	DefaultCliPermissions.DeclareScript(script, methods)
+}
+
+// DefaultValidateCliSessionPermission 使用 DefaultCliPermissions 按会话角色校验客户端调用。
+func DefaultValidateCliSessionPermission(session gate.ISession, cp callpath.CallPath) bool {
+	return DefaultCliPermissions.ValidateSession(session, cp)
+}
+
+// auditPermissionDenied 记录权限拒绝的审计日志。
+func auditPermissionDenied(svcCtx service.Context, src string, cp callpath.CallPath, reason error, fields ...zap.Field) {
+	log.L(svcCtx).Named("audit").Warn("rpc permission denied",
+		append([]zap.Field{
+			zap.String("src", src),
+			zap.String("call_path", cp.String()),
+			zap.String("script", cp.Script),
+			zap.String("method", cp.Method),
+			zap.Error(reason),
+		}, fields...)...)
+}
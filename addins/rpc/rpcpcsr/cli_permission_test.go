@@ -0,0 +1,95 @@
+package rpcpcsr
+
+import (
+	"testing"
+
+	"git.golaxy.org/framework/addins/gate"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+)
+
+func TestCliPermissionsAllow(t *testing.T) {
+	ps := NewCliPermissions()
+	ps.Declare("Bag", "Open")
+	ps.Declare("Bag", "Clear", "admin", "gm")
+
+	cases := []struct {
+		script, method string
+		roles          []string
+		want           bool
+	}{
+		{"Bag", "Open", nil, true},
+		{"Bag", "Clear", nil, false},
+		{"Bag", "Clear", []string{"player"}, false},
+		{"Bag", "Clear", []string{"player", "gm"}, true},
+		{"Bag", "Sort", nil, false},
+		{"Shop", "Open", nil, false},
+	}
+
+	for _, c := range cases {
+		if got := ps.Allow(c.script, c.method, c.roles); got != c.want {
+			t.Errorf("Allow(%q, %q, %v) = %v, want %v", c.script, c.method, c.roles, got, c.want)
+		}
+	}
+}
+
+func TestCliPermissionsFallbackPrefix(t *testing.T) {
+	ps := NewCliPermissions()
+	ps.Declare("Bag", "C_Clear", "admin")
+
+	if !ps.Permitted("Bag", "C_Open") || !ps.Allow("Bag", "C_Open", nil) {
+		t.Fatal("undeclared C_ method should fall back to allowed")
+	}
+	if ps.Declared("Bag", "C_Open") {
+		t.Fatal("fallback method reported as declared")
+	}
+	if ps.Allow("Bag", "C_Clear", nil) {
+		t.Fatal("declared roles should override the fallback prefix")
+	}
+	if ps.Permitted("Bag", "Open") {
+		t.Fatal("method without prefix should not be permitted")
+	}
+
+	ps.SetFallbackPrefix("")
+
+	if ps.Permitted("Bag", "C_Open") || ps.Allow("Bag", "C_Open", nil) {
+		t.Fatal("fallback prefix should be disabled")
+	}
+	if !ps.Permitted("Bag", "C_Clear") {
+		t.Fatal("declared method should stay permitted")
+	}
+}
+
+func TestCliPermissionsValidateSession(t *testing.T) {
+	ps := NewCliPermissions()
+	ps.Declare("Bag", "Clear", "admin")
+
+	cp := callpath.CallPath{TargetKind: callpath.Entity, Script: "Bag", Method: "Clear"}
+
+	if ps.ValidateSession(nil, cp) {
+		t.Fatal("session without roles should be denied")
+	}
+
+	ps.SetRoleResolver(func(gate.ISession) []string { return []string{"admin"} })
+
+	if !ps.ValidateSession(nil, cp) {
+		t.Fatal("session with admin role should be allowed")
+	}
+}
+
+func TestCliPermissionsDeclareScript(t *testing.T) {
+	ps := NewCliPermissions()
+	ps.DeclareScript("Bag", testCliMethods{{Method: "Open"}, {Method: "Clear", Roles: []string{"admin"}}})
+
+	if !ps.Declared("Bag", "Open") || !ps.Declared("Bag", "Clear") {
+		t.Fatal("script methods not declared")
+	}
+	if ps.Allow("Bag", "Clear", nil) || !ps.Allow("Bag", "Clear", []string{"admin"}) {
+		t.Fatal("declared roles not applied")
+	}
+}
+
+type testCliMethods []CliMethod
+
+func (ms testCliMethods) CliMethods() []CliMethod {
+	return ms
+}
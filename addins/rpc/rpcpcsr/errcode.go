@@ -0,0 +1,82 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"git.golaxy.org/core"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/correlation"
+)
+
+// 框架错误码，初始化时注册到 variant.ErrorRegistry()，调用方可直接使用 errors.Is 匹配远端返回的框架错误。
+// 区间 [1, 1000) 保留给框架使用，业务错误码应避开该区间。
+const (
+	ErrCodeUndeliverable                int32 = 100 // ErrUndeliverable 的错误码。
+	ErrCodeTerminated                   int32 = 101 // ErrTerminated 的错误码。
+	ErrCodeEntityNotFound               int32 = 102 // ErrEntityNotFound 的错误码。
+	ErrCodeSessionNotFound              int32 = 103 // ErrSessionNotFound 的错误码。
+	ErrCodeGroupNotFound                int32 = 104 // ErrGroupNotFound 的错误码。
+	ErrCodeDistEntityNotFound           int32 = 105 // ErrDistEntityNotFound 的错误码。
+	ErrCodeDistEntityNodeNotFound       int32 = 106 // ErrDistEntityNodeNotFound 的错误码。
+	ErrCodeServiceNodeNotFound          int32 = 107 // ErrServiceNodeNotFound 的错误码。
+	ErrCodeCircuitOpen                  int32 = 108 // ErrCircuitOpen 的错误码。
+	ErrCodeIncorrectDestAddress         int32 = 109 // ErrIncorrectDestAddress 的错误码。
+	ErrCodeAddInNotFound                int32 = 110 // ErrAddInNotFound 的错误码。
+	ErrCodeAddInInactive                int32 = 111 // ErrAddInInactive 的错误码。
+	ErrCodeMethodNotFound               int32 = 112 // ErrMethodNotFound 的错误码。
+	ErrCodeComponentNotFound            int32 = 113 // ErrComponentNotFound 的错误码。
+	ErrCodeMethodParameterCountMismatch int32 = 114 // ErrMethodParameterCountMismatch 的错误码。
+	ErrCodeMethodParameterTypeMismatch  int32 = 115 // ErrMethodParameterTypeMismatch 的错误码。
+	ErrCodeAsyncMethodReturnedNil       int32 = 116 // ErrAsyncMethodReturnedNil 的错误码。
+	ErrCodePermissionDenied             int32 = 403 // ErrPermissionDenied 的错误码。
+	ErrCodeThrottled                    int32 = 429 // ErrThrottled 的错误码，网关因限流拒绝客户端请求时回复。
+	ErrCodePanicked                     int32 = 500 // core.ErrPanicked 的错误码，被调方法 panic 时回复。
+	ErrCodeTimeout                      int32 = 504 // correlation.ErrTimeout 的错误码，转发的调用等待响应超时时回复。
+)
+
+// ErrCodeUnknownCallPath 是 callpath.ErrUnknownIndex 的错误码，被调方无法解析压缩调用路径时回复。
+// 该错误码由 callpath 包注册，使不依赖本包的客户端也能识别并回退到完整调用路径。
+const ErrCodeUnknownCallPath = callpath.ErrCodeUnknownIndex
+
+func init() {
+	reg := variant.ErrorRegistry()
+	reg.Declare(ErrCodeUndeliverable, ErrUndeliverable)
+	reg.Declare(ErrCodeTerminated, ErrTerminated)
+	reg.Declare(ErrCodeEntityNotFound, ErrEntityNotFound)
+	reg.Declare(ErrCodeSessionNotFound, ErrSessionNotFound)
+	reg.Declare(ErrCodeGroupNotFound, ErrGroupNotFound)
+	reg.Declare(ErrCodeDistEntityNotFound, ErrDistEntityNotFound)
+	reg.Declare(ErrCodeDistEntityNodeNotFound, ErrDistEntityNodeNotFound)
+	reg.Declare(ErrCodeServiceNodeNotFound, ErrServiceNodeNotFound)
+	reg.Declare(ErrCodeCircuitOpen, ErrCircuitOpen)
+	reg.Declare(ErrCodeIncorrectDestAddress, ErrIncorrectDestAddress)
+	reg.Declare(ErrCodeAddInNotFound, ErrAddInNotFound)
+	reg.Declare(ErrCodeAddInInactive, ErrAddInInactive)
+	reg.Declare(ErrCodeMethodNotFound, ErrMethodNotFound)
+	reg.Declare(ErrCodeComponentNotFound, ErrComponentNotFound)
+	reg.Declare(ErrCodeMethodParameterCountMismatch, ErrMethodParameterCountMismatch)
+	reg.Declare(ErrCodeMethodParameterTypeMismatch, ErrMethodParameterTypeMismatch)
+	reg.Declare(ErrCodeAsyncMethodReturnedNil, ErrAsyncMethodReturnedNil)
+	reg.Declare(ErrCodePermissionDenied, ErrPermissionDenied)
+	reg.Declare(ErrCodeThrottled, ErrThrottled)
+	reg.Declare(ErrCodePanicked, core.ErrPanicked)
+	reg.Declare(ErrCodeTimeout, correlation.ErrTimeout)
+}
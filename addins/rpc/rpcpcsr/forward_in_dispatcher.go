@@ -131,6 +131,7 @@ func (p *_ForwardProcessor) acceptNotify(transit, src gap.Origin, dst string, re
 			err = ErrPermissionDenied
 		}
 		if err != nil {
+			auditPermissionDenied(p.svcCtx, src.Addr, cp, err, zap.String("transit", transit.Addr), zap.String("dst", dst))
 			log.L(p.svcCtx).Error("accept forwarded rpc notify failed",
 				zap.String("transit", transit.Addr),
 				zap.String("src", src.Addr),
@@ -144,7 +145,7 @@ func (p *_ForwardProcessor) acceptNotify(transit, src gap.Origin, dst string, re
 	switch cp.TargetKind {
 	case callpath.Service:
 		spawnProcessorTask(p.svcCtx, p.scope, func(context.Context) {
-			rets, err := CallService(p.svcCtx, cc, cp.Script, cp.Method, req.Args)
+			rets, err := CallService(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.Script, cp.Method, req.Args)
 			if err != nil {
 				log.L(p.svcCtx).Error("accept forwarded rpc notify to service failed",
 					zap.String("transit", transit.Addr),
@@ -167,7 +168,7 @@ func (p *_ForwardProcessor) acceptNotify(transit, src gap.Origin, dst string, re
 		})
 
 	case callpath.Runtime:
-		future, err := CallRuntime(p.svcCtx, cc, cp.ID, cp.Script, cp.Method, req.Args)
+		future, err := CallRuntime(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.ID, cp.Script, cp.Method, req.Args)
 		if err != nil {
 			log.L(p.svcCtx).Error("accept forwarded rpc notify to runtime failed",
 				zap.String("transit", transit.Addr),
@@ -207,7 +208,7 @@ func (p *_ForwardProcessor) acceptNotify(transit, src gap.Origin, dst string, re
 		})
 
 	case callpath.Entity:
-		future, err := CallEntity(p.svcCtx, cc, cp.ID, cp.Script, cp.Method, req.Args)
+		future, err := CallEntity(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.ID, cp.Script, cp.Method, req.Args)
 		if err != nil {
 			log.L(p.svcCtx).Error("accept forwarded rpc notify to entity failed",
 				zap.String("transit", transit.Addr),
@@ -289,6 +290,7 @@ func (p *_ForwardProcessor) acceptRequest(transit, src gap.Origin, dst string, r
 			err = ErrPermissionDenied
 		}
 		if err != nil {
+			auditPermissionDenied(p.svcCtx, src.Addr, cp, err, zap.String("transit", transit.Addr), zap.String("dst", dst))
 			err = fmt.Errorf("permission verification failed: %w", err)
 			log.L(p.svcCtx).Error("accept forwarded rpc request failed",
 				zap.String("transit", transit.Addr),
@@ -305,7 +307,7 @@ func (p *_ForwardProcessor) acceptRequest(transit, src gap.Origin, dst string, r
 	switch cp.TargetKind {
 	case callpath.Service:
 		spawnProcessorTask(p.svcCtx, p.scope, func(context.Context) {
-			rets, err := CallService(p.svcCtx, cc, cp.Script, cp.Method, req.Args)
+			rets, err := CallService(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.Script, cp.Method, req.Args)
 			if err != nil {
 				log.L(p.svcCtx).Error("accept forwarded rpc request to service failed",
 					zap.String("transit", transit.Addr),
@@ -330,7 +332,7 @@ func (p *_ForwardProcessor) acceptRequest(transit, src gap.Origin, dst string, r
 		})
 
 	case callpath.Runtime:
-		future, err := CallRuntime(p.svcCtx, cc, cp.ID, cp.Script, cp.Method, req.Args)
+		future, err := CallRuntime(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.ID, cp.Script, cp.Method, req.Args)
 		if err != nil {
 			log.L(p.svcCtx).Error("accept forwarded rpc request to runtime failed",
 				zap.String("transit", transit.Addr),
@@ -374,7 +376,7 @@ func (p *_ForwardProcessor) acceptRequest(transit, src gap.Origin, dst string, r
 		})
 
 	case callpath.Entity:
-		future, err := CallEntity(p.svcCtx, cc, cp.ID, cp.Script, cp.Method, req.Args)
+		future, err := CallEntity(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.ID, cp.Script, cp.Method, req.Args)
 		if err != nil {
 			log.L(p.svcCtx).Error("accept forwarded rpc request to entity failed",
 				zap.String("transit", transit.Addr),
@@ -428,7 +430,7 @@ func (p *_ForwardProcessor) resolveReply(transit, src gap.Origin, reply *gap.Msg
 			ret.Value = reply.Rets
 		}
 	} else {
-		ret.Error = &reply.Error
+		ret.Error = variant.ErrorRegistry().Decode(&reply.Error)
 	}
 
 	if !p.dsvc.Correlation().Resolve(reply.CorrID, ret) {
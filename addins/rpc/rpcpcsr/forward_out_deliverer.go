@@ -32,7 +32,6 @@ import (
 	"git.golaxy.org/framework/addins/rpc/callpath"
 	"git.golaxy.org/framework/addins/rpcstack"
 	"git.golaxy.org/framework/net/gap"
-	"git.golaxy.org/framework/net/gap/variant"
 	"go.uber.org/zap"
 )
 
@@ -54,6 +53,18 @@ func (p *_ForwardProcessor) Match(svcCtx service.Context, dst string, cc rpcstac
 
 // Request 将客户端单播 RPC 包装后发送到承载目标实体的中转服务，并返回响应 Future。
 func (p *_ForwardProcessor) Request(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, args []any) async.Future {
+	return p.RequestExt(svcCtx, dst, cc, cp, CallExt{}, args)
+}
+
+// RequestExt 与 Request 相同，但为客户端请求附带追踪上下文；客户端不支持去重，幂等键被忽略。
+// 对端无法解析压缩调用路径时，降级该调用路径并以完整形式重发。
+func (p *_ForwardProcessor) RequestExt(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, ext CallExt, args []any) async.Future {
+	return fallbackLongCallPath(p.reduceCallPath, cp, p.requestExt(svcCtx, dst, cc, cp, ext, args), func() async.Future {
+		return p.requestExt(svcCtx, dst, cc, cp, ext, args)
+	})
+}
+
+func (p *_ForwardProcessor) requestExt(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, ext CallExt, args []any) async.Future {
 	controller := p.dsvc.Correlation()
 	corrID, future, err := controller.Begin()
 	if err != nil {
@@ -67,7 +78,7 @@ func (p *_ForwardProcessor) Request(svcCtx service.Context, dst string, cc rpcst
 		return future
 	}
 
-	vargs, err := variant.NewArray(args)
+	vargs, argsData, err := encodeArgs(ext.ArgsCodec, args)
 	if err != nil {
 		controller.Cancel(corrID, err)
 		return future
@@ -87,10 +98,14 @@ func (p *_ForwardProcessor) Request(svcCtx service.Context, dst string, cc rpcst
 	})
 
 	msg := &gap.MsgRPCRequest{
-		CorrID:    corrID,
-		CallChain: nextCC,
-		Path:      cpBuf,
-		Args:      vargs,
+		CorrID:      corrID,
+		CallChain:   nextCC,
+		Path:        cpBuf,
+		Args:        vargs,
+		IdemKey:     ext.IdemKey,
+		TraceParent: ext.TraceParent,
+		ArgsCodec:   ext.ArgsCodec,
+		ArgsData:    argsData,
 	}
 
 	msgBuf, err := gap.Marshal(msg)
@@ -121,12 +136,17 @@ func (p *_ForwardProcessor) Request(svcCtx service.Context, dst string, cc rpcst
 
 // Notify 将客户端域单向 RPC 包装后发送到目标实体的中转服务或中转服务广播地址。
 func (p *_ForwardProcessor) Notify(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, args []any) error {
+	return p.NotifyExt(svcCtx, dst, cc, cp, CallExt{}, args)
+}
+
+// NotifyExt 与 Notify 相同，但为客户端通知附带追踪上下文。
+func (p *_ForwardProcessor) NotifyExt(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, ext CallExt, args []any) error {
 	forwardAddr, err := p.getForwardAddr(dst)
 	if err != nil {
 		return err
 	}
 
-	vargs, err := variant.NewArray(args)
+	vargs, argsData, err := encodeArgs(ext.ArgsCodec, args)
 	if err != nil {
 		return err
 	}
@@ -144,9 +164,12 @@ func (p *_ForwardProcessor) Notify(svcCtx service.Context, dst string, cc rpcsta
 	})
 
 	msg := &gap.MsgOnewayRPC{
-		CallChain: nextCC,
-		Path:      cpBuf,
-		Args:      vargs,
+		CallChain:   nextCC,
+		Path:        cpBuf,
+		Args:        vargs,
+		TraceParent: ext.TraceParent,
+		ArgsCodec:   ext.ArgsCodec,
+		ArgsData:    argsData,
 	}
 
 	msgBuf, err := gap.Marshal(msg)
@@ -23,6 +23,7 @@ import (
 	"git.golaxy.org/core/service"
 	"git.golaxy.org/core/utils/async"
 	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/core/utils/option"
 	"git.golaxy.org/core/utils/types"
 	"git.golaxy.org/framework/addins/dent"
 	"git.golaxy.org/framework/addins/dsvc"
@@ -34,25 +35,36 @@ import (
 	"go.uber.org/zap"
 )
 
-// NewGateProcessor 创建客户端与网关间的 RPC 处理器。
+// NewGateProcessor 创建客户端与网关间的 RPC 处理器，参数使用 WithGate.Default。
 func NewGateProcessor(mc gap.IMsgCreator) any {
+	return NewGateProcessorWith(mc)
+}
+
+// NewGateProcessorWith 使用设置项创建客户端与网关间的 RPC 处理器，未设置的参数使用 WithGate.Default。
+func NewGateProcessorWith(mc gap.IMsgCreator, settings ...option.Setting[GateProcessorOptions]) any {
+	options := option.New(WithGate.Default(), settings...)
+
 	return &_GateProcessor{
-		encoder: codec.NewEncoder(),
-		decoder: codec.NewDecoder(mc),
+		encoder:       codec.NewEncoder(),
+		decoder:       codec.NewDecoder(mc),
+		permValidator: options.PermValidator,
+		limiter:       newGateLimiter(options.RateLimits),
 	}
 }
 
 // _GateProcessor 在网关会话和分布式服务消息通道之间转发 RPC。
 type _GateProcessor struct {
-	svcCtx  service.Context
-	dsvc    dsvc.IDistService
-	dentq   dent.IDistEntityQuerier
-	gate    gate.IGate
-	router  router.IRouter
-	encoder *codec.Encoder
-	decoder *codec.Decoder
-	scope   *async.Scope
-	stopped [2]async.Signal
+	svcCtx        service.Context
+	dsvc          dsvc.IDistService
+	dentq         dent.IDistEntityQuerier
+	gate          gate.IGate
+	router        router.IRouter
+	encoder       *codec.Encoder
+	decoder       *codec.Decoder
+	scope         *async.Scope
+	stopped       [2]async.Signal
+	permValidator CliPermissionValidator
+	limiter       *_GateLimiter
 }
 
 // Init 监听网关会话与分布式服务消息。
@@ -20,6 +20,7 @@
 package rpcpcsr
 
 import (
+	"fmt"
 	"slices"
 	"time"
 
@@ -27,6 +28,7 @@ import (
 	"git.golaxy.org/framework/addins/dent"
 	"git.golaxy.org/framework/addins/gate"
 	"git.golaxy.org/framework/addins/log"
+	"git.golaxy.org/framework/addins/rpc/callpath"
 	"git.golaxy.org/framework/net/gap"
 	"git.golaxy.org/framework/net/gap/variant"
 	"git.golaxy.org/framework/utils/correlation"
@@ -43,6 +45,19 @@ func (p *_GateProcessor) handleSessionEstablished(session gate.ISession) {
 		return
 	}
 	log.L(p.svcCtx).Debug("listen session data started", zap.String("session_id", session.ID().String()))
+
+	if p.limiter != nil {
+		go p.forgetOnClose(session)
+	}
+}
+
+// forgetOnClose 在会话关闭后清除其限流状态，处理器停止时直接返回。
+func (p *_GateProcessor) forgetOnClose(session gate.ISession) {
+	select {
+	case <-session.Closed().Done():
+		p.limiter.forget(session.ID())
+	case <-p.scope.Context().Done():
+	}
 }
 
 // handleSessionData 解码客户端 GAP 数据并接收入站转发消息。
@@ -76,6 +91,21 @@ func (p *_GateProcessor) acceptInbound(session gate.ISession, timestamp int64, r
 		return
 	}
 
+	if p.limiter != nil || len(p.permValidator) > 0 {
+		if kick, err := p.checkInbound(session, mapping.ClientAddr(), req); err != nil {
+			metricGateRejected.With(p.svcCtx.Name(), ResultCode(err)).Inc()
+			p.finishInbound(session, mapping.ClientAddr(), req.Dst, req.CorrID, err, req.TransID == gap.MsgID_RPC_Request)
+			if kick {
+				metricGateKicked.With(p.svcCtx.Name()).Inc()
+				log.L(p.svcCtx).Warn("session kicked, rpc throttled repeatedly",
+					zap.String("session_id", session.ID().String()),
+					zap.String("user_id", session.UserID()))
+				session.Close(err)
+			}
+			return
+		}
+	}
+
 	distEntity, ok := p.dentq.GetDistEntity(mapping.Entity().ID())
 	if !ok {
 		p.finishInbound(session, mapping.ClientAddr(), req.Dst, req.CorrID, ErrDistEntityNotFound, req.TransID == gap.MsgID_RPC_Request)
@@ -111,6 +141,75 @@ func (p *_GateProcessor) acceptInbound(session gate.ISession, timestamp int64, r
 	p.finishInbound(session, mapping.ClientAddr(), req.Dst, req.CorrID, nil, req.TransID == gap.MsgID_RPC_Request)
 }
 
+// checkInbound 解析客户端请求或通知的调用路径，先按令牌桶限流，再按会话身份校验权限。
+// 被限流时返回 ErrThrottled，并报告会话是否因连续被限流需要断开；权限拒绝时记录审计日志并返回 ErrPermissionDenied。
+// 网关无法解析压缩调用路径时返回包装 callpath.ErrUnknownIndex 的错误，客户端收到后降级该调用路径并以完整形式重发。
+func (p *_GateProcessor) checkInbound(session gate.ISession, src string, req *gap.MsgForward) (bool, error) {
+	path, ok, err := decodeCallPath(req.TransID, req.TransData)
+	if err != nil {
+		return false, fmt.Errorf("parse call path failed: %w", err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	cp, err := callpath.Parse(path)
+	if err != nil {
+		return false, fmt.Errorf("parse call path failed: %w", err)
+	}
+
+	if p.limiter != nil {
+		if !p.limiter.allow(session, cp) {
+			log.L(p.svcCtx).Warn("inbound rpc request/notify throttled",
+				zap.String("session_id", session.ID().String()),
+				zap.String("user_id", session.UserID()),
+				zap.String("src", src),
+				zap.String("call_path", cp.String()))
+			return p.limiter.throttle(session), ErrThrottled
+		}
+		p.limiter.pass(session)
+	}
+
+	if len(p.permValidator) > 0 {
+		passed, err := p.permValidator.SafeCall(func(passed bool, err error) bool {
+			return !passed || err != nil
+		}, session, cp)
+		if err != nil {
+			err = fmt.Errorf("%w: %w", ErrPermissionDenied, err)
+		} else if !passed {
+			err = ErrPermissionDenied
+		}
+		if err != nil {
+			auditPermissionDenied(p.svcCtx, src, cp, err,
+				zap.String("session_id", session.ID().String()),
+				zap.String("user_id", session.UserID()))
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// decodeCallPath 解码请求或通知，返回其中已编码的调用路径；其他消息返回 false。
+func decodeCallPath(transID gap.MsgID, data []byte) ([]byte, bool, error) {
+	switch transID {
+	case gap.MsgID_RPC_Request:
+		var msg gap.MsgRPCRequest
+		if _, err := msg.Write(data); err != nil {
+			return nil, false, err
+		}
+		return msg.Path, true, nil
+	case gap.MsgID_OnewayRPC:
+		var msg gap.MsgOnewayRPC
+		if _, err := msg.Write(data); err != nil {
+			return nil, false, err
+		}
+		return msg.Path, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
 // finishInbound 记录转发结果，并在请求转发失败时向客户端回复拒绝错误。
 func (p *_GateProcessor) finishInbound(session gate.ISession, src, dst string, corrID correlation.ID, err error, replyReject bool) {
 	if err == nil {
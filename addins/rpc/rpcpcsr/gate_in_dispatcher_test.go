@@ -0,0 +1,90 @@
+package rpcpcsr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/variant"
+)
+
+func mustMarshalMsg[T gap.ReadableMsg](t *testing.T, msg T) []byte {
+	t.Helper()
+
+	buf, err := gap.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	defer buf.Release()
+
+	return bytes.Clone(buf.Payload())
+}
+
+func TestDecodeCallPath(t *testing.T) {
+	cp := callpath.CallPath{TargetKind: callpath.Entity, Script: "Bag", Method: "C_Open"}
+
+	path, err := cp.Encode(false)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	args, err := variant.NewArray([]any{1, "a"})
+	if err != nil {
+		t.Fatalf("NewArray failed: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		transID gap.MsgID
+		data    []byte
+	}{
+		{"request", gap.MsgID_RPC_Request, mustMarshalMsg(t, &gap.MsgRPCRequest{CorrID: 7, Path: path, Args: args, TraceParent: "tp"})},
+		{"oneway", gap.MsgID_OnewayRPC, mustMarshalMsg(t, &gap.MsgOnewayRPC{Path: path, Args: args})},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok, err := decodeCallPath(c.transID, c.data)
+			if err != nil || !ok {
+				t.Fatalf("decodeCallPath failed: %v, %v", ok, err)
+			}
+			if !bytes.Equal(got, path) {
+				t.Fatalf("decodeCallPath = %v, want %v", got, path)
+			}
+
+			if _, _, err := decodeCallPath(c.transID, c.data[:len(c.data)/2]); err == nil {
+				t.Fatal("expected error for truncated message")
+			}
+		})
+	}
+
+	if _, ok, err := decodeCallPath(gap.MsgID_RPC_Reply, nil); ok || err != nil {
+		t.Fatalf("reply should be skipped, got %v, %v", ok, err)
+	}
+}
+
+func TestGateUnknownShortCallPathRepliesFallbackCode(t *testing.T) {
+	cp := callpath.CallPath{TargetKind: callpath.Entity, Script: "GateTestUncached", Method: "C_Open"}
+
+	path, err := cp.Encode(true)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	_, err = callpath.Parse(path)
+	if !errors.Is(err, callpath.ErrUnknownIndex) {
+		t.Fatalf("expected ErrUnknownIndex, got %v", err)
+	}
+
+	// 网关回复的错误必须保留错误码，客户端据此降级调用路径并以完整形式重发。
+	varErr := variant.NewError(fmt.Errorf("parse call path failed: %w", err))
+	if varErr.Code != ErrCodeUnknownCallPath {
+		t.Fatalf("expected code %d, got %d", ErrCodeUnknownCallPath, varErr.Code)
+	}
+	if !errors.Is(variant.ErrorRegistry().Decode(varErr), callpath.ErrUnknownIndex) {
+		t.Fatal("decoded error does not match ErrUnknownIndex")
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"git.golaxy.org/core/utils/option"
+)
+
+// GateProcessorOptions 定义客户端与网关间 RPC 处理器的参数。
+type GateProcessorOptions struct {
+	// PermValidator 在转发客户端请求和通知前按会话身份校验权限；为空时不校验。
+	PermValidator CliPermissionValidator
+	// RateLimits 在转发客户端请求和通知前按令牌桶限流；为 nil 时不限流。
+	RateLimits *GateRateLimits
+}
+
+// WithGate 提供 GateProcessorOptions 的设置项。
+var WithGate _GateProcessorOption
+
+type _GateProcessorOption struct{}
+
+// Default 返回默认设置，默认不校验权限、不限流，与 NewGateProcessor 行为一致。
+func (_GateProcessorOption) Default() option.Setting[GateProcessorOptions] {
+	return func(options *GateProcessorOptions) {
+		WithGate.PermValidator(nil)(options)
+		WithGate.RateLimits(nil)(options)
+	}
+}
+
+// PermValidator 设置按会话身份校验客户端调用的权限校验器，可使用 DefaultValidateCliSessionPermission。
+func (_GateProcessorOption) PermValidator(permValidator CliPermissionValidator) option.Setting[GateProcessorOptions] {
+	return func(options *GateProcessorOptions) {
+		options.PermValidator = permValidator
+	}
+}
+
+// RateLimits 设置转发客户端调用前执行的令牌桶限流。
+func (_GateProcessorOption) RateLimits(limits *GateRateLimits) option.Setting[GateProcessorOptions] {
+	return func(options *GateProcessorOptions) {
+		options.RateLimits = limits
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"sync"
+
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/gate"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/utils/ratelimit"
+)
+
+// GateRateLimits 定义网关转发客户端 RPC 请求和通知前执行的令牌桶限流，零值字段表示不限流。
+type GateRateLimits struct {
+	Global     ratelimit.Limit            // Global 由全部客户端共享。
+	PerSession ratelimit.Limit            // PerSession 按会话分别计数。
+	PerUser    ratelimit.Limit            // PerUser 按用户 ID 计数，同一用户的多个会话共享。
+	PerMethod  map[string]ratelimit.Limit // PerMethod 的键为 "脚本名.方法名"，按会话分别计数。
+	KickAfter  int                        // KickAfter 是会话连续被限流后断开连接的次数；小于等于 0 时不断开。
+}
+
+// _GateLimiter 按 GateRateLimits 对客户端调用限流，并统计会话连续被限流的次数。
+type _GateLimiter struct {
+	kickAfter int
+	global    *ratelimit.Limiter[struct{}]
+	sessions  *ratelimit.Limiter[uid.ID]
+	users     *ratelimit.Limiter[string]
+	methods   map[string]*ratelimit.Limiter[uid.ID]
+	mutex     sync.Mutex
+	throttled map[uid.ID]int
+}
+
+func newGateLimiter(limits *GateRateLimits) *_GateLimiter {
+	if limits == nil {
+		return nil
+	}
+
+	l := &_GateLimiter{
+		kickAfter: limits.KickAfter,
+		global:    ratelimit.NewLimiter[struct{}](limits.Global),
+		sessions:  ratelimit.NewLimiter[uid.ID](limits.PerSession),
+		users:     ratelimit.NewLimiter[string](limits.PerUser),
+		methods:   map[string]*ratelimit.Limiter[uid.ID]{},
+		throttled: map[uid.ID]int{},
+	}
+
+	for method, limit := range limits.PerMethod {
+		l.methods[method] = ratelimit.NewLimiter[uid.ID](limit)
+	}
+
+	return l
+}
+
+// allow 依次检查方法、会话、用户和全局限流；任一限流拒绝时归还之前已取走的令牌，使被拒绝的调用不消耗配额。
+func (l *_GateLimiter) allow(session gate.ISession, cp callpath.CallPath) bool {
+	sessionID := session.ID()
+	userID := session.UserID()
+
+	methods, ok := l.methods[cp.Script+"."+cp.Method]
+	if ok && !methods.Allow(sessionID) {
+		return false
+	}
+
+	refund := func(sessions, users bool) {
+		if methods != nil {
+			methods.Refund(sessionID)
+		}
+		if sessions {
+			l.sessions.Refund(sessionID)
+		}
+		if users {
+			l.users.Refund(userID)
+		}
+	}
+
+	if !l.sessions.Allow(sessionID) {
+		refund(false, false)
+		return false
+	}
+	if userID != "" && !l.users.Allow(userID) {
+		refund(true, false)
+		return false
+	}
+	if !l.global.Allow(struct{}{}) {
+		refund(true, userID != "")
+		return false
+	}
+	return true
+}
+
+// throttle 记录会话被限流一次，返回是否应断开会话。
+func (l *_GateLimiter) throttle(session gate.ISession) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.kickAfter <= 0 {
+		return false
+	}
+
+	n := l.throttled[session.ID()] + 1
+	if n >= l.kickAfter {
+		delete(l.throttled, session.ID())
+		return true
+	}
+	l.throttled[session.ID()] = n
+	return false
+}
+
+// pass 清除会话的连续限流计数。
+func (l *_GateLimiter) pass(session gate.ISession) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.throttled, session.ID())
+}
+
+// forget 清除已关闭会话的连续限流计数。
+func (l *_GateLimiter) forget(sessionID uid.ID) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.throttled, sessionID)
+}
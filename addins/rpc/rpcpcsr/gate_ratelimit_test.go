@@ -0,0 +1,117 @@
+package rpcpcsr
+
+import (
+	"testing"
+
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/gate"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/utils/ratelimit"
+)
+
+type fakeSession struct {
+	gate.ISession
+	id     uid.ID
+	userID string
+}
+
+func (s *fakeSession) ID() uid.ID {
+	return s.id
+}
+
+func (s *fakeSession) UserID() string {
+	return s.userID
+}
+
+// slowLimit 在测试期间不会补充令牌。
+func slowLimit(burst int) ratelimit.Limit {
+	return ratelimit.Limit{Rate: 0.0001, Burst: burst}
+}
+
+func TestGateLimiterNil(t *testing.T) {
+	if l := newGateLimiter(nil); l != nil {
+		t.Fatalf("expected nil limiter, got %+v", l)
+	}
+}
+
+func TestGateLimiterRefundsOnReject(t *testing.T) {
+	l := newGateLimiter(&GateRateLimits{
+		PerSession: slowLimit(5),
+		PerUser:    slowLimit(1),
+		PerMethod:  map[string]ratelimit.Limit{"Bag.Open": slowLimit(1)},
+	})
+
+	cp := callpath.CallPath{TargetKind: callpath.Entity, Script: "Bag", Method: "Open"}
+	a := &fakeSession{id: uid.From("session-a"), userID: "user"}
+	b := &fakeSession{id: uid.From("session-b"), userID: "user"}
+
+	if !l.allow(a, cp) {
+		t.Fatal("expected first call to be allowed")
+	}
+	if l.allow(b, cp) {
+		t.Fatal("expected call to be rejected by the shared user limit")
+	}
+
+	// 被用户限流拒绝的调用不应消耗会话 b 的方法和会话配额。
+	if !l.methods["Bag.Open"].Allow(b.id) {
+		t.Fatal("method token was not refunded")
+	}
+	for i := range 5 {
+		if !l.sessions.Allow(b.id) {
+			t.Fatalf("session token %d was not refunded", i)
+		}
+	}
+}
+
+func TestGateLimiterGlobalRejectRefundsAll(t *testing.T) {
+	l := newGateLimiter(&GateRateLimits{
+		Global:     slowLimit(1),
+		PerSession: slowLimit(1),
+		PerUser:    slowLimit(1),
+	})
+
+	cp := callpath.CallPath{TargetKind: callpath.Entity, Script: "Bag", Method: "Open"}
+	a := &fakeSession{id: uid.From("session-a"), userID: "user-a"}
+	b := &fakeSession{id: uid.From("session-b"), userID: "user-b"}
+
+	if !l.allow(a, cp) {
+		t.Fatal("expected first call to be allowed")
+	}
+	if l.allow(b, cp) {
+		t.Fatal("expected call to be rejected by the global limit")
+	}
+	if !l.sessions.Allow(b.id) || !l.users.Allow(b.userID) {
+		t.Fatal("session or user token was not refunded")
+	}
+}
+
+func TestGateLimiterThrottleKick(t *testing.T) {
+	l := newGateLimiter(&GateRateLimits{KickAfter: 3})
+	s := &fakeSession{id: uid.From("session-a")}
+
+	if l.throttle(s) || l.throttle(s) {
+		t.Fatal("session kicked too early")
+	}
+	l.pass(s)
+	if l.throttle(s) || l.throttle(s) {
+		t.Fatal("pass did not reset the throttle count")
+	}
+	if !l.throttle(s) {
+		t.Fatal("expected session to be kicked")
+	}
+	if len(l.throttled) != 0 {
+		t.Fatalf("kicked session still tracked: %v", l.throttled)
+	}
+}
+
+func TestGateLimiterForget(t *testing.T) {
+	l := newGateLimiter(&GateRateLimits{KickAfter: 3})
+	s := &fakeSession{id: uid.From("session-a")}
+
+	l.throttle(s)
+	l.forget(s.id)
+
+	if len(l.throttled) != 0 {
+		t.Fatalf("closed session still tracked: %v", l.throttled)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"sync"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/option"
+	"git.golaxy.org/core/utils/types"
+	"git.golaxy.org/framework/addins/dsvc"
+	"git.golaxy.org/framework/addins/log"
+	"go.uber.org/zap"
+)
+
+// localProcessors 保存当前进程内已启动的本地处理器，键为所在服务节点的单播地址。
+var localProcessors sync.Map
+
+// NewLocalProcessor 创建进程内短路 RPC 处理器；目标为同一进程内安装了本地处理器的服务节点时，
+// 跳过编码与消息代理，直接在目标节点上调用方法，其余目标交由后续投递器处理，因此须排在服务处理器之前。
+// permValidator 作用于投递到当前节点的本地调用，应与服务处理器使用相同的校验规则，其他参数使用 WithService.Default。
+func NewLocalProcessor(permValidator PermissionValidator) any {
+	return NewLocalProcessorWith(WithService.PermValidator(permValidator))
+}
+
+// NewLocalProcessorWith 使用服务处理器的设置项创建进程内短路 RPC 处理器，未设置的参数使用 WithService.Default；
+// 本地调用不压缩调用路径，忽略 ReduceCallPath。携带幂等键的本地请求按 Dedup 设置去重，去重表与服务处理器相互独立。
+func NewLocalProcessorWith(settings ...option.Setting[ServiceProcessorOptions]) any {
+	options := option.New(WithService.Default(), settings...)
+
+	p := &_LocalProcessor{
+		permValidator: options.PermValidator,
+	}
+	if options.DedupWindow > 0 {
+		p.dedup = newDedupTable(options.DedupWindow, options.DedupPendingTimeout)
+	}
+	return p
+}
+
+// _LocalProcessor 在同一进程内的服务节点间直接投递 RPC。
+type _LocalProcessor struct {
+	svcCtx        service.Context
+	dsvc          dsvc.IDistService
+	scope         *async.Scope
+	permValidator PermissionValidator
+	localAddr     string
+	dedup         *_DedupTable
+}
+
+// Init 将当前服务节点登记为本地可达节点。
+func (p *_LocalProcessor) Init(svcCtx service.Context) {
+	p.svcCtx = svcCtx
+	p.dsvc = dsvc.AddIn.Require(svcCtx)
+	p.scope = async.NewScope(nil)
+	p.localAddr = p.dsvc.NodeDetails().LocalAddr
+
+	if !p.register() {
+		p.scope.Close()
+		log.L(svcCtx).Panic("register local rpc processor failed, node address already registered",
+			zap.String("addr", p.localAddr),
+			zap.String("processor", types.FullName(*p)))
+	}
+
+	log.L(p.svcCtx).Debug("rpc processor started", zap.String("processor", types.FullName(*p)))
+}
+
+// Shut 注销当前服务节点，并等待已接收的本地调用退出。
+func (p *_LocalProcessor) Shut(svcCtx service.Context) {
+	p.unregister()
+
+	p.scope.Close()
+	<-p.scope.Completion().Done()
+
+	log.L(p.svcCtx).Debug("rpc processor stopped", zap.String("processor", types.FullName(*p)))
+}
+
+// register 将处理器登记为其单播地址的本地处理器，地址已被登记时返回 false。
+func (p *_LocalProcessor) register() bool {
+	_, loaded := localProcessors.LoadOrStore(p.localAddr, p)
+	return !loaded
+}
+
+// unregister 注销处理器；地址已被其他处理器重新登记时不影响后者。
+func (p *_LocalProcessor) unregister() {
+	localProcessors.CompareAndDelete(p.localAddr, p)
+}
+
+// lookupLocalProcessor 返回单播地址对应的本地处理器。
+func lookupLocalProcessor(addr string) (*_LocalProcessor, bool) {
+	v, ok := localProcessors.Load(addr)
+	if !ok {
+		return nil, false
+	}
+	return v.(*_LocalProcessor), true
+}
@@ -0,0 +1,260 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"bytes"
+	"reflect"
+	"time"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/framework/addins/log"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/binaryutil"
+	"git.golaxy.org/framework/utils/correlation"
+	"go.uber.org/zap"
+)
+
+// Match 接受同一进程内已登记节点的单播地址；客户端目标以及广播、负载均衡地址交由其他投递器处理。
+func (p *_LocalProcessor) Match(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, oneway bool) bool {
+	if cp.TargetKind == callpath.Client {
+		return false
+	}
+	_, ok := lookupLocalProcessor(dst)
+	return ok
+}
+
+// Request 直接在本地目标节点上发起调用，返回由关联 ID 匹配结果的 Future。
+func (p *_LocalProcessor) Request(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, args []any) async.Future {
+	return p.RequestExt(svcCtx, dst, cc, cp, CallExt{}, args)
+}
+
+// RequestExt 直接在本地目标节点上发起附带追踪上下文的调用；ext.IdemKey 非空时目标节点在去重窗口内抑制重复执行。
+func (p *_LocalProcessor) RequestExt(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, ext CallExt, args []any) async.Future {
+	target, ok := lookupLocalProcessor(dst)
+	if !ok {
+		return async.Rejected(ErrUndeliverable)
+	}
+
+	vargs, err := snapshotLocalArgs(args)
+	if err != nil {
+		return async.Rejected(err)
+	}
+
+	corrID, future, err := p.dsvc.Correlation().Begin()
+	if err != nil {
+		return async.Rejected(err)
+	}
+
+	target.acceptRequest(p, corrID, ext.IdemKey, p.callChain(cc), cp, parseSpanContext(ext.TraceParent), vargs)
+
+	log.L(p.svcCtx).Debug("local rpc request delivered",
+		zap.String("dst", dst),
+		zap.Uint64("corr_id", uint64(corrID)),
+		zap.String("call_path", cp.String()))
+	return future
+}
+
+// resolve 以本地调用的结果完成关联 ID 对应的 Future；rets 由调用方释放。
+func (p *_LocalProcessor) resolve(corrID correlation.ID, rets variant.Array, retErr error) {
+	ret := async.Result{}
+
+	if retErr != nil {
+		// 与远端响应一致，以可传输错误返回，并关联回注册的本地错误
+		ret.Error = variant.ErrorRegistry().Decode(variant.NewError(retErr))
+	} else {
+		copied, err := copyLocalArray(rets)
+		if err != nil {
+			ret.Error = err
+		} else if len(copied.Items) > 0 {
+			ret.Value = copied
+		}
+	}
+
+	p.dsvc.Correlation().Resolve(corrID, ret)
+}
+
+// Notify 直接在本地目标节点上发起无需响应的调用。
+func (p *_LocalProcessor) Notify(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, args []any) error {
+	return p.NotifyExt(svcCtx, dst, cc, cp, CallExt{}, args)
+}
+
+// NotifyExt 直接在本地目标节点上发起附带追踪上下文的无需响应调用；排除来源且目标为当前节点时不调用。
+func (p *_LocalProcessor) NotifyExt(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, ext CallExt, args []any) error {
+	target, ok := lookupLocalProcessor(dst)
+	if !ok {
+		return ErrUndeliverable
+	}
+
+	if cp.ExcludeSrc && target == p {
+		log.L(p.svcCtx).Debug("local rpc notify skipped, source excluded",
+			zap.String("dst", dst),
+			zap.String("call_path", cp.String()))
+		return nil
+	}
+
+	vargs, err := snapshotLocalArgs(args)
+	if err != nil {
+		return err
+	}
+
+	target.accept(p.localAddr, p.callChain(cc), cp, parseSpanContext(ext.TraceParent), vargs, func(rets variant.Array, retErr error) {
+		rets.ReleaseIfSnapshot()
+	})
+
+	log.L(p.svcCtx).Debug("local rpc notify delivered",
+		zap.String("dst", dst),
+		zap.String("call_path", cp.String()))
+	return nil
+}
+
+// callChain 在调用链末尾追加当前节点，与经消息代理投递时被调方追加的来源一致。
+func (p *_LocalProcessor) callChain(cc rpcstack.CallChain) rpcstack.CallChain {
+	return append(cc[:len(cc):len(cc)],
+		rpcstack.Call{
+			Svc:       p.svcCtx.Name(),
+			Addr:      p.localAddr,
+			Timestamp: time.Now(),
+			Transit:   false,
+		},
+	)
+}
+
+// snapshotLocalArgs 复制参数中的可变数据，使被调方持有独立副本，不与调用方共享，见 copyLocalVariant。
+func snapshotLocalArgs(args []any) (variant.Array, error) {
+	vargs, err := variant.NewArray(args)
+	if err != nil {
+		return variant.Array{}, err
+	}
+	return copyLocalArray(vargs)
+}
+
+// copyLocalArray 将数组复制为独立的非快照数组；快照直接从其编码解码。
+func copyLocalArray(arr variant.Array) (variant.Array, error) {
+	if arr.IsSnapshot {
+		var ret variant.Array
+		if _, err := ret.Write(arr.SnapshotBytes.Payload()); err != nil {
+			return variant.Array{}, err
+		}
+		return ret, nil
+	}
+
+	ret := variant.Array{
+		Items: make([]variant.Variant, 0, len(arr.Items)),
+	}
+	for i := range arr.Items {
+		item, err := copyLocalVariant(arr.Items[i])
+		if err != nil {
+			return variant.Array{}, err
+		}
+		ret.Items = append(ret.Items, item)
+	}
+	return ret, nil
+}
+
+// copyLocalVariant 复制动态值中可能与调用方共享的数据：Array、Map、Struct 逐层复制，Bytes 复制内容，
+// 不可变的标量直接共享，指向调用方变量的标量指针解引用为值，其余类型编码后再解码。
+func copyLocalVariant(v variant.Variant) (variant.Variant, error) {
+	switch val := v.Value.(type) {
+	case variant.Null, variant.Bool, variant.Byte, variant.Int, variant.Int8, variant.Int16, variant.Int32, variant.Int64,
+		variant.Uint, variant.Uint8, variant.Uint16, variant.Uint32, variant.Uint64, variant.Float, variant.Double,
+		variant.String, variant.UID, variant.Time, variant.Duration:
+		return variant.NewVariant(val)
+
+	case *variant.Null, *variant.Bool, *variant.Byte, *variant.Int, *variant.Int8, *variant.Int16, *variant.Int32, *variant.Int64,
+		*variant.Uint, *variant.Uint8, *variant.Uint16, *variant.Uint32, *variant.Uint64, *variant.Float, *variant.Double,
+		*variant.String, *variant.UID, *variant.Time, *variant.Duration:
+		return variant.NewVariant(reflect.ValueOf(val).Elem().Interface().(variant.ReadableValue))
+
+	case variant.Bytes:
+		return variant.NewVariant(variant.Bytes(bytes.Clone(val)))
+	case *variant.Bytes:
+		return variant.NewVariant(variant.Bytes(bytes.Clone(*val)))
+
+	case variant.Array:
+		return copyLocalNested(copyLocalArray(val))
+	case *variant.Array:
+		return copyLocalNested(copyLocalArray(*val))
+
+	case variant.Map:
+		return copyLocalNested(copyLocalMap(val))
+	case *variant.Map:
+		return copyLocalNested(copyLocalMap(*val))
+
+	case variant.Struct:
+		return copyLocalNested(copyLocalStruct(val))
+	case *variant.Struct:
+		return copyLocalNested(copyLocalStruct(*val))
+	}
+
+	data := make([]byte, v.Size())
+	if _, err := binaryutil.CopyToBuff(data, v); err != nil {
+		return variant.Variant{}, err
+	}
+
+	var ret variant.Variant
+	if _, err := ret.Write(data); err != nil {
+		return variant.Variant{}, err
+	}
+	return ret, nil
+}
+
+func copyLocalNested[T variant.ReadableValue](v T, err error) (variant.Variant, error) {
+	if err != nil {
+		return variant.Variant{}, err
+	}
+	return variant.NewVariant(v)
+}
+
+func copyLocalMap(m variant.Map) (variant.Map, error) {
+	ret := variant.Map{
+		Entries: make(generic.UnorderedSliceMap[variant.Variant, variant.Variant], 0, len(m.Entries)),
+	}
+	for i := range m.Entries {
+		k, err := copyLocalVariant(m.Entries[i].K)
+		if err != nil {
+			return variant.Map{}, err
+		}
+		v, err := copyLocalVariant(m.Entries[i].V)
+		if err != nil {
+			return variant.Map{}, err
+		}
+		ret.Entries = append(ret.Entries, generic.UnorderedKV[variant.Variant, variant.Variant]{K: k, V: v})
+	}
+	return ret, nil
+}
+
+func copyLocalStruct(s variant.Struct) (variant.Struct, error) {
+	ret := variant.Struct{
+		Fields: make([]variant.StructField, 0, len(s.Fields)),
+	}
+	for i := range s.Fields {
+		v, err := copyLocalVariant(s.Fields[i].Value)
+		if err != nil {
+			return variant.Struct{}, err
+		}
+		ret.Fields = append(ret.Fields, variant.StructField{ID: s.Fields[i].ID, Value: v})
+	}
+	return ret, nil
+}
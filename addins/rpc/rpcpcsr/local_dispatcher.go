@@ -0,0 +1,140 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"context"
+	"fmt"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/framework/addins/log"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/correlation"
+	"git.golaxy.org/framework/utils/tracing"
+	"go.uber.org/zap"
+)
+
+// acceptRequest 在目标节点上接收 caller 发起的请求，完成时通过 caller 的关联控制器返回结果。
+// idemKey 非空且启用去重时，来自同一节点的重复请求等待首次执行的结果或直接复用缓存结果，不再重复执行。
+func (p *_LocalProcessor) acceptRequest(caller *_LocalProcessor, corrID correlation.ID, idemKey string, cc rpcstack.CallChain, cp callpath.CallPath, sc tracing.SpanContext, args variant.Array) {
+	if idemKey == "" || p.dedup == nil {
+		p.accept(caller.localAddr, cc, cp, sc, args, func(rets variant.Array, err error) {
+			defer rets.ReleaseIfSnapshot()
+			caller.resolve(corrID, rets, err)
+		})
+		return
+	}
+
+	src := gap.Origin{Svc: caller.svcCtx.Name(), Addr: caller.localAddr}
+	key := _DedupKey{src: src.Addr, idemKey: idemKey}
+
+	outcome := p.dedup.begin(key, src, corrID)
+	for _, waiter := range outcome.abandoned {
+		resolveLocalWaiter(waiter, variant.Array{}, correlation.ErrTimeout)
+	}
+	if !outcome.first {
+		log.L(p.svcCtx).Debug("accept local rpc deduplicated",
+			zap.String("src", src.Addr),
+			zap.Uint64("corr_id", uint64(corrID)),
+			zap.String("call_path", cp.String()),
+			zap.String("idem_key", idemKey),
+			zap.Bool("cached", outcome.cached))
+		if outcome.cached {
+			caller.resolve(corrID, outcome.rets, outcome.err)
+		}
+		return
+	}
+
+	p.accept(src.Addr, cc, cp, sc, args, func(rets variant.Array, err error) {
+		defer rets.ReleaseIfSnapshot()
+
+		snapshot, waiters := p.dedup.finish(key, rets, err)
+		caller.resolve(corrID, rets, err)
+
+		for _, waiter := range waiters {
+			resolveLocalWaiter(waiter, snapshot, err)
+		}
+	})
+}
+
+// resolveLocalWaiter 向等待首次执行结果的重复请求返回结果；发起方节点已停止时丢弃。
+func resolveLocalWaiter(waiter _DedupWaiter, rets variant.Array, err error) {
+	caller, ok := lookupLocalProcessor(waiter.src.Addr)
+	if !ok {
+		return
+	}
+	caller.resolve(waiter.corrID, rets, err)
+}
+
+// accept 在目标节点上校验权限后调用服务、运行时或实体目标，完成时以结果调用 done；done 负责释放返回值快照。
+// 目标节点停止后已接收但未执行的调用不会回调 done，调用方依赖关联超时结束等待。
+func (p *_LocalProcessor) accept(src string, cc rpcstack.CallChain, cp callpath.CallPath, sc tracing.SpanContext, args variant.Array, done func(rets variant.Array, err error)) {
+	finish := func(rets variant.Array, err error) {
+		if err != nil {
+			log.L(p.svcCtx).Error("accept local rpc failed",
+				zap.String("src", src),
+				zap.String("call_path", cp.String()),
+				zap.Error(err))
+		} else {
+			log.L(p.svcCtx).Debug("accept local rpc finished",
+				zap.String("src", src),
+				zap.String("call_path", cp.String()))
+		}
+		done(rets, err)
+	}
+
+	if err := verifyPermission(p.permValidator, cc, cp); err != nil {
+		auditPermissionDenied(p.svcCtx, src, cp, err)
+		finish(variant.Array{}, fmt.Errorf("permission verification failed: %w", err))
+		return
+	}
+
+	var future async.Future
+	var err error
+
+	switch cp.TargetKind {
+	case callpath.Service:
+		spawnProcessorTask(p.svcCtx, p.scope, func(context.Context) {
+			finish(CallService(p.svcCtx, cc, sc, cp.Script, cp.Method, args))
+		})
+		return
+
+	case callpath.Runtime:
+		future, err = CallRuntime(p.svcCtx, cc, sc, cp.ID, cp.Script, cp.Method, args)
+
+	case callpath.Entity:
+		future, err = CallEntity(p.svcCtx, cc, sc, cp.ID, cp.Script, cp.Method, args)
+
+	default:
+		err = ErrUndeliverable
+	}
+
+	if err != nil {
+		finish(variant.Array{}, err)
+		return
+	}
+
+	spawnProcessorTask(p.svcCtx, p.scope, func(ctx context.Context) {
+		finish(waitAsyncResult(ctx, future))
+	})
+}
@@ -0,0 +1,157 @@
+package rpcpcsr
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpcstack"
+)
+
+func newTestLocalProcessor(t *testing.T, addr string) *_LocalProcessor {
+	t.Helper()
+
+	p := &_LocalProcessor{localAddr: addr}
+	if !p.register() {
+		t.Fatalf("register %q failed", addr)
+	}
+	t.Cleanup(p.unregister)
+	return p
+}
+
+func TestLocalProcessorRegistry(t *testing.T) {
+	p := newTestLocalProcessor(t, "local-test/registry")
+
+	if got, ok := lookupLocalProcessor(p.localAddr); !ok || got != p {
+		t.Fatalf("lookup = %p, %v, want %p", got, ok, p)
+	}
+
+	dup := &_LocalProcessor{localAddr: p.localAddr}
+	if dup.register() {
+		t.Fatal("expected duplicate address to be rejected")
+	}
+
+	// 未登记成功的处理器停止时不能注销已登记的处理器。
+	dup.unregister()
+	if got, ok := lookupLocalProcessor(p.localAddr); !ok || got != p {
+		t.Fatal("unregistering a stale processor removed the registered one")
+	}
+
+	p.unregister()
+	if _, ok := lookupLocalProcessor(p.localAddr); ok {
+		t.Fatal("processor still registered after shutdown")
+	}
+}
+
+func TestLocalProcessorMatchFallsBackToBroker(t *testing.T) {
+	p := newTestLocalProcessor(t, "local-test/match-src")
+	target := newTestLocalProcessor(t, "local-test/match-dst")
+
+	entity := callpath.CallPath{TargetKind: callpath.Entity, ID: uid.From("entity"), Script: "Bag", Method: "Open"}
+	client := callpath.CallPath{TargetKind: callpath.Client, Script: "Bag", Method: "Open"}
+
+	if !p.Match(nil, target.localAddr, rpcstack.EmptyCallChain, entity, false) {
+		t.Fatal("expected registered node to be delivered locally")
+	}
+	if p.Match(nil, "local-test/remote", rpcstack.EmptyCallChain, entity, false) {
+		t.Fatal("expected unregistered node to fall back to broker delivery")
+	}
+	if p.Match(nil, target.localAddr, rpcstack.EmptyCallChain, client, false) {
+		t.Fatal("expected client targets to fall back to broker delivery")
+	}
+
+	// 目标节点停止后，后续调用交由消息代理投递；已匹配的调用返回 ErrUndeliverable。
+	target.unregister()
+
+	if p.Match(nil, target.localAddr, rpcstack.EmptyCallChain, entity, false) {
+		t.Fatal("expected stopped node to fall back to broker delivery")
+	}
+	if ret := p.RequestExt(nil, target.localAddr, rpcstack.EmptyCallChain, entity, CallExt{}, nil).Wait(t.Context()); !errors.Is(ret.Error, ErrUndeliverable) {
+		t.Fatalf("expected ErrUndeliverable, got %v", ret.Error)
+	}
+	if err := p.NotifyExt(nil, target.localAddr, rpcstack.EmptyCallChain, entity, CallExt{}, nil); !errors.Is(err, ErrUndeliverable) {
+		t.Fatalf("expected ErrUndeliverable, got %v", err)
+	}
+}
+
+func TestSnapshotLocalArgsIsolatesCaller(t *testing.T) {
+	data := []byte{1, 2, 3}
+
+	vargs, err := snapshotLocalArgs([]any{data, "a"})
+	if err != nil {
+		t.Fatalf("snapshotLocalArgs failed: %v", err)
+	}
+	if vargs.IsSnapshot || len(vargs.Items) != 2 {
+		t.Fatalf("unexpected args: %+v", vargs)
+	}
+
+	data[0] = 9
+
+	rv, err := vargs.Items[0].ToNative(reflect.TypeFor[[]byte]())
+	if err != nil {
+		t.Fatalf("ToNative failed: %v", err)
+	}
+	if got := rv.Bytes(); !bytes.Equal(got, []byte{1, 2, 3}) {
+		t.Fatalf("callee args share memory with caller: %v", got)
+	}
+
+	copied, err := copyLocalArray(vargs)
+	if err != nil {
+		t.Fatalf("copyLocalArray failed: %v", err)
+	}
+	if copied.IsSnapshot || len(copied.Items) != len(vargs.Items) {
+		t.Fatalf("unexpected copy: %+v", copied)
+	}
+}
+
+func TestSnapshotLocalArgsCopiesNestedValues(t *testing.T) {
+	type inner struct {
+		Data []byte `gap:"data"`
+	}
+
+	data := []byte{1, 2, 3}
+	n := 7
+	m := map[string][]byte{"k": data}
+	s := inner{Data: data}
+
+	vargs, err := snapshotLocalArgs([]any{&n, m, s, [][]byte{data}})
+	if err != nil {
+		t.Fatalf("snapshotLocalArgs failed: %v", err)
+	}
+
+	data[0] = 9
+	n = 8
+
+	var gotN int
+	var gotM map[string][]byte
+	var gotS inner
+	var gotArr [][]byte
+	for i, out := range []any{&gotN, &gotM, &gotS, &gotArr} {
+		rv, err := vargs.Items[i].ToNative(reflect.TypeOf(out).Elem())
+		if err != nil {
+			t.Fatalf("item %d: ToNative failed: %v", i, err)
+		}
+		reflect.ValueOf(out).Elem().Set(rv)
+	}
+
+	want := []byte{1, 2, 3}
+	if gotN != 7 {
+		t.Fatalf("callee int shares memory with caller: %d", gotN)
+	}
+	if !bytes.Equal(gotM["k"], want) || !bytes.Equal(gotS.Data, want) || len(gotArr) != 1 || !bytes.Equal(gotArr[0], want) {
+		t.Fatalf("callee nested bytes share memory with caller: %v, %v, %v", gotM, gotS, gotArr)
+	}
+}
+
+func BenchmarkSnapshotLocalArgs(b *testing.B) {
+	args := []any{1, "name", uid.From("entity"), []byte("payload"), map[string]int{"a": 1, "b": 2}}
+
+	for b.Loop() {
+		if _, err := snapshotLocalArgs(args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"errors"
+	"strconv"
+
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/correlation"
+	"git.golaxy.org/framework/utils/metrics"
+)
+
+var (
+	metricServerCalls = metrics.Default().Counter("golaxy_rpc_server_calls_total",
+		"RPC requests and notifications handled by local targets.", "service", "target", "method", "code")
+	metricServerDuration = metrics.Default().Histogram("golaxy_rpc_server_duration_seconds",
+		"Time spent handling RPC calls, including asynchronous results.", nil, "service", "target", "method")
+	metricGateRejected = metrics.Default().Counter("golaxy_rpc_gate_rejected_total",
+		"Client RPC rejected at the gate before forwarding.", "service", "code")
+	metricGateKicked = metrics.Default().Counter("golaxy_rpc_gate_kicked_total",
+		"Sessions closed for being throttled repeatedly.", "service")
+)
+
+var resultCodes = []struct {
+	err  error
+	code string
+}{
+	{correlation.ErrTimeout, "timeout"},
+	{correlation.ErrClosed, "closed"},
+	{ErrCircuitOpen, "circuit_open"},
+	{ErrThrottled, "throttled"},
+	{ErrPermissionDenied, "permission_denied"},
+	{ErrUndeliverable, "undeliverable"},
+	{ErrTerminated, "terminated"},
+	{ErrMethodNotFound, "method_not_found"},
+}
+
+// ResultCode 返回调用结果在指标中的 code 标签：成功为 "ok"，框架已知错误为其名称，远端错误为错误码，其他错误为 "error"。
+func ResultCode(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	for _, rc := range resultCodes {
+		if errors.Is(err, rc.err) {
+			return rc.code
+		}
+	}
+	var remoteErr *variant.Error
+	if errors.As(err, &remoteErr) {
+		return strconv.Itoa(int(remoteErr.Code))
+	}
+	return "error"
+}
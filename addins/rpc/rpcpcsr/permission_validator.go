@@ -20,7 +20,7 @@
 package rpcpcsr
 
 import (
-	"strings"
+	"fmt"
 
 	"git.golaxy.org/core/utils/generic"
 	"git.golaxy.org/framework/addins/gate"
@@ -31,10 +31,29 @@ import (
 // PermissionValidator 校验调用链是否有权访问调用路径。
 type PermissionValidator = generic.Delegate2[rpcstack.CallChain, callpath.CallPath, bool]
 
-// DefaultValidateCliPermission 允许服务间调用，并将客户端 RPC 限制为调用 C_ 前缀的方法。
+// verifyPermission 使用 permValidator 校验调用；未配置校验器时放行，未通过时返回包装 ErrPermissionDenied 的错误。
+func verifyPermission(permValidator PermissionValidator, cc rpcstack.CallChain, cp callpath.CallPath) error {
+	if len(permValidator) <= 0 {
+		return nil
+	}
+
+	passed, err := permValidator.SafeCall(func(passed bool, err error) bool {
+		return !passed || err != nil
+	}, cc, cp)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPermissionDenied, err)
+	}
+	if !passed {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// DefaultValidateCliPermission 允许服务间调用，并将客户端 RPC 限制为 DefaultCliPermissions 中声明的方法，
+// 以及未声明但带有回退前缀（默认 C_）的方法；角色在网关按会话身份校验，见 DefaultValidateCliSessionPermission。
 func DefaultValidateCliPermission(cc rpcstack.CallChain, cp callpath.CallPath) bool {
 	if !gate.ClientDetails.DomainRoot.Contains(cc.First().Addr) {
 		return true
 	}
-	return strings.HasPrefix(cp.Method, "C_")
+	return DefaultCliPermissions.Permitted(cp.Script, cp.Method)
 }
@@ -24,8 +24,11 @@ import (
 
 	"git.golaxy.org/core/service"
 	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
 	"git.golaxy.org/framework/addins/rpc/callpath"
 	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/variant"
 )
 
 var (
@@ -43,6 +46,12 @@ var (
 	ErrDistEntityNotFound = errors.New("rpc: distributed entity not found")
 	// ErrDistEntityNodeNotFound 表示分布式实体没有匹配的服务节点。
 	ErrDistEntityNodeNotFound = errors.New("rpc: distributed entity node not found")
+	// ErrServiceNodeNotFound 表示服务发现中没有可路由的服务节点。
+	ErrServiceNodeNotFound = errors.New("rpc: service node not found")
+	// ErrCircuitOpen 表示目标节点的熔断器处于打开状态，请求被快速拒绝。
+	ErrCircuitOpen = errors.New("rpc: circuit open")
+	// ErrThrottled 表示客户端调用超出网关限流，回复客户端时使用错误码 ErrCodeThrottled。
+	ErrThrottled = errors.New("rpc: throttled")
 	// ErrIncorrectDestAddress 表示目标地址不符合 RPC 路由格式。
 	ErrIncorrectDestAddress = errors.New("rpc: incorrect destination Address")
 	// ErrAddInNotFound 表示目标服务或运行时插件不存在。
@@ -72,3 +81,54 @@ type IDeliverer interface {
 	// Notify 投递无需响应的通知。
 	Notify(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, args []any) error
 }
+
+// CallExt 是随 RPC 消息传递的可选扩展字段，字段为空时不编码。
+//
+// IdemKey 仅由服务处理器与本地处理器按来源节点去重，两者的去重表相互独立。批量请求不携带幂等键，
+// 按重试策略发起的请求不会合并进批量请求；发往客户端的请求会携带幂等键，但由客户端决定是否去重；
+// 客户端经网关转发到服务的请求不携带幂等键，不做去重。
+type CallExt struct {
+	IdemKey     string        // 幂等键，供被调方在去重窗口内抑制重复执行；仅对请求有效。
+	TraceParent string        // W3C traceparent 追踪上下文。
+	ArgsCodec   gap.ArgsCodec // 参数编解码器，零值使用默认的 variant 格式；仅服务域与客户端域的单个请求和通知支持，本地、分组与批量调用忽略。
+}
+
+// IExtDeliverer 是可选接口，投递器实现后可为请求与通知附带扩展字段。
+type IExtDeliverer interface {
+	// RequestExt 投递附带扩展字段的请求。
+	RequestExt(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, ext CallExt, args []any) async.Future
+	// NotifyExt 投递附带扩展字段的通知。
+	NotifyExt(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, ext CallExt, args []any) error
+}
+
+// IGroupDeliverer 是可选接口，投递器实现后可将同一节点上多个实体的同一调用合并为一条消息投递。
+// cp 的目标类型须为 callpath.Entity，其中的实体 ID 被 entityIDs 取代。
+type IGroupDeliverer interface {
+	// RequestGroup 投递需要响应的批量请求，返回的 Future 以 map[uid.ID]async.Result 完成，
+	// 成功结果的值为 variant.Array 返回值。
+	RequestGroup(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, entityIDs []uid.ID, ext CallExt, args []any) async.Future
+	// NotifyGroup 投递无需响应的批量通知。
+	NotifyGroup(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, entityIDs []uid.ID, ext CallExt, args []any) error
+}
+
+// BatchCall 是批量请求中的单个调用。
+type BatchCall struct {
+	CallPath callpath.CallPath // 调用路径。
+	Args     []any             // 调用参数。
+}
+
+// IBatchDeliverer 是可选接口，投递器实现后可将发往同一目标地址的多个请求合并为一条消息投递。
+type IBatchDeliverer interface {
+	// RequestBatch 投递批量请求，返回的 Future 以与 calls 一一对应的 []async.Result 完成，
+	// 成功结果的值为 variant.Array 返回值。被调方按顺序调度调用，同一实体的调用保持发起顺序。
+	RequestBatch(svcCtx service.Context, dst string, cc rpcstack.CallChain, calls []BatchCall, ext CallExt) async.Future
+}
+
+// encodeArgs 将调用参数转换为动态值数组并按 codec 编码，返回值分别用于填充消息的 Args 与 ArgsData 字段。
+func encodeArgs(codec gap.ArgsCodec, args []any) (variant.Array, []byte, error) {
+	vargs, err := variant.NewArray(args)
+	if err != nil {
+		return variant.Array{}, nil, err
+	}
+	return gap.EncodeArgs(codec, vargs)
+}
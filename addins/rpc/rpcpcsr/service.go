@@ -23,18 +23,30 @@ import (
 	"git.golaxy.org/core/service"
 	"git.golaxy.org/core/utils/async"
 	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/core/utils/option"
 	"git.golaxy.org/core/utils/types"
 	"git.golaxy.org/framework/addins/dsvc"
 	"git.golaxy.org/framework/addins/log"
 	"go.uber.org/zap"
 )
 
-// NewServiceProcessor 创建服务间 RPC 处理器；reduceCallPath 控制是否压缩调用路径。
+// NewServiceProcessor 创建服务间 RPC 处理器；reduceCallPath 控制是否压缩调用路径，其他参数使用 WithService.Default。
 func NewServiceProcessor(permValidator PermissionValidator, reduceCallPath bool) any {
-	return &_ServiceProcessor{
-		permValidator:  permValidator,
-		reduceCallPath: reduceCallPath,
+	return NewServiceProcessorWith(WithService.PermValidator(permValidator), WithService.ReduceCallPath(reduceCallPath))
+}
+
+// NewServiceProcessorWith 使用设置项创建服务间 RPC 处理器，未设置的参数使用 WithService.Default。
+func NewServiceProcessorWith(settings ...option.Setting[ServiceProcessorOptions]) any {
+	options := option.New(WithService.Default(), settings...)
+
+	p := &_ServiceProcessor{
+		permValidator:  options.PermValidator,
+		reduceCallPath: options.ReduceCallPath,
+	}
+	if options.DedupWindow > 0 {
+		p.dedup = newDedupTable(options.DedupWindow, options.DedupPendingTimeout)
 	}
+	return p
 }
 
 // _ServiceProcessor 通过分布式服务消息通道收发 RPC。
@@ -45,6 +57,7 @@ type _ServiceProcessor struct {
 	stopped        async.Signal
 	permValidator  PermissionValidator
 	reduceCallPath bool
+	dedup          *_DedupTable
 }
 
 // Init 订阅分布式服务消息并启动处理器。
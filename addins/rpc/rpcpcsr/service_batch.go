@@ -0,0 +1,233 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/framework/addins/log"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/variant"
+	"go.uber.org/zap"
+)
+
+// RequestBatch 将多个调用编码为一条批量 RPC 请求发送，返回由关联 ID 匹配响应的 Future。
+// 对端无法解析部分压缩调用路径时，降级这些调用路径并以完整形式重发对应的调用。
+func (p *_ServiceProcessor) RequestBatch(svcCtx service.Context, dst string, cc rpcstack.CallChain, calls []BatchCall, ext CallExt) async.Future {
+	return fallbackLongBatchCallPaths(p.reduceCallPath, calls, p.requestBatch(svcCtx, dst, cc, calls, ext), func(calls []BatchCall) async.Future {
+		return p.requestBatch(svcCtx, dst, cc, calls, ext)
+	})
+}
+
+func (p *_ServiceProcessor) requestBatch(svcCtx service.Context, dst string, cc rpcstack.CallChain, calls []BatchCall, ext CallExt) async.Future {
+	controller := p.dsvc.Correlation()
+	corrID, future, err := controller.Begin()
+	if err != nil {
+		return async.Rejected(err)
+	}
+
+	msg := &gap.MsgBatchRPCRequest{
+		CorrID:      corrID,
+		CallChain:   cc,
+		Calls:       make([]gap.BatchRPCCall, 0, len(calls)),
+		TraceParent: ext.TraceParent,
+	}
+
+	for i := range calls {
+		vargs, err := variant.NewArray(calls[i].Args)
+		if err != nil {
+			controller.Cancel(corrID, err)
+			return future
+		}
+
+		cpBuf, err := calls[i].CallPath.Encode(p.reduceCallPath)
+		if err != nil {
+			controller.Cancel(corrID, err)
+			return future
+		}
+
+		msg.Calls = append(msg.Calls, gap.BatchRPCCall{Path: cpBuf, Args: vargs})
+	}
+
+	if err = p.dsvc.Send(dst, msg); err != nil {
+		controller.Cancel(corrID, err)
+		return future
+	}
+
+	log.L(p.svcCtx).Debug("batch rpc request sent",
+		zap.String("dst", dst),
+		zap.Uint64("corr_id", uint64(corrID)),
+		zap.Int("calls", len(calls)))
+	return future
+}
+
+// acceptBatchRequest 按顺序校验并调度批量请求中的调用，全部完成后按原顺序回复结果。
+// 运行时与实体调用在接收时依次提交，同一实体的调用保持发起顺序；服务调用在处理任务中依次执行。
+func (p *_ServiceProcessor) acceptBatchRequest(src gap.Origin, req *gap.MsgBatchRPCRequest) {
+	cc := append(req.CallChain,
+		rpcstack.Call{
+			Svc:       src.Svc,
+			Addr:      src.Addr,
+			Timestamp: time.UnixMilli(src.Timestamp).Local(),
+			Transit:   false,
+		},
+	)
+	sc := parseSpanContext(req.TraceParent)
+
+	cps := make([]callpath.CallPath, len(req.Calls))
+	futures := make([]async.Future, len(req.Calls))
+	errs := make([]error, len(req.Calls))
+
+	for i := range req.Calls {
+		call := &req.Calls[i]
+
+		cp, err := callpath.Parse(call.Path)
+		if err != nil {
+			errs[i] = fmt.Errorf("parse call path failed: %w", err)
+			continue
+		}
+		cps[i] = cp
+
+		if err := verifyPermission(p.permValidator, cc, cp); err != nil {
+			auditPermissionDenied(p.svcCtx, src.Addr, cp, err)
+			errs[i] = fmt.Errorf("permission verification failed: %w", err)
+			continue
+		}
+
+		switch cp.TargetKind {
+		case callpath.Service:
+			// 在处理任务中执行
+		case callpath.Runtime:
+			futures[i], errs[i] = CallRuntime(p.svcCtx, cc, sc, cp.ID, cp.Script, cp.Method, call.Args)
+		case callpath.Entity:
+			futures[i], errs[i] = CallEntity(p.svcCtx, cc, sc, cp.ID, cp.Script, cp.Method, call.Args)
+		default:
+			errs[i] = ErrUndeliverable
+		}
+	}
+
+	spawnProcessorTask(p.svcCtx, p.scope, func(ctx context.Context) {
+		callService := func(i int) (variant.Array, error) {
+			return CallService(p.svcCtx, cc, sc, cps[i].Script, cps[i].Method, req.Calls[i].Args)
+		}
+
+		results := collectBatchResults(ctx, futures, errs, callService, func(i int, err error) {
+			log.L(p.svcCtx).Error("accept batch rpc request call failed",
+				zap.String("src", src.Addr),
+				zap.Uint64("corr_id", uint64(req.CorrID)),
+				zap.Int("index", i),
+				zap.String("call_path", cps[i].String()),
+				zap.Error(err))
+		})
+
+		log.L(p.svcCtx).Debug("accept batch rpc request finished",
+			zap.String("src", src.Addr),
+			zap.Uint64("corr_id", uint64(req.CorrID)),
+			zap.Int("calls", len(req.Calls)))
+
+		p.replyBatch(src, req, results)
+	})
+}
+
+// collectBatchResults 按调用顺序收集批量请求的结果：errs[i] 非空时直接记为该错误，futures[i] 非空时等待其完成，
+// 否则调用 callService 执行服务调用。结果与调用按下标一一对应，失败的调用逐个回调 onError。
+func collectBatchResults(ctx context.Context, futures []async.Future, errs []error, callService func(i int) (variant.Array, error), onError func(i int, err error)) []gap.BatchRPCResult {
+	results := make([]gap.BatchRPCResult, len(errs))
+
+	for i := range results {
+		rets, err := variant.Array{}, errs[i]
+
+		if err == nil {
+			if !futures[i].IsNil() {
+				rets, err = waitAsyncResult(ctx, futures[i])
+			} else {
+				rets, err = callService(i)
+			}
+		}
+
+		results[i].Rets = rets
+		if err != nil {
+			results[i].Error = *variant.NewError(err)
+			onError(i, err)
+		}
+	}
+
+	return results
+}
+
+// replyBatch 向请求来源发送批量结果并释放临时返回值快照。
+func (p *_ServiceProcessor) replyBatch(src gap.Origin, req *gap.MsgBatchRPCRequest, results []gap.BatchRPCResult) {
+	defer func() {
+		for i := range results {
+			results[i].Rets.ReleaseIfSnapshot()
+		}
+	}()
+
+	msg := &gap.MsgBatchRPCReply{
+		CorrID:  req.CorrID,
+		Results: results,
+	}
+
+	if err := p.dsvc.Send(src.Addr, msg); err != nil {
+		log.L(p.svcCtx).Error("batch rpc reply failed",
+			zap.String("src", src.Addr),
+			zap.Uint64("corr_id", uint64(req.CorrID)),
+			zap.Error(err))
+		return
+	}
+
+	log.L(p.svcCtx).Debug("batch rpc reply sent",
+		zap.String("src", src.Addr),
+		zap.Uint64("corr_id", uint64(req.CorrID)))
+}
+
+// resolveBatchReply 按关联 ID 以 []async.Result 完成批量请求的 Future。
+func (p *_ServiceProcessor) resolveBatchReply(src gap.Origin, reply *gap.MsgBatchRPCReply) {
+	rets := make([]async.Result, len(reply.Results))
+
+	for i := range reply.Results {
+		result := &reply.Results[i]
+
+		if result.Error.OK() {
+			if len(result.Rets.Items) > 0 {
+				rets[i].Value = result.Rets
+			}
+		} else {
+			rets[i].Error = variant.ErrorRegistry().Decode(&result.Error)
+		}
+	}
+
+	if !p.dsvc.Correlation().Resolve(reply.CorrID, async.NewResult(rets, nil)) {
+		log.L(p.svcCtx).Error("resolve batch rpc reply failed",
+			zap.String("src", src.Addr),
+			zap.Uint64("corr_id", uint64(reply.CorrID)))
+		return
+	}
+
+	log.L(p.svcCtx).Debug("batch rpc reply resolved",
+		zap.String("src", src.Addr),
+		zap.Uint64("corr_id", uint64(reply.CorrID)))
+}
@@ -0,0 +1,60 @@
+package rpcpcsr
+
+import (
+	"testing"
+	"time"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/framework/net/gap/variant"
+)
+
+func TestCollectBatchResultsKeepsCallOrder(t *testing.T) {
+	first, firstFuture := async.NewPromise()
+	second, secondFuture := async.NewPromise()
+
+	firstRets, secondRets := mustArray(t, "first"), mustArray(t, "second")
+
+	// 后发起的调用先完成，结果仍须按调用顺序排列。
+	go func() {
+		second.Resolve(async.NewResult(secondRets, nil))
+		time.Sleep(10 * time.Millisecond)
+		first.Resolve(async.NewResult(firstRets, nil))
+	}()
+
+	var service async.Future
+	futures := []async.Future{firstFuture, service, secondFuture, service}
+	errs := []error{nil, nil, nil, ErrPermissionDenied}
+
+	var serviceCalls []int
+	callService := func(i int) (variant.Array, error) {
+		serviceCalls = append(serviceCalls, i)
+		return mustArray(t, "service"), nil
+	}
+
+	var failed []int
+	results := collectBatchResults(t.Context(), futures, errs, callService, func(i int, err error) {
+		failed = append(failed, i)
+	})
+
+	if len(results) != len(futures) {
+		t.Fatalf("expected %d results, got %d", len(futures), len(results))
+	}
+	if len(serviceCalls) != 1 || serviceCalls[0] != 1 {
+		t.Fatalf("service calls = %v, want [1]", serviceCalls)
+	}
+	if len(failed) != 1 || failed[0] != 3 {
+		t.Fatalf("failed calls = %v, want [3]", failed)
+	}
+
+	for i, want := range []string{"first", "service", "second"} {
+		if !results[i].Error.OK() || len(results[i].Rets.Items) != 1 {
+			t.Fatalf("unexpected result %d: %+v", i, results[i])
+		}
+		if got, _ := results[i].Rets.Items[0].Value.(variant.String); string(got) != want {
+			t.Fatalf("result %d = %v, want %q", i, results[i].Rets.Items[0].Value, want)
+		}
+	}
+	if results[3].Error.Code != ErrCodePermissionDenied {
+		t.Fatalf("unexpected result 3 error: %+v", results[3].Error)
+	}
+}
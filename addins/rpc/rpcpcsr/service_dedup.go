@@ -0,0 +1,163 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"sync"
+	"time"
+
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/correlation"
+)
+
+// _DedupKey 标识一次幂等请求；幂等键由调用方生成，仅在同一来源内唯一。
+type _DedupKey struct {
+	src     string
+	idemKey string
+}
+
+// _DedupWaiter 是等待首次执行结果的重复请求。
+type _DedupWaiter struct {
+	src    gap.Origin
+	corrID correlation.ID
+}
+
+// _DedupEntry 记录幂等请求的执行状态；执行中的请求在 deadline 前有效，完成后缓存结果直到 expireAt。
+type _DedupEntry struct {
+	done     bool
+	rets     variant.Array
+	err      error
+	deadline time.Time
+	expireAt time.Time
+	waiters  []_DedupWaiter
+}
+
+// _DedupOutcome 是登记请求的结果。
+type _DedupOutcome struct {
+	first     bool           // first 表示请求需要执行。
+	cached    bool           // cached 表示请求是已完成请求的重复，rets 与 err 为缓存结果。
+	rets      variant.Array  // rets 是缓存的返回值。
+	err       error          // err 是缓存的错误。
+	abandoned []_DedupWaiter // abandoned 是首次执行超时未完成而不再等待的重复请求，调用方需向其回复错误。
+}
+
+// _DedupTable 按来源与幂等键在窗口内抑制重复执行：执行中的重复请求等待首次结果，已完成的重复请求直接复用缓存结果。
+// 首次执行超过 pendingTimeout 仍未完成时放弃等待，之后到达的重复请求会重新执行。
+type _DedupTable struct {
+	mutex          sync.Mutex
+	window         time.Duration
+	pendingTimeout time.Duration
+	entries        map[_DedupKey]*_DedupEntry
+	nextSweep      time.Time
+}
+
+func newDedupTable(window, pendingTimeout time.Duration) *_DedupTable {
+	return &_DedupTable{
+		window:         window,
+		pendingTimeout: pendingTimeout,
+		entries:        map[_DedupKey]*_DedupEntry{},
+	}
+}
+
+// begin 登记请求。首次执行返回 first；执行中的重复请求加入等待列表；已完成的重复请求返回缓存结果。
+func (t *_DedupTable) begin(key _DedupKey, src gap.Origin, corrID correlation.ID) (outcome _DedupOutcome) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	outcome.abandoned = t.sweep(now)
+
+	entry, ok := t.entries[key]
+	if ok && t.expired(entry, now) {
+		outcome.abandoned = append(outcome.abandoned, entry.waiters...)
+		delete(t.entries, key)
+		ok = false
+	}
+
+	switch {
+	case !ok:
+		t.entries[key] = &_DedupEntry{deadline: now.Add(t.pendingTimeout)}
+		outcome.first = true
+	case !entry.done:
+		entry.waiters = append(entry.waiters, _DedupWaiter{src: src, corrID: corrID})
+	default:
+		outcome.cached = true
+		outcome.rets = entry.rets
+		outcome.err = entry.err
+	}
+
+	return outcome
+}
+
+// finish 缓存执行结果并返回等待中的重复请求。rets 会被复制为不可回收快照，调用方仍负责释放原结果。
+func (t *_DedupTable) finish(key _DedupKey, rets variant.Array, retErr error) (variant.Array, []_DedupWaiter) {
+	snapshot, err := rets.Snapshot(false)
+	if err != nil {
+		snapshot = variant.Array{}
+		if retErr == nil {
+			retErr = err
+		}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	entry, ok := t.entries[key]
+	if !ok {
+		entry = &_DedupEntry{}
+		t.entries[key] = entry
+	}
+
+	entry.done = true
+	entry.rets = snapshot
+	entry.err = retErr
+	entry.expireAt = time.Now().Add(t.window)
+
+	waiters := entry.waiters
+	entry.waiters = nil
+
+	return snapshot, waiters
+}
+
+// expired 报告缓存结果是否过期，或首次执行是否超过最长等待时间。
+func (t *_DedupTable) expired(entry *_DedupEntry, now time.Time) bool {
+	if entry.done {
+		return !now.Before(entry.expireAt)
+	}
+	return !now.Before(entry.deadline)
+}
+
+// sweep 定期清理过期的结果与超时的执行，返回被放弃等待的重复请求。
+func (t *_DedupTable) sweep(now time.Time) (abandoned []_DedupWaiter) {
+	if now.Before(t.nextSweep) {
+		return nil
+	}
+	t.nextSweep = now.Add(min(t.window, t.pendingTimeout))
+
+	for key, entry := range t.entries {
+		if t.expired(entry, now) {
+			abandoned = append(abandoned, entry.waiters...)
+			delete(t.entries, key)
+		}
+	}
+
+	return abandoned
+}
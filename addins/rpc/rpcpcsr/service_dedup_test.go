@@ -0,0 +1,91 @@
+package rpcpcsr
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/variant"
+)
+
+func TestDedupTableWaitsForFirstExecution(t *testing.T) {
+	table := newDedupTable(time.Minute, time.Minute)
+	key := _DedupKey{src: "node-a", idemKey: "k1"}
+
+	if outcome := table.begin(key, gap.Origin{Addr: "node-a"}, 1); !outcome.first {
+		t.Fatal("expected first execution")
+	}
+	if outcome := table.begin(key, gap.Origin{Addr: "node-a"}, 2); outcome.first || outcome.cached {
+		t.Fatalf("expected duplicate to wait, got %+v", outcome)
+	}
+
+	cause := errors.New("failed")
+	_, waiters := table.finish(key, variant.Array{}, cause)
+	if len(waiters) != 1 || waiters[0].corrID != 2 {
+		t.Fatalf("unexpected waiters: %+v", waiters)
+	}
+
+	outcome := table.begin(key, gap.Origin{Addr: "node-a"}, 3)
+	if outcome.first || !outcome.cached || !errors.Is(outcome.err, cause) {
+		t.Fatalf("expected cached result, got %+v", outcome)
+	}
+}
+
+func TestDedupTableKeysBySource(t *testing.T) {
+	table := newDedupTable(time.Minute, time.Minute)
+
+	if outcome := table.begin(_DedupKey{src: "node-a", idemKey: "k1"}, gap.Origin{Addr: "node-a"}, 1); !outcome.first {
+		t.Fatal("expected first execution from node-a")
+	}
+	if outcome := table.begin(_DedupKey{src: "node-b", idemKey: "k1"}, gap.Origin{Addr: "node-b"}, 1); !outcome.first {
+		t.Fatal("same idempotency key from another source must execute")
+	}
+}
+
+func TestDedupTableAbandonsStalePending(t *testing.T) {
+	table := newDedupTable(time.Minute, 20*time.Millisecond)
+	key := _DedupKey{src: "node-a", idemKey: "k1"}
+
+	table.begin(key, gap.Origin{Addr: "node-a"}, 1)
+	table.begin(key, gap.Origin{Addr: "node-a"}, 2)
+
+	time.Sleep(30 * time.Millisecond)
+
+	outcome := table.begin(key, gap.Origin{Addr: "node-a"}, 3)
+	if !outcome.first {
+		t.Fatal("expected re-execution after pending timeout")
+	}
+	if len(outcome.abandoned) != 1 || outcome.abandoned[0].corrID != 2 {
+		t.Fatalf("unexpected abandoned waiters: %+v", outcome.abandoned)
+	}
+}
+
+func TestDedupTableSweep(t *testing.T) {
+	table := newDedupTable(20*time.Millisecond, 20*time.Millisecond)
+
+	done := _DedupKey{src: "node-a", idemKey: "done"}
+	table.begin(done, gap.Origin{Addr: "node-a"}, 1)
+	table.finish(done, variant.Array{}, nil)
+
+	pending := _DedupKey{src: "node-a", idemKey: "pending"}
+	table.begin(pending, gap.Origin{Addr: "node-a"}, 2)
+	table.begin(pending, gap.Origin{Addr: "node-a"}, 3)
+
+	time.Sleep(30 * time.Millisecond)
+
+	outcome := table.begin(_DedupKey{src: "node-b", idemKey: "other"}, gap.Origin{Addr: "node-b"}, 4)
+	if !outcome.first {
+		t.Fatal("expected first execution")
+	}
+	if len(outcome.abandoned) != 1 || outcome.abandoned[0].corrID != 3 {
+		t.Fatalf("unexpected abandoned waiters: %+v", outcome.abandoned)
+	}
+
+	table.mutex.Lock()
+	n := len(table.entries)
+	table.mutex.Unlock()
+	if n != 1 {
+		t.Fatalf("expected expired entries to be swept, %d left", n)
+	}
+}
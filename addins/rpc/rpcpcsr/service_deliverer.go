@@ -26,7 +26,6 @@ import (
 	"git.golaxy.org/framework/addins/rpc/callpath"
 	"git.golaxy.org/framework/addins/rpcstack"
 	"git.golaxy.org/framework/net/gap"
-	"git.golaxy.org/framework/net/gap/variant"
 	"go.uber.org/zap"
 )
 
@@ -50,13 +49,25 @@ func (p *_ServiceProcessor) Match(svcCtx service.Context, dst string, cc rpcstac
 
 // Request 编码并发送服务域 RPC 请求，返回由关联 ID 匹配响应的 Future。
 func (p *_ServiceProcessor) Request(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, args []any) async.Future {
+	return p.RequestExt(svcCtx, dst, cc, cp, CallExt{}, args)
+}
+
+// RequestExt 编码并发送附带扩展字段的服务域 RPC 请求；ext 为零值时与 Request 相同。
+// 对端无法解析压缩调用路径时，降级该调用路径并以完整形式重发。
+func (p *_ServiceProcessor) RequestExt(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, ext CallExt, args []any) async.Future {
+	return fallbackLongCallPath(p.reduceCallPath, cp, p.requestExt(svcCtx, dst, cc, cp, ext, args), func() async.Future {
+		return p.requestExt(svcCtx, dst, cc, cp, ext, args)
+	})
+}
+
+func (p *_ServiceProcessor) requestExt(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, ext CallExt, args []any) async.Future {
 	controller := p.dsvc.Correlation()
 	corrID, future, err := controller.Begin()
 	if err != nil {
 		return async.Rejected(err)
 	}
 
-	vargs, err := variant.NewArray(args)
+	vargs, argsData, err := encodeArgs(ext.ArgsCodec, args)
 	if err != nil {
 		controller.Cancel(corrID, err)
 		return future
@@ -69,10 +80,14 @@ func (p *_ServiceProcessor) Request(svcCtx service.Context, dst string, cc rpcst
 	}
 
 	msg := &gap.MsgRPCRequest{
-		CorrID:    corrID,
-		CallChain: cc,
-		Path:      cpBuf,
-		Args:      vargs,
+		CorrID:      corrID,
+		CallChain:   cc,
+		Path:        cpBuf,
+		Args:        vargs,
+		IdemKey:     ext.IdemKey,
+		TraceParent: ext.TraceParent,
+		ArgsCodec:   ext.ArgsCodec,
+		ArgsData:    argsData,
 	}
 
 	if err = p.dsvc.Send(dst, msg); err != nil {
@@ -89,7 +104,12 @@ func (p *_ServiceProcessor) Request(svcCtx service.Context, dst string, cc rpcst
 
 // Notify 编码并发送无需响应的服务域 RPC 通知。
 func (p *_ServiceProcessor) Notify(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, args []any) error {
-	vargs, err := variant.NewArray(args)
+	return p.NotifyExt(svcCtx, dst, cc, cp, CallExt{}, args)
+}
+
+// NotifyExt 编码并发送附带扩展字段的服务域 RPC 通知；ext 为零值时与 Notify 相同。
+func (p *_ServiceProcessor) NotifyExt(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, ext CallExt, args []any) error {
+	vargs, argsData, err := encodeArgs(ext.ArgsCodec, args)
 	if err != nil {
 		return err
 	}
@@ -100,9 +120,12 @@ func (p *_ServiceProcessor) Notify(svcCtx service.Context, dst string, cc rpcsta
 	}
 
 	msg := &gap.MsgOnewayRPC{
-		CallChain: cc,
-		Path:      cpBuf,
-		Args:      vargs,
+		CallChain:   cc,
+		Path:        cpBuf,
+		Args:        vargs,
+		TraceParent: ext.TraceParent,
+		ArgsCodec:   ext.ArgsCodec,
+		ArgsData:    argsData,
 	}
 
 	if err := p.dsvc.Send(dst, msg); err != nil {
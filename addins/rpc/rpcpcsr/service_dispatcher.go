@@ -50,6 +50,18 @@ func (p *_ServiceProcessor) handleServiceMsg(topic string, mp gap.MsgPacket) {
 
 	case gap.MsgID_RPC_Reply:
 		p.resolveReply(mp.Head.Src, mp.Body.(*gap.MsgRPCReply))
+
+	case gap.MsgID_GroupRPC_Request:
+		p.acceptGroupRequest(mp.Head.Src, mp.Body.(*gap.MsgGroupRPCRequest))
+
+	case gap.MsgID_GroupRPC_Reply:
+		p.resolveGroupReply(mp.Head.Src, mp.Body.(*gap.MsgGroupRPCReply))
+
+	case gap.MsgID_BatchRPC_Request:
+		p.acceptBatchRequest(mp.Head.Src, mp.Body.(*gap.MsgBatchRPCRequest))
+
+	case gap.MsgID_BatchRPC_Reply:
+		p.resolveBatchReply(mp.Head.Src, mp.Body.(*gap.MsgBatchRPCReply))
 	}
 }
 
@@ -89,6 +101,7 @@ func (p *_ServiceProcessor) acceptNotify(src gap.Origin, req *gap.MsgOnewayRPC)
 			err = ErrPermissionDenied
 		}
 		if err != nil {
+			auditPermissionDenied(p.svcCtx, src.Addr, cp, err)
 			log.L(p.svcCtx).Error("accept rpc notify failed",
 				zap.String("src", src.Addr),
 				zap.String("call_path", cp.String()),
@@ -100,7 +113,7 @@ func (p *_ServiceProcessor) acceptNotify(src gap.Origin, req *gap.MsgOnewayRPC)
 	switch cp.TargetKind {
 	case callpath.Service:
 		spawnProcessorTask(p.svcCtx, p.scope, func(context.Context) {
-			rets, err := CallService(p.svcCtx, cc, cp.Script, cp.Method, req.Args)
+			rets, err := CallService(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.Script, cp.Method, req.Args)
 			if err != nil {
 				log.L(p.svcCtx).Error("accept rpc notify to service failed",
 					zap.String("src", src.Addr),
@@ -119,7 +132,7 @@ func (p *_ServiceProcessor) acceptNotify(src gap.Origin, req *gap.MsgOnewayRPC)
 		})
 
 	case callpath.Runtime:
-		future, err := CallRuntime(p.svcCtx, cc, cp.ID, cp.Script, cp.Method, req.Args)
+		future, err := CallRuntime(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.ID, cp.Script, cp.Method, req.Args)
 		if err != nil {
 			log.L(p.svcCtx).Error("accept rpc notify to runtime failed",
 				zap.String("src", src.Addr),
@@ -153,7 +166,7 @@ func (p *_ServiceProcessor) acceptNotify(src gap.Origin, req *gap.MsgOnewayRPC)
 		})
 
 	case callpath.Entity:
-		future, err := CallEntity(p.svcCtx, cc, cp.ID, cp.Script, cp.Method, req.Args)
+		future, err := CallEntity(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.ID, cp.Script, cp.Method, req.Args)
 		if err != nil {
 			log.L(p.svcCtx).Error("accept rpc notify to entity failed",
 				zap.String("src", src.Addr),
@@ -188,7 +201,7 @@ func (p *_ServiceProcessor) acceptNotify(src gap.Origin, req *gap.MsgOnewayRPC)
 	}
 }
 
-// acceptRequest 校验权限并异步调用目标，完成后向来源地址发送响应。
+// acceptRequest 校验权限并异步调用目标，完成后向来源地址发送响应；携带幂等键的重复请求不会重复执行。
 func (p *_ServiceProcessor) acceptRequest(src gap.Origin, req *gap.MsgRPCRequest) {
 	cp, err := callpath.Parse(req.Path)
 	if err != nil {
@@ -220,6 +233,7 @@ func (p *_ServiceProcessor) acceptRequest(src gap.Origin, req *gap.MsgRPCRequest
 			err = ErrPermissionDenied
 		}
 		if err != nil {
+			auditPermissionDenied(p.svcCtx, src.Addr, cp, err)
 			err = fmt.Errorf("permission verification failed: %w", err)
 			log.L(p.svcCtx).Error("accept rpc request failed",
 				zap.String("src", src.Addr),
@@ -231,10 +245,29 @@ func (p *_ServiceProcessor) acceptRequest(src gap.Origin, req *gap.MsgRPCRequest
 		}
 	}
 
+	if req.IdemKey != "" && p.dedup != nil {
+		outcome := p.dedup.begin(_DedupKey{src: src.Addr, idemKey: req.IdemKey}, src, req.CorrID)
+		for _, waiter := range outcome.abandoned {
+			p.reply(waiter.src, waiter.corrID, variant.Array{}, correlation.ErrTimeout)
+		}
+		if !outcome.first {
+			log.L(p.svcCtx).Debug("accept rpc request deduplicated",
+				zap.String("src", src.Addr),
+				zap.Uint64("corr_id", uint64(req.CorrID)),
+				zap.String("call_path", cp.String()),
+				zap.String("idem_key", req.IdemKey),
+				zap.Bool("cached", outcome.cached))
+			if outcome.cached {
+				p.reply(src, req.CorrID, outcome.rets, outcome.err)
+			}
+			return
+		}
+	}
+
 	switch cp.TargetKind {
 	case callpath.Service:
 		spawnProcessorTask(p.svcCtx, p.scope, func(context.Context) {
-			rets, err := CallService(p.svcCtx, cc, cp.Script, cp.Method, req.Args)
+			rets, err := CallService(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.Script, cp.Method, req.Args)
 			if err != nil {
 				log.L(p.svcCtx).Error("accept rpc request to service failed",
 					zap.String("src", src.Addr),
@@ -251,11 +284,11 @@ func (p *_ServiceProcessor) acceptRequest(src gap.Origin, req *gap.MsgRPCRequest
 					zap.String("script", cp.Script),
 					zap.String("method", cp.Method))
 			}
-			p.reply(src, req.CorrID, rets, err)
+			p.replyRequest(src, req, rets, err)
 		})
 
 	case callpath.Runtime:
-		future, err := CallRuntime(p.svcCtx, cc, cp.ID, cp.Script, cp.Method, req.Args)
+		future, err := CallRuntime(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.ID, cp.Script, cp.Method, req.Args)
 		if err != nil {
 			log.L(p.svcCtx).Error("accept rpc request to runtime failed",
 				zap.String("src", src.Addr),
@@ -265,7 +298,7 @@ func (p *_ServiceProcessor) acceptRequest(src gap.Origin, req *gap.MsgRPCRequest
 				zap.String("script", cp.Script),
 				zap.String("method", cp.Method),
 				zap.Error(err))
-			p.reply(src, req.CorrID, variant.Array{}, err)
+			p.replyRequest(src, req, variant.Array{}, err)
 			return
 		}
 
@@ -289,11 +322,11 @@ func (p *_ServiceProcessor) acceptRequest(src gap.Origin, req *gap.MsgRPCRequest
 					zap.String("script", cp.Script),
 					zap.String("method", cp.Method))
 			}
-			p.reply(src, req.CorrID, rets, err)
+			p.replyRequest(src, req, rets, err)
 		})
 
 	case callpath.Entity:
-		future, err := CallEntity(p.svcCtx, cc, cp.ID, cp.Script, cp.Method, req.Args)
+		future, err := CallEntity(p.svcCtx, cc, parseSpanContext(req.TraceParent), cp.ID, cp.Script, cp.Method, req.Args)
 		if err != nil {
 			log.L(p.svcCtx).Error("accept rpc request to entity failed",
 				zap.String("src", src.Addr),
@@ -303,7 +336,7 @@ func (p *_ServiceProcessor) acceptRequest(src gap.Origin, req *gap.MsgRPCRequest
 				zap.String("script", cp.Script),
 				zap.String("method", cp.Method),
 				zap.Error(err))
-			p.reply(src, req.CorrID, variant.Array{}, err)
+			p.replyRequest(src, req, variant.Array{}, err)
 			return
 		}
 
@@ -327,7 +360,7 @@ func (p *_ServiceProcessor) acceptRequest(src gap.Origin, req *gap.MsgRPCRequest
 					zap.String("script", cp.Script),
 					zap.String("method", cp.Method))
 			}
-			p.reply(src, req.CorrID, rets, err)
+			p.replyRequest(src, req, rets, err)
 		})
 	}
 }
@@ -341,7 +374,7 @@ func (p *_ServiceProcessor) resolveReply(src gap.Origin, reply *gap.MsgRPCReply)
 			ret.Value = reply.Rets
 		}
 	} else {
-		ret.Error = &reply.Error
+		ret.Error = variant.ErrorRegistry().Decode(&reply.Error)
 	}
 
 	if !p.dsvc.Correlation().Resolve(reply.CorrID, ret) {
@@ -356,6 +389,17 @@ func (p *_ServiceProcessor) resolveReply(src gap.Origin, reply *gap.MsgRPCReply)
 		zap.Uint64("corr_id", uint64(reply.CorrID)))
 }
 
+// replyRequest 向请求来源发送结果；请求携带幂等键时缓存结果，并向等待首次执行结果的重复请求发送相同结果。
+func (p *_ServiceProcessor) replyRequest(src gap.Origin, req *gap.MsgRPCRequest, rets variant.Array, retErr error) {
+	if req.IdemKey != "" && p.dedup != nil {
+		cached, waiters := p.dedup.finish(_DedupKey{src: src.Addr, idemKey: req.IdemKey}, rets, retErr)
+		for _, waiter := range waiters {
+			p.reply(waiter.src, waiter.corrID, cached, retErr)
+		}
+	}
+	p.reply(src, req.CorrID, rets, retErr)
+}
+
 // reply 向请求来源发送结果并释放临时返回值快照；零关联 ID 不回复。
 func (p *_ServiceProcessor) reply(src gap.Origin, corrID correlation.ID, rets variant.Array, retErr error) {
 	defer rets.ReleaseIfSnapshot()
@@ -0,0 +1,272 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/service"
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/addins/log"
+	"git.golaxy.org/framework/addins/rpc/callpath"
+	"git.golaxy.org/framework/addins/rpcstack"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/correlation"
+	"go.uber.org/zap"
+)
+
+// RequestGroup 编码并发送同一节点上多个实体的批量 RPC 请求，返回由关联 ID 匹配响应的 Future。
+// 对端无法解析压缩调用路径时，降级该调用路径并以完整形式重发。
+func (p *_ServiceProcessor) RequestGroup(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, entityIDs []uid.ID, ext CallExt, args []any) async.Future {
+	return fallbackLongGroupCallPath(p.reduceCallPath, cp, p.requestGroup(svcCtx, dst, cc, cp, entityIDs, ext, args), func() async.Future {
+		return p.requestGroup(svcCtx, dst, cc, cp, entityIDs, ext, args)
+	})
+}
+
+func (p *_ServiceProcessor) requestGroup(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, entityIDs []uid.ID, ext CallExt, args []any) async.Future {
+	if cp.TargetKind != callpath.Entity {
+		return async.Rejected(fmt.Errorf("rpc: %w: group call path target kind must be entity", core.ErrArgs))
+	}
+
+	controller := p.dsvc.Correlation()
+	corrID, future, err := controller.Begin()
+	if err != nil {
+		return async.Rejected(err)
+	}
+
+	if err := p.sendGroup(dst, corrID, cc, cp, entityIDs, ext, args); err != nil {
+		controller.Cancel(corrID, err)
+		return future
+	}
+
+	log.L(p.svcCtx).Debug("group rpc request sent",
+		zap.String("dst", dst),
+		zap.Uint64("corr_id", uint64(corrID)),
+		zap.String("call_path", cp.String()),
+		zap.Int("entities", len(entityIDs)))
+	return future
+}
+
+// NotifyGroup 编码并发送同一节点上多个实体的批量 RPC 通知。
+func (p *_ServiceProcessor) NotifyGroup(svcCtx service.Context, dst string, cc rpcstack.CallChain, cp callpath.CallPath, entityIDs []uid.ID, ext CallExt, args []any) error {
+	if cp.TargetKind != callpath.Entity {
+		return fmt.Errorf("rpc: %w: group call path target kind must be entity", core.ErrArgs)
+	}
+
+	if err := p.sendGroup(dst, 0, cc, cp, entityIDs, ext, args); err != nil {
+		return err
+	}
+
+	log.L(p.svcCtx).Debug("group rpc notify sent",
+		zap.String("dst", dst),
+		zap.String("call_path", cp.String()),
+		zap.Int("entities", len(entityIDs)))
+	return nil
+}
+
+// sendGroup 编码并发送批量 RPC 请求；corrID 为零时为单向通知。
+func (p *_ServiceProcessor) sendGroup(dst string, corrID correlation.ID, cc rpcstack.CallChain, cp callpath.CallPath, entityIDs []uid.ID, ext CallExt, args []any) error {
+	vargs, err := variant.NewArray(args)
+	if err != nil {
+		return err
+	}
+
+	cpBuf, err := cp.Encode(p.reduceCallPath)
+	if err != nil {
+		return err
+	}
+
+	msg := &gap.MsgGroupRPCRequest{
+		CorrID:      corrID,
+		CallChain:   cc,
+		Path:        cpBuf,
+		EntityIDs:   entityIDs,
+		Args:        vargs,
+		TraceParent: ext.TraceParent,
+	}
+
+	return p.dsvc.Send(dst, msg)
+}
+
+// acceptGroupRequest 校验来源与权限后逐个调用目标实体，全部完成后按实体汇总结果回复；单向通知不回复。
+func (p *_ServiceProcessor) acceptGroupRequest(src gap.Origin, req *gap.MsgGroupRPCRequest) {
+	cp, err := callpath.Parse(req.Path)
+	if err == nil && cp.TargetKind != callpath.Entity {
+		err = fmt.Errorf("invalid call path target kind: %c", cp.TargetKind)
+	}
+	if err != nil {
+		err = fmt.Errorf("parse call path failed: %w", err)
+		log.L(p.svcCtx).Error("accept group rpc request failed",
+			zap.String("src", src.Addr),
+			zap.Uint64("corr_id", uint64(req.CorrID)),
+			zap.Error(err))
+		p.replyGroup(src, req.CorrID, failGroupResults(req.EntityIDs, err))
+		return
+	}
+
+	cc := append(req.CallChain,
+		rpcstack.Call{
+			Svc:       src.Svc,
+			Addr:      src.Addr,
+			Timestamp: time.UnixMilli(src.Timestamp).Local(),
+			Transit:   false,
+		},
+	)
+	sc := parseSpanContext(req.TraceParent)
+
+	futures := make([]async.Future, len(req.EntityIDs))
+	errs := make([]error, len(req.EntityIDs))
+
+	for i, id := range req.EntityIDs {
+		entityCP := cp
+		entityCP.ID = id
+
+		if err := verifyPermission(p.permValidator, cc, entityCP); err != nil {
+			auditPermissionDenied(p.svcCtx, src.Addr, entityCP, err)
+			errs[i] = fmt.Errorf("permission verification failed: %w", err)
+			continue
+		}
+
+		futures[i], errs[i] = CallEntity(p.svcCtx, cc, sc, id, cp.Script, cp.Method, req.Args)
+	}
+
+	spawnProcessorTask(p.svcCtx, p.scope, func(ctx context.Context) {
+		results := collectGroupResults(ctx, req.EntityIDs, futures, errs, func(id uid.ID, err error) {
+			log.L(p.svcCtx).Error("accept group rpc request to entity failed",
+				zap.String("src", src.Addr),
+				zap.Uint64("corr_id", uint64(req.CorrID)),
+				zap.String("call_path", cp.String()),
+				zap.String("id", id.String()),
+				zap.Error(err))
+		})
+
+		log.L(p.svcCtx).Debug("accept group rpc request finished",
+			zap.String("src", src.Addr),
+			zap.Uint64("corr_id", uint64(req.CorrID)),
+			zap.String("call_path", cp.String()),
+			zap.Int("entities", len(req.EntityIDs)))
+
+		p.replyGroup(src, req.CorrID, results)
+	})
+}
+
+// replyGroup 向请求来源发送批量结果并释放临时返回值快照；零关联 ID 不回复。
+func (p *_ServiceProcessor) replyGroup(src gap.Origin, corrID correlation.ID, results []gap.GroupRPCResult) {
+	defer func() {
+		for i := range results {
+			results[i].Rets.ReleaseIfSnapshot()
+		}
+	}()
+
+	if corrID == 0 {
+		return
+	}
+
+	msg := &gap.MsgGroupRPCReply{
+		CorrID:  corrID,
+		Results: results,
+	}
+
+	if err := p.dsvc.Send(src.Addr, msg); err != nil {
+		log.L(p.svcCtx).Error("group rpc reply failed",
+			zap.String("src", src.Addr),
+			zap.Uint64("corr_id", uint64(corrID)),
+			zap.Error(err))
+		return
+	}
+
+	log.L(p.svcCtx).Debug("group rpc reply sent",
+		zap.String("src", src.Addr),
+		zap.Uint64("corr_id", uint64(corrID)))
+}
+
+// resolveGroupReply 按关联 ID 以 map[uid.ID]async.Result 完成批量请求的 Future。
+func (p *_ServiceProcessor) resolveGroupReply(src gap.Origin, reply *gap.MsgGroupRPCReply) {
+	rets := groupReplyResults(reply)
+
+	if !p.dsvc.Correlation().Resolve(reply.CorrID, async.NewResult(rets, nil)) {
+		log.L(p.svcCtx).Error("resolve group rpc reply failed",
+			zap.String("src", src.Addr),
+			zap.Uint64("corr_id", uint64(reply.CorrID)))
+		return
+	}
+
+	log.L(p.svcCtx).Debug("group rpc reply resolved",
+		zap.String("src", src.Addr),
+		zap.Uint64("corr_id", uint64(reply.CorrID)))
+}
+
+// failGroupResults 将全部实体的结果记为同一错误。
+func failGroupResults(entityIDs []uid.ID, err error) []gap.GroupRPCResult {
+	results := make([]gap.GroupRPCResult, 0, len(entityIDs))
+	for _, id := range entityIDs {
+		results = append(results, gap.GroupRPCResult{EntityID: id, Error: *variant.NewError(err)})
+	}
+	return results
+}
+
+// collectGroupResults 按实体顺序等待各实体的调用结果；errs[i] 非空时不等待 futures[i]，直接记为该错误。
+// 失败的实体逐个回调 onError。
+func collectGroupResults(ctx context.Context, entityIDs []uid.ID, futures []async.Future, errs []error, onError func(id uid.ID, err error)) []gap.GroupRPCResult {
+	results := make([]gap.GroupRPCResult, len(entityIDs))
+
+	for i, id := range entityIDs {
+		results[i].EntityID = id
+
+		err := errs[i]
+		if err == nil {
+			results[i].Rets, err = waitAsyncResult(ctx, futures[i])
+		}
+
+		if err != nil {
+			results[i].Error = *variant.NewError(err)
+			onError(id, err)
+		}
+	}
+
+	return results
+}
+
+// groupReplyResults 将批量响应转换为按实体 ID 索引的结果，错误经错误注册表还原为本地错误。
+func groupReplyResults(reply *gap.MsgGroupRPCReply) map[uid.ID]async.Result {
+	rets := make(map[uid.ID]async.Result, len(reply.Results))
+
+	for i := range reply.Results {
+		result := &reply.Results[i]
+
+		ret := async.Result{}
+		if result.Error.OK() {
+			if len(result.Rets.Items) > 0 {
+				ret.Value = result.Rets
+			}
+		} else {
+			ret.Error = variant.ErrorRegistry().Decode(&result.Error)
+		}
+
+		rets[result.EntityID] = ret
+	}
+
+	return rets
+}
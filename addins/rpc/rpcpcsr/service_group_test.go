@@ -0,0 +1,96 @@
+package rpcpcsr
+
+import (
+	"errors"
+	"testing"
+
+	"git.golaxy.org/core/utils/async"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gap/variant"
+)
+
+func TestFailGroupResults(t *testing.T) {
+	ids := []uid.ID{uid.From("e1"), uid.From("e2")}
+
+	results := failGroupResults(ids, ErrPermissionDenied)
+	if len(results) != len(ids) {
+		t.Fatalf("expected %d results, got %d", len(ids), len(results))
+	}
+	for i := range results {
+		if results[i].EntityID != ids[i] || results[i].Error.Code != ErrCodePermissionDenied {
+			t.Fatalf("unexpected result %d: %+v", i, results[i])
+		}
+	}
+}
+
+func TestCollectGroupResultsReportsFailedMembers(t *testing.T) {
+	ids := []uid.ID{uid.From("e1"), uid.From("e2"), uid.From("e3")}
+
+	ok, okFuture := async.NewPromise()
+	ok.Resolve(async.NewResult("done", nil))
+
+	failed, failedFuture := async.NewPromise()
+	failed.Resolve(async.NewResult(nil, ErrEntityNotFound))
+
+	// 已预先失败的实体不会被等待，其 Future 保持零值。
+	var skipped async.Future
+	futures := []async.Future{okFuture, failedFuture, skipped}
+	errs := []error{nil, nil, ErrPermissionDenied}
+
+	var reported []uid.ID
+	results := collectGroupResults(t.Context(), ids, futures, errs, func(id uid.ID, err error) {
+		reported = append(reported, id)
+	})
+	defer func() {
+		for i := range results {
+			results[i].Rets.ReleaseIfSnapshot()
+		}
+	}()
+
+	if len(reported) != 2 || reported[0] != ids[1] || reported[1] != ids[2] {
+		t.Fatalf("reported failed members = %v", reported)
+	}
+	if !results[0].Error.OK() || len(results[0].Rets.Items) != 1 {
+		t.Fatalf("unexpected e1 result: %+v", results[0])
+	}
+	if results[1].Error.Code != ErrCodeEntityNotFound {
+		t.Fatalf("unexpected e2 error: %+v", results[1].Error)
+	}
+	if results[2].Error.Code != ErrCodePermissionDenied {
+		t.Fatalf("unexpected e3 error: %+v", results[2].Error)
+	}
+}
+
+func TestGroupReplyResults(t *testing.T) {
+	reply := &gap.MsgGroupRPCReply{
+		CorrID: 1,
+		Results: []gap.GroupRPCResult{
+			{EntityID: uid.From("e1"), Rets: mustArray(t, 1)},
+			{EntityID: uid.From("e2")},
+			{EntityID: uid.From("e3"), Error: *variant.NewError(ErrEntityNotFound)},
+		},
+	}
+
+	rets := groupReplyResults(reply)
+
+	if ret := rets[uid.From("e1")]; !ret.OK() || ret.Value == nil {
+		t.Fatalf("unexpected e1 result: %+v", ret)
+	}
+	if ret := rets[uid.From("e2")]; !ret.OK() || ret.Value != nil {
+		t.Fatalf("unexpected e2 result: %+v", ret)
+	}
+	if ret := rets[uid.From("e3")]; !errors.Is(ret.Error, ErrEntityNotFound) {
+		t.Fatalf("expected ErrEntityNotFound, got %v", ret.Error)
+	}
+}
+
+func mustArray(t *testing.T, values ...any) variant.Array {
+	t.Helper()
+
+	arr, err := variant.NewArray(values)
+	if err != nil {
+		t.Fatalf("NewArray failed: %v", err)
+	}
+	return arr
+}
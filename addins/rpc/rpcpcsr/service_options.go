@@ -0,0 +1,79 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package rpcpcsr
+
+import (
+	"time"
+
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/utils/exception"
+	"git.golaxy.org/core/utils/option"
+)
+
+// ServiceProcessorOptions 定义服务间 RPC 处理器的参数。
+type ServiceProcessorOptions struct {
+	// PermValidator 校验被调方法的访问权限；为空时不校验。
+	PermValidator PermissionValidator
+	// ReduceCallPath 控制发出的请求是否压缩调用路径。
+	ReduceCallPath bool
+	// DedupWindow 是携带幂等键的请求执行完成后缓存结果的去重窗口，小于等于 0 时不去重。
+	DedupWindow time.Duration
+	// DedupPendingTimeout 是首次执行的最长等待时间；超过后放弃等待中的重复请求并允许重新执行。
+	DedupPendingTimeout time.Duration
+}
+
+// WithService 提供 ServiceProcessorOptions 的设置项。
+var WithService _ServiceProcessorOption
+
+type _ServiceProcessorOption struct{}
+
+// Default 返回默认设置，默认不校验权限、压缩调用路径，并对携带幂等键的请求按 30 秒窗口去重，首次执行最长等待 30 秒。
+func (_ServiceProcessorOption) Default() option.Setting[ServiceProcessorOptions] {
+	return func(options *ServiceProcessorOptions) {
+		WithService.PermValidator(nil)(options)
+		WithService.ReduceCallPath(true)(options)
+		WithService.Dedup(30*time.Second, 30*time.Second)(options)
+	}
+}
+
+// PermValidator 设置被调方法的权限校验器。
+func (_ServiceProcessorOption) PermValidator(permValidator PermissionValidator) option.Setting[ServiceProcessorOptions] {
+	return func(options *ServiceProcessorOptions) {
+		options.PermValidator = permValidator
+	}
+}
+
+// ReduceCallPath 设置发出的请求是否压缩调用路径。
+func (_ServiceProcessorOption) ReduceCallPath(b bool) option.Setting[ServiceProcessorOptions] {
+	return func(options *ServiceProcessorOptions) {
+		options.ReduceCallPath = b
+	}
+}
+
+// Dedup 设置去重窗口与首次执行的最长等待时间；window 小于等于 0 时不去重，否则 pendingTimeout 必须大于 0。
+func (_ServiceProcessorOption) Dedup(window, pendingTimeout time.Duration) option.Setting[ServiceProcessorOptions] {
+	return func(options *ServiceProcessorOptions) {
+		if window > 0 && pendingTimeout <= 0 {
+			exception.Panicf("rpc: %w: option DedupPendingTimeout must be > 0", core.ErrArgs)
+		}
+		options.DedupWindow = window
+		options.DedupPendingTimeout = pendingTimeout
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"git.golaxy.org/core/utils/generic"
 	"git.golaxy.org/framework/addins/log"
 	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/tracing"
 	"go.uber.org/zap"
 )
 
@@ -47,10 +48,12 @@ type IRPCStack interface {
 	CallChain() CallChain
 	// Variables 返回当前调用的可变变量表；进入下一次调用时会被清空。
 	Variables() *Variables
+	// SpanContext 返回当前调用的追踪上下文，运行时内发起的 RPC 以其为父上下文；没有正在处理的 RPC 时返回零值。
+	SpanContext() tracing.SpanContext
 }
 
 type iRPCStack interface {
-	pushCallChain(cc CallChain)
+	pushCallChain(cc CallChain, sc tracing.SpanContext)
 	popCallChain()
 }
 
@@ -62,9 +65,10 @@ func newRPCStack(...any) IRPCStack {
 }
 
 type _RPCStack struct {
-	rtCtx     runtime.Context
-	callChain CallChain
-	variables Variables
+	rtCtx       runtime.Context
+	callChain   CallChain
+	variables   Variables
+	spanContext tracing.SpanContext
 }
 
 func (r *_RPCStack) Init(rtCtx runtime.Context) {
@@ -86,15 +90,22 @@ func (r *_RPCStack) Variables() *Variables {
 	return &r.variables
 }
 
-func (r *_RPCStack) pushCallChain(cc CallChain) {
+// SpanContext 返回当前调用的追踪上下文；没有正在处理的 RPC 时返回零值。
+func (r *_RPCStack) SpanContext() tracing.SpanContext {
+	return r.spanContext
+}
+
+func (r *_RPCStack) pushCallChain(cc CallChain, sc tracing.SpanContext) {
 	if cc == nil {
 		cc = EmptyCallChain
 	}
 	r.callChain = cc
 	r.variables = nil
+	r.spanContext = sc
 }
 
 func (r *_RPCStack) popCallChain() {
 	r.callChain = EmptyCallChain
 	r.variables = nil
+	r.spanContext = tracing.SpanContext{}
 }
@@ -19,6 +19,8 @@
 
 package rpcstack
 
+import "git.golaxy.org/framework/utils/tracing"
+
 // UnsafeRPCStack 返回可修改 RPC 调用链内部状态的非安全门面。
 //
 // Deprecated: 仅供框架 RPC 处理器维护调用上下文，业务代码不应使用。
@@ -32,8 +34,8 @@ type _UnsafeRPCStack struct {
 	IRPCStack
 }
 
-func (ur _UnsafeRPCStack) PushCallChain(cc CallChain) {
-	ur.pushCallChain(cc)
+func (ur _UnsafeRPCStack) PushCallChain(cc CallChain, sc tracing.SpanContext) {
+	ur.pushCallChain(cc, sc)
 }
 
 func (ur _UnsafeRPCStack) PopCallChain() {
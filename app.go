@@ -24,7 +24,7 @@ import (
 	"errors"
 	"net"
 	"net/http"
-	_ "net/http/pprof" // 注册 pprof 的默认 HTTP 处理器，供 initPProf 启动的服务使用。
+	_ "net/http/pprof" // 注册 pprof 的默认 HTTP 处理器，由 initHTTP 随 http.DefaultServeMux 挂载到管理 HTTP 服务。
 	"os/signal"
 	"strconv"
 	"sync"
@@ -34,6 +34,7 @@ import (
 	"git.golaxy.org/core"
 	"git.golaxy.org/core/utils/exception"
 	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/framework/utils/metrics"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -49,7 +50,7 @@ func NewApp() *App {
 		Run: func(*cobra.Command, []string) {
 			// Cobra 入口依次合并配置、启动辅助服务、运行服务副本并执行生命周期回调。
 			app.initConf()
-			app.initPProf()
+			app.initHTTP()
 			app.startingCB.UnsafeCall(app)
 			app.mainLoop()
 			app.terminatedCB.UnsafeCall(app)
@@ -58,6 +59,7 @@ func NewApp() *App {
 			DisableDefaultCmd: true,
 		},
 	}
+	app.cmd.AddCommand(newCatalogCmd())
 	return app
 }
 
@@ -116,7 +118,7 @@ func (app *App) InitCB(cb generic.Action1[*App]) *App {
 }
 
 // StartingCB 设置应用启动回调。
-// 回调在配置与可选 pprof 服务初始化完成后、启动服务副本前执行。
+// 回调在配置与可选管理 HTTP 服务（pprof、指标、服务目录）初始化完成后、启动服务副本前执行。
 func (app *App) StartingCB(cb generic.Action1[*App]) *App {
 	app.startingCB = cb
 	return app
@@ -214,6 +216,7 @@ func (app *App) initFlags() {
 	cmd.PersistentFlags().Duration("service.future_timeout", 3*time.Second, "timeout for future model of service interaction")
 	cmd.PersistentFlags().Duration("service.dent_ttl", 10*time.Second, "ttl for distributed entity keepalive")
 	cmd.PersistentFlags().Bool("service.auto_recover", false, "enable panic auto recover")
+	cmd.PersistentFlags().Bool("service.cpsync", false, "enable call path table sync through etcd")
 
 	// 各类服务默认启动的副本数。
 	cmd.PersistentFlags().StringToString("startup.services", func() map[string]string {
@@ -227,6 +230,16 @@ func (app *App) initFlags() {
 	// pprof 参数。
 	cmd.PersistentFlags().Bool("pprof.enable", false, "enable pprof")
 	cmd.PersistentFlags().String("pprof.address", "0.0.0.0:6060", "pprof listening address")
+
+	// 指标参数；默认独立于 pprof 监听，避免开放指标抓取时一并暴露 pprof，需要共用时将地址设为 pprof.address。
+	cmd.PersistentFlags().Bool("metrics.enable", false, "enable prometheus metrics exposition")
+	cmd.PersistentFlags().String("metrics.address", "0.0.0.0:6061", "metrics listening address, separate from pprof by default")
+	cmd.PersistentFlags().String("metrics.path", "/metrics", "metrics exposition path")
+
+	// 服务目录参数；目录列出全部可调用方法，默认独立监听，只应绑定到回环地址或管理网络。
+	cmd.PersistentFlags().Bool("catalog.enable", false, "enable service catalog exposition")
+	cmd.PersistentFlags().String("catalog.address", "0.0.0.0:6062", "catalog listening address, separate from pprof and metrics by default")
+	cmd.PersistentFlags().String("catalog.path", "/catalog", "catalog exposition path")
 }
 
 func (app *App) initConf() {
@@ -265,23 +278,51 @@ func (app *App) initConf() {
 	}
 }
 
-func (app *App) initPProf() {
-	if !app.Conf().GetBool("pprof.enable") {
-		return
+// initHTTP 按监听地址启动管理 HTTP 服务；pprof、指标与服务目录配置相同地址时共用同一个服务。
+// pprof 服务以 http.DefaultServeMux 处理其余全部路径，注册在默认多路复用器上的自定义处理器与启用 pprof 前一样可访问；
+// 指标与服务目录路径更具体，共用地址时优先匹配。
+func (app *App) initHTTP() {
+	muxes := map[string]*http.ServeMux{}
+
+	getMux := func(kind, addr string) *http.ServeMux {
+		if _, err := net.ResolveTCPAddr("tcp", addr); err != nil {
+			exception.Panicf("%w: invalid %s address %q, %s", ErrFramework, kind, addr, err)
+		}
+		mux, ok := muxes[addr]
+		if !ok {
+			mux = http.NewServeMux()
+			muxes[addr] = mux
+		}
+		return mux
 	}
 
-	addr := app.Conf().GetString("pprof.address")
+	if app.Conf().GetBool("pprof.enable") {
+		getMux("pprof", app.Conf().GetString("pprof.address")).Handle("/", http.DefaultServeMux)
+	}
 
-	_, err := net.ResolveTCPAddr("tcp", addr)
-	if err != nil {
-		exception.Panicf("%w: invalid pprof address %q, %s", ErrFramework, addr, err)
+	if app.Conf().GetBool("metrics.enable") {
+		path := app.Conf().GetString("metrics.path")
+		if path == "" || path[0] != '/' {
+			exception.Panicf("%w: invalid metrics path %q", ErrFramework, path)
+		}
+		getMux("metrics", app.Conf().GetString("metrics.address")).Handle(path, metrics.Default().Handler())
 	}
 
-	go func() {
-		if err := http.ListenAndServe(addr, nil); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			exception.Panicf("%w: interrupt listening %q, %s", ErrFramework, addr, err)
+	if app.Conf().GetBool("catalog.enable") {
+		path := app.Conf().GetString("catalog.path")
+		if path == "" || path[0] != '/' {
+			exception.Panicf("%w: invalid catalog path %q", ErrFramework, path)
 		}
-	}()
+		getMux("catalog", app.Conf().GetString("catalog.address")).Handle(path, catalogHandler())
+	}
+
+	for addr, mux := range muxes {
+		go func() {
+			if err := http.ListenAndServe(addr, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				exception.Panicf("%w: interrupt listening %q, %s", ErrFramework, addr, err)
+			}
+		}()
+	}
 }
 
 func (app *App) mainLoop() {
@@ -0,0 +1,108 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"git.golaxy.org/framework/addins/catalog"
+	"github.com/spf13/cobra"
+)
+
+// catalogHandler 以 JSON 导出进程内已登记的服务目录，支持按 service 与 target 查询参数过滤。
+func catalogHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service := r.URL.Query().Get("service")
+		target := r.URL.Query().Get("target")
+
+		scripts := slices.DeleteFunc(catalog.Lookup(service), func(script catalog.Script) bool {
+			return target != "" && script.Target != target
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(scripts)
+	})
+}
+
+// newCatalogCmd 创建 catalog 子命令，从运行中节点的管理 HTTP 服务拉取服务目录并格式化输出。
+func newCatalogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Print RPC-callable scripts and methods of a running node",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			endpoint, _ := cmd.Flags().GetString("url")
+			service, _ := cmd.Flags().GetString("service")
+			target, _ := cmd.Flags().GetString("target")
+
+			u, err := url.Parse(endpoint)
+			if err != nil {
+				return fmt.Errorf("invalid catalog url %q, %w", endpoint, err)
+			}
+			query := u.Query()
+			if service != "" {
+				query.Set("service", service)
+			}
+			if target != "" {
+				query.Set("target", target)
+			}
+			u.RawQuery = query.Encode()
+
+			client := &http.Client{Timeout: 10 * time.Second}
+
+			resp, err := client.Get(u.String())
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("fetch catalog failed, status:%q", resp.Status)
+			}
+
+			var out bytes.Buffer
+			if err := json.Indent(&out, data, "", "  "); err != nil {
+				return err
+			}
+			out.WriteByte('\n')
+
+			_, err = out.WriteTo(cmd.OutOrStdout())
+			return err
+		},
+	}
+
+	cmd.Flags().String("url", "http://localhost:6062/catalog", "catalog endpoint of the running node")
+	cmd.Flags().String("service", "", "only print scripts of the service")
+	cmd.Flags().String("target", "", "only print scripts of the target: [service|runtime|entity]")
+
+	return cmd
+}
@@ -22,19 +22,29 @@ package framework
 import (
 	"reflect"
 
+	"git.golaxy.org/framework/addins/catalog"
 	"git.golaxy.org/framework/addins/rpc/callpath"
 )
 
 func init() {
-	cacheCallPath("", reflect.TypeFor[*EntityBehavior]())
+	cacheCallPath("", 0, "", reflect.TypeFor[*EntityBehavior]())
+	catalog.IgnoreMethodsOf(
+		reflect.TypeFor[*ServiceBehavior](),
+		reflect.TypeFor[*RuntimeBehavior](),
+		reflect.TypeFor[*EntityBehavior](),
+		reflect.TypeFor[*ComponentBehavior](),
+	)
 }
 
-// cacheCallPath 为类型的全部导出方法预注册压缩调用路径。
-func cacheCallPath(script string, rt reflect.Type) {
+// cacheCallPath 为类型的全部导出方法预注册压缩调用路径；service 非空时同时将其登记到服务目录。
+func cacheCallPath(service string, kind callpath.TargetKind, script string, rt reflect.Type) {
 	if rt == nil {
 		return
 	}
 	for i := range rt.NumMethod() {
 		callpath.Cache(script, rt.Method(i).Name)
 	}
+	if service != "" {
+		catalog.Record(service, kind, script, rt)
+	}
 }
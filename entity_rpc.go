@@ -64,6 +64,16 @@ func (e *EntityBehavior) GlobalBroadcastOnewayRPC(excludeSelf bool, comp, method
 	return rpc.ProxyEntity(e, e.ID()).GlobalBroadcastOnewayRPC(excludeSelf, comp, method, args...)
 }
 
+// BroadcastRPC 向指定服务中承载当前实体的全部节点逐一发起 RPC，并汇总各节点结果；excludeSelf 为 true 时排除本节点。
+func (e *EntityBehavior) BroadcastRPC(excludeSelf bool, service, comp, method string, args ...any) async.Future {
+	return rpc.ProxyEntity(e, e.ID()).BroadcastRPC(excludeSelf, service, comp, method, args...)
+}
+
+// GlobalBroadcastRPC 向所有服务中承载当前实体的节点逐一发起 RPC，并汇总各节点结果；excludeSelf 为 true 时排除本节点。
+func (e *EntityBehavior) GlobalBroadcastRPC(excludeSelf bool, comp, method string, args ...any) async.Future {
+	return rpc.ProxyEntity(e, e.ID()).GlobalBroadcastRPC(excludeSelf, comp, method, args...)
+}
+
 // CliRPC 向当前实体 ID 对应的客户端单播地址发起 RPC。
 func (e *EntityBehavior) CliRPC(proc, method string, args ...any) async.Future {
 	return rpc.ProxyEntity(e, e.ID()).CliRPC(proc, method, args...)
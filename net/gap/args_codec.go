@@ -0,0 +1,218 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"runtime"
+	"sync/atomic"
+
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/utils/exception"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+var (
+	// ErrArgsCodecNotDeclared 表示指定的参数编解码器尚未注册。
+	ErrArgsCodecNotDeclared = fmt.Errorf("%w: args codec not declared", ErrGAP)
+)
+
+// ArgsCodec 标识 RPC 参数的序列化格式。
+type ArgsCodec uint8
+
+// 内置参数编解码器 ID。自定义编解码器必须从 ArgsCodec_Customize 起分配。
+const (
+	// ArgsCodec_Variant 是默认的 variant 二进制格式，参数直接编码在消息的 Args 字段中。
+	ArgsCodec_Variant ArgsCodec = iota
+	// ArgsCodec_JSON 将参数编码为 variant JSON 数组，格式见 variant.Variant.MarshalJSON。
+	ArgsCodec_JSON
+	// ArgsCodec_MsgPack 将参数编码为 MessagePack 数组。
+	ArgsCodec_MsgPack
+	// ArgsCodec_ProtoAny 将每个参数编码为 google.protobuf.Any，并依次以 varint 长度前缀拼接。
+	ArgsCodec_ProtoAny
+	// ArgsCodec_Customize 是自定义编解码器 ID 的起始偏移。
+	ArgsCodec_Customize ArgsCodec = 32
+)
+
+// IArgsCodec 在 variant 参数数组与其他序列化格式之间转换，供非 Go 消费方解析 RPC 参数。
+type IArgsCodec interface {
+	// ArgsCodec 返回编解码器 ID。
+	ArgsCodec() ArgsCodec
+	// Marshal 将参数数组编码为字节。
+	Marshal(args variant.Array) ([]byte, error)
+	// Unmarshal 从字节还原参数数组；结果可能引用 data。
+	Unmarshal(data []byte) (variant.Array, error)
+}
+
+var argsCodecs atomic.Pointer[map[ArgsCodec]IArgsCodec]
+
+func init() {
+	DeclareArgsCodec(_JSONArgsCodec{})
+	DeclareArgsCodec(_MsgPackArgsCodec{})
+	DeclareArgsCodec(_ProtoAnyArgsCodec{})
+}
+
+// DeclareArgsCodec 注册参数编解码器；ID 为 ArgsCodec_Variant 或已注册时 panic。
+func DeclareArgsCodec(codec IArgsCodec) {
+	if codec == nil {
+		exception.Panicf("%w: %w: codec is nil", ErrGAP, core.ErrArgs)
+	}
+
+	if codec.ArgsCodec() == ArgsCodec_Variant {
+		exception.Panicf("%w: %w: args codec(%d) is reserved for variant", ErrGAP, core.ErrArgs, codec.ArgsCodec())
+	}
+
+	for {
+		var m map[ArgsCodec]IArgsCodec
+
+		old := argsCodecs.Load()
+		if old != nil {
+			m = maps.Clone(*old)
+		}
+
+		if m == nil {
+			m = make(map[ArgsCodec]IArgsCodec)
+		}
+
+		if _, ok := m[codec.ArgsCodec()]; ok {
+			exception.Panicf("%w: args codec(%d) has already been declared", ErrGAP, codec.ArgsCodec())
+		}
+
+		m[codec.ArgsCodec()] = codec
+
+		if argsCodecs.CompareAndSwap(old, &m) {
+			break
+		}
+
+		runtime.Gosched()
+	}
+}
+
+// LookupArgsCodec 查询已注册的参数编解码器。
+func LookupArgsCodec(codec ArgsCodec) (IArgsCodec, error) {
+	m := argsCodecs.Load()
+	if m == nil {
+		return nil, ErrArgsCodecNotDeclared
+	}
+
+	c, ok := (*m)[codec]
+	if !ok {
+		return nil, fmt.Errorf("%w: args codec(%d)", ErrArgsCodecNotDeclared, codec)
+	}
+
+	return c, nil
+}
+
+// EncodeArgs 按 codec 编码参数：ArgsCodec_Variant 原样返回 args，其他编解码器返回空数组与编码后的字节，
+// 分别用于填充消息的 Args 与 ArgsData 字段。
+func EncodeArgs(codec ArgsCodec, args variant.Array) (variant.Array, []byte, error) {
+	if codec == ArgsCodec_Variant {
+		return args, nil, nil
+	}
+
+	c, err := LookupArgsCodec(codec)
+	if err != nil {
+		return variant.Array{}, nil, err
+	}
+
+	data, err := c.Marshal(args)
+	if err != nil {
+		return variant.Array{}, nil, fmt.Errorf("%w: marshal args with codec(%d) failed: %w", ErrGAP, codec, err)
+	}
+
+	return variant.Array{}, data, nil
+}
+
+// DecodeArgs 按 codec 还原 EncodeArgs 编码的参数；ArgsCodec_Variant 原样返回 args。
+func DecodeArgs(codec ArgsCodec, args variant.Array, data []byte) (variant.Array, error) {
+	if codec == ArgsCodec_Variant {
+		return args, nil
+	}
+
+	c, err := LookupArgsCodec(codec)
+	if err != nil {
+		return variant.Array{}, err
+	}
+
+	ret, err := c.Unmarshal(data)
+	if err != nil {
+		return variant.Array{}, fmt.Errorf("%w: unmarshal args with codec(%d) failed: %w", ErrGAP, codec, err)
+	}
+
+	return ret, nil
+}
+
+// _JSONArgsCodec 使用 variant JSON 数组编码参数，便于 HTTP 工具与其他语言直接读写。
+type _JSONArgsCodec struct{}
+
+func (_JSONArgsCodec) ArgsCodec() ArgsCodec {
+	return ArgsCodec_JSON
+}
+
+func (_JSONArgsCodec) Marshal(args variant.Array) ([]byte, error) {
+	return json.Marshal(args)
+}
+
+func (_JSONArgsCodec) Unmarshal(data []byte) (variant.Array, error) {
+	var args variant.Array
+	if err := json.Unmarshal(data, &args); err != nil {
+		return variant.Array{}, err
+	}
+	return args, nil
+}
+
+// readArgsCodec 读取消息末尾可选的参数编解码器 ID 与编码后的参数；字段不存在时返回 ArgsCodec_Variant。
+func readArgsCodec(bs *binaryutil.ByteStream) (ArgsCodec, []byte, error) {
+	if bs.BytesUnread() <= 0 {
+		return ArgsCodec_Variant, nil, nil
+	}
+
+	codec, err := bs.ReadUint8()
+	if err != nil {
+		return ArgsCodec_Variant, nil, err
+	}
+
+	data, err := bs.ReadBytesRef()
+	if err != nil {
+		return ArgsCodec_Variant, nil, err
+	}
+
+	return ArgsCodec(codec), data, nil
+}
+
+// arrayItems 返回数组项；快照形态先解码。
+func arrayItems(arr variant.Array) ([]variant.Variant, error) {
+	if !arr.IsSnapshot {
+		return arr.Items, nil
+	}
+
+	var decoded variant.Array
+	if _, err := decoded.Write(arr.SnapshotBytes.Payload()); err != nil {
+		return nil, err
+	}
+	return decoded.Items, nil
+}
+
+func newVariant(v variant.ReadableValue) variant.Variant {
+	return variant.Variant{TypeID: v.TypeID(), Value: v}
+}
@@ -0,0 +1,563 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/net/gap/variant"
+)
+
+// MessagePack 扩展类型。
+const (
+	msgpackExtTimestamp int8 = -1 // 规范定义的时间戳扩展。
+	msgpackExtVariant   int8 = 1  // 无对应 MessagePack 类型的动态值，载荷为其 variant 编码。
+)
+
+// msgpackMaxDepth 限制解码时数组与映射的嵌套深度。
+const msgpackMaxDepth = 64
+
+var (
+	errMsgPackMalformed = errors.New("malformed msgpack data")
+	errMsgPackTooDeep   = errors.New("msgpack nesting too deep")
+)
+
+// _MsgPackArgsCodec 将参数编码为 MessagePack 数组。整数按值选择最短编码，解码时统一还原为 Int64 或 Uint64；
+// uid.ID 编码为字符串，time.Duration 编码为纳秒整数；time.Time 使用时间戳扩展；数组与映射递归编码；
+// 动态结构体编码为以字段 ID 为键的映射，Proto 编码为含 name 与 data 的映射，解码时均还原为 Map；
+// 其余动态值以扩展类型 1 携带其 variant 编码。
+type _MsgPackArgsCodec struct{}
+
+func (_MsgPackArgsCodec) ArgsCodec() ArgsCodec {
+	return ArgsCodec_MsgPack
+}
+
+func (_MsgPackArgsCodec) Marshal(args variant.Array) ([]byte, error) {
+	items, err := arrayItems(args)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := appendMsgPackArrayHeader(nil, len(items))
+	for i := range items {
+		if buf, err = appendMsgPackVariant(buf, items[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+func (_MsgPackArgsCodec) Unmarshal(data []byte) (variant.Array, error) {
+	d := _MsgPackDecoder{data: data}
+
+	v, err := d.decode(0)
+	if err != nil {
+		return variant.Array{}, err
+	}
+	if d.pos != len(d.data) {
+		return variant.Array{}, fmt.Errorf("%w: %d trailing bytes", errMsgPackMalformed, len(d.data)-d.pos)
+	}
+
+	args, ok := v.Value.(variant.Array)
+	if !ok {
+		return variant.Array{}, fmt.Errorf("%w: args must be an array", errMsgPackMalformed)
+	}
+
+	return args, nil
+}
+
+func appendMsgPackVariant(buf []byte, v variant.Variant) ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("%w: invalid variant", variant.ErrVariant)
+	}
+
+	// Proto 的 Indirect 会反序列化消息，直接使用其原始字节
+	switch x := v.Value.(type) {
+	case variant.Proto:
+		return appendMsgPackProto(buf, x)
+	case *variant.Proto:
+		return appendMsgPackProto(buf, *x)
+	}
+
+	switch x := v.Value.Indirect().(type) {
+	case nil:
+		return append(buf, 0xc0), nil
+	case bool:
+		if x {
+			return append(buf, 0xc3), nil
+		}
+		return append(buf, 0xc2), nil
+	case int:
+		return appendMsgPackInt(buf, int64(x)), nil
+	case int8:
+		return appendMsgPackInt(buf, int64(x)), nil
+	case int16:
+		return appendMsgPackInt(buf, int64(x)), nil
+	case int32:
+		return appendMsgPackInt(buf, int64(x)), nil
+	case int64:
+		return appendMsgPackInt(buf, x), nil
+	case uint:
+		return appendMsgPackUint(buf, uint64(x)), nil
+	case uint8:
+		return appendMsgPackUint(buf, uint64(x)), nil
+	case uint16:
+		return appendMsgPackUint(buf, uint64(x)), nil
+	case uint32:
+		return appendMsgPackUint(buf, uint64(x)), nil
+	case uint64:
+		return appendMsgPackUint(buf, x), nil
+	case float32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xca), math.Float32bits(x)), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(x)), nil
+	case string:
+		return appendMsgPackString(buf, x)
+	case []byte:
+		return appendMsgPackBin(buf, x)
+	case uid.ID:
+		return appendMsgPackString(buf, string(x))
+	case time.Duration:
+		return appendMsgPackInt(buf, int64(x)), nil
+	case time.Time:
+		payload := binary.BigEndian.AppendUint32(nil, uint32(x.Nanosecond()))
+		payload = binary.BigEndian.AppendUint64(payload, uint64(x.Unix()))
+		return appendMsgPackExt(buf, msgpackExtTimestamp, payload)
+	case variant.Array:
+		items, err := arrayItems(x)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendMsgPackArrayHeader(buf, len(items))
+		for i := range items {
+			if buf, err = appendMsgPackVariant(buf, items[i]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case variant.Map:
+		var err error
+		buf = appendMsgPackMapHeader(buf, len(x.Entries))
+		for _, kv := range x.Entries {
+			if buf, err = appendMsgPackVariant(buf, kv.K); err != nil {
+				return nil, err
+			}
+			if buf, err = appendMsgPackVariant(buf, kv.V); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case variant.Struct:
+		var err error
+		buf = appendMsgPackMapHeader(buf, len(x.Fields))
+		for i := range x.Fields {
+			buf = appendMsgPackUint(buf, uint64(x.Fields[i].ID))
+			if buf, err = appendMsgPackVariant(buf, x.Fields[i].Value); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		payload := make([]byte, v.Size())
+		if _, err := v.Read(payload); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return appendMsgPackExt(buf, msgpackExtVariant, payload)
+	}
+}
+
+// appendMsgPackProto 将 Proto 编码为 {"name": 消息名称, "data": 序列化字节} 映射。
+func appendMsgPackProto(buf []byte, p variant.Proto) ([]byte, error) {
+	var err error
+	buf = appendMsgPackMapHeader(buf, 2)
+	if buf, err = appendMsgPackString(buf, "name"); err != nil {
+		return nil, err
+	}
+	if buf, err = appendMsgPackString(buf, p.Name); err != nil {
+		return nil, err
+	}
+	if buf, err = appendMsgPackString(buf, "data"); err != nil {
+		return nil, err
+	}
+	return appendMsgPackBin(buf, p.Data)
+}
+
+func appendMsgPackInt(buf []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendMsgPackUint(buf, uint64(v))
+	case v >= -32:
+		return append(buf, byte(int8(v)))
+	case v >= math.MinInt8:
+		return append(buf, 0xd0, byte(int8(v)))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(int16(v)))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(int32(v)))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(v))
+	}
+}
+
+func appendMsgPackUint(buf []byte, v uint64) []byte {
+	switch {
+	case v <= math.MaxInt8:
+		return append(buf, byte(v))
+	case v <= math.MaxUint8:
+		return append(buf, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcf), v)
+	}
+}
+
+func appendMsgPackString(buf []byte, s string) ([]byte, error) {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+	case uint64(n) <= math.MaxUint32:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n))
+	default:
+		return nil, fmt.Errorf("msgpack string too long: %d", n)
+	}
+	return append(buf, s...), nil
+}
+
+func appendMsgPackBin(buf []byte, b []byte) ([]byte, error) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xc5), uint16(n))
+	case uint64(n) <= math.MaxUint32:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xc6), uint32(n))
+	default:
+		return nil, fmt.Errorf("msgpack binary too long: %d", n)
+	}
+	return append(buf, b...), nil
+}
+
+func appendMsgPackArrayHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, 0xdd), uint32(n))
+	}
+}
+
+func appendMsgPackMapHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, 0xdf), uint32(n))
+	}
+}
+
+func appendMsgPackExt(buf []byte, typ int8, payload []byte) ([]byte, error) {
+	n := len(payload)
+	switch {
+	case n == 1:
+		buf = append(buf, 0xd4)
+	case n == 2:
+		buf = append(buf, 0xd5)
+	case n == 4:
+		buf = append(buf, 0xd6)
+	case n == 8:
+		buf = append(buf, 0xd7)
+	case n == 16:
+		buf = append(buf, 0xd8)
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xc7, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xc8), uint16(n))
+	case uint64(n) <= math.MaxUint32:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xc9), uint32(n))
+	default:
+		return nil, fmt.Errorf("msgpack ext too long: %d", n)
+	}
+	return append(append(buf, byte(typ)), payload...), nil
+}
+
+// _MsgPackDecoder 从字节切片顺序解码 MessagePack 值；字节串引用输入数据。
+type _MsgPackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *_MsgPackDecoder) decode(depth int) (variant.Variant, error) {
+	if depth > msgpackMaxDepth {
+		return variant.Variant{}, errMsgPackTooDeep
+	}
+
+	b, err := d.readByte()
+	if err != nil {
+		return variant.Variant{}, err
+	}
+
+	switch {
+	case b <= 0x7f:
+		return newVariant(variant.Int64(b)), nil
+	case b >= 0xe0:
+		return newVariant(variant.Int64(int8(b))), nil
+	case b&0xf0 == 0x80:
+		return d.decodeMap(int(b&0x0f), depth)
+	case b&0xf0 == 0x90:
+		return d.decodeArray(int(b&0x0f), depth)
+	case b&0xe0 == 0xa0:
+		return d.decodeString(int(b & 0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return newVariant(variant.Null{}), nil
+	case 0xc2:
+		return newVariant(variant.Bool(false)), nil
+	case 0xc3:
+		return newVariant(variant.Bool(true)), nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readLen(b - 0xc4)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		bs, err := d.read(n)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return newVariant(variant.Bytes(bs)), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readLen(b - 0xc7)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return d.decodeExt(n)
+	case 0xca:
+		bs, err := d.read(4)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return newVariant(variant.Float(math.Float32frombits(binary.BigEndian.Uint32(bs)))), nil
+	case 0xcb:
+		bs, err := d.read(8)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return newVariant(variant.Double(math.Float64frombits(binary.BigEndian.Uint64(bs)))), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		bs, err := d.read(1 << (b - 0xcc))
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return newVariant(variant.Uint64(readMsgPackUint(bs))), nil
+	case 0xd0:
+		bs, err := d.read(1)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return newVariant(variant.Int64(int8(bs[0]))), nil
+	case 0xd1:
+		bs, err := d.read(2)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return newVariant(variant.Int64(int16(binary.BigEndian.Uint16(bs)))), nil
+	case 0xd2:
+		bs, err := d.read(4)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return newVariant(variant.Int64(int32(binary.BigEndian.Uint32(bs)))), nil
+	case 0xd3:
+		bs, err := d.read(8)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return newVariant(variant.Int64(binary.BigEndian.Uint64(bs))), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (b - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readLen(b - 0xd9)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return d.decodeString(n)
+	case 0xdc, 0xdd:
+		n, err := d.readLen(b - 0xdc + 1)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return d.decodeArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.readLen(b - 0xde + 1)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		return d.decodeMap(n, depth)
+	default:
+		return variant.Variant{}, fmt.Errorf("%w: unknown format 0x%02x", errMsgPackMalformed, b)
+	}
+}
+
+func (d *_MsgPackDecoder) decodeString(n int) (variant.Variant, error) {
+	bs, err := d.read(n)
+	if err != nil {
+		return variant.Variant{}, err
+	}
+	return newVariant(variant.String(bs)), nil
+}
+
+func (d *_MsgPackDecoder) decodeArray(n, depth int) (variant.Variant, error) {
+	// 每个元素至少占用 1 字节，先校验长度以免按伪造的数量预分配
+	if n > len(d.data)-d.pos {
+		return variant.Variant{}, fmt.Errorf("%w: %w", errMsgPackMalformed, io.ErrUnexpectedEOF)
+	}
+
+	arr := variant.Array{Items: make([]variant.Variant, 0, n)}
+	for range n {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		arr.Items = append(arr.Items, item)
+	}
+	return newVariant(arr), nil
+}
+
+func (d *_MsgPackDecoder) decodeMap(n, depth int) (variant.Variant, error) {
+	if n > (len(d.data)-d.pos)/2 {
+		return variant.Variant{}, fmt.Errorf("%w: %w", errMsgPackMalformed, io.ErrUnexpectedEOF)
+	}
+
+	m := variant.Map{Entries: make(generic.UnorderedSliceMap[variant.Variant, variant.Variant], 0, n)}
+	for range n {
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return variant.Variant{}, err
+		}
+		m.Entries = append(m.Entries, generic.UnorderedKV[variant.Variant, variant.Variant]{K: k, V: v})
+	}
+	return newVariant(m), nil
+}
+
+func (d *_MsgPackDecoder) decodeExt(n int) (variant.Variant, error) {
+	typ, err := d.readByte()
+	if err != nil {
+		return variant.Variant{}, err
+	}
+
+	payload, err := d.read(n)
+	if err != nil {
+		return variant.Variant{}, err
+	}
+
+	switch int8(typ) {
+	case msgpackExtTimestamp:
+		var t time.Time
+		switch n {
+		case 4:
+			t = time.Unix(int64(binary.BigEndian.Uint32(payload)), 0)
+		case 8:
+			v := binary.BigEndian.Uint64(payload)
+			t = time.Unix(int64(v&0x3ffffffff), int64(v>>34))
+		case 12:
+			t = time.Unix(int64(binary.BigEndian.Uint64(payload[4:])), int64(binary.BigEndian.Uint32(payload)))
+		default:
+			return variant.Variant{}, fmt.Errorf("%w: invalid timestamp length %d", errMsgPackMalformed, n)
+		}
+		return newVariant(variant.Time(t)), nil
+	case msgpackExtVariant:
+		var v variant.Variant
+		if _, err := v.Write(payload); err != nil {
+			return variant.Variant{}, err
+		}
+		return v, nil
+	default:
+		return variant.Variant{}, fmt.Errorf("%w: unsupported ext type %d", errMsgPackMalformed, int8(typ))
+	}
+}
+
+// readLen 读取 1<<size 字节的大端长度。
+func (d *_MsgPackDecoder) readLen(size byte) (int, error) {
+	bs, err := d.read(1 << size)
+	if err != nil {
+		return 0, err
+	}
+	n := readMsgPackUint(bs)
+	if n > uint64(len(d.data)) {
+		return 0, fmt.Errorf("%w: %w", errMsgPackMalformed, io.ErrUnexpectedEOF)
+	}
+	return int(n), nil
+}
+
+func (d *_MsgPackDecoder) readByte() (byte, error) {
+	bs, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return bs[0], nil
+}
+
+func (d *_MsgPackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, fmt.Errorf("%w: %w", errMsgPackMalformed, io.ErrUnexpectedEOF)
+	}
+	bs := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return bs, nil
+}
+
+func readMsgPackUint(bs []byte) uint64 {
+	switch len(bs) {
+	case 1:
+		return uint64(bs[0])
+	case 2:
+		return uint64(binary.BigEndian.Uint16(bs))
+	case 4:
+		return uint64(binary.BigEndian.Uint32(bs))
+	default:
+		return binary.BigEndian.Uint64(bs)
+	}
+}
@@ -0,0 +1,294 @@
+package gap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/net/gap/variant"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestMsgPackArgsCodecRoundTrip(t *testing.T) {
+	now := time.Unix(1700000000, 123456789)
+	long := strings.Repeat("x", 40)
+
+	args := mustArray(t,
+		nil, true, false,
+		1, -1, -100, 300, -70000, int64(1)<<40, uint8(200), uint64(math.MaxUint64),
+		float32(1.5), 2.25,
+		"a", long, []byte{1, 2, 3},
+		now, 3*time.Second,
+		[]any{1, "b"},
+		map[string]int{"k": 7},
+	)
+
+	codec := _MsgPackArgsCodec{}
+
+	data, err := codec.Marshal(args)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	got, err := codec.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	// 整数统一还原为 Int64 或 Uint64。
+	want := []any{
+		nil, true, false,
+		int64(1), int64(-1), int64(-100), uint64(300), int64(-70000), uint64(1) << 40, uint64(200), uint64(math.MaxUint64),
+		float32(1.5), 2.25,
+		"a", long, []byte{1, 2, 3},
+	}
+
+	if len(got.Items) != len(args.Items) {
+		t.Fatalf("got %d args, want %d", len(got.Items), len(args.Items))
+	}
+	for i, w := range want {
+		if v := got.Items[i].Value.Indirect(); !reflect.DeepEqual(v, w) {
+			t.Fatalf("arg %d = %#v, want %#v", i, v, w)
+		}
+	}
+
+	if ts, ok := got.Items[16].Value.Indirect().(time.Time); !ok || !ts.Equal(now) {
+		t.Fatalf("time arg = %#v, want %v", got.Items[16].Value.Indirect(), now)
+	}
+	// time.Duration 默认以整数编码，仍可转换回 time.Duration。
+	if d, err := got.Items[17].ToNative(reflect.TypeFor[time.Duration]()); err != nil || d.Interface() != 3*time.Second {
+		t.Fatalf("duration arg = %#v, %v", got.Items[17].Value.Indirect(), err)
+	}
+
+	arr, ok := got.Items[18].Value.Indirect().(variant.Array)
+	if !ok || len(arr.Items) != 2 || arr.Items[0].Value.Indirect() != int64(1) || arr.Items[1].Value.Indirect() != "b" {
+		t.Fatalf("array arg = %#v", got.Items[18].Value.Indirect())
+	}
+
+	m, ok := got.Items[19].Value.Indirect().(variant.Map)
+	if !ok || len(m.Entries) != 1 || m.Entries[0].K.Value.Indirect() != "k" || m.Entries[0].V.Value.Indirect() != int64(7) {
+		t.Fatalf("map arg = %#v", got.Items[19].Value.Indirect())
+	}
+
+	again, err := codec.Marshal(got)
+	if err != nil {
+		t.Fatalf("re-Marshal failed: %v", err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Fatalf("re-encoded args differ:\n got  %x\n want %x", again, data)
+	}
+}
+
+func TestMsgPackArgsCodecEncoding(t *testing.T) {
+	data, err := _MsgPackArgsCodec{}.Marshal(mustArray(t, 1, "a", nil))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if want := []byte{0x93, 0x01, 0xa1, 'a', 0xc0}; !bytes.Equal(data, want) {
+		t.Fatalf("encoded %x, want %x", data, want)
+	}
+}
+
+func TestMsgPackArgsCodecMalformed(t *testing.T) {
+	deep := append(bytes.Repeat([]byte{0x91}, msgpackMaxDepth+2), 0xc0)
+
+	cases := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, errMsgPackMalformed},
+		{"not an array", []byte{0x01}, errMsgPackMalformed},
+		{"trailing bytes", []byte{0x90, 0x00}, errMsgPackMalformed},
+		{"unknown format", []byte{0x91, 0xc1}, errMsgPackMalformed},
+		{"forged array length", []byte{0xdd, 0xff, 0xff, 0xff, 0xff}, errMsgPackMalformed},
+		{"forged map length", []byte{0x91, 0xdf, 0xff, 0xff, 0xff, 0xff}, errMsgPackMalformed},
+		{"forged string length", []byte{0x91, 0xdb, 0x7f, 0xff, 0xff, 0xff}, errMsgPackMalformed},
+		{"invalid timestamp", []byte{0x91, 0xd5, 0xff, 0x00, 0x00}, errMsgPackMalformed},
+		{"unsupported ext", []byte{0x91, 0xd4, 0x05, 0x00}, errMsgPackMalformed},
+		{"too deep", deep, errMsgPackTooDeep},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := (_MsgPackArgsCodec{}).Unmarshal(c.data); !errors.Is(err, c.err) {
+				t.Fatalf("expected %v, got %v", c.err, err)
+			}
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		data, err := _MsgPackArgsCodec{}.Marshal(mustArray(t, 300, "abc", []byte{1}, time.Unix(1, 2), 3*time.Second))
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		for n := range len(data) {
+			if _, err := (_MsgPackArgsCodec{}).Unmarshal(data[:n]); err == nil {
+				t.Fatalf("expected error decoding %d of %d bytes", n, len(data))
+			}
+		}
+	})
+
+	t.Run("invalid variant ext", func(t *testing.T) {
+		if _, err := (_MsgPackArgsCodec{}).Unmarshal([]byte{0x91, 0xd4, 0x01, 0xff}); err == nil {
+			t.Fatalf("expected error decoding an invalid variant payload")
+		}
+	})
+}
+
+func TestMsgPackArgsCodecPlainTypes(t *testing.T) {
+	type plainStruct struct {
+		Name  string `gap:"name,id=1"`
+		Level int32  `gap:"level,id=2"`
+	}
+
+	msg := wrapperspb.String("hero")
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("proto.Marshal failed: %v", err)
+	}
+
+	args := mustArray(t,
+		uid.ID("u1"), variant.UID(uid.ID("u2")),
+		3*time.Second, variant.Duration(-time.Millisecond),
+		plainStruct{Name: "a", Level: 7},
+		msg,
+	)
+
+	encoded, err := _MsgPackArgsCodec{}.Marshal(args)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	// 使用与编解码器无关的最简 MessagePack 解码器校验，不应出现扩展类型。
+	d := plainMsgPackDecoder{data: encoded}
+	got := d.decode(t)
+	if d.pos != len(encoded) {
+		t.Fatalf("%d trailing bytes", len(encoded)-d.pos)
+	}
+
+	want := []any{
+		"u1", "u2",
+		uint64(3 * time.Second), int64(-time.Millisecond),
+		map[any]any{uint64(1): "a", uint64(2): uint64(7)},
+		map[any]any{"name": "google.protobuf.StringValue", "data": data},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("decoded %#v, want %#v", got, want)
+	}
+
+	// 经编解码器解码后仍可转换回原生类型。
+	back, err := _MsgPackArgsCodec{}.Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if id, err := back.Items[0].ToNative(reflect.TypeFor[uid.ID]()); err != nil || id.Interface() != uid.ID("u1") {
+		t.Fatalf("uid arg = %#v, %v", back.Items[0].Value.Indirect(), err)
+	}
+	if dur, err := back.Items[2].ToNative(reflect.TypeFor[time.Duration]()); err != nil || dur.Interface() != 3*time.Second {
+		t.Fatalf("duration arg = %#v, %v", back.Items[2].Value.Indirect(), err)
+	}
+	if _, ok := back.Items[4].Value.Indirect().(variant.Map); !ok {
+		t.Fatalf("struct arg = %#v, want map", back.Items[4].Value.Indirect())
+	}
+}
+
+// plainMsgPackDecoder 是仅供测试的最简 MessagePack 解码器，将数据还原为 Go 原生值，不支持扩展类型。
+type plainMsgPackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *plainMsgPackDecoder) next(t *testing.T, n int) []byte {
+	t.Helper()
+	if n > len(d.data)-d.pos {
+		t.Fatalf("unexpected end of msgpack data at %d", d.pos)
+	}
+	bs := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return bs
+}
+
+func (d *plainMsgPackDecoder) uint(t *testing.T, n int) uint64 {
+	bs := d.next(t, n)
+	var v uint64
+	for _, b := range bs {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+func (d *plainMsgPackDecoder) decode(t *testing.T) any {
+	t.Helper()
+	b := d.next(t, 1)[0]
+	switch {
+	case b <= 0x7f:
+		return uint64(b)
+	case b >= 0xe0:
+		return int64(int8(b))
+	case b&0xf0 == 0x80:
+		return d.decodeMap(t, int(b&0x0f))
+	case b&0xf0 == 0x90:
+		return d.decodeArray(t, int(b&0x0f))
+	case b&0xe0 == 0xa0:
+		return string(d.next(t, int(b&0x1f)))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil
+	case 0xc2:
+		return false
+	case 0xc3:
+		return true
+	case 0xc4, 0xc5, 0xc6:
+		return bytes.Clone(d.next(t, int(d.uint(t, 1<<(b-0xc4)))))
+	case 0xca:
+		return math.Float32frombits(binary.BigEndian.Uint32(d.next(t, 4)))
+	case 0xcb:
+		return math.Float64frombits(binary.BigEndian.Uint64(d.next(t, 8)))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(t, 1<<(b-0xcc))
+	case 0xd0:
+		return int64(int8(d.uint(t, 1)))
+	case 0xd1:
+		return int64(int16(d.uint(t, 2)))
+	case 0xd2:
+		return int64(int32(d.uint(t, 4)))
+	case 0xd3:
+		return int64(d.uint(t, 8))
+	case 0xd9, 0xda, 0xdb:
+		return string(d.next(t, int(d.uint(t, 1<<(b-0xd9)))))
+	case 0xdc, 0xdd:
+		return d.decodeArray(t, int(d.uint(t, 2<<(b-0xdc))))
+	case 0xde, 0xdf:
+		return d.decodeMap(t, int(d.uint(t, 2<<(b-0xde))))
+	default:
+		t.Fatalf("unsupported msgpack format 0x%02x at %d", b, d.pos-1)
+		return nil
+	}
+}
+
+func (d *plainMsgPackDecoder) decodeArray(t *testing.T, n int) []any {
+	arr := make([]any, 0, n)
+	for range n {
+		arr = append(arr, d.decode(t))
+	}
+	return arr
+}
+
+func (d *plainMsgPackDecoder) decodeMap(t *testing.T, n int) map[any]any {
+	m := make(map[any]any, n)
+	for range n {
+		k := d.decode(t)
+		m[k] = d.decode(t)
+	}
+	return m
+}
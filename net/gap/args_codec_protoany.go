@@ -0,0 +1,183 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"git.golaxy.org/framework/net/gap/variant"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// protoAnyTypeURLPrefix 是 google.protobuf.Any 类型 URL 的标准前缀。
+const protoAnyTypeURLPrefix = "type.googleapis.com/"
+
+// _ProtoAnyArgsCodec 将每个参数编码为 google.protobuf.Any，并依次以 varint 长度前缀拼接。
+// Proto 动态值直接作为 Any 的载荷；标量、字节串、time.Time 与 time.Duration 使用对应的 well-known 类型；
+// 不支持数组、映射等无对应 protobuf 类型的动态值。解码时 well-known 类型还原为内置动态值，其余还原为 Proto。
+type _ProtoAnyArgsCodec struct{}
+
+func (_ProtoAnyArgsCodec) ArgsCodec() ArgsCodec {
+	return ArgsCodec_ProtoAny
+}
+
+func (_ProtoAnyArgsCodec) Marshal(args variant.Array) ([]byte, error) {
+	items, err := arrayItems(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for i := range items {
+		a, err := variantToProtoAny(items[i])
+		if err != nil {
+			return nil, fmt.Errorf("arg %d: %w", i, err)
+		}
+		if _, err := protodelim.MarshalTo(&buf, a); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (_ProtoAnyArgsCodec) Unmarshal(data []byte) (variant.Array, error) {
+	r := bytes.NewReader(data)
+
+	var args variant.Array
+	for {
+		a := &anypb.Any{}
+		if err := protodelim.UnmarshalFrom(r, a); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return variant.Array{}, err
+		}
+		args.Items = append(args.Items, protoAnyToVariant(a))
+	}
+
+	return args, nil
+}
+
+func variantToProtoAny(v variant.Variant) (*anypb.Any, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("%w: invalid variant", variant.ErrVariant)
+	}
+
+	switch x := v.Value.(type) {
+	case variant.Proto:
+		return &anypb.Any{TypeUrl: protoAnyTypeURLPrefix + x.Name, Value: x.Data}, nil
+	case *variant.Proto:
+		return &anypb.Any{TypeUrl: protoAnyTypeURLPrefix + x.Name, Value: x.Data}, nil
+	}
+
+	var m proto.Message
+
+	switch x := v.Value.Indirect().(type) {
+	case nil:
+		m = &emptypb.Empty{}
+	case bool:
+		m = wrapperspb.Bool(x)
+	case int8:
+		m = wrapperspb.Int32(int32(x))
+	case int16:
+		m = wrapperspb.Int32(int32(x))
+	case int32:
+		m = wrapperspb.Int32(x)
+	case int:
+		m = wrapperspb.Int64(int64(x))
+	case int64:
+		m = wrapperspb.Int64(x)
+	case uint8:
+		m = wrapperspb.UInt32(uint32(x))
+	case uint16:
+		m = wrapperspb.UInt32(uint32(x))
+	case uint32:
+		m = wrapperspb.UInt32(x)
+	case uint:
+		m = wrapperspb.UInt64(uint64(x))
+	case uint64:
+		m = wrapperspb.UInt64(x)
+	case float32:
+		m = wrapperspb.Float(x)
+	case float64:
+		m = wrapperspb.Double(x)
+	case string:
+		m = wrapperspb.String(x)
+	case []byte:
+		m = wrapperspb.Bytes(x)
+	case time.Time:
+		m = timestamppb.New(x)
+	case time.Duration:
+		m = durationpb.New(x)
+	default:
+		return nil, fmt.Errorf("%w: variant type %d not supported by protobuf-any codec", variant.ErrVariant, v.TypeID)
+	}
+
+	return anypb.New(m)
+}
+
+func protoAnyToVariant(a *anypb.Any) variant.Variant {
+	name := string(a.MessageName())
+
+	if strings.HasPrefix(name, "google.protobuf.") {
+		if m, err := a.UnmarshalNew(); err == nil {
+			switch x := m.(type) {
+			case *emptypb.Empty:
+				return newVariant(variant.Null{})
+			case *wrapperspb.BoolValue:
+				return newVariant(variant.Bool(x.Value))
+			case *wrapperspb.Int32Value:
+				return newVariant(variant.Int32(x.Value))
+			case *wrapperspb.Int64Value:
+				return newVariant(variant.Int64(x.Value))
+			case *wrapperspb.UInt32Value:
+				return newVariant(variant.Uint32(x.Value))
+			case *wrapperspb.UInt64Value:
+				return newVariant(variant.Uint64(x.Value))
+			case *wrapperspb.FloatValue:
+				return newVariant(variant.Float(x.Value))
+			case *wrapperspb.DoubleValue:
+				return newVariant(variant.Double(x.Value))
+			case *wrapperspb.StringValue:
+				return newVariant(variant.String(x.Value))
+			case *wrapperspb.BytesValue:
+				return newVariant(variant.Bytes(x.Value))
+			case *timestamppb.Timestamp:
+				return newVariant(variant.Time(x.AsTime().Local()))
+			case *durationpb.Duration:
+				return newVariant(variant.Duration(x.AsDuration()))
+			}
+		}
+	}
+
+	return newVariant(variant.Proto{Name: name, Data: a.Value})
+}
@@ -0,0 +1,139 @@
+package gap
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.golaxy.org/framework/net/gap/variant"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestProtoAnyArgsCodecRoundTrip(t *testing.T) {
+	now := time.Unix(1700000000, 123456789)
+
+	msg, err := structpb.NewStruct(map[string]any{"k": "v"})
+	if err != nil {
+		t.Fatalf("NewStruct failed: %v", err)
+	}
+
+	args := mustArray(t,
+		nil, true,
+		int8(-1), int16(-2), int32(-3), 4, int64(5),
+		uint8(6), uint16(7), uint32(8), uint(9), uint64(10),
+		float32(1.5), 2.25,
+		"a", []byte{1, 2},
+		now, 3*time.Second,
+		msg,
+	)
+
+	codec := _ProtoAnyArgsCodec{}
+
+	data, err := codec.Marshal(args)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	got, err := codec.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	// 整数按 wrapper 类型宽度还原。
+	want := []any{
+		nil, true,
+		int32(-1), int32(-2), int32(-3), int64(4), int64(5),
+		uint32(6), uint32(7), uint32(8), uint64(9), uint64(10),
+		float32(1.5), 2.25,
+		"a", []byte{1, 2},
+	}
+
+	if len(got.Items) != len(args.Items) {
+		t.Fatalf("got %d args, want %d", len(got.Items), len(args.Items))
+	}
+	for i, w := range want {
+		if v := got.Items[i].Value.Indirect(); !reflect.DeepEqual(v, w) {
+			t.Fatalf("arg %d = %#v, want %#v", i, v, w)
+		}
+	}
+
+	if ts, ok := got.Items[16].Value.Indirect().(time.Time); !ok || !ts.Equal(now) {
+		t.Fatalf("time arg = %#v, want %v", got.Items[16].Value.Indirect(), now)
+	}
+	// time.Duration 默认以整数编码，仍可转换回 time.Duration。
+	if d, err := got.Items[17].ToNative(reflect.TypeFor[time.Duration]()); err != nil || d.Interface() != 3*time.Second {
+		t.Fatalf("duration arg = %#v, %v", got.Items[17].Value.Indirect(), err)
+	}
+
+	p, ok := got.Items[18].Value.(variant.Proto)
+	if !ok || p.Name != string(msg.ProtoReflect().Descriptor().FullName()) {
+		t.Fatalf("proto arg = %#v", got.Items[18].Value)
+	}
+	decoded := &structpb.Struct{}
+	if err := proto.Unmarshal(p.Data, decoded); err != nil || !proto.Equal(decoded, msg) {
+		t.Fatalf("proto arg payload = %v, %v", decoded, err)
+	}
+
+	again, err := codec.Marshal(got)
+	if err != nil {
+		t.Fatalf("re-Marshal failed: %v", err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Fatalf("re-encoded args differ:\n got  %x\n want %x", again, data)
+	}
+}
+
+func TestProtoAnyArgsCodecEmpty(t *testing.T) {
+	data, err := _ProtoAnyArgsCodec{}.Marshal(variant.Array{})
+	if err != nil || len(data) != 0 {
+		t.Fatalf("Marshal empty args = %x, %v", data, err)
+	}
+
+	args, err := _ProtoAnyArgsCodec{}.Unmarshal(nil)
+	if err != nil || len(args.Items) != 0 {
+		t.Fatalf("Unmarshal empty data = %+v, %v", args, err)
+	}
+}
+
+func TestProtoAnyArgsCodecUnsupported(t *testing.T) {
+	for _, arg := range []any{[]any{1}, map[string]int{"k": 1}} {
+		if _, err := (_ProtoAnyArgsCodec{}).Marshal(mustArray(t, arg)); err == nil {
+			t.Fatalf("expected error marshaling %#v", arg)
+		}
+	}
+}
+
+func TestProtoAnyArgsCodecMalformed(t *testing.T) {
+	valid, err := _ProtoAnyArgsCodec{}.Marshal(mustArray(t, "abc", 1))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	cases := map[string][]byte{
+		"truncated length":  {0x80},
+		"truncated message": valid[:len(valid)-1],
+		"forged length":     protowire.AppendVarint(nil, 1<<40),
+		"invalid message":   append(protowire.AppendVarint(nil, 2), 0xff, 0xff),
+	}
+
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := (_ProtoAnyArgsCodec{}).Unmarshal(data); err == nil {
+				t.Fatalf("expected error decoding %x", data)
+			}
+		})
+	}
+
+	t.Run("unknown well-known payload", func(t *testing.T) {
+		// 类型名属于 google.protobuf 但载荷无法解析时按 Proto 原样保留。
+		a := &anypb.Any{TypeUrl: protoAnyTypeURLPrefix + "google.protobuf.Int32Value", Value: []byte{0xff}}
+		v := protoAnyToVariant(a)
+		if p, ok := v.Value.(variant.Proto); !ok || p.Name != "google.protobuf.Int32Value" {
+			t.Fatalf("unexpected variant %#v", v.Value)
+		}
+	})
+}
@@ -37,6 +37,7 @@ var (
 var decoder = &Decoder{MsgCreator: gap.DefaultMsgCreator()}
 
 // NewDecoder 创建使用指定消息构建器的解码器；构建器不得为 nil。
+// 使用默认构建器时返回共享解码器，调用方不得修改其字段。
 func NewDecoder(msgCreator gap.IMsgCreator) *Decoder {
 	if msgCreator == nil {
 		exception.Panicf("%w: %w: msgCreator is nil", ErrDecode, core.ErrArgs)
@@ -51,11 +52,22 @@ func NewDecoder(msgCreator gap.IMsgCreator) *Decoder {
 
 // Decoder 根据消息头中的类型 ID 构造并解码消息体。
 type Decoder struct {
-	MsgCreator gap.IMsgCreator // 用于构造消息体的消息构建器。
+	MsgCreator          gap.IMsgCreator // 用于构造消息体的消息构建器。
+	Compression         ICompression    // 可选的解压模块；为 nil 时拒绝压缩的消息包。
+	MaxUncompressedSize int             // 解压后消息体的字节上限，用于防御压缩炸弹。
+	Encryption          IEncryption     // 可选的解密模块；为 nil 时拒绝加密的消息包。
+	Authentication      IAuthentication // 可选的验证模块；配置后默认拒绝未签名的消息包。
+	AcceptUnsigned      bool            // 配置验证模块时仍接受未签名的消息包，用于逐步启用签名。
 }
 
-// Decode 从 data 解码一个消息包；消息字段可能直接引用 data，调用方不得提前复用它。
+// Decode 等同于目的地址为空的 DecodeFor，用于不按地址投递的链路，例如网关会话。
 func (d *Decoder) Decode(data []byte) (gap.MsgPacket, error) {
+	return d.DecodeFor("", data)
+}
+
+// DecodeFor 从 data 解码一个发往 dst 的消息包；dst 与编码时不同的已签名或已加密消息包无法通过验证。
+// 消息字段可能直接引用 data，调用方不得提前复用它。
+func (d *Decoder) DecodeFor(dst string, data []byte) (gap.MsgPacket, error) {
 	if d.MsgCreator == nil {
 		return gap.MsgPacket{}, fmt.Errorf("%w: MsgCreator is nil", ErrDecode)
 	}
@@ -72,14 +84,54 @@ func (d *Decoder) Decode(data []byte) (gap.MsgPacket, error) {
 		return gap.MsgPacket{}, fmt.Errorf("%w: %w (%d < %d)", ErrDecode, io.ErrShortBuffer, len(data), mp.Head.Len)
 	}
 
+	if int(mp.Head.Len) < n {
+		return gap.MsgPacket{}, fmt.Errorf("%w: msg-packet-len less than head size (%d < %d)", ErrDecode, mp.Head.Len, n)
+	}
+
 	// 按消息类型构造具体消息；未知类型由 MsgCreator 返回错误。
 	msg, err := d.MsgCreator.New(mp.Head.MsgID)
 	if err != nil {
 		return gap.MsgPacket{}, fmt.Errorf("%w: new msg failed, %w (%d)", ErrDecode, err, mp.Head.MsgID)
 	}
 
-	// 消息的 Write 直接接收输入子切片，引用型字段可能与 data 共享底层存储。
-	if _, err = msg.Write(data[n:]); err != nil {
+	// 消息的 Write 直接接收按包长截取的输入子切片，引用型字段可能与 data 共享底层存储；
+	// 截取包长使消息能以剩余字节判断是否携带可选的尾部字段。
+	body := data[n:mp.Head.Len]
+
+	// 按签名、加密和压缩的逆序还原消息体；解密和解压结果为独立分配的缓冲区，可被消息字段引用。
+	if mp.Head.Flags.Is(gap.Flag_Signed) {
+		if d.Authentication == nil {
+			return gap.MsgPacket{}, fmt.Errorf("%w: signed msg-packet not supported", ErrDecode)
+		}
+		body, err = d.Authentication.Auth(mp.Head.Src, authHead(dst, mp.Head), body)
+		if err != nil {
+			return gap.MsgPacket{}, fmt.Errorf("%w: authenticate msg failed, %w", ErrDecode, err)
+		}
+	} else if d.Authentication != nil && !d.AcceptUnsigned {
+		return gap.MsgPacket{}, fmt.Errorf("%w: %w", ErrDecode, ErrUnsigned)
+	}
+
+	if mp.Head.Flags.Is(gap.Flag_Encrypted) {
+		if d.Encryption == nil {
+			return gap.MsgPacket{}, fmt.Errorf("%w: encrypted msg-packet not supported", ErrDecode)
+		}
+		body, err = d.Encryption.Open(authHead(dst, mp.Head), body)
+		if err != nil {
+			return gap.MsgPacket{}, fmt.Errorf("%w: decrypt msg failed, %w", ErrDecode, err)
+		}
+	}
+
+	if mp.Head.Flags.Is(gap.Flag_Compressed) {
+		if d.Compression == nil {
+			return gap.MsgPacket{}, fmt.Errorf("%w: compressed msg-packet not supported", ErrDecode)
+		}
+		body, err = d.Compression.Uncompress(body, d.MaxUncompressedSize)
+		if err != nil {
+			return gap.MsgPacket{}, fmt.Errorf("%w: uncompress msg failed, %w", ErrDecode, err)
+		}
+	}
+
+	if _, err = msg.Write(body); err != nil {
 		return gap.MsgPacket{}, fmt.Errorf("%w: read msg failed, %w", ErrDecode, err)
 	}
 
@@ -35,16 +35,29 @@ var (
 
 var encoder = &Encoder{}
 
-// NewEncoder 返回无状态的共享消息包编码器。
+// NewEncoder 返回不启用压缩、加密和签名的共享消息包编码器；调用方不得修改其字段。
 func NewEncoder() *Encoder {
 	return encoder
 }
 
 // Encoder 将 GAP 消息和来源信息编码为完整消息包。
-type Encoder struct{}
+type Encoder struct {
+	Compression          ICompression    // 可选的压缩模块。
+	CompressionThreshold int             // 启用压缩的消息体字节阈值；小于等于零时禁用压缩。
+	Encryption           IEncryption     // 可选的加密模块。
+	Authentication       IAuthentication // 可选的签名模块。
+}
+
+// Encode 等同于目的地址为空的 EncodeTo，用于不按地址投递的链路，例如网关会话。
+func (e *Encoder) Encode(src gap.Origin, seq int64, msg gap.ReadableMsg) (binaryutil.Bytes, error) {
+	return e.EncodeTo("", src, seq, msg)
+}
 
-// Encode 编码消息包并返回池化字节缓冲区；调用方使用完后必须调用 Release。
-func (*Encoder) Encode(src gap.Origin, seq int64, msg gap.ReadableMsg) (ret binaryutil.Bytes, err error) {
+// EncodeTo 编码发往 dst 的消息包并返回池化字节缓冲区；调用方使用完后必须调用 Release。
+// 消息体依次经过压缩、加密和签名：配置压缩模块且消息体达到阈值时，压缩有收益的消息体替换为 MsgCompressed；
+// 配置加密模块时替换为 MsgEncrypted；配置签名模块时替换为 MsgSigned。消息头设置相应标志位。
+// dst 不写入消息包，但参与加密附加数据和签名，接收方须以相同的 dst 调用 Decoder.DecodeFor。
+func (e *Encoder) EncodeTo(dst string, src gap.Origin, seq int64, msg gap.ReadableMsg) (ret binaryutil.Bytes, err error) {
 	if msg == nil {
 		return binaryutil.EmptyBytes, fmt.Errorf("%w: %w: msg is nil", ErrEncode, core.ErrArgs)
 	}
@@ -70,5 +83,65 @@ func (*Encoder) Encode(src gap.Origin, seq int64, msg gap.ReadableMsg) (ret bina
 		return binaryutil.EmptyBytes, fmt.Errorf("%w: write msg failed, %w", ErrEncode, err)
 	}
 
-	return mpBuf, nil
+	headSize := mp.Head.Size()
+	body := mpBuf.Payload()[headSize:]
+
+	// 各阶段产生的中间缓冲区在写出最终消息包后统一释放。
+	var stageBufs []binaryutil.Bytes
+	defer func() {
+		for _, buf := range stageBufs {
+			buf.Release()
+		}
+	}()
+
+	if e.Compression != nil && e.CompressionThreshold > 0 && msg.Size() >= e.CompressionThreshold {
+		compressedBuf, compressed, err := e.Compression.Compress(body)
+		if err != nil {
+			return binaryutil.EmptyBytes, fmt.Errorf("%w: compress msg failed, %w", ErrEncode, err)
+		}
+		if compressed {
+			stageBufs = append(stageBufs, compressedBuf)
+			body = compressedBuf.Payload()
+			mp.Head.Flags.Set(gap.Flag_Compressed, true)
+		}
+	}
+
+	if e.Encryption != nil {
+		mp.Head.Flags.Set(gap.Flag_Encrypted, true)
+
+		encryptedBuf, err := e.Encryption.Seal(authHead(dst, mp.Head), body)
+		if err != nil {
+			return binaryutil.EmptyBytes, fmt.Errorf("%w: encrypt msg failed, %w", ErrEncode, err)
+		}
+		stageBufs = append(stageBufs, encryptedBuf)
+		body = encryptedBuf.Payload()
+	}
+
+	if e.Authentication != nil {
+		mp.Head.Flags.Set(gap.Flag_Signed, true)
+
+		signedBuf, err := e.Authentication.Sign(authHead(dst, mp.Head), body)
+		if err != nil {
+			return binaryutil.EmptyBytes, fmt.Errorf("%w: sign msg failed, %w", ErrEncode, err)
+		}
+		stageBufs = append(stageBufs, signedBuf)
+		body = signedBuf.Payload()
+	}
+
+	if len(stageBufs) <= 0 {
+		return mpBuf, nil
+	}
+
+	// 消息体已被替换，按新的标志位和包长重写消息包。
+	mp.Head.Len = uint32(headSize + len(body))
+
+	outBuf := binaryutil.NewBytes(true, int(mp.Head.Len))
+
+	if _, err := binaryutil.CopyToBuff(outBuf.Payload(), mp.Head); err != nil {
+		outBuf.Release()
+		return binaryutil.EmptyBytes, fmt.Errorf("%w: write msg-packet-head failed, %w", ErrEncode, err)
+	}
+	copy(outBuf.Payload()[headSize:], body)
+
+	return outBuf, nil
 }
@@ -20,5 +20,7 @@
 // Package codec 提供 GAP 消息包的编解码能力。
 //
 // 这个包位于 GAP 协议的线格式层，负责把 gap.MsgPacket 编码为字节流，
-// 并在接收侧把字节流还原为对应的消息包对象。
+// 并在接收侧把字节流还原为对应的消息包对象。编码器与解码器可选配压缩模块，
+// 复用 GTP 的压缩流，对达到阈值的消息体压缩并在消息头设置 Flag_Compressed；
+// 也可选配 AES-GCM 加密和 HMAC 或 Ed25519 签名模块，密钥环支持运行时轮换。
 package codec
@@ -0,0 +1,130 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/utils/exception"
+)
+
+var (
+	// ErrKeyring 是密钥环操作错误的根错误。
+	ErrKeyring = errors.New("gap-keyring")
+)
+
+// NewKeyring 创建以 activeID 为当前密钥的密钥环；keys 会被复制，且必须包含 activeID。
+func NewKeyring(activeID string, keys map[string][]byte) *Keyring {
+	if _, ok := keys[activeID]; !ok || activeID == "" {
+		exception.Panicf("%w: %w: active key %q not found", ErrKeyring, core.ErrArgs, activeID)
+	}
+
+	k := &Keyring{
+		activeID: activeID,
+		keys:     make(map[string][]byte, len(keys)),
+	}
+	for id, key := range keys {
+		if id == "" || len(key) <= 0 {
+			exception.Panicf("%w: %w: key id or key is empty", ErrKeyring, core.ErrArgs)
+		}
+		k.keys[id] = slices.Clone(key)
+	}
+
+	return k
+}
+
+// Keyring 保存按 ID 索引的对称密钥及当前用于签名或加密的密钥，支持运行时轮换；并发安全。
+//
+// 轮换时先在所有节点 Add 新密钥，待全部节点可以验证后再 Activate，最后 Remove 旧密钥。
+type Keyring struct {
+	mutex    sync.RWMutex
+	activeID string
+	keys     map[string][]byte
+}
+
+// Active 返回当前密钥 ID 和密钥；调用方不得修改返回的密钥。
+func (k *Keyring) Active() (string, []byte) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	return k.activeID, k.keys[k.activeID]
+}
+
+// Get 返回指定 ID 的密钥；调用方不得修改返回的密钥。
+func (k *Keyring) Get(id string) ([]byte, bool) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	key, ok := k.keys[id]
+	return key, ok
+}
+
+// IDs 返回全部密钥 ID 的有序列表。
+func (k *Keyring) IDs() []string {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	return slices.Sorted(maps.Keys(k.keys))
+}
+
+// Add 添加仅用于验证或解密的新密钥；密钥会被复制，ID 已存在时返回错误。
+func (k *Keyring) Add(id string, key []byte) error {
+	if id == "" || len(key) <= 0 {
+		return fmt.Errorf("%w: %w: key id or key is empty", ErrKeyring, core.ErrArgs)
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("%w: key %q already exists", ErrKeyring, id)
+	}
+	k.keys[id] = slices.Clone(key)
+
+	return nil
+}
+
+// Activate 将已存在的密钥设为当前密钥。
+func (k *Keyring) Activate(id string) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: key %q not found", ErrKeyring, id)
+	}
+	k.activeID = id
+
+	return nil
+}
+
+// Remove 移除不再使用的密钥；当前密钥不可移除。
+func (k *Keyring) Remove(id string) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if id == k.activeID {
+		return fmt.Errorf("%w: can't remove active key %q", ErrKeyring, id)
+	}
+	delete(k.keys, id)
+
+	return nil
+}
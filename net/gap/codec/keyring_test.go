@@ -0,0 +1,109 @@
+package codec
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNewKeyringPanics(t *testing.T) {
+	cases := []struct {
+		name     string
+		activeID string
+		keys     map[string][]byte
+	}{
+		{"active missing", "k2", map[string][]byte{"k1": []byte("key")}},
+		{"empty active id", "", map[string][]byte{"": []byte("key")}},
+		{"empty key", "k1", map[string][]byte{"k1": []byte("key"), "k2": nil}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			NewKeyring(c.activeID, c.keys)
+		})
+	}
+}
+
+func TestKeyringCopiesKeys(t *testing.T) {
+	key := []byte("key-1")
+	k := NewKeyring("k1", map[string][]byte{"k1": key})
+
+	key[0] = 'x'
+	if _, got := k.Active(); string(got) != "key-1" {
+		t.Fatalf("keyring shares the caller's key: %q", got)
+	}
+
+	added := []byte("key-2")
+	if err := k.Add("k2", added); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	added[0] = 'x'
+	if got, _ := k.Get("k2"); string(got) != "key-2" {
+		t.Fatalf("Add shares the caller's key: %q", got)
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	k := NewKeyring("k1", map[string][]byte{"k1": []byte("key-1")})
+
+	if err := k.Add("k2", []byte("key-2")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if id, _ := k.Active(); id != "k1" {
+		t.Fatalf("Add changed the active key to %q", id)
+	}
+	if ids := k.IDs(); !slices.Equal(ids, []string{"k1", "k2"}) {
+		t.Fatalf("IDs = %v", ids)
+	}
+
+	if err := k.Activate("k2"); err != nil {
+		t.Fatalf("Activate failed: %v", err)
+	}
+	if id, key := k.Active(); id != "k2" || string(key) != "key-2" {
+		t.Fatalf("Active = %q, %q", id, key)
+	}
+
+	if err := k.Remove("k2"); !errors.Is(err, ErrKeyring) {
+		t.Fatalf("expected removing the active key to fail, got %v", err)
+	}
+	if err := k.Remove("k1"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, ok := k.Get("k1"); ok {
+		t.Fatal("removed key still present")
+	}
+	if ids := k.IDs(); !slices.Equal(ids, []string{"k2"}) {
+		t.Fatalf("IDs = %v", ids)
+	}
+}
+
+func TestKeyringErrors(t *testing.T) {
+	k := NewKeyring("k1", map[string][]byte{"k1": []byte("key-1")})
+
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{"add duplicate", k.Add("k1", []byte("other"))},
+		{"add empty id", k.Add("", []byte("key"))},
+		{"add empty key", k.Add("k2", nil)},
+		{"activate unknown", k.Activate("k3")},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if !errors.Is(c.err, ErrKeyring) {
+				t.Fatalf("expected ErrKeyring, got %v", c.err)
+			}
+		})
+	}
+
+	if id, key := k.Active(); id != "k1" || string(key) != "key-1" {
+		t.Fatalf("failed operations changed the keyring: %q, %q", id, key)
+	}
+}
@@ -0,0 +1,219 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package codec
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/utils/exception"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gtp"
+	"git.golaxy.org/framework/net/gtp/method"
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+var (
+	// ErrAuthenticate 是 GAP 消息签名和验证错误的根错误。
+	ErrAuthenticate = errors.New("gap-authenticate")
+	// ErrInvalidSignature 表示签名校验失败。
+	ErrInvalidSignature = fmt.Errorf("%w: invalid signature", ErrAuthenticate)
+	// ErrUnknownKey 表示找不到签名所用密钥 ID 对应的验证密钥。
+	ErrUnknownKey = fmt.Errorf("%w: unknown key", ErrAuthenticate)
+	// ErrUnsigned 表示要求签名时收到了未签名的消息包。
+	ErrUnsigned = fmt.Errorf("%w: unsigned msg-packet", ErrAuthenticate)
+)
+
+// IAuthentication 为消息头认证数据和消息体生成并验证签名；认证数据包含目的地址，实现必须支持并发调用。
+type IAuthentication interface {
+	// Sign 包装消息体和签名；返回的池化缓冲区由调用方释放。
+	Sign(head, msgBuf []byte) (signedBuf binaryutil.Bytes, err error)
+	// Auth 以 src 对应的密钥验证签名，并返回包装中的原始消息体。
+	Auth(src gap.Origin, head, msgBuf []byte) (authBuf []byte, err error)
+}
+
+// NewHMACAuthentication 创建使用集群共享密钥环的 HMAC 签名模块；持有任一密钥的节点均可通过验证，
+// 因此只能证明发送方属于集群，不能区分具体节点。不支持的摘要算法或 nil 密钥环会 panic。
+func NewHMACAuthentication(h gtp.Hash, keyring *Keyring) IAuthentication {
+	if keyring == nil {
+		exception.Panicf("%w: %w: keyring is nil", ErrAuthenticate, core.ErrArgs)
+	}
+	if _, err := method.NewHMAC(h, []byte{0}); err != nil {
+		exception.Panicf("%w: %w: %w", ErrAuthenticate, core.ErrArgs, err)
+	}
+	return &HMACAuthentication{
+		Hash:    h,
+		Keyring: keyring,
+	}
+}
+
+// HMACAuthentication 使用密钥环中的当前密钥签名，并按签名携带的密钥 ID 验证。
+type HMACAuthentication struct {
+	Hash    gtp.Hash // HMAC 摘要算法。
+	Keyring *Keyring // 签名与验证使用的密钥环。
+}
+
+// Sign 以当前密钥计算 HMAC 并返回池化的 MsgSigned 编码。
+func (a *HMACAuthentication) Sign(head, msgBuf []byte) (binaryutil.Bytes, error) {
+	keyID, key := a.Keyring.Active()
+
+	mac, err := a.sum(key, head, msgBuf)
+	if err != nil {
+		return binaryutil.EmptyBytes, err
+	}
+
+	return encodeSigned(gap.MsgSigned{KeyID: keyID, Data: msgBuf, Sig: mac})
+}
+
+// Auth 按 MsgSigned 记录的密钥 ID 验证 HMAC，并返回引用 msgBuf 的原始消息体。
+func (a *HMACAuthentication) Auth(_ gap.Origin, head, msgBuf []byte) ([]byte, error) {
+	msgSigned := gap.MsgSigned{}
+
+	if _, err := msgSigned.Write(msgBuf); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthenticate, err)
+	}
+
+	key, ok := a.Keyring.Get(msgSigned.KeyID)
+	if !ok {
+		return nil, fmt.Errorf("%w (%q)", ErrUnknownKey, msgSigned.KeyID)
+	}
+
+	mac, err := a.sum(key, head, msgSigned.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hmac.Equal(mac, msgSigned.Sig) {
+		return nil, ErrInvalidSignature
+	}
+
+	return msgSigned.Data, nil
+}
+
+func (a *HMACAuthentication) sum(key, head, msgBuf []byte) ([]byte, error) {
+	h, err := method.NewHMAC(a.Hash, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthenticate, err)
+	}
+	h.Write(head)
+	h.Write(msgBuf)
+	return h.Sum(nil), nil
+}
+
+// Ed25519KeyResolver 返回 src 所在节点以 keyID 标识的 Ed25519 公钥。
+type Ed25519KeyResolver = func(src gap.Origin, keyID string) (ed25519.PublicKey, error)
+
+// NewEd25519Authentication 创建使用节点私钥签名的 Ed25519 签名模块；验证时由 resolver 按消息来源查找公钥，
+// 因此签名可证明消息确实来自 Origin 所声明的节点。私钥长度不正确或 resolver 为 nil 会 panic。
+func NewEd25519Authentication(keyID string, privateKey ed25519.PrivateKey, resolver Ed25519KeyResolver) IAuthentication {
+	if keyID == "" || len(privateKey) != ed25519.PrivateKeySize {
+		exception.Panicf("%w: %w: invalid Ed25519 key", ErrAuthenticate, core.ErrArgs)
+	}
+	if resolver == nil {
+		exception.Panicf("%w: %w: resolver is nil", ErrAuthenticate, core.ErrArgs)
+	}
+	return &Ed25519Authentication{
+		KeyID:      keyID,
+		PrivateKey: privateKey,
+		Resolver:   resolver,
+	}
+}
+
+// Ed25519Authentication 以本节点私钥签名，并以来源节点公钥验证。
+type Ed25519Authentication struct {
+	KeyID      string             // 本节点密钥 ID。
+	PrivateKey ed25519.PrivateKey // 本节点签名私钥。
+	Resolver   Ed25519KeyResolver // 来源节点公钥查找函数。
+}
+
+// Sign 以本节点私钥按 Ed25519ph 签名并返回池化的 MsgSigned 编码。
+func (a *Ed25519Authentication) Sign(head, msgBuf []byte) (binaryutil.Bytes, error) {
+	sig, err := a.PrivateKey.Sign(nil, ed25519Digest(head, msgBuf), ed25519Options)
+	if err != nil {
+		return binaryutil.EmptyBytes, fmt.Errorf("%w: %w", ErrAuthenticate, err)
+	}
+	return encodeSigned(gap.MsgSigned{KeyID: a.KeyID, Data: msgBuf, Sig: sig})
+}
+
+// Auth 以来源节点公钥验证签名，并返回引用 msgBuf 的原始消息体。
+func (a *Ed25519Authentication) Auth(src gap.Origin, head, msgBuf []byte) ([]byte, error) {
+	msgSigned := gap.MsgSigned{}
+
+	if _, err := msgSigned.Write(msgBuf); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthenticate, err)
+	}
+
+	publicKey, err := a.Resolver(src, msgSigned.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%q, %q): %w", ErrUnknownKey, src.Addr, msgSigned.KeyID, err)
+	}
+
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w (%q, %q): invalid public key", ErrUnknownKey, src.Addr, msgSigned.KeyID)
+	}
+
+	if err := ed25519.VerifyWithOptions(publicKey, ed25519Digest(head, msgSigned.Data), msgSigned.Sig, ed25519Options); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	return msgSigned.Data, nil
+}
+
+// ed25519Options 选择 Ed25519ph，先对消息头和消息体做 SHA-512 摘要，避免为签名拼接完整消息。
+var ed25519Options = &ed25519.Options{Hash: crypto.SHA512}
+
+func ed25519Digest(head, msgBuf []byte) []byte {
+	h := sha512.New()
+	h.Write(head)
+	h.Write(msgBuf)
+	return h.Sum(nil)
+}
+
+func encodeSigned(msgSigned gap.MsgSigned) (binaryutil.Bytes, error) {
+	signedBuf := binaryutil.NewBytes(true, msgSigned.Size())
+
+	if _, err := binaryutil.CopyToBuff(signedBuf.Payload(), msgSigned); err != nil {
+		signedBuf.Release()
+		return binaryutil.EmptyBytes, fmt.Errorf("%w: %w", ErrAuthenticate, err)
+	}
+
+	return signedBuf, nil
+}
+
+// authHead 编码用于签名和加密附加数据的消息头：包长置零且清除 Flag_Signed，
+// 使签名前后及收发两端得到相同的字节；末尾追加目的地址，使截获的消息包无法重放到其他地址。
+func authHead(dst string, head gap.MsgHead) []byte {
+	head.Len = 0
+	head.Flags.Set(gap.Flag_Signed, false)
+
+	headSize := head.Size()
+	buf := make([]byte, headSize+binaryutil.SizeofString(dst))
+	binaryutil.CopyToBuff(buf, head)
+
+	bs := binaryutil.NewBigEndianStream(buf[headSize:])
+	bs.WriteString(dst)
+
+	return buf
+}
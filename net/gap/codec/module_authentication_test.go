@@ -0,0 +1,288 @@
+package codec
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gtp"
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+var testHead = authHead("node-b", gap.MsgHead{MsgID: gap.MsgID_Forward, Src: gap.Origin{Svc: "svc", Addr: "node-a", Timestamp: 1000}, Seq: 1})
+
+func mustSign(t *testing.T, a IAuthentication, head, body []byte) []byte {
+	t.Helper()
+
+	signedBuf, err := a.Sign(head, body)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	defer signedBuf.Release()
+
+	return bytes.Clone(signedBuf.Payload())
+}
+
+// tamperSigned 解码 signed，以 modify 修改后重新编码。
+func tamperSigned(t *testing.T, signed []byte, modify func(msg *gap.MsgSigned)) []byte {
+	t.Helper()
+
+	var msg gap.MsgSigned
+	if _, err := msg.Write(bytes.Clone(signed)); err != nil {
+		t.Fatalf("decode MsgSigned failed: %v", err)
+	}
+	modify(&msg)
+
+	buf := make([]byte, msg.Size())
+	if _, err := binaryutil.CopyToBuff(buf, msg); err != nil {
+		t.Fatalf("encode MsgSigned failed: %v", err)
+	}
+	return buf
+}
+
+func flipLast(b []byte) []byte {
+	b = bytes.Clone(b)
+	b[len(b)-1] ^= 0xff
+	return b
+}
+
+func TestNewAuthenticationPanics(t *testing.T) {
+	_, privateKey, _ := ed25519.GenerateKey(nil)
+
+	cases := []struct {
+		name string
+		new  func()
+	}{
+		{"hmac nil keyring", func() { NewHMACAuthentication(gtp.Hash_SHA256, nil) }},
+		{"hmac unsupported hash", func() {
+			NewHMACAuthentication(gtp.Hash_None, NewKeyring("k1", map[string][]byte{"k1": []byte("key")}))
+		}},
+		{"ed25519 empty key id", func() { NewEd25519Authentication("", privateKey, ed25519Resolver(nil)) }},
+		{"ed25519 short private key", func() { NewEd25519Authentication("k1", privateKey[:10], ed25519Resolver(nil)) }},
+		{"ed25519 nil resolver", func() { NewEd25519Authentication("k1", privateKey, nil) }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			c.new()
+		})
+	}
+}
+
+func TestHMACAuthentication(t *testing.T) {
+	body := []byte("message body")
+
+	signer := NewHMACAuthentication(gtp.Hash_SHA256, NewKeyring("k1", map[string][]byte{"k1": []byte("key-1")}))
+	signed := mustSign(t, signer, testHead, body)
+
+	cases := []struct {
+		name     string
+		verifier IAuthentication
+		head     []byte
+		signed   []byte
+		err      error
+	}{
+		{"valid", signer, testHead, signed, nil},
+		{"same key other keyring", NewHMACAuthentication(gtp.Hash_SHA256, NewKeyring("k1", map[string][]byte{"k1": []byte("key-1")})), testHead, signed, nil},
+		{"tampered head", signer, flipLast(testHead), signed, ErrInvalidSignature},
+		{"tampered body", signer, testHead, tamperSigned(t, signed, func(msg *gap.MsgSigned) { msg.Data = flipLast(msg.Data) }), ErrInvalidSignature},
+		{"tampered signature", signer, testHead, tamperSigned(t, signed, func(msg *gap.MsgSigned) { msg.Sig = flipLast(msg.Sig) }), ErrInvalidSignature},
+		{"wrong key", NewHMACAuthentication(gtp.Hash_SHA256, NewKeyring("k1", map[string][]byte{"k1": []byte("key-2")})), testHead, signed, ErrInvalidSignature},
+		{"wrong hash", NewHMACAuthentication(gtp.Hash_BLAKE2b256, NewKeyring("k1", map[string][]byte{"k1": []byte("key-1")})), testHead, signed, ErrInvalidSignature},
+		{"unknown key", NewHMACAuthentication(gtp.Hash_SHA256, NewKeyring("k2", map[string][]byte{"k2": []byte("key-1")})), testHead, signed, ErrUnknownKey},
+		{"malformed", signer, testHead, signed[:len(signed)-1], ErrAuthenticate},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.verifier.Auth(gap.Origin{}, c.head, c.signed)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("expected %v, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, body) {
+				t.Fatalf("Auth = %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestHMACAuthenticationRotation(t *testing.T) {
+	body := []byte("message body")
+
+	senderKeys := NewKeyring("k1", map[string][]byte{"k1": []byte("key-1")})
+	receiverKeys := NewKeyring("k1", map[string][]byte{"k1": []byte("key-1")})
+	sender := NewHMACAuthentication(gtp.Hash_SHA256, senderKeys)
+	receiver := NewHMACAuthentication(gtp.Hash_SHA256, receiverKeys)
+
+	old := mustSign(t, sender, testHead, body)
+
+	// 先在全部节点添加新密钥，再切换发送方的当前密钥。
+	for _, k := range []*Keyring{senderKeys, receiverKeys} {
+		if err := k.Add("k2", []byte("key-2")); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if err := senderKeys.Activate("k2"); err != nil {
+		t.Fatalf("Activate failed: %v", err)
+	}
+
+	rotated := mustSign(t, sender, testHead, body)
+
+	for name, signed := range map[string][]byte{"old": old, "rotated": rotated} {
+		if got, err := receiver.Auth(gap.Origin{}, testHead, signed); err != nil || !bytes.Equal(got, body) {
+			t.Fatalf("%s signature: Auth = %q, %v", name, got, err)
+		}
+	}
+
+	// 移除旧密钥后，以旧密钥签名的消息不再被接受。
+	if err := receiverKeys.Activate("k2"); err != nil {
+		t.Fatalf("Activate failed: %v", err)
+	}
+	if err := receiverKeys.Remove("k1"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := receiver.Auth(gap.Origin{}, testHead, old); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+	if _, err := receiver.Auth(gap.Origin{}, testHead, rotated); err != nil {
+		t.Fatalf("Auth failed: %v", err)
+	}
+}
+
+// ed25519Resolver 返回按节点地址和密钥 ID 查找公钥的解析函数。
+func ed25519Resolver(keys map[[2]string]ed25519.PublicKey) Ed25519KeyResolver {
+	return func(src gap.Origin, keyID string) (ed25519.PublicKey, error) {
+		publicKey, ok := keys[[2]string{src.Addr, keyID}]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return publicKey, nil
+	}
+}
+
+func TestEd25519Authentication(t *testing.T) {
+	body := []byte("message body")
+	src := gap.Origin{Svc: "svc", Addr: "node-a"}
+
+	publicKey, privateKey, _ := ed25519.GenerateKey(nil)
+	otherPublicKey, otherPrivateKey, _ := ed25519.GenerateKey(nil)
+
+	signer := NewEd25519Authentication("k1", privateKey, ed25519Resolver(nil))
+	signed := mustSign(t, signer, testHead, body)
+
+	verifier := NewEd25519Authentication("k9", otherPrivateKey, ed25519Resolver(map[[2]string]ed25519.PublicKey{
+		{"node-a", "k1"}: publicKey,
+		{"node-b", "k1"}: otherPublicKey,
+		{"node-c", "k1"}: publicKey[:10],
+	}))
+
+	cases := []struct {
+		name   string
+		src    gap.Origin
+		head   []byte
+		signed []byte
+		err    error
+	}{
+		{"valid", src, testHead, signed, nil},
+		{"tampered head", src, flipLast(testHead), signed, ErrInvalidSignature},
+		{"tampered body", src, testHead, tamperSigned(t, signed, func(msg *gap.MsgSigned) { msg.Data = flipLast(msg.Data) }), ErrInvalidSignature},
+		{"tampered signature", src, testHead, tamperSigned(t, signed, func(msg *gap.MsgSigned) { msg.Sig = flipLast(msg.Sig) }), ErrInvalidSignature},
+		{"impersonated node", gap.Origin{Svc: "svc", Addr: "node-b"}, testHead, signed, ErrInvalidSignature},
+		{"unknown node", gap.Origin{Svc: "svc", Addr: "node-x"}, testHead, signed, ErrUnknownKey},
+		{"unknown key id", src, testHead, tamperSigned(t, signed, func(msg *gap.MsgSigned) { msg.KeyID = "k2" }), ErrUnknownKey},
+		{"invalid public key", gap.Origin{Svc: "svc", Addr: "node-c"}, testHead, signed, ErrUnknownKey},
+		{"malformed", src, testHead, signed[:len(signed)-1], ErrAuthenticate},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := verifier.Auth(c.src, c.head, c.signed)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("expected %v, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, body) {
+				t.Fatalf("Auth = %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestSignedPacket(t *testing.T) {
+	msg := &gap.MsgForward{Dst: "node-b", TransID: gap.MsgID_Fragment, TransData: []byte("payload")}
+	src := gap.Origin{Svc: "svc", Addr: "node-a", Timestamp: 1000}
+
+	auth := NewHMACAuthentication(gtp.Hash_SHA256, NewKeyring("k1", map[string][]byte{"k1": []byte("key-1")}))
+
+	encode := func(encoder *Encoder) []byte {
+		buf, err := encoder.EncodeTo("node-b", src, 7, msg)
+		if err != nil {
+			t.Fatalf("EncodeTo failed: %v", err)
+		}
+		defer buf.Release()
+		return bytes.Clone(buf.Payload())
+	}
+	signed := encode(&Encoder{Authentication: auth})
+	unsigned := encode(&Encoder{})
+
+	// 篡改签名覆盖的消息头序号。
+	var head gap.MsgHead
+	n, err := head.Write(signed)
+	if err != nil {
+		t.Fatalf("read head failed: %v", err)
+	}
+	if !head.Flags.Is(gap.Flag_Signed) {
+		t.Fatalf("signed packet head %+v", head)
+	}
+	head.Seq++
+	tampered := bytes.Clone(signed)
+	if _, err := binaryutil.CopyToBuff(tampered[:n], head); err != nil {
+		t.Fatalf("write head failed: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		decoder *Decoder
+		dst     string
+		data    []byte
+		err     error
+	}{
+		{"signed", &Decoder{MsgCreator: gap.DefaultMsgCreator(), Authentication: auth}, "node-b", signed, nil},
+		{"tampered head", &Decoder{MsgCreator: gap.DefaultMsgCreator(), Authentication: auth}, "node-b", tampered, ErrInvalidSignature},
+		// 截获的消息包重放到其他地址时签名校验失败。
+		{"replayed to other dst", &Decoder{MsgCreator: gap.DefaultMsgCreator(), Authentication: auth}, "node-c", signed, ErrInvalidSignature},
+		{"unsigned rejected", &Decoder{MsgCreator: gap.DefaultMsgCreator(), Authentication: auth}, "node-b", unsigned, ErrUnsigned},
+		{"unsigned accepted", &Decoder{MsgCreator: gap.DefaultMsgCreator(), Authentication: auth, AcceptUnsigned: true}, "node-b", unsigned, nil},
+		{"signed without authentication", &Decoder{MsgCreator: gap.DefaultMsgCreator()}, "node-b", signed, ErrDecode},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mp, err := c.decoder.DecodeFor(c.dst, c.data)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("expected %v, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeFor failed: %v", err)
+			}
+			if got, ok := mp.Body.(*gap.MsgForward); !ok || !bytes.Equal(got.TransData, msg.TransData) || mp.Head.Seq != 7 {
+				t.Fatalf("unexpected packet %+v", mp)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/utils/exception"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gtp"
+	"git.golaxy.org/framework/net/gtp/method"
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+var (
+	// ErrCompress 是 GAP 消息压缩和解压错误的根错误。
+	ErrCompress = errors.New("gap-compress")
+)
+
+// ICompression 压缩和还原 GAP 消息体；实现必须支持并发调用。
+type ICompression interface {
+	// Compress 仅在编码结果更小时返回 MsgCompressed 编码数据；池化结果由调用方释放。
+	Compress(src []byte) (compressedBuf binaryutil.Bytes, compressed bool, err error)
+	// Uncompress 解码 MsgCompressed 并在 max 字节上限内还原数据；结果不会被池回收，可被解码后的消息长期引用。
+	Uncompress(src []byte, max int) (uncompressedBuf []byte, err error)
+}
+
+// NewCompression 创建以 c 压缩的模块；解压时按 MsgCompressed 记录的算法选择压缩流，
+// 因此 c 为 Compression_None 时仍可还原其他节点发送的压缩消息。不支持的算法会 panic。
+func NewCompression(c gtp.Compression) ICompression {
+	if c != gtp.Compression_None {
+		if _, err := method.NewCompressionStream(c); err != nil {
+			exception.Panicf("%w: %w: %w", ErrCompress, core.ErrArgs, err)
+		}
+	}
+	return &Compression{
+		Method: c,
+	}
+}
+
+// Compression 通过按算法池化的 method.CompressionStream 压缩和还原消息体。
+type Compression struct {
+	Method gtp.Compression // 压缩使用的算法；Compression_None 表示只解压。
+	pools  sync.Map        // gtp.Compression -> *sync.Pool
+}
+
+// Compress 压缩 src；未指定算法、空输入或压缩无收益时返回 compressed=false。
+func (c *Compression) Compress(src []byte) (binaryutil.Bytes, bool, error) {
+	if len(src) <= 0 || c.Method == gtp.Compression_None {
+		return binaryutil.EmptyBytes, false, nil
+	}
+
+	cs, err := c.getStream(c.Method)
+	if err != nil {
+		return binaryutil.EmptyBytes, false, fmt.Errorf("%w: %w", ErrCompress, err)
+	}
+	defer c.putStream(c.Method, cs)
+
+	compressedDataBuf := binaryutil.NewBytes(true, len(src))
+	defer compressedDataBuf.Release()
+
+	n, err := func() (int, error) {
+		bw := binaryutil.NewBytesWriter(compressedDataBuf.Payload())
+		w, err := cs.WrapWriter(bw)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := w.Write(src); err != nil {
+			return 0, err
+		}
+		if err := w.Close(); err != nil {
+			return 0, err
+		}
+		return bw.N, nil
+	}()
+	if err != nil {
+		if errors.Is(err, binaryutil.ErrLimitReached) {
+			return binaryutil.EmptyBytes, false, nil
+		}
+		return binaryutil.EmptyBytes, false, fmt.Errorf("%w: %w", ErrCompress, err)
+	}
+
+	msgCompressed := gap.MsgCompressed{
+		Compression:  uint8(c.Method),
+		Data:         compressedDataBuf.Payload()[:n],
+		OriginalSize: int64(len(src)),
+	}
+
+	if msgCompressed.Size() >= len(src) {
+		return binaryutil.EmptyBytes, false, nil
+	}
+
+	compressedBuf := binaryutil.NewBytes(true, msgCompressed.Size())
+
+	if _, err := binaryutil.CopyToBuff(compressedBuf.Payload(), msgCompressed); err != nil {
+		compressedBuf.Release()
+		return binaryutil.EmptyBytes, false, fmt.Errorf("%w: %w", ErrCompress, err)
+	}
+
+	return compressedBuf, true, nil
+}
+
+// Uncompress 解码 MsgCompressed 并按其记录的算法还原数据；max 必须容纳原始大小，且解压结果须与原始大小一致。
+func (c *Compression) Uncompress(src []byte, max int) ([]byte, error) {
+	msgCompressed := gap.MsgCompressed{}
+
+	if _, err := msgCompressed.Write(src); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompress, err)
+	}
+
+	if msgCompressed.OriginalSize < 0 {
+		return nil, fmt.Errorf("%w: negative original size", ErrCompress)
+	}
+	if msgCompressed.OriginalSize > int64(max) {
+		return nil, fmt.Errorf("%w: original size too large (%d > %d)", ErrCompress, msgCompressed.OriginalSize, max)
+	}
+
+	m := gtp.Compression(msgCompressed.Compression)
+
+	cs, err := c.getStream(m)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w (%d)", ErrCompress, err, m)
+	}
+	defer c.putStream(m, cs)
+
+	r, err := cs.WrapReader(bytes.NewReader(msgCompressed.Data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompress, err)
+	}
+
+	// OriginalSize 由发送方填写，不可信：不按它预分配，而是随实际解压的数据增长缓冲区，
+	// 并至多多读 1 字节，以识别解压结果长于所声明大小的消息。
+	uncompressed, err := io.ReadAll(io.LimitReader(r, msgCompressed.OriginalSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompress, err)
+	}
+
+	if int64(len(uncompressed)) != msgCompressed.OriginalSize {
+		return nil, fmt.Errorf("%w: uncompressed size mismatch (%d != %d)", ErrCompress, len(uncompressed), msgCompressed.OriginalSize)
+	}
+
+	return uncompressed, nil
+}
+
+// getStream 从对应算法的池中取出压缩流；CompressionStream 不支持并发，因此每次调用独占一个实例。
+func (c *Compression) getStream(m gtp.Compression) (method.CompressionStream, error) {
+	if v, ok := c.pools.Load(m); ok {
+		if cs := v.(*sync.Pool).Get(); cs != nil {
+			return cs.(method.CompressionStream), nil
+		}
+	}
+	return method.NewCompressionStream(m)
+}
+
+func (c *Compression) putStream(m gtp.Compression, cs method.CompressionStream) {
+	v, _ := c.pools.LoadOrStore(m, &sync.Pool{})
+	v.(*sync.Pool).Put(cs)
+}
@@ -0,0 +1,224 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/net/gtp"
+	"git.golaxy.org/framework/net/gtp/method"
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+var compressions = []gtp.Compression{
+	gtp.Compression_Gzip,
+	gtp.Compression_Deflate,
+	gtp.Compression_Brotli,
+	gtp.Compression_LZ4,
+	gtp.Compression_Snappy,
+}
+
+// compressRaw 直接以压缩流压缩 src，用于构造 OriginalSize 与实际数据不符的消息。
+func compressRaw(t *testing.T, m gtp.Compression, src []byte) []byte {
+	t.Helper()
+
+	cs, err := method.NewCompressionStream(m)
+	if err != nil {
+		t.Fatalf("NewCompressionStream failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := cs.WrapWriter(&buf)
+	if err != nil {
+		t.Fatalf("WrapWriter failed: %v", err)
+	}
+	if _, err := w.Write(src); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func mustMarshalCompressed(t *testing.T, msg gap.MsgCompressed) []byte {
+	t.Helper()
+
+	buf := make([]byte, msg.Size())
+	if _, err := binaryutil.CopyToBuff(buf, msg); err != nil {
+		t.Fatalf("encode MsgCompressed failed: %v", err)
+	}
+	return buf
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	src := bytes.Repeat([]byte("compress-me-"), 64)
+
+	for _, m := range compressions {
+		t.Run(m.String(), func(t *testing.T) {
+			c := NewCompression(m)
+
+			compressed, ok, err := c.Compress(src)
+			if err != nil {
+				t.Fatalf("Compress failed: %v", err)
+			}
+			defer compressed.Release()
+			if !ok {
+				t.Fatal("expected data to be compressed")
+			}
+
+			// 解压按消息记录的算法进行，只解压的模块也能还原。
+			for _, u := range []ICompression{c, NewCompression(gtp.Compression_None)} {
+				uncompressed, err := u.Uncompress(compressed.Payload(), len(src))
+				if err != nil {
+					t.Fatalf("Uncompress failed: %v", err)
+				}
+				if !bytes.Equal(uncompressed, src) {
+					t.Fatal("unexpected uncompressed payload")
+				}
+			}
+		})
+	}
+}
+
+func TestCompressionSkipped(t *testing.T) {
+	cases := []struct {
+		name string
+		m    gtp.Compression
+		src  []byte
+	}{
+		{"none", gtp.Compression_None, bytes.Repeat([]byte("a"), 256)},
+		{"empty", gtp.Compression_Gzip, nil},
+		{"no benefit", gtp.Compression_Gzip, []byte("abc")},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			compressed, ok, err := NewCompression(c.m).Compress(c.src)
+			if err != nil {
+				t.Fatalf("Compress failed: %v", err)
+			}
+			defer compressed.Release()
+			if ok {
+				t.Fatal("expected compression to be skipped")
+			}
+		})
+	}
+}
+
+func TestCompressionUncompressErrors(t *testing.T) {
+	src := bytes.Repeat([]byte("0123456789"), 100)
+	data := compressRaw(t, gtp.Compression_Gzip, src)
+
+	cases := []struct {
+		name string
+		msg  gap.MsgCompressed
+		max  int
+	}{
+		{"negative original size", gap.MsgCompressed{Compression: uint8(gtp.Compression_Gzip), Data: data, OriginalSize: -1}, len(src)},
+		{"original size over max", gap.MsgCompressed{Compression: uint8(gtp.Compression_Gzip), Data: data, OriginalSize: int64(len(src))}, len(src) - 1},
+		{"longer than declared", gap.MsgCompressed{Compression: uint8(gtp.Compression_Gzip), Data: data, OriginalSize: 10}, len(src)},
+		{"shorter than declared", gap.MsgCompressed{Compression: uint8(gtp.Compression_Gzip), Data: data, OriginalSize: int64(len(src)) + 1}, len(src) * 2},
+		{"corrupted data", gap.MsgCompressed{Compression: uint8(gtp.Compression_Gzip), Data: data[:len(data)/2], OriginalSize: int64(len(src))}, len(src)},
+		{"unknown algorithm", gap.MsgCompressed{Compression: 0xff, Data: data, OriginalSize: int64(len(src))}, len(src)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := NewCompression(gtp.Compression_None).Uncompress(mustMarshalCompressed(t, c.msg), c.max); !errors.Is(err, ErrCompress) {
+				t.Fatalf("expected ErrCompress, got %v", err)
+			}
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		encoded := mustMarshalCompressed(t, gap.MsgCompressed{Compression: uint8(gtp.Compression_Gzip), Data: data, OriginalSize: int64(len(src))})
+		for n := range len(encoded) {
+			if _, err := NewCompression(gtp.Compression_None).Uncompress(encoded[:n], len(src)); err == nil {
+				t.Fatalf("expected error uncompressing %d of %d bytes", n, len(encoded))
+			}
+		}
+	})
+}
+
+func TestCompressionUncompressBomb(t *testing.T) {
+	// 声明 1 KiB、实际解压出 64 MiB 的消息须在读到声明大小后即被拒绝，而不是先解压全部数据。
+	data := compressRaw(t, gtp.Compression_Gzip, make([]byte, 64<<20))
+	msg := mustMarshalCompressed(t, gap.MsgCompressed{Compression: uint8(gtp.Compression_Gzip), Data: data, OriginalSize: 1 << 10})
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	if _, err := NewCompression(gtp.Compression_None).Uncompress(msg, 1<<30); !errors.Is(err, ErrCompress) {
+		t.Fatalf("expected ErrCompress, got %v", err)
+	}
+
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 8<<20 {
+		t.Fatalf("Uncompress allocated %d bytes, expected it to stop after the declared size", allocated)
+	}
+}
+
+func TestCompressedPacketRoundTrip(t *testing.T) {
+	msg := &gap.MsgForward{
+		Dst:       "node-b",
+		TransID:   gap.MsgID_Fragment,
+		TransData: bytes.Repeat([]byte("payload-"), 128),
+	}
+
+	cases := []struct {
+		name       string
+		threshold  int
+		compressed bool
+	}{
+		{"compressed", 1, true},
+		{"below threshold", msg.Size() + 1, false},
+		{"disabled", 0, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			encoder := &Encoder{Compression: NewCompression(gtp.Compression_Gzip), CompressionThreshold: c.threshold}
+
+			buf, err := encoder.Encode(gap.Origin{Svc: "svc", Addr: "node-a"}, 7, msg)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			defer buf.Release()
+
+			var head gap.MsgHead
+			if _, err := head.Write(buf.Payload()); err != nil {
+				t.Fatalf("read head failed: %v", err)
+			}
+			if head.Flags.Is(gap.Flag_Compressed) != c.compressed || int(head.Len) != len(buf.Payload()) {
+				t.Fatalf("unexpected head %+v for %d bytes", head, len(buf.Payload()))
+			}
+
+			decoder := &Decoder{MsgCreator: gap.DefaultMsgCreator(), Compression: NewCompression(gtp.Compression_None), MaxUncompressedSize: msg.Size()}
+			mp, err := decoder.Decode(buf.Payload())
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			got, ok := mp.Body.(*gap.MsgForward)
+			if !ok || got.Dst != msg.Dst || got.TransID != msg.TransID || !bytes.Equal(got.TransData, msg.TransData) {
+				t.Fatalf("unexpected body %+v", mp.Body)
+			}
+			if mp.Head.Seq != 7 || mp.Head.Src.Addr != "node-a" {
+				t.Fatalf("unexpected head %+v", mp.Head)
+			}
+
+			if !c.compressed {
+				return
+			}
+
+			if _, err := (&Decoder{MsgCreator: gap.DefaultMsgCreator()}).Decode(buf.Payload()); !errors.Is(err, ErrDecode) {
+				t.Fatalf("expected decoder without compression to reject the packet, got %v", err)
+			}
+			if _, err := (&Decoder{MsgCreator: gap.DefaultMsgCreator(), Compression: NewCompression(gtp.Compression_None), MaxUncompressedSize: msg.Size() - 1}).Decode(buf.Payload()); !errors.Is(err, ErrCompress) {
+				t.Fatalf("expected MaxUncompressedSize to be enforced, got %v", err)
+			}
+		})
+	}
+}
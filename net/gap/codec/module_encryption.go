@@ -0,0 +1,137 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package codec
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/utils/exception"
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+var (
+	// ErrEncrypt 是 GAP 消息加密和解密错误的根错误。
+	ErrEncrypt = errors.New("gap-encrypt")
+)
+
+// IEncryption 以消息头和目的地址为附加数据加密和解密消息体；实现必须支持并发调用。
+type IEncryption interface {
+	// Seal 加密消息体并返回池化的 MsgEncrypted 编码；结果由调用方释放。
+	Seal(head, msgBuf []byte) (encryptedBuf binaryutil.Bytes, err error)
+	// Open 解密 MsgEncrypted；结果不会被池回收，可被解码后的消息长期引用。
+	Open(head, msgBuf []byte) (decryptedBuf []byte, err error)
+}
+
+// NewAESGCMEncryption 创建使用集群共享密钥环的 AES-GCM 加密模块；密钥长度须为 16、24 或 32 字节。
+// 密钥环为 nil 会 panic。
+func NewAESGCMEncryption(keyring *Keyring) IEncryption {
+	if keyring == nil {
+		exception.Panicf("%w: %w: keyring is nil", ErrEncrypt, core.ErrArgs)
+	}
+	return &AESGCMEncryption{
+		Keyring: keyring,
+	}
+}
+
+// AESGCMEncryption 以密钥环中的当前密钥加密，并按密文携带的密钥 ID 解密；每条消息使用随机 nonce。
+type AESGCMEncryption struct {
+	Keyring *Keyring // 加密与解密使用的密钥环。
+}
+
+// Seal 以当前密钥加密消息体并返回池化的 MsgEncrypted 编码。
+func (e *AESGCMEncryption) Seal(head, msgBuf []byte) (binaryutil.Bytes, error) {
+	keyID, key := e.Keyring.Active()
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return binaryutil.EmptyBytes, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return binaryutil.EmptyBytes, fmt.Errorf("%w: %w", ErrEncrypt, err)
+	}
+
+	dataBuf := binaryutil.NewBytes(true, len(msgBuf)+aead.Overhead())
+	defer dataBuf.Release()
+
+	msgEncrypted := gap.MsgEncrypted{
+		KeyID: keyID,
+		Nonce: nonce,
+		Data:  aead.Seal(dataBuf.Payload()[:0], nonce, msgBuf, head),
+	}
+
+	encryptedBuf := binaryutil.NewBytes(true, msgEncrypted.Size())
+
+	if _, err := binaryutil.CopyToBuff(encryptedBuf.Payload(), msgEncrypted); err != nil {
+		encryptedBuf.Release()
+		return binaryutil.EmptyBytes, fmt.Errorf("%w: %w", ErrEncrypt, err)
+	}
+
+	return encryptedBuf, nil
+}
+
+// Open 按 MsgEncrypted 记录的密钥 ID 解密消息体，并校验消息头未被篡改。
+func (e *AESGCMEncryption) Open(head, msgBuf []byte) ([]byte, error) {
+	msgEncrypted := gap.MsgEncrypted{}
+
+	if _, err := msgEncrypted.Write(msgBuf); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncrypt, err)
+	}
+
+	key, ok := e.Keyring.Get(msgEncrypted.KeyID)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrEncrypt, msgEncrypted.KeyID)
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msgEncrypted.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce size", ErrEncrypt)
+	}
+
+	decrypted, err := aead.Open(nil, msgEncrypted.Nonce, msgEncrypted.Data, head)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncrypt, err)
+	}
+
+	return decrypted, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncrypt, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncrypt, err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,178 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"git.golaxy.org/framework/net/gap"
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+var testAESKey = bytes.Repeat([]byte{0x42}, 32)
+
+func mustSeal(t *testing.T, e IEncryption, head, body []byte) []byte {
+	t.Helper()
+
+	encryptedBuf, err := e.Seal(head, body)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	defer encryptedBuf.Release()
+
+	return bytes.Clone(encryptedBuf.Payload())
+}
+
+// tamperEncrypted 解码 encrypted，以 modify 修改后重新编码。
+func tamperEncrypted(t *testing.T, encrypted []byte, modify func(msg *gap.MsgEncrypted)) []byte {
+	t.Helper()
+
+	var msg gap.MsgEncrypted
+	if _, err := msg.Write(bytes.Clone(encrypted)); err != nil {
+		t.Fatalf("decode MsgEncrypted failed: %v", err)
+	}
+	modify(&msg)
+
+	buf := make([]byte, msg.Size())
+	if _, err := binaryutil.CopyToBuff(buf, msg); err != nil {
+		t.Fatalf("encode MsgEncrypted failed: %v", err)
+	}
+	return buf
+}
+
+func TestNewAESGCMEncryptionPanicsWithNilKeyring(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+
+	NewAESGCMEncryption(nil)
+}
+
+func TestAESGCMEncryption(t *testing.T) {
+	body := []byte("message body")
+
+	e := NewAESGCMEncryption(NewKeyring("k1", map[string][]byte{"k1": testAESKey}))
+	encrypted := mustSeal(t, e, testHead, body)
+
+	if bytes.Contains(encrypted, body) {
+		t.Fatal("ciphertext contains the plaintext")
+	}
+	if bytes.Equal(encrypted, mustSeal(t, e, testHead, body)) {
+		t.Fatal("two seals of the same body are identical, nonce is not random")
+	}
+
+	cases := []struct {
+		name      string
+		e         IEncryption
+		head      []byte
+		encrypted []byte
+		fail      bool
+	}{
+		{"valid", e, testHead, encrypted, false},
+		{"same key other keyring", NewAESGCMEncryption(NewKeyring("k1", map[string][]byte{"k1": bytes.Clone(testAESKey)})), testHead, encrypted, false},
+		{"tampered head", e, flipLast(testHead), encrypted, true},
+		{"tampered ciphertext", e, testHead, tamperEncrypted(t, encrypted, func(msg *gap.MsgEncrypted) { msg.Data = flipLast(msg.Data) }), true},
+		{"tampered nonce", e, testHead, tamperEncrypted(t, encrypted, func(msg *gap.MsgEncrypted) { msg.Nonce = flipLast(msg.Nonce) }), true},
+		{"short nonce", e, testHead, tamperEncrypted(t, encrypted, func(msg *gap.MsgEncrypted) { msg.Nonce = msg.Nonce[:4] }), true},
+		{"wrong key", NewAESGCMEncryption(NewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{0x24}, 32)})), testHead, encrypted, true},
+		{"unknown key", NewAESGCMEncryption(NewKeyring("k2", map[string][]byte{"k2": testAESKey})), testHead, encrypted, true},
+		{"malformed", e, testHead, encrypted[:len(encrypted)-1], true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.e.Open(c.head, c.encrypted)
+			if c.fail {
+				if !errors.Is(err, ErrEncrypt) {
+					t.Fatalf("expected ErrEncrypt, got %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, body) {
+				t.Fatalf("Open = %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestAESGCMEncryptionRotation(t *testing.T) {
+	body := []byte("message body")
+
+	senderKeys := NewKeyring("k1", map[string][]byte{"k1": testAESKey})
+	receiverKeys := NewKeyring("k1", map[string][]byte{"k1": testAESKey})
+	sender, receiver := NewAESGCMEncryption(senderKeys), NewAESGCMEncryption(receiverKeys)
+
+	old := mustSeal(t, sender, testHead, body)
+
+	newKey := bytes.Repeat([]byte{0x24}, 16)
+	for _, k := range []*Keyring{senderKeys, receiverKeys} {
+		if err := k.Add("k2", newKey); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if err := senderKeys.Activate("k2"); err != nil {
+		t.Fatalf("Activate failed: %v", err)
+	}
+
+	rotated := mustSeal(t, sender, testHead, body)
+
+	for name, encrypted := range map[string][]byte{"old": old, "rotated": rotated} {
+		if got, err := receiver.Open(testHead, encrypted); err != nil || !bytes.Equal(got, body) {
+			t.Fatalf("%s key: Open = %q, %v", name, got, err)
+		}
+	}
+
+	if err := receiverKeys.Activate("k2"); err != nil {
+		t.Fatalf("Activate failed: %v", err)
+	}
+	if err := receiverKeys.Remove("k1"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := receiver.Open(testHead, old); !errors.Is(err, ErrEncrypt) {
+		t.Fatalf("expected ErrEncrypt after removing the old key, got %v", err)
+	}
+}
+
+func TestAESGCMEncryptionInvalidKey(t *testing.T) {
+	e := NewAESGCMEncryption(NewKeyring("k1", map[string][]byte{"k1": []byte("short")}))
+
+	if _, err := e.Seal(testHead, []byte("body")); !errors.Is(err, ErrEncrypt) {
+		t.Fatalf("expected ErrEncrypt, got %v", err)
+	}
+}
+
+func TestEncryptedPacket(t *testing.T) {
+	msg := &gap.MsgForward{Dst: "node-b", TransID: gap.MsgID_Fragment, TransData: []byte("payload")}
+	e := NewAESGCMEncryption(NewKeyring("k1", map[string][]byte{"k1": testAESKey}))
+
+	buf, err := (&Encoder{Encryption: e}).Encode(gap.Origin{Svc: "svc", Addr: "node-a"}, 7, msg)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	defer buf.Release()
+
+	if bytes.Contains(buf.Payload(), msg.TransData) {
+		t.Fatal("encrypted packet contains the plaintext")
+	}
+
+	mp, err := (&Decoder{MsgCreator: gap.DefaultMsgCreator(), Encryption: e}).Decode(buf.Payload())
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if !mp.Head.Flags.Is(gap.Flag_Encrypted) {
+		t.Fatalf("unexpected head %+v", mp.Head)
+	}
+	if got, ok := mp.Body.(*gap.MsgForward); !ok || !bytes.Equal(got.TransData, msg.TransData) {
+		t.Fatalf("unexpected body %+v", mp.Body)
+	}
+
+	if _, err := (&Decoder{MsgCreator: gap.DefaultMsgCreator()}).Decode(buf.Payload()); !errors.Is(err, ErrDecode) {
+		t.Fatalf("expected decoder without encryption to reject the packet, got %v", err)
+	}
+	// 目的地址参与附加数据，不同地址无法解密。
+	if _, err := (&Decoder{MsgCreator: gap.DefaultMsgCreator(), Encryption: e}).DecodeFor("node-c", buf.Payload()); !errors.Is(err, ErrEncrypt) {
+		t.Fatalf("expected decrypting for another dst to fail, got %v", err)
+	}
+}
@@ -22,9 +22,11 @@
 // GAP 运行在 GTP 或消息队列之上，负责承载应用层消息，适合服务到服务、
 // 服务到客户端、以及路由转发等通信场景。当前包提供：
 //   - 统一的消息接口、消息头和消息创建器
-//   - Forward、RPC 请求/响应、单向 RPC 等基础消息模型
+//   - Forward、RPC 请求/响应、单向 RPC、批量实体 RPC、合并请求、大消息分片等基础消息模型
 //   - 序列化与反序列化入口
 //   - 配套的 codec 与 variant 子包，用于编解码和动态类型参数传输
+//   - 可插拔的 RPC 参数编解码器，支持 JSON、MessagePack 与 protobuf Any 等非 variant 格式
+//   - gapc 代码生成工具，按结构体字段生成自定义消息和自定义值的编解码方法
 //
 // 当需要在稳定传输层之上表达业务消息、RPC 参数或可扩展载荷时，应优先使用 GAP。
 package gap
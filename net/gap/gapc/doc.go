@@ -0,0 +1,38 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+// Command gapc 根据结构体字段声明生成基于 binaryutil 的 Read、Write、Size 方法。
+//
+// gapc 通过 go generate 调用，为 GAP 自定义消息或 variant 自定义值生成编解码方法、
+// 缺省的 MsgID/TypeID（按类型完整名称哈希）以及向 gap.DefaultMsgCreator 或
+// variant.VariantCreator 注册的 init 函数：
+//
+//	//go:generate go run git.golaxy.org/framework/net/gap/gapc msg --types=MsgLogin,MsgLogout
+//	//go:generate go run git.golaxy.org/framework/net/gap/gapc variant --types=Point
+//
+// 字段按声明顺序编码，编码方式由字段类型决定，可用 gapc 标签覆盖：
+//   - bool、int8~int64、uint8~uint64、byte、float32、float64 使用定长编码
+//   - int、uint 使用变长编码；标签 varint、uvarint 可让其他整数类型使用变长编码
+//   - string、[]byte 带长度前缀；标签选项 ref 使解码结果引用输入缓冲区
+//   - 其他具名类型需实现 Read、Write、Size，按嵌套值编解码
+//   - 其他切片类型先编码元素个数，再逐个编码元素，标签作用于元素
+//   - 标签 "-" 跳过字段；底层为基础类型的具名类型需在标签中写明编码，例如 gapc:"uint32"
+//
+// 类型已声明 MsgID、TypeID 或 Indirect 方法时不再生成对应方法。
+package main
@@ -0,0 +1,379 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// _Kind 是生成代码的目标类别。
+type _Kind string
+
+const (
+	kindMsg     _Kind = "msg"     // GAP 自定义消息
+	kindVariant _Kind = "variant" // variant 自定义值
+)
+
+const (
+	gapPath        = "git.golaxy.org/framework/net/gap"
+	variantPath    = "git.golaxy.org/framework/net/gap/variant"
+	binaryutilPath = "git.golaxy.org/framework/utils/binaryutil"
+)
+
+// _Generator 输出单个源文件的生成代码。
+type _Generator struct {
+	src     *_Source
+	kind    _Kind
+	buf     bytes.Buffer
+	imports map[string]string
+	loops   int
+}
+
+func generate(src *_Source, kind _Kind, declare bool, cmdline string) ([]byte, error) {
+	g := &_Generator{
+		src:     src,
+		kind:    kind,
+		imports: map[string]string{},
+	}
+
+	g.use(binaryutilPath, "binaryutil")
+	g.use("io", "io")
+
+	for _, st := range src.structs {
+		g.genStruct(st)
+	}
+
+	if declare {
+		g.genDeclare()
+	}
+
+	var out bytes.Buffer
+
+	for _, cg := range src.header {
+		for _, c := range cg.List {
+			fmt.Fprintln(&out, c.Text)
+		}
+		fmt.Fprintln(&out)
+	}
+	fmt.Fprintf(&out, "// Code generated by %s; DO NOT EDIT.\n\n", cmdline)
+	fmt.Fprintf(&out, "package %s\n\n", src.file.Name.Name)
+
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	fmt.Fprintln(&out, "import (")
+	for _, std := range []bool{true, false} {
+		if !std {
+			fmt.Fprintln(&out)
+		}
+		for _, path := range paths {
+			if strings.Contains(strings.Split(path, "/")[0], ".") == std {
+				continue
+			}
+			name := g.imports[path]
+			if name == path[strings.LastIndex(path, "/")+1:] {
+				fmt.Fprintf(&out, "\t%s\n", strconv.Quote(path))
+			} else {
+				fmt.Fprintf(&out, "\t%s %s\n", name, strconv.Quote(path))
+			}
+		}
+	}
+	fmt.Fprintln(&out, ")")
+
+	out.Write(g.buf.Bytes())
+
+	code, err := format.Source(out.Bytes())
+	if err != nil {
+		return out.Bytes(), fmt.Errorf("format generated code failed, %w", err)
+	}
+	return code, nil
+}
+
+func (g *_Generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// use 登记生成代码引用的包，返回引用时使用的限定符；目标包即当前包时返回空。
+func (g *_Generator) use(path, name string) string {
+	if path == gapPath && g.src.file.Name.Name == "gap" && !g.imported(gapPath) {
+		return ""
+	}
+	if path == variantPath && g.src.file.Name.Name == "variant" && !g.imported(variantPath) {
+		return ""
+	}
+	g.imports[path] = name
+	return name + "."
+}
+
+func (g *_Generator) imported(path string) bool {
+	for _, spec := range g.src.file.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// typeText 返回类型表达式的源码文本，并登记其中引用的包。
+func (g *_Generator) typeText(expr ast.Expr) string {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		for _, spec := range g.src.file.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			name := path[strings.LastIndex(path, "/")+1:]
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			if name == ident.Name {
+				g.imports[path] = name
+				break
+			}
+		}
+		return false
+	})
+
+	var buf bytes.Buffer
+	printer.Fprint(&buf, g.src.fset, expr)
+	return buf.String()
+}
+
+func (g *_Generator) recv() string {
+	if g.kind == kindMsg {
+		return "m"
+	}
+	return "v"
+}
+
+func (g *_Generator) genStruct(st *_Struct) {
+	r := g.recv()
+	what := "消息"
+	if g.kind == kindVariant {
+		what = "值"
+	}
+
+	g.printf("\n// Read 将%s编码到 p。\n", what)
+	g.printf("func (%s %s) Read(p []byte) (int, error) {\n", r, st.name)
+	g.printf("bs := binaryutil.NewBigEndianStream(p)\n")
+	g.loops = 0
+	for _, field := range st.fields {
+		g.genEncode(field.codec, r+"."+field.name)
+	}
+	g.printf("return bs.BytesWritten(), io.EOF\n}\n")
+
+	g.printf("\n// Write 从 p 解码%s。\n", what)
+	g.printf("func (%s *%s) Write(p []byte) (int, error) {\n", r, st.name)
+	g.printf("bs := binaryutil.NewBigEndianStream(p)\n")
+	g.loops = 0
+	for _, field := range st.fields {
+		g.genDecode(field.codec, r+"."+field.name, false)
+	}
+	g.printf("return bs.BytesRead(), nil\n}\n")
+
+	g.printf("\n// Size 返回%s编码后的字节数。\n", what)
+	g.printf("func (%s %s) Size() int {\n", r, st.name)
+	g.loops = 0
+	g.genSize(st)
+	g.printf("}\n")
+
+	switch g.kind {
+	case kindMsg:
+		if !g.src.hasMethod(st.name, "MsgID") {
+			gap := g.use(gapPath, "gap")
+			g.printf("\nvar _MsgID_%s = %sGenMsgIDT[%s]()\n", st.name, gap, st.name)
+			g.printf("\n// MsgID 返回按类型完整名称生成的消息 ID。\n")
+			g.printf("func (%s) MsgID() %sMsgID {\nreturn _MsgID_%s\n}\n", st.name, gap, st.name)
+		}
+	case kindVariant:
+		if !g.src.hasMethod(st.name, "TypeID") {
+			variant := g.use(variantPath, "variant")
+			g.printf("\nvar _TypeID_%s = %sGenTypeIDT[%s]()\n", st.name, variant, st.name)
+			g.printf("\n// TypeID 返回按类型完整名称生成的类型 ID。\n")
+			g.printf("func (%s) TypeID() %sTypeID {\nreturn _TypeID_%s\n}\n", st.name, variant, st.name)
+		}
+		if !g.src.hasMethod(st.name, "Indirect") {
+			g.printf("\n// Indirect 返回值本身。\n")
+			g.printf("func (%s %s) Indirect() any {\nreturn %s\n}\n", r, st.name, r)
+		}
+	}
+}
+
+func (g *_Generator) genDeclare() {
+	g.printf("\nfunc init() {\n")
+	for _, st := range g.src.structs {
+		switch g.kind {
+		case kindMsg:
+			g.printf("%sDefaultMsgCreator().Declare(&%s{})\n", g.use(gapPath, "gap"), st.name)
+		case kindVariant:
+			g.printf("%sVariantCreator().Declare(&%s{})\n", g.use(variantPath, "variant"), st.name)
+		}
+	}
+	g.printf("}\n")
+}
+
+func (g *_Generator) loopVar() string {
+	v := fmt.Sprintf("i%d", g.loops)
+	g.loops++
+	return v
+}
+
+// convert 在类型文本与目标类型不同时为表达式加上类型转换。
+func convert(typ, expr string) string {
+	if strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "*") {
+		typ = "(" + typ + ")"
+	}
+	return typ + "(" + expr + ")"
+}
+
+func (g *_Generator) genEncode(c *_Codec, expr string) {
+	switch c.kind {
+	case "Value":
+		g.printf("if _, err := binaryutil.CopyToByteStream(&bs, %s); err != nil {\nreturn bs.BytesWritten(), err\n}\n", expr)
+
+	case "Slice":
+		g.printf("if err := bs.WriteUvarint(uint64(len(%s))); err != nil {\nreturn bs.BytesWritten(), err\n}\n", expr)
+		i := g.loopVar()
+		g.printf("for %s := range %s {\n", i, expr)
+		g.genEncode(c.elem, expr+"["+i+"]")
+		g.printf("}\n")
+
+	default:
+		arg := expr
+		if g.typeText(c.typ) != c.base {
+			arg = convert(c.base, expr)
+		}
+		g.printf("if err := bs.Write%s(%s); err != nil {\nreturn bs.BytesWritten(), err\n}\n", c.kind, arg)
+	}
+}
+
+// genDecode 输出解码语句；scoped 表示当前已处于独立的语句块中。
+func (g *_Generator) genDecode(c *_Codec, target string, scoped bool) {
+	switch c.kind {
+	case "Value":
+		g.printf("if _, err := bs.WriteTo(&%s); err != nil {\nreturn bs.BytesRead(), err\n}\n", target)
+
+	case "Slice":
+		if !scoped {
+			g.printf("{\n")
+		}
+		g.printf("n, err := bs.ReadUvarint()\nif err != nil {\nreturn bs.BytesRead(), err\n}\n")
+		g.printf("if n > uint64(bs.BytesUnread()) {\nreturn bs.BytesRead(), io.ErrUnexpectedEOF\n}\n")
+		g.printf("%s = make(%s, n)\n", target, g.typeText(c.typ))
+		i := g.loopVar()
+		g.printf("for %s := range %s {\n", i, target)
+		g.genDecode(c.elem, target+"["+i+"]", true)
+		g.printf("}\n")
+		if !scoped {
+			g.printf("}\n")
+		}
+
+	default:
+		read := "Read" + c.kind
+		if c.ref {
+			read += "Ref"
+		}
+		val := "val"
+		if typ := g.typeText(c.typ); typ != c.base {
+			val = convert(typ, val)
+		}
+		if !scoped {
+			g.printf("{\n")
+		}
+		g.printf("val, err := bs.%s()\nif err != nil {\nreturn bs.BytesRead(), err\n}\n%s = %s\n", read, target, val)
+		if !scoped {
+			g.printf("}\n")
+		}
+	}
+}
+
+func (g *_Generator) genSize(st *_Struct) {
+	if !slices.ContainsFunc(st.fields, func(f *_Field) bool { return f.codec.kind == "Slice" }) {
+		terms := make([]string, 0, len(st.fields))
+		for _, field := range st.fields {
+			terms = append(terms, g.sizeExpr(field.codec, g.recv()+"."+field.name))
+		}
+		if len(terms) <= 0 {
+			terms = append(terms, "0")
+		}
+		g.printf("return %s\n", strings.Join(terms, " + "))
+		return
+	}
+
+	g.printf("size := 0\n")
+	for _, field := range st.fields {
+		g.genSizeStmt(field.codec, g.recv()+"."+field.name)
+	}
+	g.printf("return size\n")
+}
+
+func (g *_Generator) genSizeStmt(c *_Codec, expr string) {
+	if c.kind != "Slice" {
+		g.printf("size += %s\n", g.sizeExpr(c, expr))
+		return
+	}
+	g.printf("size += binaryutil.SizeofUvarint(uint64(len(%s)))\n", expr)
+	if c.elem.fixed() {
+		g.printf("size += len(%s) * %s\n", expr, g.sizeExpr(c.elem, ""))
+		return
+	}
+	i := g.loopVar()
+	g.printf("for %s := range %s {\n", i, expr)
+	g.genSizeStmt(c.elem, expr+"["+i+"]")
+	g.printf("}\n")
+}
+
+// fixed 判断编码长度是否与值无关。
+func (c *_Codec) fixed() bool {
+	switch c.kind {
+	case "Value", "Slice", "Varint", "Uvarint", "String", "Bytes":
+		return false
+	}
+	return true
+}
+
+func (g *_Generator) sizeExpr(c *_Codec, expr string) string {
+	switch c.kind {
+	case "Value":
+		return expr + ".Size()"
+	case "Varint", "Uvarint", "String", "Bytes":
+		if g.typeText(c.typ) != c.base {
+			expr = convert(c.base, expr)
+		}
+		return "binaryutil.Sizeof" + c.kind + "(" + expr + ")"
+	default:
+		return "binaryutil.Sizeof" + c.kind
+	}
+}
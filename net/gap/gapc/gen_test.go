@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerateGolden(t *testing.T) {
+	cases := []struct {
+		file   string
+		kind   _Kind
+		types  []string
+		golden string
+	}{
+		{"sample.go", kindMsg, []string{"MsgSample", "MsgCustom"}, "sample_msg.golden"},
+		{"sample.go", kindVariant, []string{"Point"}, "sample_variant.golden"},
+		{"gap/msg.go", kindMsg, []string{"MsgLocal"}, "gap/msg_msg.golden"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.golden, func(t *testing.T) {
+			file := filepath.Join("testdata", c.file)
+			output := strings.TrimSuffix(file, ".go") + "_" + string(c.kind) + ".gen.go"
+
+			src, err := loadSource(file, output, c.types)
+			if err != nil {
+				t.Fatalf("loadSource failed: %v", err)
+			}
+
+			code, err := generate(src, c.kind, true, "gapc "+string(c.kind)+" --types="+strings.Join(c.types, ","))
+			if err != nil {
+				t.Fatalf("generate failed: %v\n%s", err, code)
+			}
+
+			golden := filepath.Join("testdata", c.golden)
+			if *update {
+				if err := os.WriteFile(golden, code, 0644); err != nil {
+					t.Fatalf("write golden file failed: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file failed: %v", err)
+			}
+			if !bytes.Equal(code, want) {
+				t.Fatalf("generated code differs from %s, rerun with -update after checking the diff:\n%s", golden, code)
+			}
+		})
+	}
+}
+
+func TestLoadSourceErrors(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "bad.go")
+
+	src := `package bad
+
+type Generic[T any] struct{ V T }
+type NotStruct int
+type Array struct{ V [4]int }
+type Map struct{ V map[string]int }
+type BadTag struct{ V int32 ` + "`gapc:\"int\"`" + ` }
+type BadRef struct{ V int32 ` + "`gapc:\",ref\"`" + ` }
+type BadOption struct{ V string ` + "`gapc:\",copy\"`" + ` }
+`
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatalf("write source failed: %v", err)
+	}
+
+	cases := map[string]string{
+		"Generic":   "generic types are not supported",
+		"NotStruct": "is not a struct",
+		"Array":     "arrays are not supported",
+		"Map":       "unsupported field type",
+		"BadTag":    "unknown gapc tag encoding",
+		"BadRef":    "ref only applies to string and bytes",
+		"BadOption": "unknown gapc tag option",
+		"Missing":   "not found",
+	}
+
+	for typeName, want := range cases {
+		t.Run(typeName, func(t *testing.T) {
+			_, err := loadSource(file, filepath.Join(dir, "bad_msg.gen.go"), []string{typeName})
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Fatalf("expected error containing %q, got %v", want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func main() {
+	cmd := &cobra.Command{
+		Use:   "gapc",
+		Short: "GAP 消息与 variant 自定义值编解码代码生成工具。",
+		CompletionOptions: cobra.CompletionOptions{
+			DisableDefaultCmd: true,
+		},
+	}
+	cmd.PersistentFlags().String("file", os.Getenv("GOFILE"), "source file declaring the types")
+	cmd.PersistentFlags().String("types", "", "comma separated struct type names")
+	cmd.PersistentFlags().String("output", "", "output file, default is <file>_<kind>.gen.go")
+	cmd.PersistentFlags().Bool("declare", true, "generate init function declaring the types")
+
+	cmd.AddCommand(newKindCmd(kindMsg, "生成 GAP 自定义消息的编解码方法。"))
+	cmd.AddCommand(newKindCmd(kindVariant, "生成 variant 自定义值的编解码方法。"))
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newKindCmd(kind _Kind, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   string(kind),
+		Short: short,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file := viper.GetString("file")
+			if file == "" {
+				return fmt.Errorf("--file is required when not running under go generate")
+			}
+
+			var typeNames []string
+			for name := range strings.SplitSeq(viper.GetString("types"), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					typeNames = append(typeNames, name)
+				}
+			}
+			if len(typeNames) <= 0 {
+				return fmt.Errorf("--types is required")
+			}
+
+			output := viper.GetString("output")
+			if output == "" {
+				output = strings.TrimSuffix(file, filepath.Ext(file)) + "_" + string(kind) + ".gen.go"
+			}
+
+			src, err := loadSource(file, output, typeNames)
+			if err != nil {
+				return err
+			}
+
+			code, err := generate(src, kind, viper.GetBool("declare"), "gapc "+strings.Join(os.Args[1:], " "))
+			if err != nil {
+				return err
+			}
+
+			return os.WriteFile(output, code, 0644)
+		},
+	}
+}
@@ -0,0 +1,314 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// _Source 是待生成代码的源文件解析结果。
+type _Source struct {
+	fset    *token.FileSet
+	file    *ast.File
+	header  []*ast.CommentGroup
+	structs []*_Struct
+	methods map[string][]string
+}
+
+// _Struct 是待生成编解码方法的结构体。
+type _Struct struct {
+	name   string
+	fields []*_Field
+}
+
+// _Field 是参与编码的结构体字段。
+type _Field struct {
+	name  string
+	codec *_Codec
+}
+
+// _Codec 描述一个值的编码方式。
+type _Codec struct {
+	kind string   // 编码方式，对应 binaryutil.ByteStream 的 Write*/Read* 方法名后缀，或 Value、Slice。
+	base string   // 编码使用的基础类型。
+	typ  ast.Expr // 值的声明类型。
+	ref  bool     // 解码时是否引用输入缓冲区。
+	elem *_Codec  // 切片元素的编码方式。
+}
+
+var fixedKinds = map[string]struct {
+	kind string
+	base string
+}{
+	"bool":    {"Bool", "bool"},
+	"int8":    {"Int8", "int8"},
+	"int16":   {"Int16", "int16"},
+	"int32":   {"Int32", "int32"},
+	"int64":   {"Int64", "int64"},
+	"uint8":   {"Uint8", "uint8"},
+	"uint16":  {"Uint16", "uint16"},
+	"uint32":  {"Uint32", "uint32"},
+	"uint64":  {"Uint64", "uint64"},
+	"byte":    {"Byte", "byte"},
+	"float32": {"Float", "float32"},
+	"float64": {"Double", "float64"},
+	"varint":  {"Varint", "int64"},
+	"uvarint": {"Uvarint", "uint64"},
+	"int":     {"Varint", "int64"},
+	"uint":    {"Uvarint", "uint64"},
+	"string":  {"String", "string"},
+	"bytes":   {"Bytes", "[]byte"},
+}
+
+func loadSource(file, output string, typeNames []string) (*_Source, error) {
+	src := &_Source{
+		fset:    token.NewFileSet(),
+		methods: map[string][]string{},
+	}
+
+	var err error
+	src.file, err = parser.ParseFile(src.fset, file, nil, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cg := range src.file.Comments {
+		if cg.End() < src.file.Package && cg != src.file.Doc {
+			src.header = append(src.header, cg)
+		}
+	}
+
+	if err := src.scanMethods(filepath.Dir(file), file, output); err != nil {
+		return nil, err
+	}
+
+	for _, name := range typeNames {
+		st, err := src.parseStruct(name)
+		if err != nil {
+			return nil, err
+		}
+		src.structs = append(src.structs, st)
+	}
+
+	return src, nil
+}
+
+// scanMethods 收集同目录其他源文件中已声明的方法，避免重复生成。
+func (src *_Source) scanMethods(dir, file, output string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		if path == filepath.Join(dir, filepath.Base(output)) {
+			continue
+		}
+
+		f := src.file
+		if path != filepath.Join(dir, filepath.Base(file)) {
+			f, err = parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return err
+			}
+			if f.Name.Name != src.file.Name.Name {
+				continue
+			}
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) <= 0 {
+				continue
+			}
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				src.methods[ident.Name] = append(src.methods[ident.Name], fn.Name.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (src *_Source) hasMethod(typeName, method string) bool {
+	return slices.Contains(src.methods[typeName], method)
+}
+
+func (src *_Source) parseStruct(name string) (*_Struct, error) {
+	for _, decl := range src.file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if ts.Name.Name != name {
+				continue
+			}
+			if ts.TypeParams != nil {
+				return nil, fmt.Errorf("type %s: generic types are not supported", name)
+			}
+			stt, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				return nil, fmt.Errorf("type %s is not a struct", name)
+			}
+			return src.parseFields(name, stt)
+		}
+	}
+	return nil, fmt.Errorf("type %s not found in %s", name, src.fset.File(src.file.Pos()).Name())
+}
+
+func (src *_Source) parseFields(name string, stt *ast.StructType) (*_Struct, error) {
+	st := &_Struct{name: name}
+
+	for _, field := range stt.Fields.List {
+		var tag string
+		if field.Tag != nil {
+			unquoted, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("type %s: invalid field tag %s", name, field.Tag.Value)
+			}
+			tag = reflect.StructTag(unquoted).Get("gapc")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		enc, opts, _ := strings.Cut(tag, ",")
+		ref := false
+		for opt := range strings.SplitSeq(opts, ",") {
+			switch opt {
+			case "":
+			case "ref":
+				ref = true
+			default:
+				return nil, fmt.Errorf("type %s: unknown gapc tag option %q", name, opt)
+			}
+		}
+
+		var names []string
+		if len(field.Names) > 0 {
+			for _, ident := range field.Names {
+				if ident.Name != "_" {
+					names = append(names, ident.Name)
+				}
+			}
+		} else {
+			names = append(names, embeddedName(field.Type))
+		}
+
+		for _, fieldName := range names {
+			codec, err := resolveCodec(field.Type, enc, ref)
+			if err != nil {
+				return nil, fmt.Errorf("type %s field %s: %w", name, fieldName, err)
+			}
+			st.fields = append(st.fields, &_Field{name: fieldName, codec: codec})
+		}
+	}
+
+	return st, nil
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+func resolveCodec(typ ast.Expr, enc string, ref bool) (*_Codec, error) {
+	codec := &_Codec{typ: typ, ref: ref}
+	tagged := enc != ""
+
+	switch t := typ.(type) {
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return nil, fmt.Errorf("arrays are not supported")
+		}
+		if ident, ok := t.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") && enc == "" {
+			enc = "bytes"
+			break
+		}
+		if enc == "bytes" {
+			break
+		}
+		elem, err := resolveCodec(t.Elt, enc, ref)
+		if err != nil {
+			return nil, err
+		}
+		codec.kind = "Slice"
+		codec.elem = elem
+		return codec, nil
+
+	case *ast.Ident:
+		if enc == "" {
+			if _, ok := fixedKinds[t.Name]; ok {
+				enc = t.Name
+			}
+		}
+
+	case *ast.SelectorExpr:
+
+	default:
+		return nil, fmt.Errorf("unsupported field type, declare its encoding with a gapc tag or use a named type")
+	}
+
+	if enc == "" || enc == "value" {
+		codec.kind = "Value"
+		return codec, nil
+	}
+
+	fixed, ok := fixedKinds[enc]
+	if !ok || (tagged && (enc == "int" || enc == "uint")) {
+		return nil, fmt.Errorf("unknown gapc tag encoding %q", enc)
+	}
+	codec.kind = fixed.kind
+	codec.base = fixed.base
+
+	if ref && codec.kind != "String" && codec.kind != "Bytes" {
+		return nil, fmt.Errorf("gapc tag option ref only applies to string and bytes")
+	}
+
+	return codec, nil
+}
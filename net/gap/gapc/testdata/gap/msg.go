@@ -0,0 +1,9 @@
+package gap
+
+import "git.golaxy.org/framework/net/gap/variant"
+
+// MsgLocal 位于 gap 包内，生成代码不导入 gap 包本身。
+type MsgLocal struct {
+	Args  variant.Array
+	Flags []bool
+}
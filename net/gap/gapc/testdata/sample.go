@@ -0,0 +1,45 @@
+// 该注释位于包声明之前，作为文件头复制到生成代码中。
+
+// Package sample 是 gapc 生成代码的测试输入。
+package sample
+
+import (
+	"git.golaxy.org/framework/net/gap"
+	gv "git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/correlation"
+)
+
+// Level 是底层为基础类型的具名类型。
+type Level uint16
+
+// MsgSample 覆盖类型转换、导入与嵌套切片。
+type MsgSample struct {
+	CorrID  correlation.ID `gapc:"uvarint"`
+	Level   Level          `gapc:"uint16"`
+	Count   int32          `gapc:"varint"`
+	Total   int
+	Name    string `gapc:",ref"`
+	Data    []byte
+	Args    gv.Array
+	Levels  []Level `gapc:"uvarint"`
+	Matrix  [][]int32
+	Tags    [][]string
+	Chains  []gv.CallChain
+	Skipped string `gapc:"-"`
+}
+
+// MsgCustom 已声明 MsgID，不再生成。
+type MsgCustom struct {
+	ID uint32
+}
+
+// MsgID 返回自定义的消息 ID。
+func (MsgCustom) MsgID() gap.MsgID {
+	return gap.MsgID_Customize + 1
+}
+
+// Point 是 variant 自定义值。
+type Point struct {
+	X, Y  float32
+	Label string
+}
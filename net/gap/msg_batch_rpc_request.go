@@ -0,0 +1,159 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"io"
+
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/binaryutil"
+	"git.golaxy.org/framework/utils/correlation"
+)
+
+// BatchRPCCall 是批量 RPC 请求中的单个调用。
+type BatchRPCCall struct {
+	Path []byte        // 已编码调用路径；解码时引用输入缓冲区。
+	Args variant.Array // 调用参数。
+}
+
+// Read 将单个调用编码到 p。
+func (c BatchRPCCall) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteBytes(c.Path); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if _, err := binaryutil.CopyToByteStream(&bs, c.Args); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码单个调用。
+func (c *BatchRPCCall) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	var err error
+
+	c.Path, err = bs.ReadBytesRef()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	if _, err = bs.WriteTo(&c.Args); err != nil {
+		return bs.BytesRead(), err
+	}
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回单个调用编码后的字节数。
+func (c BatchRPCCall) Size() int {
+	return binaryutil.SizeofBytes(c.Path) + c.Args.Size()
+}
+
+// MsgBatchRPCRequest 表示发往同一节点、合并为一条消息的多个 RPC 请求，共用关联 ID、调用链与追踪上下文。
+type MsgBatchRPCRequest struct {
+	CorrID      correlation.ID    // 用于匹配响应与 Future 的关联 ID。
+	CallChain   variant.CallChain // 调用来源链。
+	Calls       []BatchRPCCall    // 按发起顺序排列的调用。
+	TraceParent string            // W3C traceparent 追踪上下文；为空时不编码。
+}
+
+// Read 将批量 RPC 请求编码到 p。
+func (m MsgBatchRPCRequest) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteUvarint(uint64(m.CorrID)); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if _, err := binaryutil.CopyToByteStream(&bs, m.CallChain); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteUvarint(uint64(len(m.Calls))); err != nil {
+		return bs.BytesWritten(), err
+	}
+	for i := range m.Calls {
+		if _, err := binaryutil.CopyToByteStream(&bs, m.Calls[i]); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
+	if m.TraceParent != "" {
+		if err := bs.WriteString(m.TraceParent); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码批量 RPC 请求。
+func (m *MsgBatchRPCRequest) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	var err error
+
+	corrID, err := bs.ReadUvarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+	m.CorrID = correlation.ID(corrID)
+
+	if _, err = bs.WriteTo(&m.CallChain); err != nil {
+		return bs.BytesRead(), err
+	}
+
+	l, err := bs.ReadUvarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Calls = make([]BatchRPCCall, 0, min(l, 256))
+
+	for i := uint64(0); i < l; i++ {
+		var call BatchRPCCall
+		if _, err := bs.WriteTo(&call); err != nil {
+			return bs.BytesRead(), err
+		}
+		m.Calls = append(m.Calls, call)
+	}
+
+	m.TraceParent = ""
+	if bs.BytesUnread() > 0 {
+		m.TraceParent, err = bs.ReadString()
+		if err != nil {
+			return bs.BytesRead(), err
+		}
+	}
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回批量 RPC 请求编码后的字节数。
+func (m MsgBatchRPCRequest) Size() int {
+	n := binaryutil.SizeofUvarint(uint64(m.CorrID)) + m.CallChain.Size() + binaryutil.SizeofUvarint(uint64(len(m.Calls)))
+	for i := range m.Calls {
+		n += m.Calls[i].Size()
+	}
+	if m.TraceParent != "" {
+		n += binaryutil.SizeofString(m.TraceParent)
+	}
+	return n
+}
+
+// MsgID 返回批量 RPC 请求的内置类型 ID。
+func (MsgBatchRPCRequest) MsgID() MsgID {
+	return MsgID_BatchRPC_Request
+}
@@ -0,0 +1,99 @@
+package gap
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"git.golaxy.org/framework/net/gap/variant"
+)
+
+func TestMsgBatchRPCRequestRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  MsgBatchRPCRequest
+	}{
+		{"request", MsgBatchRPCRequest{
+			CorrID:    42,
+			CallChain: variant.CallChain{{Svc: "lobby", Addr: "node-a", Timestamp: time.UnixMilli(1000)}},
+			Calls: []BatchRPCCall{
+				{Path: []byte("E\x00Bag\x00Open\x00"), Args: mustArray(t, 1, "a")},
+				{Path: []byte("S\x00\x00Ping\x00"), Args: mustArray(t)},
+				{Path: []byte("E\x00Bag\x00Close\x00"), Args: mustArray(t, true)},
+			},
+			TraceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		}},
+		{"no trace", MsgBatchRPCRequest{
+			CorrID: 1,
+			Calls:  []BatchRPCCall{{Path: []byte("S\x00\x00Ping\x00"), Args: mustArray(t, 1)}},
+		}},
+		{"empty", MsgBatchRPCRequest{CorrID: 2}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got MsgBatchRPCRequest
+			data := roundTrip(t, c.msg, &got)
+
+			if got.CorrID != c.msg.CorrID || got.TraceParent != c.msg.TraceParent || len(got.CallChain) != len(c.msg.CallChain) {
+				t.Fatalf("unexpected request: %+v", got)
+			}
+			if len(got.Calls) != len(c.msg.Calls) {
+				t.Fatalf("got %d calls, want %d", len(got.Calls), len(c.msg.Calls))
+			}
+			for i := range c.msg.Calls {
+				if !bytes.Equal(got.Calls[i].Path, c.msg.Calls[i].Path) || len(got.Calls[i].Args.Items) != len(c.msg.Calls[i].Args.Items) {
+					t.Fatalf("call %d = %+v, want %+v", i, got.Calls[i], c.msg.Calls[i])
+				}
+			}
+			assertSameEncoding(t, data, got)
+		})
+	}
+}
+
+func TestMsgBatchRPCRequestTruncated(t *testing.T) {
+	data := mustMarshal(t, MsgBatchRPCRequest{
+		CorrID: 7,
+		Calls: []BatchRPCCall{
+			{Path: []byte("E\x00Bag\x00Open\x00"), Args: mustArray(t, 1)},
+			{Path: []byte("E\x00Bag\x00Close\x00"), Args: mustArray(t, 2)},
+		},
+	})
+
+	// 缺少可选的 TraceParent 属于合法的旧版本消息，因此只截断到最后一个调用的参数之前。
+	argsSize := mustArray(t, 2).Size()
+	assertTruncatedFails(t, data[:len(data)-argsSize+1], func() Msg { return &MsgBatchRPCRequest{} })
+}
+
+func TestMsgBatchRPCReplyRoundTrip(t *testing.T) {
+	cause := errors.New("call failed")
+
+	msg := MsgBatchRPCReply{
+		CorrID: 9,
+		Results: []BatchRPCResult{
+			{Rets: mustArray(t, "ok", 3)},
+			{Error: *variant.NewError(cause)},
+			{},
+		},
+	}
+
+	var got MsgBatchRPCReply
+	data := roundTrip(t, msg, &got)
+
+	if got.CorrID != msg.CorrID || len(got.Results) != len(msg.Results) {
+		t.Fatalf("unexpected reply: %+v", got)
+	}
+	for i := range msg.Results {
+		want, result := &msg.Results[i], &got.Results[i]
+		if result.Error.OK() != want.Error.OK() || result.Error.Message != want.Error.Message {
+			t.Fatalf("result %d error = %+v, want %+v", i, result.Error, want.Error)
+		}
+		if len(result.Rets.Items) != len(want.Rets.Items) {
+			t.Fatalf("result %d rets = %+v, want %+v", i, result.Rets, want.Rets)
+		}
+	}
+	assertSameEncoding(t, data, got)
+
+	assertTruncatedFails(t, data, func() Msg { return &MsgBatchRPCReply{} })
+}
@@ -0,0 +1,108 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"io"
+
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+// MsgFragment 携带一个已编码 GAP 消息包的连续片段；同一 FragID 的全部分片按 Offset 拼接后还原原始消息包。
+type MsgFragment struct {
+	FragID   uint64 // 分片组 ID，在发送方地址内唯一。
+	Index    uint32 // 分片序号，从 0 开始。
+	Total    uint32 // 分片总数。
+	Offset   uint32 // 片段在原始消息包中的字节偏移。
+	TotalLen uint32 // 原始消息包的字节数。
+	Data     []byte // 片段内容；解码时引用输入缓冲区。
+}
+
+// Read 将分片消息编码到 p。
+func (m MsgFragment) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteUvarint(m.FragID); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteUint32(m.Index); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteUint32(m.Total); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteUint32(m.Offset); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteUint32(m.TotalLen); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteBytes(m.Data); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码分片消息。
+func (m *MsgFragment) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	var err error
+
+	m.FragID, err = bs.ReadUvarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Index, err = bs.ReadUint32()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Total, err = bs.ReadUint32()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Offset, err = bs.ReadUint32()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.TotalLen, err = bs.ReadUint32()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Data, err = bs.ReadBytesRef()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回分片消息编码后的字节数。
+func (m MsgFragment) Size() int {
+	return binaryutil.SizeofUvarint(m.FragID) + binaryutil.SizeofUint32*4 + binaryutil.SizeofBytes(m.Data)
+}
+
+// MsgID 返回分片消息的内置类型 ID。
+func (MsgFragment) MsgID() MsgID {
+	return MsgID_Fragment
+}
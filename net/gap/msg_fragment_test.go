@@ -0,0 +1,44 @@
+package gap
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestMsgFragmentRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  MsgFragment
+	}{
+		{"first", MsgFragment{FragID: 1, Index: 0, Total: 3, Offset: 0, TotalLen: 10, Data: []byte("0123")}},
+		{"last", MsgFragment{FragID: 1, Index: 2, Total: 3, Offset: 8, TotalLen: 10, Data: []byte("89")}},
+		{"large ids", MsgFragment{FragID: math.MaxUint64, Index: math.MaxUint32 - 1, Total: math.MaxUint32, Offset: math.MaxUint32 - 1, TotalLen: math.MaxUint32, Data: []byte{0}}},
+		{"empty data", MsgFragment{FragID: 2, Total: 1, TotalLen: 1}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got MsgFragment
+			data := roundTrip(t, c.msg, &got)
+
+			if got.FragID != c.msg.FragID || got.Index != c.msg.Index || got.Total != c.msg.Total ||
+				got.Offset != c.msg.Offset || got.TotalLen != c.msg.TotalLen || !bytes.Equal(got.Data, c.msg.Data) {
+				t.Fatalf("got %+v, want %+v", got, c.msg)
+			}
+			assertSameEncoding(t, data, got)
+			assertTruncatedFails(t, data, func() Msg { return &MsgFragment{} })
+		})
+	}
+}
+
+func TestMsgFragmentDataReferencesInput(t *testing.T) {
+	var got MsgFragment
+	data := roundTrip(t, MsgFragment{FragID: 1, Total: 1, TotalLen: 4, Data: []byte("abcd")}, &got)
+
+	// Data 为编码的最后一个字段，位于缓冲区末尾。
+	data[len(data)-1] = 'x'
+	if string(got.Data) != "abcx" {
+		t.Fatalf("decoded Data does not reference the input buffer: %q", got.Data)
+	}
+}
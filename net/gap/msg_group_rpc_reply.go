@@ -0,0 +1,142 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"io"
+
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/binaryutil"
+	"git.golaxy.org/framework/utils/correlation"
+)
+
+// GroupRPCResult 是批量 RPC 中单个实体的调用结果。
+type GroupRPCResult struct {
+	EntityID uid.ID        // 目标实体 ID。
+	Rets     variant.Array // 调用返回值。
+	Error    variant.Error // 调用错误；OK 为 true 时表示成功。
+}
+
+// Read 将单个实体的调用结果编码到 p。
+func (r GroupRPCResult) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteString(r.EntityID.String()); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if _, err := binaryutil.CopyToByteStream(&bs, r.Rets); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if _, err := binaryutil.CopyToByteStream(&bs, r.Error); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码单个实体的调用结果。
+func (r *GroupRPCResult) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+
+	id, err := bs.ReadString()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+	r.EntityID = uid.From(id)
+
+	if _, err = bs.WriteTo(&r.Rets); err != nil {
+		return bs.BytesRead(), err
+	}
+
+	if _, err = bs.WriteTo(&r.Error); err != nil {
+		return bs.BytesRead(), err
+	}
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回单个实体的调用结果编码后的字节数。
+func (r GroupRPCResult) Size() int {
+	return binaryutil.SizeofString(r.EntityID.String()) + r.Rets.Size() + r.Error.Size()
+}
+
+// MsgGroupRPCReply 表示批量 RPC 请求的响应，按实体汇总调用结果。
+type MsgGroupRPCReply struct {
+	CorrID  correlation.ID   // 对应请求的关联 ID。
+	Results []GroupRPCResult // 各目标实体的调用结果。
+}
+
+// Read 将批量 RPC 响应编码到 p。
+func (m MsgGroupRPCReply) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteUvarint(uint64(m.CorrID)); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteUvarint(uint64(len(m.Results))); err != nil {
+		return bs.BytesWritten(), err
+	}
+	for i := range m.Results {
+		if _, err := binaryutil.CopyToByteStream(&bs, m.Results[i]); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码批量 RPC 响应。
+func (m *MsgGroupRPCReply) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+
+	corrID, err := bs.ReadUvarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+	m.CorrID = correlation.ID(corrID)
+
+	l, err := bs.ReadUvarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Results = make([]GroupRPCResult, 0, min(l, 256))
+
+	for i := uint64(0); i < l; i++ {
+		var result GroupRPCResult
+		if _, err := bs.WriteTo(&result); err != nil {
+			return bs.BytesRead(), err
+		}
+		m.Results = append(m.Results, result)
+	}
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回批量 RPC 响应编码后的字节数。
+func (m MsgGroupRPCReply) Size() int {
+	n := binaryutil.SizeofUvarint(uint64(m.CorrID)) + binaryutil.SizeofUvarint(uint64(len(m.Results)))
+	for i := range m.Results {
+		n += m.Results[i].Size()
+	}
+	return n
+}
+
+// MsgID 返回批量 RPC 响应的内置类型 ID。
+func (MsgGroupRPCReply) MsgID() MsgID {
+	return MsgID_GroupRPC_Reply
+}
@@ -0,0 +1,139 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"io"
+
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/binaryutil"
+	"git.golaxy.org/framework/utils/correlation"
+)
+
+// MsgGroupRPCRequest 表示对同一节点上多个实体发起的同一调用，调用路径中的实体 ID 被 EntityIDs 取代。
+type MsgGroupRPCRequest struct {
+	CorrID      correlation.ID    // 用于匹配响应与 Future 的关联 ID；为零时表示单向通知，被调方不回复。
+	CallChain   variant.CallChain // 调用来源链。
+	Path        []byte            // 已编码调用路径；解码时引用输入缓冲区。
+	EntityIDs   []uid.ID          // 目标实体 ID。
+	Args        variant.Array     // 调用参数，全部目标实体共用。
+	TraceParent string            // W3C traceparent 追踪上下文；为空时不编码。
+}
+
+// Read 将批量 RPC 请求编码到 p。
+func (m MsgGroupRPCRequest) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteUvarint(uint64(m.CorrID)); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if _, err := binaryutil.CopyToByteStream(&bs, m.CallChain); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteBytes(m.Path); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteUvarint(uint64(len(m.EntityIDs))); err != nil {
+		return bs.BytesWritten(), err
+	}
+	for _, id := range m.EntityIDs {
+		if err := bs.WriteString(id.String()); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
+	if _, err := binaryutil.CopyToByteStream(&bs, m.Args); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if m.TraceParent != "" {
+		if err := bs.WriteString(m.TraceParent); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码批量 RPC 请求。
+func (m *MsgGroupRPCRequest) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	var err error
+
+	corrID, err := bs.ReadUvarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+	m.CorrID = correlation.ID(corrID)
+
+	if _, err = bs.WriteTo(&m.CallChain); err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Path, err = bs.ReadBytesRef()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	l, err := bs.ReadUvarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.EntityIDs = make([]uid.ID, 0, min(l, 256))
+
+	for i := uint64(0); i < l; i++ {
+		id, err := bs.ReadString()
+		if err != nil {
+			return bs.BytesRead(), err
+		}
+		m.EntityIDs = append(m.EntityIDs, uid.From(id))
+	}
+
+	if _, err = bs.WriteTo(&m.Args); err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.TraceParent = ""
+	if bs.BytesUnread() > 0 {
+		m.TraceParent, err = bs.ReadString()
+		if err != nil {
+			return bs.BytesRead(), err
+		}
+	}
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回批量 RPC 请求编码后的字节数。
+func (m MsgGroupRPCRequest) Size() int {
+	n := binaryutil.SizeofUvarint(uint64(m.CorrID)) + m.CallChain.Size() + binaryutil.SizeofBytes(m.Path)
+	n += binaryutil.SizeofUvarint(uint64(len(m.EntityIDs)))
+	for _, id := range m.EntityIDs {
+		n += binaryutil.SizeofString(id.String())
+	}
+	n += m.Args.Size()
+	if m.TraceParent != "" {
+		n += binaryutil.SizeofString(m.TraceParent)
+	}
+	return n
+}
+
+// MsgID 返回批量 RPC 请求的内置类型 ID。
+func (MsgGroupRPCRequest) MsgID() MsgID {
+	return MsgID_GroupRPC_Request
+}
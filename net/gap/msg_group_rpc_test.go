@@ -0,0 +1,104 @@
+package gap
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/net/gap/variant"
+)
+
+func TestMsgGroupRPCRequestRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  MsgGroupRPCRequest
+	}{
+		{"request", MsgGroupRPCRequest{
+			CorrID:      42,
+			CallChain:   variant.CallChain{{Svc: "lobby", Addr: "node-a", Timestamp: time.UnixMilli(1000)}},
+			Path:        []byte("E\x00Bag\x00Open\x00"),
+			EntityIDs:   []uid.ID{uid.From("e1"), uid.From("e2"), uid.From("e3")},
+			Args:        mustArray(t, 1, "a"),
+			TraceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		}},
+		{"notify", MsgGroupRPCRequest{
+			Path:      []byte("E\x00Bag\x00Open\x00"),
+			EntityIDs: []uid.ID{uid.From("e1")},
+			Args:      mustArray(t),
+		}},
+		{"no entities", MsgGroupRPCRequest{
+			CorrID: 1,
+			Path:   []byte("E\x00Bag\x00Open\x00"),
+			Args:   mustArray(t, true),
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got MsgGroupRPCRequest
+			data := roundTrip(t, c.msg, &got)
+
+			if got.CorrID != c.msg.CorrID || got.TraceParent != c.msg.TraceParent || !bytes.Equal(got.Path, c.msg.Path) {
+				t.Fatalf("unexpected request: %+v", got)
+			}
+			if !slices.Equal(got.EntityIDs, c.msg.EntityIDs) {
+				t.Fatalf("entity ids = %v, want %v", got.EntityIDs, c.msg.EntityIDs)
+			}
+			if len(got.Args.Items) != len(c.msg.Args.Items) || len(got.CallChain) != len(c.msg.CallChain) {
+				t.Fatalf("unexpected args or call chain: %+v", got)
+			}
+			assertSameEncoding(t, data, got)
+		})
+	}
+}
+
+func TestMsgGroupRPCRequestTruncated(t *testing.T) {
+	data := mustMarshal(t, MsgGroupRPCRequest{
+		CorrID:    7,
+		Path:      []byte("E\x00Bag\x00Open\x00"),
+		EntityIDs: []uid.ID{uid.From("e1"), uid.From("e2")},
+		Args:      mustArray(t, 1),
+	})
+
+	// 缺少可选的 TraceParent 属于合法的旧版本消息，因此只截断到参数之前。
+	argsSize := mustArray(t, 1).Size()
+	assertTruncatedFails(t, data[:len(data)-argsSize+1], func() Msg { return &MsgGroupRPCRequest{} })
+}
+
+func TestMsgGroupRPCReplyRoundTrip(t *testing.T) {
+	cause := errors.New("entity failed")
+
+	msg := MsgGroupRPCReply{
+		CorrID: 9,
+		Results: []GroupRPCResult{
+			{EntityID: uid.From("e1"), Rets: mustArray(t, "ok", 3)},
+			{EntityID: uid.From("e2"), Error: *variant.NewError(cause)},
+			{EntityID: uid.From("e3")},
+		},
+	}
+
+	var got MsgGroupRPCReply
+	data := roundTrip(t, msg, &got)
+
+	if got.CorrID != msg.CorrID || len(got.Results) != len(msg.Results) {
+		t.Fatalf("unexpected reply: %+v", got)
+	}
+	for i := range msg.Results {
+		want, result := &msg.Results[i], &got.Results[i]
+		if result.EntityID != want.EntityID {
+			t.Fatalf("result %d entity = %v, want %v", i, result.EntityID, want.EntityID)
+		}
+		if result.Error.OK() != want.Error.OK() || result.Error.Message != want.Error.Message {
+			t.Fatalf("result %d error = %+v, want %+v", i, result.Error, want.Error)
+		}
+		if len(result.Rets.Items) != len(want.Rets.Items) {
+			t.Fatalf("result %d rets = %+v, want %+v", i, result.Rets, want.Rets)
+		}
+	}
+	assertSameEncoding(t, data, got)
+
+	assertTruncatedFails(t, data, func() Msg { return &MsgGroupRPCReply{} })
+}
@@ -28,9 +28,12 @@ import (
 
 // MsgOnewayRPC 表示无需响应的 RPC 通知。
 type MsgOnewayRPC struct {
-	CallChain variant.CallChain // 调用来源链。
-	Path      []byte            // 已编码调用路径；解码时引用输入缓冲区。
-	Args      variant.Array     // 调用参数。
+	CallChain   variant.CallChain // 调用来源链。
+	Path        []byte            // 已编码调用路径；解码时引用输入缓冲区。
+	Args        variant.Array     // 调用参数。
+	TraceParent string            // W3C traceparent 追踪上下文；为空时不编码，兼容旧版本消息。
+	ArgsCodec   ArgsCodec         // 参数编解码器；为 ArgsCodec_Variant 时不编码，非默认值时 TraceParent 即使为空也会编码。
+	ArgsData    []byte            // 以 ArgsCodec 编码的参数，此时编码时忽略 Args；解码时引用输入缓冲区，并还原到 Args。
 }
 
 // Read 将 RPC 通知编码到 p。
@@ -42,9 +45,22 @@ func (m MsgOnewayRPC) Read(p []byte) (int, error) {
 	if err := bs.WriteBytes(m.Path); err != nil {
 		return bs.BytesWritten(), err
 	}
-	if _, err := binaryutil.CopyToByteStream(&bs, m.Args); err != nil {
+	if _, err := binaryutil.CopyToByteStream(&bs, m.wireArgs()); err != nil {
 		return bs.BytesWritten(), err
 	}
+	if m.TraceParent != "" || m.ArgsCodec != ArgsCodec_Variant {
+		if err := bs.WriteString(m.TraceParent); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
+	if m.ArgsCodec != ArgsCodec_Variant {
+		if err := bs.WriteUint8(uint8(m.ArgsCodec)); err != nil {
+			return bs.BytesWritten(), err
+		}
+		if err := bs.WriteBytes(m.ArgsData); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
 	return bs.BytesWritten(), io.EOF
 }
 
@@ -66,15 +82,48 @@ func (m *MsgOnewayRPC) Write(p []byte) (int, error) {
 		return bs.BytesRead(), err
 	}
 
+	m.TraceParent = ""
+	if bs.BytesUnread() > 0 {
+		m.TraceParent, err = bs.ReadString()
+		if err != nil {
+			return bs.BytesRead(), err
+		}
+	}
+
+	m.ArgsCodec, m.ArgsData, err = readArgsCodec(&bs)
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Args, err = DecodeArgs(m.ArgsCodec, m.Args, m.ArgsData)
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
 	return bs.BytesRead(), nil
 }
 
 // Size 返回 RPC 通知编码后的字节数。
 func (m MsgOnewayRPC) Size() int {
-	return m.CallChain.Size() + binaryutil.SizeofBytes(m.Path) + m.Args.Size()
+	n := m.CallChain.Size() + binaryutil.SizeofBytes(m.Path) + m.wireArgs().Size()
+	if m.TraceParent != "" || m.ArgsCodec != ArgsCodec_Variant {
+		n += binaryutil.SizeofString(m.TraceParent)
+	}
+	if m.ArgsCodec != ArgsCodec_Variant {
+		n += binaryutil.SizeofUint8 + binaryutil.SizeofBytes(m.ArgsData)
+	}
+	return n
 }
 
 // MsgID 返回单向 RPC 消息的内置类型 ID。
 func (MsgOnewayRPC) MsgID() MsgID {
 	return MsgID_OnewayRPC
 }
+
+// wireArgs 返回 Args 字段的编码内容；使用其他参数编解码器时参数以 ArgsData 传输，Args 字段编码为空数组。
+func (m MsgOnewayRPC) wireArgs() variant.Array {
+	if m.ArgsCodec != ArgsCodec_Variant {
+		return variant.Array{}
+	}
+	return m.Args
+}
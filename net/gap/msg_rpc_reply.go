@@ -31,7 +31,7 @@ import (
 type MsgRPCReply struct {
 	CorrID correlation.ID // 对应请求的关联 ID。
 	Rets   variant.Array  // 调用返回值。
-	Error  variant.Error  // 调用错误；OK 为 true 时表示成功。
+	Error  variant.Error  // 调用错误；OK 为 true 时表示成功。Error.Details 非空时作为尾部字段编码，兼容旧版本消息。
 }
 
 // Read 将 RPC 响应编码到 p。
@@ -46,6 +46,11 @@ func (m MsgRPCReply) Read(p []byte) (int, error) {
 	if _, err := binaryutil.CopyToByteStream(&bs, m.Error); err != nil {
 		return bs.BytesWritten(), err
 	}
+	if m.Error.Details.Entries.Len() > 0 {
+		if _, err := binaryutil.CopyToByteStream(&bs, m.Error.Details); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
 	return bs.BytesWritten(), io.EOF
 }
 
@@ -68,12 +73,23 @@ func (m *MsgRPCReply) Write(p []byte) (int, error) {
 		return bs.BytesRead(), err
 	}
 
+	m.Error.Details = variant.Map{}
+	if bs.BytesUnread() > 0 {
+		if _, err = bs.WriteTo(&m.Error.Details); err != nil {
+			return bs.BytesRead(), err
+		}
+	}
+
 	return bs.BytesRead(), nil
 }
 
 // Size 返回 RPC 响应编码后的字节数。
 func (m MsgRPCReply) Size() int {
-	return binaryutil.SizeofUvarint(uint64(m.CorrID)) + m.Rets.Size() + m.Error.Size()
+	n := binaryutil.SizeofUvarint(uint64(m.CorrID)) + m.Rets.Size() + m.Error.Size()
+	if m.Error.Details.Entries.Len() > 0 {
+		n += m.Error.Details.Size()
+	}
+	return n
 }
 
 // MsgID 返回 RPC 响应的内置类型 ID。
@@ -29,10 +29,14 @@ import (
 
 // MsgRPCRequest 表示需要响应的 RPC 请求。
 type MsgRPCRequest struct {
-	CorrID    correlation.ID    // 用于匹配响应与 Future 的关联 ID。
-	CallChain variant.CallChain // 调用来源链。
-	Path      []byte            // 已编码调用路径；解码时引用输入缓冲区。
-	Args      variant.Array     // 调用参数。
+	CorrID      correlation.ID    // 用于匹配响应与 Future 的关联 ID。
+	CallChain   variant.CallChain // 调用来源链。
+	Path        []byte            // 已编码调用路径；解码时引用输入缓冲区。
+	Args        variant.Array     // 调用参数。
+	IdemKey     string            // 幂等键；非空时被调方在去重窗口内抑制重复执行，去重范围见 rpcpcsr.CallExt。为空时不编码，兼容旧版本消息。
+	TraceParent string            // W3C traceparent 追踪上下文；为空时不编码。非空时 IdemKey 即使为空也会编码以保持字段顺序。
+	ArgsCodec   ArgsCodec         // 参数编解码器；为 ArgsCodec_Variant 时不编码，非默认值时前面的可选字段即使为空也会编码。
+	ArgsData    []byte            // 以 ArgsCodec 编码的参数，此时编码时忽略 Args；解码时引用输入缓冲区，并还原到 Args。
 }
 
 // Read 将 RPC 请求编码到 p。
@@ -47,9 +51,27 @@ func (m MsgRPCRequest) Read(p []byte) (int, error) {
 	if err := bs.WriteBytes(m.Path); err != nil {
 		return bs.BytesWritten(), err
 	}
-	if _, err := binaryutil.CopyToByteStream(&bs, m.Args); err != nil {
+	if _, err := binaryutil.CopyToByteStream(&bs, m.wireArgs()); err != nil {
 		return bs.BytesWritten(), err
 	}
+	if m.IdemKey != "" || m.TraceParent != "" || m.ArgsCodec != ArgsCodec_Variant {
+		if err := bs.WriteString(m.IdemKey); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
+	if m.TraceParent != "" || m.ArgsCodec != ArgsCodec_Variant {
+		if err := bs.WriteString(m.TraceParent); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
+	if m.ArgsCodec != ArgsCodec_Variant {
+		if err := bs.WriteUint8(uint8(m.ArgsCodec)); err != nil {
+			return bs.BytesWritten(), err
+		}
+		if err := bs.WriteBytes(m.ArgsData); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
 	return bs.BytesWritten(), io.EOF
 }
 
@@ -77,15 +99,59 @@ func (m *MsgRPCRequest) Write(p []byte) (int, error) {
 		return bs.BytesRead(), err
 	}
 
+	m.IdemKey = ""
+	if bs.BytesUnread() > 0 {
+		m.IdemKey, err = bs.ReadString()
+		if err != nil {
+			return bs.BytesRead(), err
+		}
+	}
+
+	m.TraceParent = ""
+	if bs.BytesUnread() > 0 {
+		m.TraceParent, err = bs.ReadString()
+		if err != nil {
+			return bs.BytesRead(), err
+		}
+	}
+
+	m.ArgsCodec, m.ArgsData, err = readArgsCodec(&bs)
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Args, err = DecodeArgs(m.ArgsCodec, m.Args, m.ArgsData)
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
 	return bs.BytesRead(), nil
 }
 
 // Size 返回 RPC 请求编码后的字节数。
 func (m MsgRPCRequest) Size() int {
-	return binaryutil.SizeofUvarint(uint64(m.CorrID)) + m.CallChain.Size() + binaryutil.SizeofBytes(m.Path) + m.Args.Size()
+	n := binaryutil.SizeofUvarint(uint64(m.CorrID)) + m.CallChain.Size() + binaryutil.SizeofBytes(m.Path) + m.wireArgs().Size()
+	if m.IdemKey != "" || m.TraceParent != "" || m.ArgsCodec != ArgsCodec_Variant {
+		n += binaryutil.SizeofString(m.IdemKey)
+	}
+	if m.TraceParent != "" || m.ArgsCodec != ArgsCodec_Variant {
+		n += binaryutil.SizeofString(m.TraceParent)
+	}
+	if m.ArgsCodec != ArgsCodec_Variant {
+		n += binaryutil.SizeofUint8 + binaryutil.SizeofBytes(m.ArgsData)
+	}
+	return n
 }
 
 // MsgID 返回 RPC 请求的内置类型 ID。
 func (MsgRPCRequest) MsgID() MsgID {
 	return MsgID_RPC_Request
 }
+
+// wireArgs 返回 Args 字段的编码内容；使用其他参数编解码器时参数以 ArgsData 传输，Args 字段编码为空数组。
+func (m MsgRPCRequest) wireArgs() variant.Array {
+	if m.ArgsCodec != ArgsCodec_Variant {
+		return variant.Array{}
+	}
+	return m.Args
+}
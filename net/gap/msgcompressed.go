@@ -0,0 +1,76 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"io"
+
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+// MsgCompressed 包装压缩后的消息体、压缩算法和原始字节数；消息头设置 Flag_Compressed 时消息体为该结构。
+type MsgCompressed struct {
+	Compression  uint8  // 压缩算法，取值与 gtp.Compression 相同。
+	Data         []byte // 压缩数据；解码时引用输入缓冲区。
+	OriginalSize int64  // 解压后的预期字节数。
+}
+
+// Read 将压缩消息编码到 p。
+func (m MsgCompressed) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteUint8(m.Compression); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteBytes(m.Data); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteVarint(m.OriginalSize); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码压缩消息。
+func (m *MsgCompressed) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	var err error
+
+	m.Compression, err = bs.ReadUint8()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Data, err = bs.ReadBytesRef()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.OriginalSize, err = bs.ReadVarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回压缩消息编码后的字节数。
+func (m MsgCompressed) Size() int {
+	return binaryutil.SizeofUint8 + binaryutil.SizeofBytes(m.Data) + binaryutil.SizeofVarint(m.OriginalSize)
+}
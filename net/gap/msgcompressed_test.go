@@ -0,0 +1,38 @@
+package gap
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestMsgCompressedRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  MsgCompressed
+	}{
+		{"compressed", MsgCompressed{Compression: 1, Data: []byte("\x1f\x8b\x08\x00"), OriginalSize: 1024}},
+		{"large size", MsgCompressed{Compression: 5, Data: []byte{0}, OriginalSize: math.MaxInt64}},
+		{"negative size", MsgCompressed{Compression: 1, Data: []byte{0}, OriginalSize: -1}},
+		{"empty", MsgCompressed{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := mustEncode(t, c.msg)
+
+			var got MsgCompressed
+			if n, err := got.Write(data); err != nil || n != len(data) {
+				t.Fatalf("Write = %d, %v", n, err)
+			}
+			if got.Compression != c.msg.Compression || got.OriginalSize != c.msg.OriginalSize || !bytes.Equal(got.Data, c.msg.Data) {
+				t.Fatalf("got %+v, want %+v", got, c.msg)
+			}
+			if !bytes.Equal(mustEncode(t, got), data) {
+				t.Fatal("re-encoded message differs")
+			}
+			assertTruncatedWriteFails(t, data, func() io.Writer { return &MsgCompressed{} })
+		})
+	}
+}
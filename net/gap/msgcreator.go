@@ -56,6 +56,11 @@ func init() {
 	DefaultMsgCreator().Declare(&MsgRPCReply{})
 	DefaultMsgCreator().Declare(&MsgOnewayRPC{})
 	DefaultMsgCreator().Declare(&MsgForward{})
+	DefaultMsgCreator().Declare(&MsgGroupRPCRequest{})
+	DefaultMsgCreator().Declare(&MsgGroupRPCReply{})
+	DefaultMsgCreator().Declare(&MsgBatchRPCRequest{})
+	DefaultMsgCreator().Declare(&MsgBatchRPCReply{})
+	DefaultMsgCreator().Declare(&MsgFragment{})
 }
 
 // NewMsgCreator 创建空的并发安全消息构建器。
@@ -0,0 +1,76 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"io"
+
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+// MsgEncrypted 包装加密后的消息体、加密密钥 ID 和随机数；消息头设置 Flag_Encrypted 时消息体为该结构。
+type MsgEncrypted struct {
+	KeyID string // 加密密钥 ID，供接收方选择解密密钥。
+	Nonce []byte // AEAD 随机数；解码时引用输入缓冲区。
+	Data  []byte // 密文及认证标签；解码时引用输入缓冲区。
+}
+
+// Read 将加密消息编码到 p。
+func (m MsgEncrypted) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteString(m.KeyID); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteBytes(m.Nonce); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteBytes(m.Data); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码加密消息。
+func (m *MsgEncrypted) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	var err error
+
+	m.KeyID, err = bs.ReadString()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Nonce, err = bs.ReadBytesRef()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Data, err = bs.ReadBytesRef()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回加密消息编码后的字节数。
+func (m MsgEncrypted) Size() int {
+	return binaryutil.SizeofString(m.KeyID) + binaryutil.SizeofBytes(m.Nonce) + binaryutil.SizeofBytes(m.Data)
+}
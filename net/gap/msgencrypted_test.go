@@ -0,0 +1,36 @@
+package gap
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestMsgEncryptedRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  MsgEncrypted
+	}{
+		{"encrypted", MsgEncrypted{KeyID: "k1", Nonce: bytes.Repeat([]byte{1}, 12), Data: []byte("ciphertext-and-tag")}},
+		{"empty data", MsgEncrypted{KeyID: "k1", Nonce: []byte{1}}},
+		{"empty", MsgEncrypted{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := mustEncode(t, c.msg)
+
+			var got MsgEncrypted
+			if n, err := got.Write(data); err != nil || n != len(data) {
+				t.Fatalf("Write = %d, %v", n, err)
+			}
+			if got.KeyID != c.msg.KeyID || !bytes.Equal(got.Nonce, c.msg.Nonce) || !bytes.Equal(got.Data, c.msg.Data) {
+				t.Fatalf("got %+v, want %+v", got, c.msg)
+			}
+			if !bytes.Equal(mustEncode(t, got), data) {
+				t.Fatal("re-encoded message differs")
+			}
+			assertTruncatedWriteFails(t, data, func() io.Writer { return &MsgEncrypted{} })
+		})
+	}
+}
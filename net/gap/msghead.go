@@ -75,10 +75,58 @@ func (o Origin) Size() int {
 	return binaryutil.SizeofString(o.Svc) + binaryutil.SizeofString(o.Addr) + binaryutil.SizeofInt64
 }
 
+// Flags 是消息头中的标志位集合。
+type Flags uint8
+
+// Is 报告指定标志位是否已设置。
+func (f Flags) Is(b Flag) bool {
+	return f&Flags(b) != 0
+}
+
+// Set 原地设置或清除指定标志位，并返回接收者。
+func (f *Flags) Set(b Flag, v bool) *Flags {
+	if v {
+		*f |= Flags(b)
+	} else {
+		*f &= ^Flags(b)
+	}
+	return f
+}
+
+// Setd 在副本上设置或清除指定标志位并返回副本。
+func (f Flags) Setd(b Flag, v bool) Flags {
+	if v {
+		f |= Flags(b)
+	} else {
+		f &= ^Flags(b)
+	}
+	return f
+}
+
+// Flags_None 返回不包含任何标志位的集合。
+func Flags_None() Flags {
+	return 0
+}
+
+// Flag 表示一个消息头标志位掩码。
+type Flag = uint8
+
+const (
+	// Flag_Compressed 表示消息体已压缩为 MsgCompressed。
+	Flag_Compressed Flag = 1 << iota
+	// Flag_Encrypted 表示消息体已加密为 MsgEncrypted。
+	Flag_Encrypted
+	// Flag_Signed 表示消息体已包装为附带签名的 MsgSigned。
+	Flag_Signed
+	// Flag_Customize 是自定义标志位的起始位序号。
+	Flag_Customize = iota
+)
+
 // MsgHead 是每个 GAP 消息包的公共头部。
 type MsgHead struct {
 	Len   uint32 // 完整消息包的字节数。
 	MsgID MsgID  // 消息类型 ID。
+	Flags Flags  // 压缩、加密和签名标志。
 	Src   Origin // 消息来源。
 	Seq   int64  // 发送方分配的序号。
 }
@@ -92,6 +140,9 @@ func (m MsgHead) Read(p []byte) (int, error) {
 	if err := bs.WriteUint32(m.MsgID); err != nil {
 		return bs.BytesWritten(), err
 	}
+	if err := bs.WriteUint8(uint8(m.Flags)); err != nil {
+		return bs.BytesWritten(), err
+	}
 	if _, err := binaryutil.CopyToByteStream(&bs, m.Src); err != nil {
 		return bs.BytesWritten(), err
 	}
@@ -116,6 +167,12 @@ func (m *MsgHead) Write(p []byte) (int, error) {
 		return bs.BytesRead(), err
 	}
 
+	flags, err := bs.ReadUint8()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+	m.Flags = Flags(flags)
+
 	_, err = bs.WriteTo(&m.Src)
 	if err != nil {
 		return bs.BytesRead(), err
@@ -131,5 +188,5 @@ func (m *MsgHead) Write(p []byte) (int, error) {
 
 // Size 返回消息头编码后的字节数。
 func (m MsgHead) Size() int {
-	return binaryutil.SizeofUint32 + binaryutil.SizeofUint32 + m.Src.Size() + binaryutil.SizeofInt64
+	return binaryutil.SizeofUint32 + binaryutil.SizeofUint32 + binaryutil.SizeofUint8 + m.Src.Size() + binaryutil.SizeofInt64
 }
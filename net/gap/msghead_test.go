@@ -0,0 +1,67 @@
+package gap
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	var f Flags
+	f.Set(Flag_Compressed, true).Set(Flag_Signed, true)
+
+	if !f.Is(Flag_Compressed) || f.Is(Flag_Encrypted) || !f.Is(Flag_Signed) {
+		t.Fatalf("unexpected flags %08b", f)
+	}
+
+	cleared := f.Setd(Flag_Compressed, false)
+	if cleared.Is(Flag_Compressed) || !cleared.Is(Flag_Signed) || !f.Is(Flag_Compressed) {
+		t.Fatalf("Setd changed the receiver or missed the flag: %08b, %08b", f, cleared)
+	}
+
+	f.Set(Flag_Signed, false)
+	if f != Flags(Flag_Compressed) {
+		t.Fatalf("unexpected flags %08b", f)
+	}
+
+	if custom := Flag(1 << Flag_Customize); custom <= Flag_Signed {
+		t.Fatalf("custom flag %08b overlaps builtin flags", custom)
+	}
+}
+
+func TestMsgHeadRoundTrip(t *testing.T) {
+	src := Origin{Svc: "lobby", Addr: "node-a", Timestamp: 1700000000000}
+
+	cases := []struct {
+		name string
+		head MsgHead
+	}{
+		{"no flags", MsgHead{Len: 64, MsgID: MsgID_RPC_Request, Src: src, Seq: 1}},
+		{"compressed", MsgHead{Len: 64, MsgID: MsgID_RPC_Request, Flags: Flags_None().Setd(Flag_Compressed, true), Src: src, Seq: 2}},
+		{"all flags", MsgHead{Len: 64, MsgID: MsgID_Forward, Flags: Flags(Flag_Compressed | Flag_Encrypted | Flag_Signed), Src: src, Seq: -1}},
+		{"custom flag", MsgHead{MsgID: MsgID_Fragment, Flags: Flags(1 << Flag_Customize)}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := mustEncode(t, c.head)
+
+			var got MsgHead
+			if n, err := got.Write(data); err != nil || n != len(data) {
+				t.Fatalf("Write = %d, %v", n, err)
+			}
+			if got != c.head {
+				t.Fatalf("got %+v, want %+v", got, c.head)
+			}
+			for _, flag := range []Flag{Flag_Compressed, Flag_Encrypted, Flag_Signed} {
+				if got.Flags.Is(flag) != c.head.Flags.Is(flag) {
+					t.Fatalf("flag %08b not preserved", flag)
+				}
+			}
+			if !bytes.Equal(mustEncode(t, got), data) {
+				t.Fatal("re-encoded head differs")
+			}
+			assertTruncatedWriteFails(t, data, func() io.Writer { return &MsgHead{} })
+		})
+	}
+}
@@ -30,6 +30,16 @@ const (
 	MsgID_OnewayRPC
 	// MsgID_Forward 标识封装其他消息的路由转发消息。
 	MsgID_Forward
+	// MsgID_GroupRPC_Request 标识同一节点上多个实体的批量 RPC 请求。
+	MsgID_GroupRPC_Request
+	// MsgID_GroupRPC_Reply 标识批量 RPC 响应。
+	MsgID_GroupRPC_Reply
+	// MsgID_BatchRPC_Request 标识合并多个调用的批量 RPC 请求。
+	MsgID_BatchRPC_Request
+	// MsgID_BatchRPC_Reply 标识合并多个调用结果的批量 RPC 响应。
+	MsgID_BatchRPC_Reply
+	// MsgID_Fragment 标识超出单条负载上限的消息包分片。
+	MsgID_Fragment
 	// MsgID_Customize 是自定义消息 ID 的起始偏移。
 	MsgID_Customize = 32
 )
@@ -0,0 +1,91 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"git.golaxy.org/core/utils/types"
+	"git.golaxy.org/framework/utils/correlation"
+)
+
+// _MsgPacketJSON 是消息包的 JSON 表示。
+type _MsgPacketJSON struct {
+	Head MsgHead     `json:"Head"`
+	Type string      `json:"Type,omitempty"`
+	Body ReadableMsg `json:"Body,omitempty"`
+}
+
+// MarshalJSON 将消息包渲染为 JSON，消息体附带其类型完整名称，用于日志、调试和 golden 文件比对。
+// 消息体中的动态值按 variant 的带类型 JSON 表示输出。
+func (mp MsgPacket) MarshalJSON() ([]byte, error) {
+	j := _MsgPacketJSON{Head: mp.Head}
+	if mp.Body != nil {
+		j.Type = msgTypeName(mp.Body)
+		j.Body = mp.Body
+	}
+	return json.Marshal(j)
+}
+
+// _MsgForwardJSON 是转发消息的 JSON 表示。
+type _MsgForwardJSON struct {
+	Src       Origin          `json:"Src"`
+	Dst       string          `json:"Dst"`
+	CorrID    correlation.ID  `json:"CorrID"`
+	TransID   MsgID           `json:"TransID"`
+	TransType string          `json:"TransType,omitempty"`
+	Trans     json.RawMessage `json:"Trans,omitempty"`
+	TransData []byte          `json:"TransData,omitempty"`
+}
+
+// MarshalJSON 将转发消息渲染为 JSON；被转发消息已注册到 DefaultMsgCreator 时解码后展开输出，
+// 否则以 base64 输出其原始编码。
+func (m MsgForward) MarshalJSON() ([]byte, error) {
+	j := _MsgForwardJSON{
+		Src:     m.Src,
+		Dst:     m.Dst,
+		CorrID:  m.CorrID,
+		TransID: m.TransID,
+	}
+
+	if msg, err := DefaultMsgCreator().New(m.TransID); err == nil {
+		if _, err := msg.Write(m.TransData); err == nil {
+			if trans, err := json.Marshal(msg); err == nil {
+				j.TransType = msgTypeName(msg)
+				j.Trans = trans
+			}
+		}
+	}
+
+	if j.Trans == nil {
+		j.TransData = m.TransData
+	}
+
+	return json.Marshal(j)
+}
+
+func msgTypeName(msg ReadableMsg) string {
+	rt := reflect.TypeOf(msg)
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return types.FullNameRT(rt)
+}
@@ -0,0 +1,76 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package gap
+
+import (
+	"io"
+
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+// MsgSigned 包装消息体、签名密钥 ID 和签名；消息头设置 Flag_Signed 时消息体为该结构。
+type MsgSigned struct {
+	KeyID string // 签名密钥 ID，供接收方选择验证密钥。
+	Data  []byte // 被签名的消息体；解码时引用输入缓冲区。
+	Sig   []byte // 覆盖消息头与 Data 的签名；解码时引用输入缓冲区。
+}
+
+// Read 将签名消息编码到 p。
+func (m MsgSigned) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteString(m.KeyID); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteBytes(m.Data); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteBytes(m.Sig); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码签名消息。
+func (m *MsgSigned) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	var err error
+
+	m.KeyID, err = bs.ReadString()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Data, err = bs.ReadBytesRef()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	m.Sig, err = bs.ReadBytesRef()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回签名消息编码后的字节数。
+func (m MsgSigned) Size() int {
+	return binaryutil.SizeofString(m.KeyID) + binaryutil.SizeofBytes(m.Data) + binaryutil.SizeofBytes(m.Sig)
+}
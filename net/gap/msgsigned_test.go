@@ -0,0 +1,36 @@
+package gap
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestMsgSignedRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  MsgSigned
+	}{
+		{"signed", MsgSigned{KeyID: "k1", Data: []byte("body"), Sig: bytes.Repeat([]byte{0xab}, 64)}},
+		{"empty data", MsgSigned{KeyID: "k1", Sig: []byte{1}}},
+		{"empty", MsgSigned{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := mustEncode(t, c.msg)
+
+			var got MsgSigned
+			if n, err := got.Write(data); err != nil || n != len(data) {
+				t.Fatalf("Write = %d, %v", n, err)
+			}
+			if got.KeyID != c.msg.KeyID || !bytes.Equal(got.Data, c.msg.Data) || !bytes.Equal(got.Sig, c.msg.Sig) {
+				t.Fatalf("got %+v, want %+v", got, c.msg)
+			}
+			if !bytes.Equal(mustEncode(t, got), data) {
+				t.Fatal("re-encoded message differs")
+			}
+			assertTruncatedWriteFails(t, data, func() io.Writer { return &MsgSigned{} })
+		})
+	}
+}
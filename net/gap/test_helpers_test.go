@@ -0,0 +1,95 @@
+package gap
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"git.golaxy.org/framework/net/gap/variant"
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+func mustArray(t *testing.T, values ...any) variant.Array {
+	t.Helper()
+
+	arr, err := variant.NewArray(values)
+	if err != nil {
+		t.Fatalf("NewArray failed: %v", err)
+	}
+	return arr
+}
+
+func mustMarshal[T ReadableMsg](t *testing.T, msg T) []byte {
+	t.Helper()
+
+	buf, err := Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	defer buf.Release()
+
+	if len(buf.Payload()) != msg.Size() {
+		t.Fatalf("encoded %d bytes, Size reports %d", len(buf.Payload()), msg.Size())
+	}
+	return bytes.Clone(buf.Payload())
+}
+
+// roundTrip 编码 msg 并解码到 out，返回编码结果；解码后的引用型字段引用返回的字节。
+func roundTrip[T ReadableMsg](t *testing.T, msg T, out Msg) []byte {
+	t.Helper()
+
+	data := mustMarshal(t, msg)
+	if err := Unmarshal(out, data); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	return data
+}
+
+// assertSameEncoding 校验解码后的消息重新编码后与原编码一致。
+func assertSameEncoding(t *testing.T, data []byte, decoded ReadableMsg) {
+	t.Helper()
+
+	if got := mustMarshal(t, decoded); !bytes.Equal(got, data) {
+		t.Fatalf("re-encoded message differs:\n got  %x\n want %x", got, data)
+	}
+}
+
+// assertTruncatedFails 校验截断的编码在每个截断位置都解码失败。
+func assertTruncatedFails(t *testing.T, data []byte, newMsg func() Msg) {
+	t.Helper()
+
+	for n := range len(data) {
+		if err := Unmarshal(newMsg(), data[:n]); err == nil {
+			t.Fatalf("expected error decoding %d of %d bytes", n, len(data))
+		}
+	}
+}
+
+// mustEncode 编码不带消息类型 ID 的结构，如消息头和消息体包装，并校验 Size。
+func mustEncode(t *testing.T, v interface {
+	io.Reader
+	Size() int
+}) []byte {
+	t.Helper()
+
+	buf := make([]byte, v.Size())
+	n, err := binaryutil.CopyToBuff(buf, v)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if n != int64(len(buf)) {
+		t.Fatalf("encoded %d bytes, Size reports %d", n, len(buf))
+	}
+	return buf
+}
+
+// assertTruncatedWriteFails 校验截断的编码在每个截断位置都解码失败。
+func assertTruncatedWriteFails(t *testing.T, data []byte, newV func() io.Writer) {
+	t.Helper()
+
+	for n := range len(data) {
+		if _, err := newV().Write(data[:n]); err == nil {
+			t.Fatalf("expected error decoding %d of %d bytes", n, len(data))
+		}
+	}
+}
@@ -20,7 +20,11 @@
 // Package variant 提供 GAP 消息和 RPC 负载使用的动态值模型。
 //
 // Variant 是统一的协议值包装，持有 TypeID 和对应的可读值。内置值包括整数、
-// 浮点数、布尔值、字节串、字符串、Null、Array、Map、Error 和 CallChain。
+// 浮点数、布尔值、字节串、字符串、Null、Array、Map、Error、CallChain、Struct、Proto，
+// 以及 time.Time、time.Duration、uid.ID 和 *big.Int 对应的 Time、Duration、UID 和 BigInt；
+// 为兼容旧版本节点，uid.ID 与 time.Duration 默认编码为 String 与 Int64，见 EncodeBuiltinUIDAndDuration；
+// 普通 Go 结构体按 gap 标签以字段 ID 编码为 Struct，解码时忽略未知字段；
+// protobuf 消息携带完整消息名称编码为 Proto，转换为具体消息类型时再反序列化。
 // 自定义值需要实现 Value 接口，并通过 VariantCreator 注册后，才能根据 TypeID
 // 反序列化。
 //
@@ -30,4 +34,6 @@
 //   - Variant.ToNative：把反序列化后的 GAP 值转换为指定 Go reflect.Type。
 //   - Array.Snapshot：冻结 Array 的编码载荷，用于延迟交付或跨 goroutine
 //     交付后的写出。快照 Array 是只读形态，只用于后续编码。
+//   - Variant、Array、Map 的 MarshalJSON/UnmarshalJSON：与带类型名称的 JSON 表示
+//     无损互转，用于日志、调试控制台和 golden 文件测试。
 package variant
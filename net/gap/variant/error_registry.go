@@ -0,0 +1,201 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package variant
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+
+	"git.golaxy.org/core"
+	"git.golaxy.org/core/utils/exception"
+)
+
+// IErrorDetails 由可提供结构化详情的错误实现，详情随可传输错误一起编码。
+type IErrorDetails interface {
+	// ErrorDetails 返回错误详情，键和值须可转换为动态值。
+	ErrorDetails() map[string]any
+}
+
+// IErrorRegistry 维护错误码与本地错误之间的映射，使错误跨网络传输后仍可使用 errors.Is/As 匹配。
+type IErrorRegistry interface {
+	// Declare 将错误码关联到哨兵错误，编码时使用 errors.Is 匹配；错误码为 0、-1 或重复时 panic。
+	Declare(code int32, sentinel error)
+	// DeclareFunc 使用自定义匹配和解码函数关联错误码；错误码为 0、-1 或重复时 panic。
+	DeclareFunc(code int32, match func(err error) bool, decode func(varErr *Error) error)
+	// Encode 将本地错误转换为可传输错误；未注册的错误使用错误码 -1。
+	Encode(err error) *Error
+	// Decode 将可传输错误关联回注册的本地错误；成功结果返回 nil。
+	Decode(varErr *Error) error
+}
+
+// DeclareErrorType 将错误码关联到错误类型 T，编码时使用 errors.As 匹配，解码时使用 decode 重建错误值。
+func DeclareErrorType[T error](reg IErrorRegistry, code int32, decode func(varErr *Error) T) {
+	if reg == nil {
+		exception.Panicf("%w: %w: reg is nil", ErrVariant, core.ErrArgs)
+	}
+	if decode == nil {
+		exception.Panicf("%w: %w: decode is nil", ErrVariant, core.ErrArgs)
+	}
+	reg.DeclareFunc(code,
+		func(err error) bool {
+			var target T
+			return errors.As(err, &target)
+		},
+		func(varErr *Error) error {
+			return decode(varErr)
+		},
+	)
+}
+
+var errorRegistry = _NewErrorRegistry()
+
+// ErrorRegistry 返回进程级错误注册表，NewError 与 RPC 回复解码均使用它。
+func ErrorRegistry() IErrorRegistry {
+	return errorRegistry
+}
+
+// _NewErrorRegistry 创建空的并发安全错误注册表。
+func _NewErrorRegistry() IErrorRegistry {
+	return &_ErrorRegistry{}
+}
+
+// _ErrorEntry 是一条错误码注册项。
+type _ErrorEntry struct {
+	code   int32
+	match  func(err error) bool
+	decode func(varErr *Error) error
+}
+
+// _ErrorTable 是错误注册表的不可变快照，entries 保留注册顺序，编码时按顺序匹配。
+type _ErrorTable struct {
+	entries []*_ErrorEntry
+	byCode  map[int32]*_ErrorEntry
+}
+
+// _ErrorRegistry 使用写时复制快照保存错误码映射。
+type _ErrorRegistry struct {
+	table atomic.Pointer[_ErrorTable]
+}
+
+// Declare 将错误码关联到哨兵错误。
+func (r *_ErrorRegistry) Declare(code int32, sentinel error) {
+	if sentinel == nil {
+		exception.Panicf("%w: %w: sentinel is nil", ErrVariant, core.ErrArgs)
+	}
+	r.DeclareFunc(code,
+		func(err error) bool { return errors.Is(err, sentinel) },
+		func(*Error) error { return sentinel },
+	)
+}
+
+// DeclareFunc 使用自定义匹配和解码函数关联错误码。
+func (r *_ErrorRegistry) DeclareFunc(code int32, match func(err error) bool, decode func(varErr *Error) error) {
+	if code == 0 || code == -1 {
+		exception.Panicf("%w: %w: error code %d is reserved", ErrVariant, core.ErrArgs, code)
+	}
+	if match == nil {
+		exception.Panicf("%w: %w: match is nil", ErrVariant, core.ErrArgs)
+	}
+	if decode == nil {
+		exception.Panicf("%w: %w: decode is nil", ErrVariant, core.ErrArgs)
+	}
+
+	entry := &_ErrorEntry{code: code, match: match, decode: decode}
+
+	for {
+		old := r.table.Load()
+
+		table := &_ErrorTable{byCode: make(map[int32]*_ErrorEntry)}
+		if old != nil {
+			if _, ok := old.byCode[code]; ok {
+				exception.Panicf("%w: error code %d has already been declared", ErrVariant, code)
+			}
+			table.entries = append(table.entries, old.entries...)
+			for k, v := range old.byCode {
+				table.byCode[k] = v
+			}
+		}
+		table.entries = append(table.entries, entry)
+		table.byCode[code] = entry
+
+		if r.table.CompareAndSwap(old, table) {
+			break
+		}
+
+		runtime.Gosched()
+	}
+}
+
+// Encode 将本地错误转换为可传输错误。
+func (r *_ErrorRegistry) Encode(err error) *Error {
+	if err == nil {
+		return &Error{}
+	}
+
+	var varErr *Error
+	if errors.As(err, &varErr) {
+		return varErr
+	}
+
+	code := int32(-1)
+	if table := r.table.Load(); table != nil {
+		for _, entry := range table.entries {
+			if entry.match(err) {
+				code = entry.code
+				break
+			}
+		}
+	}
+
+	ret := &Error{
+		Code:    code,
+		Message: err.Error(),
+	}
+	if code != -1 {
+		ret.cause = err
+	}
+
+	var details IErrorDetails
+	if errors.As(err, &details) {
+		if m := details.ErrorDetails(); len(m) > 0 {
+			ret.Details, _ = NewMapFromGoMap(m)
+		}
+	}
+
+	return ret
+}
+
+// Decode 将可传输错误关联回注册的本地错误。
+func (r *_ErrorRegistry) Decode(varErr *Error) error {
+	if varErr == nil || varErr.OK() {
+		return nil
+	}
+
+	if varErr.cause == nil {
+		if table := r.table.Load(); table != nil {
+			if entry, ok := table.byCode[varErr.Code]; ok {
+				varErr.cause = entry.decode(varErr)
+			}
+		}
+	}
+
+	return varErr
+}
@@ -0,0 +1,114 @@
+package variant
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testQuotaError struct {
+	Limit int
+}
+
+func (e *testQuotaError) Error() string {
+	return fmt.Sprintf("quota exceeded: %d", e.Limit)
+}
+
+func (e *testQuotaError) ErrorDetails() map[string]any {
+	return map[string]any{"limit": e.Limit}
+}
+
+func TestErrorRegistrySentinelRoundTrip(t *testing.T) {
+	sentinel := errors.New("not found")
+	reg := _NewErrorRegistry()
+	reg.Declare(1001, sentinel)
+
+	encoded := reg.Encode(fmt.Errorf("load user: %w", sentinel))
+	if encoded.Code != 1001 {
+		t.Fatalf("encoded code = %d, want 1001", encoded.Code)
+	}
+
+	var decoded Error
+	wire := encodeValue(t, encoded)
+	if _, err := decoded.Write(wire); err != nil {
+		t.Fatalf("decode error failed: %v", err)
+	}
+	if errors.Is(&decoded, sentinel) {
+		t.Fatal("decoded error should not match sentinel before registry decode")
+	}
+
+	err := reg.Decode(&decoded)
+	if !errors.Is(err, sentinel) {
+		t.Fatalf("decoded error %v does not match sentinel", err)
+	}
+	if !errors.Is(err, Errorln(1001, "")) {
+		t.Fatal("decoded error should match transferable error with the same code")
+	}
+}
+
+func TestErrorRegistryTypedErrorRoundTrip(t *testing.T) {
+	reg := _NewErrorRegistry()
+	DeclareErrorType(reg, 1002, func(varErr *Error) *testQuotaError {
+		ret := &testQuotaError{}
+		for _, kv := range varErr.Details.Entries {
+			k, err := kv.K.ToNative(reflect.TypeFor[string]())
+			if err != nil || k.String() != "limit" {
+				continue
+			}
+			if v, err := kv.V.ToNative(reflect.TypeFor[int]()); err == nil {
+				ret.Limit = int(v.Int())
+			}
+		}
+		return ret
+	})
+
+	encoded := reg.Encode(&testQuotaError{Limit: 10})
+	if encoded.Code != 1002 {
+		t.Fatalf("encoded code = %d, want 1002", encoded.Code)
+	}
+	if encoded.Details.Entries.Len() != 1 {
+		t.Fatalf("encoded details length = %d, want 1", encoded.Details.Entries.Len())
+	}
+
+	decoded := &Error{Code: encoded.Code, Message: encoded.Message, Details: encoded.Details}
+	var quotaErr *testQuotaError
+	if !errors.As(reg.Decode(decoded), &quotaErr) {
+		t.Fatal("decoded error should unwrap to *testQuotaError")
+	}
+	if quotaErr.Limit != 10 {
+		t.Fatalf("decoded limit = %d, want 10", quotaErr.Limit)
+	}
+}
+
+func TestErrorRegistryUnregistered(t *testing.T) {
+	reg := _NewErrorRegistry()
+
+	encoded := reg.Encode(errors.New("boom"))
+	if encoded.Code != -1 || encoded.Message != "boom" {
+		t.Fatalf("encoded = %v, want (-1) boom", encoded)
+	}
+	if reg.Decode(&Error{}) != nil {
+		t.Fatal("decoding a successful result should return nil")
+	}
+
+	err := reg.Decode(Errorln(2000, "unknown"))
+	if errors.Unwrap(err) != nil {
+		t.Fatalf("unregistered code should not unwrap, got %v", errors.Unwrap(err))
+	}
+	if errors.Is(Errorln(-1, "a"), Errorln(-1, "b")) {
+		t.Fatal("code -1 should not match by code")
+	}
+}
+
+func TestErrorRegistryDeclareDuplicatePanics(t *testing.T) {
+	reg := _NewErrorRegistry()
+	reg.Declare(1003, errors.New("first"))
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicate declaration should panic")
+		}
+	}()
+	reg.Declare(1003, errors.New("second"))
+}
@@ -20,14 +20,29 @@
 package variant
 
 import (
+	"math/big"
 	"reflect"
+	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"git.golaxy.org/core/utils/generic"
 	"git.golaxy.org/core/utils/uid"
+	"google.golang.org/protobuf/proto"
 )
 
-// ToVariant 将受支持的 Go 值或 reflect.Value 转换为 GAP 动态值。
+// builtinUIDAndDuration 控制 ToVariant 是否以 UID 与 Duration 编码 uid.ID 与 time.Duration，见 EncodeBuiltinUIDAndDuration。
+var builtinUIDAndDuration atomic.Bool
+
+// EncodeBuiltinUIDAndDuration 设置 ToVariant 是否将 uid.ID 与 time.Duration 编码为 UID 与 Duration。
+// 默认关闭，分别编码为 String 与 Int64，使无法解码 UID 与 Duration 的旧版本节点在滚动升级期间仍能解码；
+// 集群全部节点升级后再开启。两种编码都可转换回 uid.ID 与 time.Duration。
+func EncodeBuiltinUIDAndDuration(enable bool) {
+	builtinUIDAndDuration.Store(enable)
+}
+
+// ToVariant 将受支持的 Go 值或 reflect.Value 转换为 GAP 动态值；protobuf 消息编码为 Proto，
+// 未实现 ReadableValue 的结构体按 gap 标签编码为 Struct。
 func ToVariant(a any) (Variant, error) {
 retry:
 	switch v := a.(type) {
@@ -154,13 +169,47 @@ retry:
 		}
 		return NewVariant((*String)(v))
 	case uid.ID:
-		return NewVariant(String(v))
+		if !builtinUIDAndDuration.Load() {
+			return NewVariant(String(v))
+		}
+		return NewVariant(UID(v))
 	case *uid.ID:
 		if v == nil {
 			a = nil
 			goto retry
 		}
-		return NewVariant((*String)(v))
+		if !builtinUIDAndDuration.Load() {
+			return NewVariant((*String)(v))
+		}
+		return NewVariant((*UID)(v))
+	case time.Time:
+		return NewVariant(Time(v))
+	case *time.Time:
+		if v == nil {
+			a = nil
+			goto retry
+		}
+		return NewVariant((*Time)(v))
+	case time.Duration:
+		if !builtinUIDAndDuration.Load() {
+			return NewVariant(Int64(v))
+		}
+		return NewVariant(Duration(v))
+	case *time.Duration:
+		if v == nil {
+			a = nil
+			goto retry
+		}
+		if !builtinUIDAndDuration.Load() {
+			return NewVariant((*Int64)(v))
+		}
+		return NewVariant((*Duration)(v))
+	case *big.Int:
+		if v == nil {
+			a = nil
+			goto retry
+		}
+		return NewVariant(NewBigInt(v))
 	case Array:
 		return NewVariant(v)
 	case *Array:
@@ -274,6 +323,16 @@ retry:
 		return NewVariant(v)
 	case error:
 		return NewVariant(NewError(v))
+	case proto.Message:
+		if v == nil || !v.ProtoReflect().IsValid() {
+			a = nil
+			goto retry
+		}
+		pm, err := NewProto(v)
+		if err != nil {
+			return Variant{}, err
+		}
+		return NewVariant(pm)
 	case CallChain:
 		return NewVariant(v)
 	case *CallChain:
@@ -282,6 +341,22 @@ retry:
 			goto retry
 		}
 		return NewVariant(*v)
+	case Struct:
+		return NewVariant(v)
+	case *Struct:
+		if v == nil {
+			a = nil
+			goto retry
+		}
+		return NewVariant(*v)
+	case Proto:
+		return NewVariant(v)
+	case *Proto:
+		if v == nil {
+			a = nil
+			goto retry
+		}
+		return NewVariant(*v)
 	case reflect.Value:
 		if !v.CanInterface() {
 			return Variant{}, ErrInvalidCast
@@ -336,6 +411,17 @@ retry:
 				return Variant{}, err
 			}
 			return NewVariant(m)
+
+		case reflect.Struct:
+			// 以指针接收者实现 ReadableValue 的自定义值不可寻址时无法编码为自身类型，不降级为 Struct
+			if reflect.PointerTo(rv.Type()).Implements(reflect.TypeFor[ReadableValue]()) {
+				return Variant{}, ErrInvalidCast
+			}
+			s, err := newStructFromNativeValue(rv)
+			if err != nil {
+				return Variant{}, err
+			}
+			return NewVariant(s)
 		}
 
 		return Variant{}, ErrInvalidCast
@@ -0,0 +1,149 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package variant
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 结构体字段通过 gap 标签控制编码方式，格式为 `gap:"name,id=N,omitempty"`：
+//   - name 为字段名，为空时使用 Go 字段名；字段名为 "-" 时忽略该字段。
+//   - id 指定字段 ID，未指定时使用字段名的 FNV-1a 哈希；重命名字段时可指定原 ID 以保持兼容。
+//   - omitempty 表示字段为零值时不编码。
+//
+// 仅编码已导出字段，匿名嵌入的结构体作为普通字段整体编码，不会展开。
+const structTagKey = "gap"
+
+type _StructFieldCodec struct {
+	index     int
+	id        uint32
+	name      string
+	omitEmpty bool
+}
+
+type _StructCodec struct {
+	fields []_StructFieldCodec // 按字段 ID 升序排列，编码顺序与之一致，便于解码端二分查找。
+}
+
+var structCodecs sync.Map
+
+// structCodecFor 返回结构体类型的编解码描述，结果按类型缓存。
+func structCodecFor(rt reflect.Type) (*_StructCodec, error) {
+	if cached, ok := structCodecs.Load(rt); ok {
+		return cached.(*_StructCodec), nil
+	}
+
+	codec, err := newStructCodec(rt)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := structCodecs.LoadOrStore(rt, codec)
+	return actual.(*_StructCodec), nil
+}
+
+func newStructCodec(rt reflect.Type) (*_StructCodec, error) {
+	if rt.Kind() != reflect.Struct {
+		return nil, ErrInvalidCast
+	}
+
+	codec := &_StructCodec{
+		fields: make([]_StructFieldCodec, 0, rt.NumField()),
+	}
+
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fc := _StructFieldCodec{
+			index: i,
+			name:  field.Name,
+		}
+
+		idSet := false
+
+		if tag, ok := field.Tag.Lookup(structTagKey); ok {
+			opts := strings.Split(tag, ",")
+			if opts[0] == "-" && len(opts) == 1 {
+				continue
+			}
+			if opts[0] != "" {
+				fc.name = opts[0]
+			}
+			for _, opt := range opts[1:] {
+				switch {
+				case opt == "omitempty":
+					fc.omitEmpty = true
+				case strings.HasPrefix(opt, "id="):
+					id, err := strconv.ParseUint(strings.TrimPrefix(opt, "id="), 10, 32)
+					if err != nil {
+						return nil, fmt.Errorf("%w: invalid field id in tag of %s.%s: %w", ErrVariant, rt, field.Name, err)
+					}
+					fc.id = uint32(id)
+					idSet = true
+				}
+			}
+		}
+
+		if !idSet {
+			fc.id = genFieldID(fc.name)
+		}
+
+		codec.fields = append(codec.fields, fc)
+	}
+
+	slices.SortStableFunc(codec.fields, func(a, b _StructFieldCodec) int {
+		return cmp.Compare(a.id, b.id)
+	})
+
+	for i := 1; i < len(codec.fields); i++ {
+		prev, curr := &codec.fields[i-1], &codec.fields[i]
+		if prev.id == curr.id {
+			return nil, fmt.Errorf("%w: field id %d of %s conflicts between %q and %q; set a distinct id in the gap tag", ErrVariant, curr.id, rt, prev.name, curr.name)
+		}
+	}
+
+	return codec, nil
+}
+
+func (c *_StructCodec) lookup(id uint32) (*_StructFieldCodec, bool) {
+	idx, ok := slices.BinarySearchFunc(c.fields, id, func(fc _StructFieldCodec, id uint32) int {
+		return cmp.Compare(fc.id, id)
+	})
+	if !ok {
+		return nil, false
+	}
+	return &c.fields[idx], true
+}
+
+func genFieldID(name string) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return hash.Sum32()
+}
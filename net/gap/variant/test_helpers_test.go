@@ -59,3 +59,13 @@ func assertWireRoundTrip(t *testing.T, v Variant) Variant {
 	}
 	return got
 }
+
+func mustToVariant(t *testing.T, a any) Variant {
+	t.Helper()
+
+	v, err := ToVariant(a)
+	if err != nil {
+		t.Fatalf("ToVariant failed: %v", err)
+	}
+	return v
+}
@@ -65,6 +65,18 @@ const (
 	TypeID_Error
 	// TypeID_CallChain 标识 RPC 调用链。
 	TypeID_CallChain
+	// TypeID_Struct 标识按字段 ID 编码的结构体。
+	TypeID_Struct
+	// TypeID_Proto 标识携带完整消息名称的 protobuf 消息。
+	TypeID_Proto
+	// TypeID_Time 标识 time.Time。
+	TypeID_Time
+	// TypeID_Duration 标识 time.Duration。
+	TypeID_Duration
+	// TypeID_UID 标识 uid.ID。
+	TypeID_UID
+	// TypeID_BigInt 标识 *big.Int。
+	TypeID_BigInt
 	// TypeID_Customize 是自定义类型 ID 的起始偏移。
 	TypeID_Customize = 32
 )
@@ -0,0 +1,137 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package variant
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+var (
+	bigIntRT = reflect.TypeFor[big.Int]()
+)
+
+// NewBigInt 创建大整数动态值，x 为 nil 时视为 0。
+func NewBigInt(x *big.Int) BigInt {
+	if x == nil {
+		x = new(big.Int)
+	}
+	return BigInt{Int: x}
+}
+
+// BigInt 是 GAP 的大整数动态值，以符号和绝对值的大端字节编码。
+type BigInt struct {
+	Int *big.Int
+}
+
+// Read 将符号和绝对值编码到 p。
+func (v BigInt) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteInt8(int8(v.sign())); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteBytes(v.abs()); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码大整数。
+func (v *BigInt) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+
+	sign, err := bs.ReadInt8()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	abs, err := bs.ReadBytes()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	x := new(big.Int).SetBytes(abs)
+	switch {
+	case sign < 0:
+		x.Neg(x)
+	case sign == 0 && x.Sign() != 0:
+		return bs.BytesRead(), fmt.Errorf("%w: invalid big int sign", ErrVariant)
+	}
+	v.Int = x
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回符号和绝对值编码后的字节数。
+func (v BigInt) Size() int {
+	return binaryutil.SizeofInt8 + binaryutil.SizeofBytes(v.abs())
+}
+
+// TypeID 返回大整数的内置类型 ID。
+func (BigInt) TypeID() TypeID {
+	return TypeID_BigInt
+}
+
+// Indirect 返回 *big.Int。
+func (v BigInt) Indirect() any {
+	if v.Int == nil {
+		return new(big.Int)
+	}
+	return v.Int
+}
+
+func (v BigInt) sign() int {
+	if v.Int == nil {
+		return 0
+	}
+	return v.Int.Sign()
+}
+
+func (v BigInt) abs() []byte {
+	if v.Int == nil {
+		return nil
+	}
+	return v.Int.Bytes()
+}
+
+func convertBigIntTo(v Variant, valueRT reflect.Type) (reflect.Value, error) {
+	var x *big.Int
+	switch value := v.Value.(type) {
+	case BigInt:
+		x = value.Indirect().(*big.Int)
+	case *BigInt:
+		x = value.Indirect().(*big.Int)
+	default:
+		return reflect.Value{}, ErrInvalidCast
+	}
+
+	switch valueRT {
+	case bigIntRT:
+		return reflect.ValueOf(new(big.Int).Set(x)).Elem(), nil
+	case reflect.PointerTo(bigIntRT):
+		return reflect.ValueOf(new(big.Int).Set(x)), nil
+	}
+
+	return reflect.Value{}, ErrInvalidCast
+}
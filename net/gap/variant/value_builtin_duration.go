@@ -0,0 +1,65 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package variant
+
+import (
+	"io"
+	"time"
+
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+// Duration 是 GAP 的时间段动态值。
+type Duration time.Duration
+
+// Read 将值编码到 p。
+func (v Duration) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteVarint(int64(v)); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码值。
+func (v *Duration) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	val, err := bs.ReadVarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+	*v = Duration(val)
+	return bs.BytesRead(), nil
+}
+
+// Size 返回值编码后的字节数。
+func (v Duration) Size() int {
+	return binaryutil.SizeofVarint(int64(v))
+}
+
+// TypeID 返回时间段的内置类型 ID。
+func (Duration) TypeID() TypeID {
+	return TypeID_Duration
+}
+
+// Indirect 返回 time.Duration。
+func (v Duration) Indirect() any {
+	return time.Duration(v)
+}
@@ -20,7 +20,6 @@
 package variant
 
 import (
-	"errors"
 	"fmt"
 	"io"
 
@@ -28,17 +27,9 @@ import (
 )
 
 // NewError 将普通 error 转换为可传输错误；nil 转换为成功结果。
+// 已注册到 ErrorRegistry() 的错误使用注册的错误码，其余错误使用错误码 -1。
 func NewError(err error) *Error {
-	if err == nil {
-		return &Error{}
-	}
-
-	var varErr *Error
-	if !errors.As(err, &varErr) {
-		return Errorln(-1, err.Error())
-	}
-
-	return varErr
+	return ErrorRegistry().Encode(err)
 }
 
 // Errorf 创建带错误码和格式化消息的可传输错误。
@@ -58,9 +49,12 @@ func Errorln(code int32, message string) *Error {
 }
 
 // Error 是包含数值错误码和消息的可传输错误。
+// Details 仅由支持的消息（如 MsgRPCReply）单独编码，Error 自身的编码只包含错误码和消息。
 type Error struct {
 	Code    int32  // 错误码；零表示成功。
 	Message string // 错误消息。
+	Details Map    // 可选的结构化错误详情。
+	cause   error  // 注册表解码或编码时关联的本地错误，供 errors.Is/As 匹配。
 }
 
 // Read 将错误编码到 p。
@@ -90,6 +84,8 @@ func (v *Error) Write(p []byte) (int, error) {
 		return bs.BytesRead(), err
 	}
 
+	v.cause = nil
+
 	return bs.BytesRead(), nil
 }
 
@@ -113,6 +109,20 @@ func (v Error) Error() string {
 	return fmt.Sprintf("(%d) %s", v.Code, v.Message)
 }
 
+// Unwrap 返回错误关联的本地错误；错误码未注册时返回 nil。
+func (v Error) Unwrap() error {
+	return v.cause
+}
+
+// Is 报告 target 是否为错误码相同的可传输错误，错误码 -1 不参与匹配。
+func (v Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t == nil {
+		return false
+	}
+	return t.Code == v.Code && v.Code != -1 && v.Code != 0
+}
+
 // OK 报告错误码是否为零。
 func (v Error) OK() bool {
 	return v.Code == 0
@@ -0,0 +1,167 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package variant
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"git.golaxy.org/framework/utils/binaryutil"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+var (
+	protoMessageRT = reflect.TypeFor[proto.Message]()
+)
+
+// NewProto 将 protobuf 消息编码为动态值，携带消息的完整名称。
+func NewProto(m proto.Message) (Proto, error) {
+	if m == nil {
+		return Proto{}, ErrInvalidCast
+	}
+
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return Proto{}, fmt.Errorf("%w: marshal proto message failed, %w", ErrVariant, err)
+	}
+
+	return Proto{
+		Name: string(m.ProtoReflect().Descriptor().FullName()),
+		Data: data,
+	}, nil
+}
+
+// Proto 以完整消息名称和序列化字节保存 protobuf 消息。
+// 解码时不依赖本进程是否注册了该消息类型，转换为具体消息类型时才反序列化。
+type Proto struct {
+	Name string // 消息的完整名称，例如 "pkg.LoginReq"。
+	Data []byte // 消息的 protobuf 序列化字节。
+}
+
+// Read 将 protobuf 动态值编码到 p。
+func (v Proto) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteString(v.Name); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteBytes(v.Data); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码 protobuf 动态值。
+func (v *Proto) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+
+	name, err := bs.ReadString()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	data, err := bs.ReadBytes()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	v.Name = name
+	v.Data = data
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回 protobuf 动态值编码后的字节数。
+func (v Proto) Size() int {
+	return binaryutil.SizeofString(v.Name) + binaryutil.SizeofBytes(v.Data)
+}
+
+// TypeID 返回 protobuf 动态值的内置类型 ID。
+func (Proto) TypeID() TypeID {
+	return TypeID_Proto
+}
+
+// Indirect 返回反序列化后的 protobuf 消息；本进程未注册该消息类型或反序列化失败时返回 Proto 本身。
+func (v Proto) Indirect() any {
+	m, err := v.Unmarshal()
+	if err != nil {
+		return v
+	}
+	return m
+}
+
+// Unmarshal 按全局注册表中的消息类型反序列化 protobuf 消息。
+func (v Proto) Unmarshal() (proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(v.Name))
+	if err != nil {
+		return nil, fmt.Errorf("%w: find proto message %q failed, %w", ErrVariant, v.Name, err)
+	}
+
+	m := mt.New().Interface()
+	if err := proto.Unmarshal(v.Data, m); err != nil {
+		return nil, fmt.Errorf("%w: unmarshal proto message %q failed, %w", ErrVariant, v.Name, err)
+	}
+
+	return m, nil
+}
+
+func convertProtoTo(v Variant, valueRT reflect.Type) (reflect.Value, error) {
+	pm, ok := indirectProto(v.Value)
+	if !ok {
+		return reflect.Value{}, ErrInvalidCast
+	}
+
+	switch {
+	case valueRT.Kind() == reflect.Pointer && valueRT.Implements(protoMessageRT):
+		retRV := reflect.New(valueRT.Elem())
+		m := retRV.Interface().(proto.Message)
+
+		if string(m.ProtoReflect().Descriptor().FullName()) != pm.Name {
+			return reflect.Value{}, ErrInvalidCast
+		}
+		if err := proto.Unmarshal(pm.Data, m); err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: unmarshal proto message %q failed, %w", ErrVariant, pm.Name, err)
+		}
+
+		return retRV, nil
+
+	case valueRT == protoMessageRT:
+		m, err := pm.Unmarshal()
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&m).Elem(), nil
+	}
+
+	return reflect.Value{}, ErrInvalidCast
+}
+
+func indirectProto(v ReadableValue) (Proto, bool) {
+	switch pm := v.(type) {
+	case Proto:
+		return pm, true
+	case *Proto:
+		return *pm, true
+	default:
+		return Proto{}, false
+	}
+}
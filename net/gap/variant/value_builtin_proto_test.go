@@ -0,0 +1,77 @@
+package variant
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProtoRoundTripAndConvert(t *testing.T) {
+	in := wrapperspb.String("hero")
+
+	v, err := ToVariant(in)
+	if err != nil {
+		t.Fatalf("ToVariant failed: %v", err)
+	}
+	if v.TypeID != TypeID_Proto {
+		t.Fatalf("type id mismatch: got %d want %d", v.TypeID, TypeID_Proto)
+	}
+
+	got := assertWireRoundTrip(t, v)
+
+	pm, ok := indirectProto(got.Value)
+	if !ok {
+		t.Fatalf("decoded value is %T, want Proto", got.Value)
+	}
+	if pm.Name != "google.protobuf.StringValue" {
+		t.Fatalf("message name = %q", pm.Name)
+	}
+
+	rv, err := got.ToNative(reflect.TypeFor[*wrapperspb.StringValue]())
+	if err != nil {
+		t.Fatalf("ToNative failed: %v", err)
+	}
+	if !proto.Equal(rv.Interface().(*wrapperspb.StringValue), in) {
+		t.Fatalf("converted message = %v, want %v", rv.Interface(), in)
+	}
+
+	irv, err := got.ToNative(reflect.TypeFor[proto.Message]())
+	if err != nil {
+		t.Fatalf("ToNative interface failed: %v", err)
+	}
+	if !proto.Equal(irv.Interface().(proto.Message), in) {
+		t.Fatalf("converted message = %v, want %v", irv.Interface(), in)
+	}
+
+	if m, ok := pm.Indirect().(*wrapperspb.StringValue); !ok || m.GetValue() != "hero" {
+		t.Fatalf("Indirect = %v", pm.Indirect())
+	}
+}
+
+func TestProtoNameMismatch(t *testing.T) {
+	v, err := ToVariant(wrapperspb.String("hero"))
+	if err != nil {
+		t.Fatalf("ToVariant failed: %v", err)
+	}
+
+	got := assertWireRoundTrip(t, v)
+
+	if _, err := got.ToNative(reflect.TypeFor[*wrapperspb.Int32Value]()); !errors.Is(err, ErrInvalidCast) {
+		t.Fatalf("ToNative error = %v, want ErrInvalidCast", err)
+	}
+}
+
+func TestProtoNilMessage(t *testing.T) {
+	var in *wrapperspb.StringValue
+
+	v, err := ToVariant(in)
+	if err != nil {
+		t.Fatalf("ToVariant failed: %v", err)
+	}
+	if v.TypeID != TypeID_Null {
+		t.Fatalf("type id mismatch: got %d want %d", v.TypeID, TypeID_Null)
+	}
+}
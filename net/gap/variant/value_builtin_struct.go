@@ -0,0 +1,223 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package variant
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+// NewStruct 按 gap 标签将 Go 结构体或其指针的已导出字段转换为动态结构体。
+func NewStruct(s any) (Struct, error) {
+	rv := reflect.ValueOf(s)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return Struct{}, ErrInvalidCast
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return Struct{}, ErrInvalidCast
+	}
+	return newStructFromNativeValue(rv)
+}
+
+// StructField 是动态结构体中的一个字段。
+type StructField struct {
+	ID    uint32  // 字段 ID，由字段名哈希生成或通过标签 id 选项指定。
+	Value Variant // 字段值。
+}
+
+// Struct 以字段 ID 保存结构体的已导出字段，字段按 ID 升序排列。
+// 字段 ID 与字段声明顺序无关，新增或删除字段后，新旧版本仍可互相解码共同的字段。
+type Struct struct {
+	Fields []StructField // 按 ID 升序排列的字段。
+}
+
+// Read 将动态结构体编码到 p。
+func (v Struct) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+
+	if err := bs.WriteUvarint(uint64(len(v.Fields))); err != nil {
+		return bs.BytesWritten(), err
+	}
+
+	for i := range v.Fields {
+		field := &v.Fields[i]
+		if err := bs.WriteUvarint(uint64(field.ID)); err != nil {
+			return bs.BytesWritten(), err
+		}
+		if _, err := binaryutil.CopyToByteStream(&bs, field.Value); err != nil {
+			return bs.BytesWritten(), err
+		}
+	}
+
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码动态结构体；字段 ID 必须严格升序且不超过 uint32 范围，否则返回错误。
+func (v *Struct) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+
+	l, err := bs.ReadUvarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+
+	v.Fields = make([]StructField, 0, min(l, 256))
+
+	for i := uint64(0); i < l; i++ {
+		id, err := bs.ReadUvarint()
+		if err != nil {
+			return bs.BytesRead(), err
+		}
+		if id > math.MaxUint32 {
+			return bs.BytesRead(), fmt.Errorf("%w: struct field id %d out of range", ErrVariant, id)
+		}
+		if len(v.Fields) > 0 && uint32(id) <= v.Fields[len(v.Fields)-1].ID {
+			return bs.BytesRead(), fmt.Errorf("%w: struct field id %d not in strictly ascending order", ErrVariant, id)
+		}
+
+		field := StructField{ID: uint32(id)}
+		if _, err := bs.WriteTo(&field.Value); err != nil {
+			return bs.BytesRead(), err
+		}
+
+		v.Fields = append(v.Fields, field)
+	}
+
+	return bs.BytesRead(), nil
+}
+
+// Size 返回动态结构体编码后的字节数。
+func (v Struct) Size() int {
+	n := binaryutil.SizeofUvarint(uint64(len(v.Fields)))
+	for i := range v.Fields {
+		field := &v.Fields[i]
+		n += binaryutil.SizeofUvarint(uint64(field.ID))
+		n += field.Value.Size()
+	}
+	return n
+}
+
+// TypeID 返回动态结构体的内置类型 ID。
+func (Struct) TypeID() TypeID {
+	return TypeID_Struct
+}
+
+// Indirect 返回动态结构体本身。
+func (v Struct) Indirect() any {
+	return v
+}
+
+// Get 按字段 ID 查找字段值。
+func (v Struct) Get(id uint32) (Variant, bool) {
+	idx, ok := slices.BinarySearchFunc(v.Fields, id, func(field StructField, id uint32) int {
+		return cmp.Compare(field.ID, id)
+	})
+	if !ok {
+		return Variant{}, false
+	}
+	return v.Fields[idx].Value, true
+}
+
+func newStructFromNativeValue(rv reflect.Value) (Struct, error) {
+	codec, err := structCodecFor(rv.Type())
+	if err != nil {
+		return Struct{}, err
+	}
+
+	ret := Struct{
+		Fields: make([]StructField, 0, len(codec.fields)),
+	}
+
+	for i := range codec.fields {
+		fc := &codec.fields[i]
+
+		fieldRV := rv.Field(fc.index)
+		if fc.omitEmpty && fieldRV.IsZero() {
+			continue
+		}
+
+		value, err := toVariantFromNativeValue(fieldRV)
+		if err != nil {
+			return Struct{}, err
+		}
+
+		ret.Fields = append(ret.Fields, StructField{ID: fc.id, Value: value})
+	}
+
+	return ret, nil
+}
+
+func convertStructTo(v Variant, valueRT reflect.Type) (reflect.Value, error) {
+	s, ok := indirectStruct(v.Value)
+	if !ok {
+		return reflect.Value{}, ErrInvalidCast
+	}
+
+	codec, err := structCodecFor(valueRT)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	retRV := reflect.New(valueRT).Elem()
+
+	for i := range s.Fields {
+		field := &s.Fields[i]
+
+		// 未知字段来自更新版本的结构体，直接忽略。
+		fc, ok := codec.lookup(field.ID)
+		if !ok {
+			continue
+		}
+
+		fieldRT := valueRT.Field(fc.index).Type
+		fieldRV, err := field.Value.ToNative(fieldRT)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		fieldRV, err = assignableOrConvert(fieldRV, fieldRT)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		retRV.Field(fc.index).Set(fieldRV)
+	}
+
+	return retRV, nil
+}
+
+func indirectStruct(v ReadableValue) (Struct, bool) {
+	switch s := v.(type) {
+	case Struct:
+		return s, true
+	case *Struct:
+		return *s, true
+	default:
+		return Struct{}, false
+	}
+}
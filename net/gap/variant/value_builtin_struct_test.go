@@ -0,0 +1,147 @@
+package variant
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type structTestInner struct {
+	Score int32
+	Tags  []string
+}
+
+type structTestV1 struct {
+	Name   string `gap:"name"`
+	Level  int32  `gap:"level,omitempty"`
+	Inner  structTestInner
+	Ptr    *structTestInner `gap:",omitempty"`
+	hidden int
+	Skip   string `gap:"-"`
+}
+
+type structTestV2 struct {
+	Title string `gap:"title,id=2"`
+	Level int64  `gap:"level"`
+	Extra string
+	Inner *structTestInner
+}
+
+func TestStructRoundTripAndConvert(t *testing.T) {
+	in := structTestV1{
+		Name:   "hero",
+		Level:  7,
+		Inner:  structTestInner{Score: 3, Tags: []string{"a", "b"}},
+		hidden: 1,
+		Skip:   "skip",
+	}
+
+	v, err := ToVariant(&in)
+	if err != nil {
+		t.Fatalf("ToVariant failed: %v", err)
+	}
+	if v.TypeID != TypeID_Struct {
+		t.Fatalf("type id mismatch: got %d want %d", v.TypeID, TypeID_Struct)
+	}
+
+	got := assertWireRoundTrip(t, v)
+
+	s, ok := indirectStruct(got.Value)
+	if !ok {
+		t.Fatalf("decoded value is %T, want Struct", got.Value)
+	}
+	// Ptr 为零值且设置了 omitempty，hidden 与 Skip 不参与编码。
+	if len(s.Fields) != 3 {
+		t.Fatalf("field count = %d, want 3", len(s.Fields))
+	}
+
+	rv, err := got.ToNative(reflect.TypeFor[structTestV1]())
+	if err != nil {
+		t.Fatalf("ToNative failed: %v", err)
+	}
+	out := rv.Interface().(structTestV1)
+	want := in
+	want.hidden = 0
+	want.Skip = ""
+	if !reflect.DeepEqual(out, want) {
+		t.Fatalf("converted struct = %+v, want %+v", out, want)
+	}
+
+	prv, err := got.ToNative(reflect.TypeFor[*structTestV1]())
+	if err != nil {
+		t.Fatalf("ToNative pointer failed: %v", err)
+	}
+	if !reflect.DeepEqual(*prv.Interface().(*structTestV1), want) {
+		t.Fatalf("converted struct pointer = %+v, want %+v", *prv.Interface().(*structTestV1), want)
+	}
+}
+
+func TestStructFieldEvolution(t *testing.T) {
+	v, err := ToVariant(structTestV1{Name: "hero", Level: 7, Inner: structTestInner{Score: 3}})
+	if err != nil {
+		t.Fatalf("ToVariant failed: %v", err)
+	}
+
+	got := assertWireRoundTrip(t, v)
+
+	// structTestV2 新增 Extra，按名称匹配 level 与 Inner，Title 的 ID 与 name 不同因而不匹配。
+	rv, err := got.ToNative(reflect.TypeFor[structTestV2]())
+	if err != nil {
+		t.Fatalf("ToNative failed: %v", err)
+	}
+	out := rv.Interface().(structTestV2)
+	if out.Level != 7 || out.Title != "" || out.Extra != "" || out.Inner == nil || out.Inner.Score != 3 {
+		t.Fatalf("converted struct = %+v", out)
+	}
+}
+
+func TestStructFieldIDConflict(t *testing.T) {
+	type conflict struct {
+		A int32 `gap:"a,id=1"`
+		B int32 `gap:"b,id=1"`
+	}
+
+	if _, err := ToVariant(conflict{}); !errors.Is(err, ErrVariant) {
+		t.Fatalf("ToVariant error = %v, want ErrVariant", err)
+	}
+}
+
+func TestStructWriteRejectsUnorderedFields(t *testing.T) {
+	field := func(id uint32) StructField {
+		v, err := ToVariant(int32(id))
+		if err != nil {
+			t.Fatalf("ToVariant failed: %v", err)
+		}
+		return StructField{ID: id, Value: v}
+	}
+
+	value := encodeVariant(t, field(0).Value)
+	outOfRange := binary.AppendUvarint(binary.AppendUvarint(nil, 1), math.MaxUint32+1)
+	outOfRange = append(outOfRange, value...)
+
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{"descending", encodeValue(t, Struct{Fields: []StructField{field(2), field(1)}})},
+		{"duplicate", encodeValue(t, Struct{Fields: []StructField{field(1), field(1)}})},
+		{"unsorted tail", encodeValue(t, Struct{Fields: []StructField{field(1), field(3), field(2)}})},
+		{"id out of range", outOfRange},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var s Struct
+			if _, err := s.Write(c.data); !errors.Is(err, ErrVariant) {
+				t.Fatalf("Write error = %v, want ErrVariant", err)
+			}
+		})
+	}
+
+	var s Struct
+	if _, err := s.Write(encodeValue(t, Struct{Fields: []StructField{field(1), field(2), field(math.MaxUint32)}})); err != nil {
+		t.Fatalf("Write of ascending fields failed: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package variant
+
+import (
+	"io"
+	"time"
+
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+// Time 是 GAP 的时间点动态值，以 Unix 秒和纳秒编码，不携带时区信息。
+type Time time.Time
+
+// Read 将 Unix 秒和纳秒编码到 p。
+func (v Time) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	t := time.Time(v)
+	if err := bs.WriteVarint(t.Unix()); err != nil {
+		return bs.BytesWritten(), err
+	}
+	if err := bs.WriteUvarint(uint64(t.Nanosecond())); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码时间点，解码结果使用本地时区。
+func (v *Time) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	sec, err := bs.ReadVarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+	nsec, err := bs.ReadUvarint()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+	*v = Time(time.Unix(sec, int64(nsec)))
+	return bs.BytesRead(), nil
+}
+
+// Size 返回值编码后的字节数。
+func (v Time) Size() int {
+	t := time.Time(v)
+	return binaryutil.SizeofVarint(t.Unix()) + binaryutil.SizeofUvarint(uint64(t.Nanosecond()))
+}
+
+// TypeID 返回时间点的内置类型 ID。
+func (Time) TypeID() TypeID {
+	return TypeID_Time
+}
+
+// Indirect 返回 time.Time。
+func (v Time) Indirect() any {
+	return time.Time(v)
+}
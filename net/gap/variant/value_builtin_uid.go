@@ -0,0 +1,65 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package variant
+
+import (
+	"io"
+
+	"git.golaxy.org/core/utils/uid"
+	"git.golaxy.org/framework/utils/binaryutil"
+)
+
+// UID 是 GAP 的唯一 ID 动态值。
+type UID uid.ID
+
+// Read 将长度前缀和 ID 内容编码到 p。
+func (v UID) Read(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	if err := bs.WriteString(string(v)); err != nil {
+		return bs.BytesWritten(), err
+	}
+	return bs.BytesWritten(), io.EOF
+}
+
+// Write 从 p 解码 ID。
+func (v *UID) Write(p []byte) (int, error) {
+	bs := binaryutil.NewBigEndianStream(p)
+	val, err := bs.ReadString()
+	if err != nil {
+		return bs.BytesRead(), err
+	}
+	*v = UID(val)
+	return bs.BytesRead(), nil
+}
+
+// Size 返回长度前缀和 ID 内容的总字节数。
+func (v UID) Size() int {
+	return binaryutil.SizeofString(string(v))
+}
+
+// TypeID 返回唯一 ID 的内置类型 ID。
+func (UID) TypeID() TypeID {
+	return TypeID_UID
+}
+
+// Indirect 返回 uid.ID。
+func (v UID) Indirect() any {
+	return uid.ID(v)
+}
@@ -2,9 +2,12 @@ package variant
 
 import (
 	"errors"
+	"math/big"
 	"reflect"
 	"testing"
 	"time"
+
+	"git.golaxy.org/core/utils/uid"
 )
 
 func TestVariantRoundTripBuiltins(t *testing.T) {
@@ -33,6 +36,10 @@ func TestVariantRoundTripBuiltins(t *testing.T) {
 		{name: "null", input: nil, typeID: TypeID_Null},
 		{name: "error", input: Errorln(7, "boom"), typeID: TypeID_Error},
 		{name: "callchain", input: CallChain{{Svc: "svc", Addr: "addr", Timestamp: now, Transit: true}}, typeID: TypeID_CallChain},
+		{name: "time", input: now, typeID: TypeID_Time},
+		{name: "duration", input: 3 * time.Second, typeID: TypeID_Int64},
+		{name: "uid", input: uid.ID("entity-1"), typeID: TypeID_String},
+		{name: "bigint", input: big.NewInt(-12345), typeID: TypeID_BigInt},
 	}
 
 	for _, tc := range tests {
@@ -53,6 +60,82 @@ func TestVariantRoundTripBuiltins(t *testing.T) {
 	}
 }
 
+func TestToVariantBuiltinUIDAndDuration(t *testing.T) {
+	EncodeBuiltinUIDAndDuration(true)
+	t.Cleanup(func() { EncodeBuiltinUIDAndDuration(false) })
+
+	id := uid.ID("entity-1")
+	d := 3 * time.Second
+
+	tests := []struct {
+		name   string
+		input  any
+		typeID TypeID
+	}{
+		{name: "duration", input: d, typeID: TypeID_Duration},
+		{name: "duration pointer", input: &d, typeID: TypeID_Duration},
+		{name: "uid", input: id, typeID: TypeID_UID},
+		{name: "uid pointer", input: &id, typeID: TypeID_UID},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := ToVariant(tc.input)
+			if err != nil {
+				t.Fatalf("ToVariant failed: %v", err)
+			}
+			if v.TypeID != tc.typeID {
+				t.Fatalf("type id mismatch: got %d want %d", v.TypeID, tc.typeID)
+			}
+			if got := assertWireRoundTrip(t, v); got.TypeID != tc.typeID {
+				t.Fatalf("decoded type id mismatch: got %d want %d", got.TypeID, tc.typeID)
+			}
+		})
+	}
+}
+
+// TestToVariantUIDAndDurationDecodeOnOldVersion 以未注册 UID 与 Duration 的类型构建器模拟旧版本节点解码。
+func TestToVariantUIDAndDurationDecodeOnOldVersion(t *testing.T) {
+	old := _NewVariantCreator()
+	old.Declare(new(Int64))
+	old.Declare(new(String))
+
+	decodeOld := func(t *testing.T, data []byte) Value {
+		t.Helper()
+
+		var typeID TypeID
+		n, err := typeID.Write(data)
+		if err != nil {
+			t.Fatalf("decode type id failed: %v", err)
+		}
+		value, err := old.New(typeID)
+		if err != nil {
+			t.Fatalf("old version cannot decode type %d: %v", typeID, err)
+		}
+		if _, err := value.Write(data[n:]); err != nil {
+			t.Fatalf("decode value failed: %v", err)
+		}
+		return value
+	}
+
+	id := uid.ID("entity-1")
+	d := 1500 * time.Millisecond
+
+	if got, ok := decodeOld(t, encodeVariant(t, mustToVariant(t, id))).(*String); !ok || uid.ID(*got) != id {
+		t.Fatalf("old version decoded uid as %#v", got)
+	}
+	if got, ok := decodeOld(t, encodeVariant(t, mustToVariant(t, &d))).(*Int64); !ok || time.Duration(*got) != d {
+		t.Fatalf("old version decoded duration as %#v", got)
+	}
+
+	EncodeBuiltinUIDAndDuration(true)
+	defer EncodeBuiltinUIDAndDuration(false)
+
+	if _, err := old.New(mustToVariant(t, id).TypeID); !errors.Is(err, ErrNotDeclared) {
+		t.Fatalf("expected opt-in uid encoding to be unknown to old versions, got %v", err)
+	}
+}
+
 func TestToVariantTypedNilPointers(t *testing.T) {
 	tests := []struct {
 		name  string
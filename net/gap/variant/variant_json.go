@@ -0,0 +1,543 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package variant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/core/utils/uid"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// typeNames 是内置类型在 JSON 表示中使用的类型名称。
+var typeNames = map[TypeID]string{
+	TypeID_Int:       "int",
+	TypeID_Int8:      "int8",
+	TypeID_Int16:     "int16",
+	TypeID_Int32:     "int32",
+	TypeID_Int64:     "int64",
+	TypeID_Uint:      "uint",
+	TypeID_Uint8:     "uint8",
+	TypeID_Uint16:    "uint16",
+	TypeID_Uint32:    "uint32",
+	TypeID_Uint64:    "uint64",
+	TypeID_Float:     "float",
+	TypeID_Double:    "double",
+	TypeID_Byte:      "byte",
+	TypeID_Bool:      "bool",
+	TypeID_Bytes:     "bytes",
+	TypeID_String:    "string",
+	TypeID_Null:      "null",
+	TypeID_Array:     "array",
+	TypeID_Map:       "map",
+	TypeID_Error:     "error",
+	TypeID_CallChain: "callchain",
+	TypeID_Struct:    "struct",
+	TypeID_Proto:     "proto",
+	TypeID_Time:      "time",
+	TypeID_Duration:  "duration",
+	TypeID_UID:       "uid",
+	TypeID_BigInt:    "bigint",
+}
+
+// typeNameCustom 是自定义类型在 JSON 表示中使用的类型名称，类型 ID 单独记录。
+const typeNameCustom = "custom"
+
+var typeIDsByName = func() map[string]TypeID {
+	m := make(map[string]TypeID, len(typeNames))
+	for id, name := range typeNames {
+		m[name] = id
+	}
+	return m
+}()
+
+// _VariantJSON 是动态值的 JSON 表示。
+type _VariantJSON struct {
+	Type  string          `json:"type"`
+	ID    TypeID          `json:"id,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// _MapEntryJSON 是动态值映射中一个键值对的 JSON 表示。
+type _MapEntryJSON struct {
+	K Variant `json:"k"`
+	V Variant `json:"v"`
+}
+
+// _ErrorJSON 是可传输错误的 JSON 表示。
+type _ErrorJSON struct {
+	Code    int32           `json:"code"`
+	Message string          `json:"message"`
+	Details json.RawMessage `json:"details,omitempty"`
+}
+
+// _CallJSON 是调用链节点的 JSON 表示。
+type _CallJSON struct {
+	Svc       string    `json:"svc"`
+	Addr      string    `json:"addr"`
+	Timestamp time.Time `json:"timestamp"`
+	Transit   bool      `json:"transit,omitempty"`
+}
+
+// _StructFieldJSON 是动态结构体字段的 JSON 表示。
+type _StructFieldJSON struct {
+	ID    uint32  `json:"id"`
+	Value Variant `json:"value"`
+}
+
+// _ProtoJSON 是 protobuf 动态值的 JSON 表示；Message 仅供阅读，解码时忽略。
+type _ProtoJSON struct {
+	Name    string          `json:"name"`
+	Data    []byte          `json:"data"`
+	Message json.RawMessage `json:"message,omitempty"`
+}
+
+// MarshalJSON 将动态值编码为带类型名称的 JSON，形如 {"type":"int32","value":1}。
+// 64 位整数保持十进制数字，非有限浮点数编码为字符串，字节串使用 base64，
+// 自定义类型记录类型 ID 并以 base64 保存其 GAP 编码，可经 UnmarshalJSON 无损还原。
+func (v Variant) MarshalJSON() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("%w: invalid variant", ErrVariant)
+	}
+
+	value, err := marshalValueJSON(v)
+	if err != nil {
+		return nil, err
+	}
+
+	j := _VariantJSON{Value: value}
+	if name, ok := typeNames[v.TypeID]; ok {
+		j.Type = name
+	} else {
+		j.Type = typeNameCustom
+		j.ID = v.TypeID
+	}
+
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON 从 MarshalJSON 生成的 JSON 还原动态值；自定义类型需已注册到 VariantCreator。
+func (v *Variant) UnmarshalJSON(data []byte) error {
+	var j _VariantJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return fmt.Errorf("%w: %w", ErrVariant, err)
+	}
+
+	typeID, ok := typeIDsByName[j.Type]
+	if !ok {
+		if j.Type != typeNameCustom || j.ID < TypeID_Customize {
+			return fmt.Errorf("%w: unknown variant json type %q", ErrVariant, j.Type)
+		}
+		typeID = j.ID
+	}
+
+	ret, err := unmarshalValueJSON(typeID, j.Value)
+	if err != nil {
+		return fmt.Errorf("%w: unmarshal variant json type %q failed, %w", ErrVariant, j.Type, err)
+	}
+
+	*v = ret
+	return nil
+}
+
+// MarshalJSON 将数组编码为动态值 JSON 数组；快照形态先解码再编码。
+func (v Array) MarshalJSON() ([]byte, error) {
+	items := v.Items
+
+	if v.IsSnapshot {
+		var arr Array
+		if _, err := arr.Write(v.SnapshotBytes.Payload()); err != nil {
+			return nil, err
+		}
+		items = arr.Items
+	}
+
+	if items == nil {
+		items = []Variant{}
+	}
+
+	return json.Marshal(items)
+}
+
+// UnmarshalJSON 从动态值 JSON 数组还原数组；快照形态返回 ErrSnapshotReadonly。
+func (v *Array) UnmarshalJSON(data []byte) error {
+	if v.IsSnapshot {
+		return ErrSnapshotReadonly
+	}
+
+	var items []Variant
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	v.Items = items
+	return nil
+}
+
+// MarshalJSON 将映射按原有顺序编码为键值对数组，形如 [{"k":...,"v":...}]，以支持非字符串键。
+func (v Map) MarshalJSON() ([]byte, error) {
+	entries := make([]_MapEntryJSON, 0, len(v.Entries))
+	for _, kv := range v.Entries {
+		entries = append(entries, _MapEntryJSON{K: kv.K, V: kv.V})
+	}
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON 从键值对数组还原映射。
+func (v *Map) UnmarshalJSON(data []byte) error {
+	var entries []_MapEntryJSON
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	v.Entries = make(generic.UnorderedSliceMap[Variant, Variant], 0, len(entries))
+	for _, kv := range entries {
+		v.Entries = append(v.Entries, generic.UnorderedKV[Variant, Variant]{K: kv.K, V: kv.V})
+	}
+
+	return nil
+}
+
+func marshalValueJSON(v Variant) (json.RawMessage, error) {
+	switch v.TypeID {
+	case TypeID_Null:
+		return nil, nil
+
+	case TypeID_Float, TypeID_Double:
+		f := toFloat64(v.Value.Indirect())
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return json.Marshal(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+		return json.Marshal(v.Value.Indirect())
+
+	case TypeID_Array:
+		return json.Marshal(v.Value)
+
+	case TypeID_Map:
+		m, ok := indirectMap(v.Value)
+		if !ok {
+			return nil, ErrInvalidCast
+		}
+		return json.Marshal(Map{Entries: m})
+
+	case TypeID_Error:
+		err, ok := v.Value.(*Error)
+		if !ok {
+			return nil, ErrInvalidCast
+		}
+		j := _ErrorJSON{Code: err.Code, Message: err.Message}
+		if len(err.Details.Entries) > 0 {
+			details, e := json.Marshal(err.Details)
+			if e != nil {
+				return nil, e
+			}
+			j.Details = details
+		}
+		return json.Marshal(j)
+
+	case TypeID_CallChain:
+		cc, ok := v.Value.Indirect().(CallChain)
+		if !ok {
+			return nil, ErrInvalidCast
+		}
+		calls := make([]_CallJSON, 0, len(cc))
+		for _, call := range cc {
+			calls = append(calls, _CallJSON{Svc: call.Svc, Addr: call.Addr, Timestamp: call.Timestamp, Transit: call.Transit})
+		}
+		return json.Marshal(calls)
+
+	case TypeID_Struct:
+		s, ok := indirectStruct(v.Value)
+		if !ok {
+			return nil, ErrInvalidCast
+		}
+		fields := make([]_StructFieldJSON, 0, len(s.Fields))
+		for _, field := range s.Fields {
+			fields = append(fields, _StructFieldJSON{ID: field.ID, Value: field.Value})
+		}
+		return json.Marshal(fields)
+
+	case TypeID_Proto:
+		pm, ok := indirectProto(v.Value)
+		if !ok {
+			return nil, ErrInvalidCast
+		}
+		j := _ProtoJSON{Name: pm.Name, Data: pm.Data}
+		if m, err := pm.Unmarshal(); err == nil {
+			if message, err := protojson.Marshal(m); err == nil {
+				j.Message = message
+			}
+		}
+		return json.Marshal(j)
+
+	case TypeID_Time:
+		return json.Marshal(v.Value.Indirect().(time.Time).Format(time.RFC3339Nano))
+
+	case TypeID_Duration:
+		return json.Marshal(v.Value.Indirect().(time.Duration).String())
+
+	case TypeID_BigInt:
+		return json.Marshal(v.Value.Indirect().(*big.Int).String())
+
+	case TypeID_Int, TypeID_Int8, TypeID_Int16, TypeID_Int32, TypeID_Int64,
+		TypeID_Uint, TypeID_Uint8, TypeID_Uint16, TypeID_Uint32, TypeID_Uint64,
+		TypeID_Byte, TypeID_Bool, TypeID_Bytes, TypeID_String, TypeID_UID:
+		return json.Marshal(v.Value.Indirect())
+	}
+
+	data := make([]byte, v.Value.Size())
+	if _, err := v.Value.Read(data); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+func toFloat64(a any) float64 {
+	switch f := a.(type) {
+	case float32:
+		return float64(f)
+	case float64:
+		return f
+	}
+	return 0
+}
+
+func unmarshalValueJSON(typeID TypeID, raw json.RawMessage) (Variant, error) {
+	var value ReadableValue
+
+	switch typeID {
+	case TypeID_Int:
+		n, err := strconv.ParseInt(string(raw), 10, strconv.IntSize)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Int(n)
+	case TypeID_Int8:
+		n, err := strconv.ParseInt(string(raw), 10, 8)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Int8(n)
+	case TypeID_Int16:
+		n, err := strconv.ParseInt(string(raw), 10, 16)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Int16(n)
+	case TypeID_Int32:
+		n, err := strconv.ParseInt(string(raw), 10, 32)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Int32(n)
+	case TypeID_Int64:
+		n, err := strconv.ParseInt(string(raw), 10, 64)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Int64(n)
+	case TypeID_Uint:
+		n, err := strconv.ParseUint(string(raw), 10, strconv.IntSize)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Uint(n)
+	case TypeID_Uint8:
+		n, err := strconv.ParseUint(string(raw), 10, 8)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Uint8(n)
+	case TypeID_Uint16:
+		n, err := strconv.ParseUint(string(raw), 10, 16)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Uint16(n)
+	case TypeID_Uint32:
+		n, err := strconv.ParseUint(string(raw), 10, 32)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Uint32(n)
+	case TypeID_Uint64:
+		n, err := strconv.ParseUint(string(raw), 10, 64)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Uint64(n)
+	case TypeID_Byte:
+		n, err := strconv.ParseUint(string(raw), 10, 8)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Byte(n)
+	case TypeID_Float:
+		f, err := parseFloatJSON(raw, 32)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Float(f)
+	case TypeID_Double:
+		f, err := parseFloatJSON(raw, 64)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Double(f)
+	case TypeID_Bool:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return Variant{}, err
+		}
+		value = Bool(b)
+	case TypeID_Bytes:
+		var bs []byte
+		if err := json.Unmarshal(raw, &bs); err != nil {
+			return Variant{}, err
+		}
+		value = Bytes(bs)
+	case TypeID_String:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return Variant{}, err
+		}
+		value = String(s)
+	case TypeID_UID:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return Variant{}, err
+		}
+		value = UID(uid.ID(s))
+	case TypeID_Null:
+		value = Null{}
+	case TypeID_Array:
+		var arr Array
+		if err := json.Unmarshal(raw, &arr); err != nil {
+			return Variant{}, err
+		}
+		value = arr
+	case TypeID_Map:
+		var m Map
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return Variant{}, err
+		}
+		value = m
+	case TypeID_Error:
+		var j _ErrorJSON
+		if err := json.Unmarshal(raw, &j); err != nil {
+			return Variant{}, err
+		}
+		err := &Error{Code: j.Code, Message: j.Message}
+		if len(j.Details) > 0 {
+			if e := json.Unmarshal(j.Details, &err.Details); e != nil {
+				return Variant{}, e
+			}
+		}
+		value = err
+	case TypeID_CallChain:
+		var calls []_CallJSON
+		if err := json.Unmarshal(raw, &calls); err != nil {
+			return Variant{}, err
+		}
+		cc := make(CallChain, 0, len(calls))
+		for _, call := range calls {
+			cc = append(cc, Call{Svc: call.Svc, Addr: call.Addr, Timestamp: call.Timestamp, Transit: call.Transit})
+		}
+		value = cc
+	case TypeID_Struct:
+		var fields []_StructFieldJSON
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return Variant{}, err
+		}
+		s := Struct{Fields: make([]StructField, 0, len(fields))}
+		for _, field := range fields {
+			s.Fields = append(s.Fields, StructField{ID: field.ID, Value: field.Value})
+		}
+		value = s
+	case TypeID_Proto:
+		var j _ProtoJSON
+		if err := json.Unmarshal(raw, &j); err != nil {
+			return Variant{}, err
+		}
+		value = Proto{Name: j.Name, Data: j.Data}
+	case TypeID_Time:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return Variant{}, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Time(t)
+	case TypeID_Duration:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return Variant{}, err
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return Variant{}, err
+		}
+		value = Duration(d)
+	case TypeID_BigInt:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return Variant{}, err
+		}
+		x, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return Variant{}, fmt.Errorf("invalid big int %q", s)
+		}
+		value = NewBigInt(x)
+	default:
+		var data []byte
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return Variant{}, err
+		}
+		reflected, err := typeID.NewReflected()
+		if err != nil {
+			return Variant{}, err
+		}
+		custom := reflected.Interface().(Value)
+		if _, err := custom.Write(data); err != nil {
+			return Variant{}, err
+		}
+		return Variant{TypeID: typeID, Value: custom, Reflected: reflected}, nil
+	}
+
+	return NewVariant(value)
+}
+
+func parseFloatJSON(raw json.RawMessage, bitSize int) (float64, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strconv.ParseFloat(s, bitSize)
+	}
+	return strconv.ParseFloat(string(raw), bitSize)
+}
@@ -0,0 +1,116 @@
+package variant
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"git.golaxy.org/core/utils/uid"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func assertJSONRoundTrip(t *testing.T, v Variant) Variant {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+
+	var got Variant
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal %s failed: %v", data, err)
+	}
+	if got.TypeID != v.TypeID {
+		t.Fatalf("type id mismatch: got %d want %d", got.TypeID, v.TypeID)
+	}
+	if !bytes.Equal(encodeVariant(t, got), encodeVariant(t, v)) {
+		t.Fatalf("json roundtrip wire mismatch for %s", data)
+	}
+	return got
+}
+
+func TestVariantJSONRoundTrip(t *testing.T) {
+	n, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+
+	tests := []struct {
+		name  string
+		input any
+	}{
+		{name: "int", input: int(-1)},
+		{name: "int8", input: int8(-128)},
+		{name: "int64", input: int64(math.MinInt64)},
+		{name: "uint64", input: uint64(math.MaxUint64)},
+		{name: "float32", input: float32(0.1)},
+		{name: "float64", input: 0.1},
+		{name: "nan", input: math.NaN()},
+		{name: "inf", input: math.Inf(-1)},
+		{name: "bool", input: true},
+		{name: "bytes", input: []byte{0, 1, 2, 255}},
+		{name: "string", input: "hello"},
+		{name: "null", input: nil},
+		{name: "error", input: Errorln(7, "boom")},
+		{name: "callchain", input: CallChain{{Svc: "svc", Addr: "addr", Timestamp: time.UnixMilli(1710000000123), Transit: true}}},
+		{name: "struct", input: structTestV1{Name: "hero", Level: 7, Inner: structTestInner{Score: 3, Tags: []string{"a"}}}},
+		{name: "proto", input: wrapperspb.String("hero")},
+		{name: "time", input: time.Unix(1710000000, 123456789)},
+		{name: "duration", input: -1500 * time.Millisecond},
+		{name: "uid", input: uid.ID("entity-1")},
+		{name: "bigint", input: n},
+		{name: "array", input: []any{int32(1), "x", nil, []any{true}}},
+		{name: "map", input: map[int32]string{1: "a"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assertJSONRoundTrip(t, mustToVariant(t, tc.input))
+		})
+	}
+}
+
+func TestVariantJSONFormat(t *testing.T) {
+	arr, err := NewArray([]any{int32(1), "x", int64(math.MaxInt64)})
+	if err != nil {
+		t.Fatalf("NewArray failed: %v", err)
+	}
+
+	data, err := json.Marshal(arr)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+
+	want := `[{"type":"int32","value":1},{"type":"string","value":"x"},{"type":"int64","value":9223372036854775807}]`
+	if string(data) != want {
+		t.Fatalf("array json = %s, want %s", data, want)
+	}
+
+	var got Array
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if !bytes.Equal(encodeValue(t, got), encodeValue(t, arr)) {
+		t.Fatal("array json roundtrip wire mismatch")
+	}
+
+	snapshot, err := arr.Snapshot(false)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	snapshotData, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("json.Marshal snapshot failed: %v", err)
+	}
+	if string(snapshotData) != want {
+		t.Fatalf("snapshot json = %s, want %s", snapshotData, want)
+	}
+}
+
+func TestVariantJSONUnknownType(t *testing.T) {
+	var v Variant
+	if err := json.Unmarshal([]byte(`{"type":"nope","value":1}`), &v); err == nil {
+		t.Fatal("json.Unmarshal unknown type succeeded")
+	}
+}
@@ -234,6 +234,26 @@ func (v Variant) ToNative(valueRT reflect.Type) (reflect.Value, error) {
 		if valueRT.Kind() == reflect.Map {
 			return convertMapTo(v, valueRT)
 		}
+
+	case TypeID_Proto:
+		return convertProtoTo(v, valueRT)
+
+	case TypeID_BigInt:
+		return convertBigIntTo(v, valueRT)
+
+	case TypeID_Struct:
+		switch {
+		case valueRT.Kind() == reflect.Struct:
+			return convertStructTo(v, valueRT)
+		case valueRT.Kind() == reflect.Pointer && valueRT.Elem().Kind() == reflect.Struct:
+			retRV, err := convertStructTo(v, valueRT.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			ptrRV := reflect.New(valueRT.Elem())
+			ptrRV.Elem().Set(retRV)
+			return ptrRV, nil
+		}
 	}
 
 	return reflect.Value{}, ErrInvalidCast
@@ -3,10 +3,13 @@ package variant
 import (
 	"errors"
 	"io"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"git.golaxy.org/core/utils/generic"
+	"git.golaxy.org/core/utils/uid"
 	"git.golaxy.org/framework/utils/binaryutil"
 )
 
@@ -580,3 +583,70 @@ func TestVariantConvertCustomValueTargets(t *testing.T) {
 		t.Fatalf("converted Value TypeID = %v, want %v", gotValueInterface.Interface().(Value).TypeID(), src.TypeID())
 	}
 }
+
+func TestVariantConvertTimeDurationUIDBigInt(t *testing.T) {
+	now := time.Unix(1710000000, 123456789)
+
+	tv := assertWireRoundTrip(t, mustToVariant(t, now))
+	gotTime, err := tv.ToNative(reflect.TypeFor[time.Time]())
+	if err != nil {
+		t.Fatalf("ToNative time.Time failed: %v", err)
+	}
+	if !gotTime.Interface().(time.Time).Equal(now) {
+		t.Fatalf("converted time = %v, want %v", gotTime.Interface(), now)
+	}
+	gotTimePtr, err := tv.ToNative(reflect.TypeFor[*time.Time]())
+	if err != nil {
+		t.Fatalf("ToNative *time.Time failed: %v", err)
+	}
+	if !gotTimePtr.Interface().(*time.Time).Equal(now) {
+		t.Fatalf("converted time pointer = %v, want %v", gotTimePtr.Interface(), now)
+	}
+
+	dv := assertWireRoundTrip(t, mustToVariant(t, 1500*time.Millisecond))
+	gotDuration, err := dv.ToNative(reflect.TypeFor[time.Duration]())
+	if err != nil {
+		t.Fatalf("ToNative time.Duration failed: %v", err)
+	}
+	if gotDuration.Interface().(time.Duration) != 1500*time.Millisecond {
+		t.Fatalf("converted duration = %v", gotDuration.Interface())
+	}
+
+	uv := assertWireRoundTrip(t, mustToVariant(t, uid.ID("entity-1")))
+	gotUID, err := uv.ToNative(reflect.TypeFor[uid.ID]())
+	if err != nil {
+		t.Fatalf("ToNative uid.ID failed: %v", err)
+	}
+	if gotUID.Interface().(uid.ID) != "entity-1" {
+		t.Fatalf("converted uid = %v", gotUID.Interface())
+	}
+
+	// 旧版本以 String 编码的 uid.ID 仍可转换。
+	sv := assertWireRoundTrip(t, mustToVariant(t, "entity-2"))
+	gotUID, err = sv.ToNative(reflect.TypeFor[uid.ID]())
+	if err != nil {
+		t.Fatalf("ToNative uid.ID from string failed: %v", err)
+	}
+	if gotUID.Interface().(uid.ID) != "entity-2" {
+		t.Fatalf("converted uid = %v", gotUID.Interface())
+	}
+
+	n, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	bv := assertWireRoundTrip(t, mustToVariant(t, n))
+	gotBigInt, err := bv.ToNative(reflect.TypeFor[*big.Int]())
+	if err != nil {
+		t.Fatalf("ToNative *big.Int failed: %v", err)
+	}
+	if gotBigInt.Interface().(*big.Int).Cmp(n) != 0 {
+		t.Fatalf("converted big int = %v, want %v", gotBigInt.Interface(), n)
+	}
+
+	zv := assertWireRoundTrip(t, mustToVariant(t, new(big.Int)))
+	gotBigInt, err = zv.ToNative(reflect.TypeFor[*big.Int]())
+	if err != nil {
+		t.Fatalf("ToNative zero *big.Int failed: %v", err)
+	}
+	if gotBigInt.Interface().(*big.Int).Sign() != 0 {
+		t.Fatalf("converted big int = %v, want 0", gotBigInt.Interface())
+	}
+}
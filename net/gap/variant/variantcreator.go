@@ -75,6 +75,12 @@ func init() {
 	VariantCreator().Declare(&Array{})
 	VariantCreator().Declare(&Error{})
 	VariantCreator().Declare(&CallChain{})
+	VariantCreator().Declare(&Struct{})
+	VariantCreator().Declare(&Proto{})
+	VariantCreator().Declare(new(Time))
+	VariantCreator().Declare(new(Duration))
+	VariantCreator().Declare(new(UID))
+	VariantCreator().Declare(&BigInt{})
 }
 
 // _NewVariantCreator 创建空的并发安全类型构建器。
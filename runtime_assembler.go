@@ -33,6 +33,7 @@ import (
 	"git.golaxy.org/core/utils/reinterpret"
 	"git.golaxy.org/core/utils/uid"
 	. "git.golaxy.org/framework/addins"
+	"git.golaxy.org/framework/addins/rpc/callpath"
 	etcdv3 "go.etcd.io/etcd/client/v3"
 )
 
@@ -128,7 +129,7 @@ func (r *RuntimeAssembler) assemble(settings _RuntimeSettings) (core.Runtime, er
 
 			switch runningEvent {
 			case runtime.RunningEvent_Birth:
-				cacheCallPath("", rtInst.Reflected().Type())
+				cacheCallPath(r.svcInst.Name(), callpath.Runtime, "", rtInst.Reflected().Type())
 
 				if cb, ok := r.instance.(LifecycleRuntimeBirth); ok {
 					cb.OnBirth(rtInst)
@@ -231,7 +232,7 @@ func (r *RuntimeAssembler) assemble(settings _RuntimeSettings) (core.Runtime, er
 				}
 			case runtime.RunningEvent_AddInActivating:
 				addInStatus := args[0].(extension.AddInStatus)
-				cacheCallPath(addInStatus.Name(), addInStatus.Reflected().Type())
+				cacheCallPath(r.svcInst.Name(), callpath.Runtime, addInStatus.Name(), addInStatus.Reflected().Type())
 				if cb, ok := r.instance.(LifecycleRuntimeAddInActivating); ok {
 					cb.OnAddInActivating(rtInst, addInStatus)
 				}
@@ -274,7 +275,7 @@ func (r *RuntimeAssembler) assemble(settings _RuntimeSettings) (core.Runtime, er
 				entity := args[0].(ec.Entity)
 
 				if entity.PT().Prototype() == "" {
-					cacheCallPath("", entity.Reflected().Type())
+					cacheCallPath(r.svcInst.Name(), callpath.Entity, "", entity.Reflected().Type())
 				}
 
 				if rtInst.AutoInjection() {
@@ -331,7 +332,7 @@ func (r *RuntimeAssembler) assemble(settings _RuntimeSettings) (core.Runtime, er
 
 				for i := range components {
 					comp := components[i]
-					cacheCallPath(comp.Name(), comp.Reflected().Type())
+					cacheCallPath(r.svcInst.Name(), callpath.Entity, comp.Name(), comp.Reflected().Type())
 				}
 
 				if rtInst.AutoInjection() {
@@ -29,6 +29,7 @@ import (
 	"git.golaxy.org/core/utils/reinterpret"
 	"git.golaxy.org/framework/addins"
 	"git.golaxy.org/framework/addins/broker"
+	"git.golaxy.org/framework/addins/catalog"
 	"git.golaxy.org/framework/addins/dent"
 	"git.golaxy.org/framework/addins/discovery"
 	"git.golaxy.org/framework/addins/dsvc"
@@ -64,6 +65,8 @@ type IService interface {
 	DistEntityQuerier() dent.IDistEntityQuerier
 	// RPC 返回 RPC add-in；未安装时会 panic。
 	RPC() rpc.IRPC
+	// Catalog 返回服务目录 add-in；未安装时会 panic。
+	Catalog() catalog.ICatalog
 	// ReplicaNo 返回当前服务在本次应用启动中的副本序号，从 0 开始。
 	ReplicaNo() int
 	// Memory 返回服务私有的并发键值存储。
@@ -140,6 +143,11 @@ func (svc *ServiceBehavior) RPC() rpc.IRPC {
 	return addins.RPC.Require(svc)
 }
 
+// Catalog 返回服务目录 add-in；未安装时会 panic。
+func (svc *ServiceBehavior) Catalog() catalog.ICatalog {
+	return addins.Catalog.Require(svc)
+}
+
 // ReplicaNo 返回当前服务在本次应用启动中的副本序号，从 0 开始。
 func (svc *ServiceBehavior) ReplicaNo() int {
 	v, _ := svc.Memory().Load(memReplicaNo)
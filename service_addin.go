@@ -63,8 +63,20 @@ type InstallServiceRPC interface {
 	InstallRPC(svc IService)
 }
 
+// InstallServiceCallPathSync 为服务提供自定义调用路径表同步 add-in 安装钩子。
+// 仅当 Birth 阶段尚未安装同名 add-in 时调用；实现未安装时，按 service.cpsync 配置决定是否安装默认实现。
+type InstallServiceCallPathSync interface {
+	InstallCallPathSync(svc IService)
+}
+
 // InstallServiceDistEntityQuerier 为服务提供自定义分布式实体查询 add-in 安装钩子。
 // 仅当 Birth 阶段尚未安装同名 add-in 时调用；实现必须在返回前完成安装。
 type InstallServiceDistEntityQuerier interface {
 	InstallDistEntityQuerier(svc IService)
 }
+
+// InstallServiceCatalog 为服务提供自定义服务目录 add-in 安装钩子。
+// 仅当 Birth 阶段尚未安装同名 add-in 时调用；实现必须在返回前完成安装。
+type InstallServiceCatalog interface {
+	InstallCatalog(svc IService)
+}
@@ -31,6 +31,7 @@ import (
 	"git.golaxy.org/core/utils/iface"
 	"git.golaxy.org/core/utils/reinterpret"
 	. "git.golaxy.org/framework/addins"
+	"git.golaxy.org/framework/addins/rpc/callpath"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	etcdv3 "go.etcd.io/etcd/client/v3"
@@ -106,7 +107,7 @@ func (s *ServiceAssembler) assemble(ctx context.Context, replicaNo int) core.Ser
 
 			switch runningEvent {
 			case service.RunningEvent_Birth:
-				cacheCallPath("", svcInst.Reflected().Type())
+				cacheCallPath(svcInst.Name(), callpath.Service, "", svcInst.Reflected().Type())
 
 				svcInst.Memory().Store(memReplicaNo, replicaNo)
 
@@ -130,7 +131,7 @@ func (s *ServiceAssembler) assemble(ctx context.Context, replicaNo int) core.Ser
 				}
 
 				for _, addInStatus := range svcInst.AddInManager().ListStatuses() {
-					cacheCallPath(addInStatus.Name(), addInStatus.Reflected().Type())
+					cacheCallPath(svcInst.Name(), callpath.Service, addInStatus.Name(), addInStatus.Reflected().Type())
 				}
 
 				svcInst.Memory().Store(memEtcdClientOnce, sync.OnceValue(func() *etcdv3.Client {
@@ -214,10 +215,10 @@ func (s *ServiceAssembler) assemble(ctx context.Context, replicaNo int) core.Ser
 				}
 			case service.RunningEvent_EntityPTDeclared:
 				entityPT := args[0].(ec.EntityPT)
-				cacheCallPath("", entityPT.InstanceRT())
+				cacheCallPath(svcInst.Name(), callpath.Entity, "", entityPT.InstanceRT())
 				for i := range entityPT.CountComponents() {
 					comp := entityPT.GetComponent(i)
-					cacheCallPath(comp.Name, comp.PT.InstanceRT())
+					cacheCallPath(svcInst.Name(), callpath.Entity, comp.Name, comp.PT.InstanceRT())
 				}
 				if cb, ok := s.instance.(LifecycleServiceEntityPTDeclared); ok {
 					cb.OnEntityPTDeclared(svcInst, entityPT)
@@ -483,6 +484,27 @@ func (s *ServiceAssembler) installAddIns(svcInst IService) {
 	}
 	requireInstalled(Dentq.Name)
 
+	// 安装调用路径表同步插件；可选，未安装时无法解析的压缩调用路径由调用方降级为完整形式重发
+	if !installed(CallPathSync.Name) {
+		if cb, ok := svcInst.(InstallServiceCallPathSync); ok {
+			cb.InstallCallPathSync(svcInst)
+		}
+	}
+	if !installed(CallPathSync.Name) {
+		if cb, ok := s.instance.(InstallServiceCallPathSync); ok {
+			cb.InstallCallPathSync(svcInst)
+		}
+	}
+	if !installed(CallPathSync.Name) && conf.GetBool("service.cpsync") {
+		CallPathSync.Install(svcInst,
+			CallPathSyncWith.CustomAddresses(conf.GetString("etcd.address")),
+			CallPathSyncWith.CustomAuth(
+				conf.GetString("etcd.username"),
+				conf.GetString("etcd.password"),
+			),
+		)
+	}
+
 	// 安装RPC支持插件
 	if !installed(RPC.Name) {
 		if cb, ok := svcInst.(InstallServiceRPC); ok {
@@ -498,4 +520,20 @@ func (s *ServiceAssembler) installAddIns(svcInst IService) {
 		RPC.Install(svcInst)
 	}
 	requireInstalled(RPC.Name)
+
+	// 安装服务目录插件
+	if !installed(Catalog.Name) {
+		if cb, ok := svcInst.(InstallServiceCatalog); ok {
+			cb.InstallCatalog(svcInst)
+		}
+	}
+	if !installed(Catalog.Name) {
+		if cb, ok := s.instance.(InstallServiceCatalog); ok {
+			cb.InstallCatalog(svcInst)
+		}
+	}
+	if !installed(Catalog.Name) {
+		Catalog.Install(svcInst)
+	}
+	requireInstalled(Catalog.Name)
 }
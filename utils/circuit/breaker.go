@@ -0,0 +1,362 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package circuit
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// State 是熔断器状态。
+type State int32
+
+const (
+	Closed   State = iota // 关闭，正常放行请求
+	Open                  // 打开，快速拒绝请求
+	HalfOpen              // 半开，仅放行有限的探测请求
+)
+
+// String 返回状态名称。
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Options 定义熔断器的阈值与时间参数。
+type Options struct {
+	Window              time.Duration // Window 是关闭状态下统计失败率的窗口长度。
+	MinRequests         int           // MinRequests 是按失败率判定前窗口内至少需要的请求数。
+	FailureRatio        float64       // FailureRatio 是触发熔断的失败率，取值 (0, 1]；小于等于 0 时不按失败率判定。
+	ConsecutiveFailures int           // ConsecutiveFailures 是触发熔断的连续失败次数；小于等于 0 时不按连续失败判定。
+	OpenTimeout         time.Duration // OpenTimeout 是打开状态持续多久后进入半开状态。
+	HalfOpenProbes      int           // HalfOpenProbes 是半开状态下允许同时进行的探测请求数，至少为 1。
+	IdleTimeout         time.Duration // IdleTimeout 仅用于 Group，熔断器超过该时长未被使用时回收；小于等于 0 时不回收。
+}
+
+// DefaultOptions 返回默认熔断参数：10 秒窗口内至少 20 次请求且失败率达到 50%，或连续 5 次失败时熔断 5 秒，
+// 随后放行 1 个探测请求；在 Group 中空闲 10 分钟的熔断器会被回收。
+func DefaultOptions() Options {
+	return Options{
+		Window:              10 * time.Second,
+		MinRequests:         20,
+		FailureRatio:        0.5,
+		ConsecutiveFailures: 5,
+		OpenTimeout:         5 * time.Second,
+		HalfOpenProbes:      1,
+		IdleTimeout:         10 * time.Minute,
+	}
+}
+
+// Ticket 标识一次被放行的请求，完成时需要交还给 Breaker.Done 或 Breaker.Cancel。
+type Ticket struct {
+	generation uint64
+}
+
+// Breaker 是单个目标的熔断器，可并发使用。
+type Breaker struct {
+	mutex       sync.Mutex
+	options     Options
+	now         func() time.Time
+	onChange    func(from, to State)
+	state       State
+	generation  uint64
+	openUntil   time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+	consecutive int
+	probes      int
+	lastUsed    atomic.Int64
+}
+
+// NewBreaker 创建熔断器。
+func NewBreaker(options Options) *Breaker {
+	return newBreaker(options, time.Now, nil)
+}
+
+func newBreaker(options Options, now func() time.Time, onChange func(from, to State)) *Breaker {
+	options.HalfOpenProbes = max(options.HalfOpenProbes, 1)
+	return &Breaker{
+		options:  options,
+		now:      now,
+		onChange: onChange,
+	}
+}
+
+// State 返回熔断器当前状态；打开状态冷却结束后返回半开。
+func (b *Breaker) State() State {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.advance(b.now())
+	return b.state
+}
+
+// Available 报告熔断器当前是否可能放行请求，不占用探测名额。
+func (b *Breaker) Available() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.advance(b.now())
+
+	switch b.state {
+	case Closed:
+		return true
+	case HalfOpen:
+		return b.probes < b.options.HalfOpenProbes
+	default:
+		return false
+	}
+}
+
+// Allow 尝试放行一次请求；放行时返回的 Ticket 必须在请求完成后交给 Done。
+func (b *Breaker) Allow() (Ticket, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.advance(b.now())
+
+	switch b.state {
+	case Closed:
+		return Ticket{generation: b.generation}, true
+	case HalfOpen:
+		if b.probes >= b.options.HalfOpenProbes {
+			return Ticket{}, false
+		}
+		b.probes++
+		return Ticket{generation: b.generation}, true
+	default:
+		return Ticket{}, false
+	}
+}
+
+// Done 报告请求结果。状态切换前放行的请求结果会被忽略。
+func (b *Breaker) Done(ticket Ticket, success bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if ticket.generation != b.generation {
+		return
+	}
+
+	now := b.now()
+	b.advance(now)
+
+	switch b.state {
+	case Closed:
+		if now.Sub(b.windowStart) >= b.options.Window {
+			b.windowStart = now
+			b.requests = 0
+			b.failures = 0
+		}
+		b.requests++
+		if success {
+			b.consecutive = 0
+			return
+		}
+		b.failures++
+		b.consecutive++
+		if b.tripped() {
+			b.transit(Open, now)
+		}
+	case HalfOpen:
+		b.probes--
+		if success {
+			b.transit(Closed, now)
+		} else {
+			b.transit(Open, now)
+		}
+	}
+}
+
+// Cancel 归还未能得出结果的请求，不计入统计；半开状态下释放探测名额。
+func (b *Breaker) Cancel(ticket Ticket) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if ticket.generation != b.generation {
+		return
+	}
+
+	if b.state == HalfOpen {
+		b.probes--
+	}
+}
+
+func (b *Breaker) tripped() bool {
+	if b.options.ConsecutiveFailures > 0 && b.consecutive >= b.options.ConsecutiveFailures {
+		return true
+	}
+	if b.options.FailureRatio > 0 && b.requests >= max(b.options.MinRequests, 1) &&
+		float64(b.failures) >= b.options.FailureRatio*float64(b.requests) {
+		return true
+	}
+	return false
+}
+
+func (b *Breaker) advance(now time.Time) {
+	if b.state == Open && !now.Before(b.openUntil) {
+		b.transit(HalfOpen, now)
+	}
+}
+
+// detach 使熔断器不再报告状态变化，并返回其当前状态。
+func (b *Breaker) detach() State {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.advance(b.now())
+	b.onChange = nil
+	return b.state
+}
+
+func (b *Breaker) transit(state State, now time.Time) {
+	from := b.state
+
+	b.state = state
+	b.generation++
+	b.requests = 0
+	b.failures = 0
+	b.consecutive = 0
+	b.probes = 0
+	b.windowStart = now
+
+	if state == Open {
+		b.openUntil = now.Add(b.options.OpenTimeout)
+	}
+
+	if b.onChange != nil {
+		b.onChange(from, state)
+	}
+}
+
+// Group 按键惰性创建并管理一组共享配置的熔断器，可并发使用。
+// 键对应的目标下线时应调用 Remove 回收熔断器；Options.IdleTimeout 大于 0 时，长时间未使用的熔断器也会被回收。
+type Group[K comparable] struct {
+	options   Options
+	now       func() time.Time
+	breakers  sync.Map
+	tripped   atomic.Int64
+	lastSweep atomic.Int64
+}
+
+// NewGroup 创建熔断器组。
+func NewGroup[K comparable](options Options) *Group[K] {
+	return newGroup[K](options, time.Now)
+}
+
+func newGroup[K comparable](options Options, now func() time.Time) *Group[K] {
+	g := &Group[K]{
+		options: options,
+		now:     now,
+	}
+	g.lastSweep.Store(now().UnixNano())
+	return g
+}
+
+// Get 返回键对应的熔断器，不存在时创建。
+func (g *Group[K]) Get(key K) *Breaker {
+	now := g.now()
+	g.sweep(now)
+
+	v, ok := g.breakers.Load(key)
+	if !ok {
+		v, _ = g.breakers.LoadOrStore(key, newBreaker(g.options, g.now, g.changed))
+	}
+
+	b := v.(*Breaker)
+	b.lastUsed.Store(now.UnixNano())
+	return b
+}
+
+// Available 报告键对应的目标当前是否可能被放行；尚未记录的键总是可用。
+func (g *Group[K]) Available(key K) bool {
+	v, ok := g.breakers.Load(key)
+	if !ok {
+		return true
+	}
+	return v.(*Breaker).Available()
+}
+
+// Remove 回收键对应的熔断器；仍在进行的请求结果不再影响组的统计，之后的 Get 会创建新的熔断器。
+func (g *Group[K]) Remove(key K) {
+	v, ok := g.breakers.LoadAndDelete(key)
+	if !ok {
+		return
+	}
+	if v.(*Breaker).detach() != Closed {
+		g.tripped.Add(-1)
+	}
+}
+
+// Len 返回组中熔断器的数量。
+func (g *Group[K]) Len() int {
+	n := 0
+	g.breakers.Range(func(_, _ any) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Tripped 返回当前处于打开或半开状态的熔断器数量。
+func (g *Group[K]) Tripped() int {
+	return int(g.tripped.Load())
+}
+
+func (g *Group[K]) changed(from, to State) {
+	switch {
+	case from == Closed && to != Closed:
+		g.tripped.Add(1)
+	case from != Closed && to == Closed:
+		g.tripped.Add(-1)
+	}
+}
+
+// sweep 每隔 IdleTimeout 回收一次空闲超过 IdleTimeout 的熔断器。
+func (g *Group[K]) sweep(now time.Time) {
+	idle := g.options.IdleTimeout
+	if idle <= 0 {
+		return
+	}
+
+	last := g.lastSweep.Load()
+	if now.UnixNano()-last < int64(idle) || !g.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	deadline := now.Add(-idle).UnixNano()
+
+	g.breakers.Range(func(key, v any) bool {
+		if v.(*Breaker).lastUsed.Load() < deadline {
+			g.Remove(key.(K))
+		}
+		return true
+	})
+}
@@ -0,0 +1,230 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestBreaker(options Options) (*Breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	return newBreaker(options, clock.Now, nil), clock
+}
+
+func report(t *testing.T, b *Breaker, success bool) {
+	t.Helper()
+	ticket, ok := b.Allow()
+	if !ok {
+		t.Fatalf("expected request to be allowed in state %s", b.State())
+	}
+	b.Done(ticket, success)
+}
+
+func TestBreakerConsecutiveFailures(t *testing.T) {
+	b, _ := newTestBreaker(Options{Window: time.Second, ConsecutiveFailures: 3, OpenTimeout: time.Second})
+
+	report(t, b, false)
+	report(t, b, false)
+	report(t, b, true)
+	report(t, b, false)
+	report(t, b, false)
+	if b.State() != Closed {
+		t.Fatalf("unexpected state after interrupted failures: got %s want closed", b.State())
+	}
+
+	report(t, b, false)
+	if b.State() != Open {
+		t.Fatalf("unexpected state: got %s want open", b.State())
+	}
+	if _, ok := b.Allow(); ok {
+		t.Fatal("expected open breaker to reject requests")
+	}
+	if b.Available() {
+		t.Fatal("expected open breaker to be unavailable")
+	}
+}
+
+func TestBreakerFailureRatio(t *testing.T) {
+	b, clock := newTestBreaker(Options{Window: time.Second, MinRequests: 4, FailureRatio: 0.5, OpenTimeout: time.Second})
+
+	report(t, b, false)
+	report(t, b, true)
+	report(t, b, false)
+	if b.State() != Closed {
+		t.Fatalf("unexpected state below MinRequests: got %s want closed", b.State())
+	}
+
+	clock.Add(time.Second)
+	report(t, b, false)
+	report(t, b, true)
+	report(t, b, true)
+	report(t, b, true)
+	if b.State() != Closed {
+		t.Fatalf("unexpected state after window reset: got %s want closed", b.State())
+	}
+
+	report(t, b, false)
+	if b.State() != Closed {
+		t.Fatalf("unexpected state at 2/5 failures: got %s want closed", b.State())
+	}
+
+	report(t, b, false)
+	if b.State() != Open {
+		t.Fatalf("unexpected state at 3/6 failures: got %s want open", b.State())
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b, clock := newTestBreaker(Options{Window: time.Second, ConsecutiveFailures: 1, OpenTimeout: time.Second, HalfOpenProbes: 1})
+
+	stale, _ := b.Allow()
+	report(t, b, false)
+	if b.State() != Open {
+		t.Fatalf("unexpected state: got %s want open", b.State())
+	}
+
+	clock.Add(time.Second)
+	if b.State() != HalfOpen {
+		t.Fatalf("unexpected state after timeout: got %s want half-open", b.State())
+	}
+
+	probe, ok := b.Allow()
+	if !ok {
+		t.Fatal("expected half-open breaker to allow a probe")
+	}
+	b.Cancel(probe)
+
+	probe, ok = b.Allow()
+	if !ok {
+		t.Fatal("expected half-open breaker to allow a probe")
+	}
+	if _, ok := b.Allow(); ok {
+		t.Fatal("expected half-open breaker to limit probes")
+	}
+
+	b.Done(stale, false)
+	if b.State() != HalfOpen {
+		t.Fatalf("unexpected state after stale report: got %s want half-open", b.State())
+	}
+
+	b.Done(probe, false)
+	if b.State() != Open {
+		t.Fatalf("unexpected state after failed probe: got %s want open", b.State())
+	}
+
+	clock.Add(time.Second)
+	report(t, b, true)
+	if b.State() != Closed {
+		t.Fatalf("unexpected state after successful probe: got %s want closed", b.State())
+	}
+}
+
+func TestGroupTripped(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	g := NewGroup[string](Options{Window: time.Second, ConsecutiveFailures: 1, OpenTimeout: time.Second})
+	g.now = clock.Now
+
+	if !g.Available("a") {
+		t.Fatal("expected unknown key to be available")
+	}
+
+	report(t, g.Get("a"), false)
+	report(t, g.Get("b"), true)
+	if got := g.Tripped(); got != 1 {
+		t.Fatalf("unexpected tripped count: got %d want 1", got)
+	}
+	if g.Available("a") || !g.Available("b") {
+		t.Fatal("unexpected availability")
+	}
+
+	clock.Add(time.Second)
+	report(t, g.Get("a"), true)
+	if got := g.Tripped(); got != 0 {
+		t.Fatalf("unexpected tripped count after recovery: got %d want 0", got)
+	}
+}
+
+func TestGroupRemove(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	g := newGroup[string](Options{Window: time.Second, ConsecutiveFailures: 1, OpenTimeout: time.Second}, clock.Now)
+
+	report(t, g.Get("a"), false)
+	if got := g.Tripped(); got != 1 {
+		t.Fatalf("unexpected tripped count: got %d want 1", got)
+	}
+
+	clock.Add(time.Second)
+	stale := g.Get("a")
+	probe, ok := stale.Allow()
+	if !ok {
+		t.Fatal("expected half-open probe to be allowed")
+	}
+
+	g.Remove("a")
+	if got := g.Tripped(); got != 0 {
+		t.Fatalf("unexpected tripped count after remove: got %d want 0", got)
+	}
+	if got := g.Len(); got != 0 {
+		t.Fatalf("unexpected group size after remove: got %d want 0", got)
+	}
+
+	// 回收前放行的请求完成时不再影响组的统计
+	stale.Done(probe, false)
+	if got := g.Tripped(); got != 0 {
+		t.Fatalf("stale breaker changed tripped count: got %d want 0", got)
+	}
+
+	if b := g.Get("a"); b == stale || b.State() != Closed {
+		t.Fatal("expected a new closed breaker after remove")
+	}
+
+	g.Remove("missing")
+}
+
+func TestGroupIdleSweep(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	g := newGroup[string](Options{Window: time.Second, ConsecutiveFailures: 1, OpenTimeout: time.Second, IdleTimeout: time.Minute}, clock.Now)
+
+	report(t, g.Get("idle"), false)
+	report(t, g.Get("busy"), true)
+
+	clock.Add(40 * time.Second)
+	g.Get("busy")
+
+	clock.Add(30 * time.Second)
+	g.Get("busy")
+
+	if got := g.Len(); got != 1 {
+		t.Fatalf("unexpected group size after sweep: got %d want 1", got)
+	}
+	if got := g.Tripped(); got != 0 {
+		t.Fatalf("unexpected tripped count after sweep: got %d want 0", got)
+	}
+	if !g.Available("idle") {
+		t.Fatal("expected swept key to be available")
+	}
+}
+
+func TestGroupNoIdleSweep(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	g := newGroup[string](Options{Window: time.Second, OpenTimeout: time.Second}, clock.Now)
+
+	g.Get("a")
+	clock.Add(24 * time.Hour)
+	g.Get("b")
+
+	if got := g.Len(); got != 2 {
+		t.Fatalf("unexpected group size: got %d want 2", got)
+	}
+}
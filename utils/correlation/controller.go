@@ -165,6 +165,13 @@ func (controller *Controller) Close() bool {
 	return true
 }
 
+// Pending 返回尚未完成的请求数量。
+func (controller *Controller) Pending() int {
+	controller.mu.Lock()
+	defer controller.mu.Unlock()
+	return len(controller.pending)
+}
+
 // Done 返回 Controller 完成关闭和待处理请求收尾时兑现的 Signal。
 func (controller *Controller) Done() async.Signal {
 	return controller.done.Signal()
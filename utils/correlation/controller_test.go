@@ -21,6 +21,10 @@ func TestControllerResolve(t *testing.T) {
 		t.Fatal("correlation ID is zero")
 	}
 
+	if n := controller.Pending(); n != 1 {
+		t.Fatalf("unexpected pending count: %d", n)
+	}
+
 	want := async.NewResult("ok", nil)
 	if !controller.Resolve(id, want) {
 		t.Fatal("Resolve failed")
@@ -54,6 +58,9 @@ func TestControllerCancel(t *testing.T) {
 	if controller.Cancel(id, cause) {
 		t.Fatal("second Cancel succeeded")
 	}
+	if n := controller.Pending(); n != 0 {
+		t.Fatalf("unexpected pending count after Cancel: %d", n)
+	}
 }
 
 func TestControllerTimeout(t *testing.T) {
@@ -21,7 +21,12 @@
 //
 // 当前主要子包包括：
 //   - binaryutil：二进制读写、字节流和字节池工具
+//   - circuit：按目标统计失败率的熔断器
 //   - concurrent：Future 控制、监听器集合等并发辅助组件
+//   - hashring：带虚拟节点的一致性哈希环
+//   - metrics：Prometheus 风格的指标与文本格式输出
+//   - ratelimit：令牌桶限流器
+//   - tracing：W3C Trace Context 追踪与 Span 导出
 //
 // 根包本身不提供具体实现，主要用于承载工具层的总览文档。
 package utils
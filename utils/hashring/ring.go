@@ -0,0 +1,147 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package hashring
+
+import (
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// New 使用每个成员 virtualNodes 个虚拟节点创建一致性哈希环；virtualNodes 小于 1 时按 1 处理，
+// 重复成员只保留一个。Ring 创建后只读，成员变化时应重新创建。
+func New(virtualNodes int, members ...string) *Ring {
+	virtualNodes = max(virtualNodes, 1)
+
+	members = slices.Clone(members)
+	slices.Sort(members)
+	members = slices.Compact(members)
+
+	r := &Ring{
+		members: members,
+		points:  make([]point, 0, len(members)*virtualNodes),
+	}
+
+	for i, member := range members {
+		for v := range virtualNodes {
+			r.points = append(r.points, point{
+				hash:   Hash(member + "#" + strconv.Itoa(v)),
+				member: i,
+			})
+		}
+	}
+
+	slices.SortFunc(r.points, func(a, b point) int {
+		switch {
+		case a.hash < b.hash:
+			return -1
+		case a.hash > b.hash:
+			return 1
+		default:
+			return a.member - b.member
+		}
+	})
+
+	return r
+}
+
+// Ring 是不可变的一致性哈希环，可被多个 goroutine 并发查询。
+type Ring struct {
+	members []string
+	points  []point
+}
+
+type point struct {
+	hash   uint64
+	member int
+}
+
+// Len 返回环中成员数量。
+func (r *Ring) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.members)
+}
+
+// Members 返回按字典序排列的成员列表；调用方不得修改。
+func (r *Ring) Members() []string {
+	if r == nil {
+		return nil
+	}
+	return r.members
+}
+
+// Get 返回 key 顺时针方向的首个成员；环为空时返回 false。
+func (r *Ring) Get(key string) (string, bool) {
+	return r.GetFunc(key, nil)
+}
+
+// GetFunc 返回 key 顺时针方向首个被 accept 接受的成员；accept 为 nil 时接受全部成员。
+// 被拒绝成员的键会按环序溢出到后继成员，其余键的映射保持不变。没有成员被接受时返回 false。
+func (r *Ring) GetFunc(key string, accept func(member string) bool) (string, bool) {
+	if r.Len() <= 0 {
+		return "", false
+	}
+
+	hash := Hash(key)
+	idx := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= hash
+	})
+
+	var rejected []bool
+
+	for i := range r.points {
+		p := r.points[(idx+i)%len(r.points)]
+
+		if accept == nil {
+			return r.members[p.member], true
+		}
+
+		if rejected != nil && rejected[p.member] {
+			continue
+		}
+
+		member := r.members[p.member]
+		if accept(member) {
+			return member, true
+		}
+
+		if rejected == nil {
+			rejected = make([]bool, len(r.members))
+		}
+		rejected[p.member] = true
+	}
+
+	return "", false
+}
+
+// Hash 返回 s 的 64 位稳定哈希值，用于在环上定位键和虚拟节点。
+func Hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	z := h.Sum64()
+
+	// FNV 对短键的高位扩散不足，使用 splitmix64 终混改善分布。
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
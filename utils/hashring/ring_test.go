@@ -0,0 +1,105 @@
+package hashring
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRingEmpty(t *testing.T) {
+	var nilRing *Ring
+	if _, ok := nilRing.Get("key"); ok {
+		t.Fatal("expected nil ring lookup to fail")
+	}
+
+	r := New(16)
+	if r.Len() != 0 {
+		t.Fatalf("unexpected member count: got %d want 0", r.Len())
+	}
+	if _, ok := r.Get("key"); ok {
+		t.Fatal("expected empty ring lookup to fail")
+	}
+}
+
+func TestRingDeterministic(t *testing.T) {
+	a := New(64, "n1", "n2", "n3")
+	b := New(64, "n3", "n1", "n2", "n1")
+
+	if b.Len() != 3 {
+		t.Fatalf("unexpected member count: got %d want 3", b.Len())
+	}
+
+	for i := range 1000 {
+		key := fmt.Sprintf("user-%d", i)
+		ma, _ := a.Get(key)
+		mb, _ := b.Get(key)
+		if ma != mb {
+			t.Fatalf("unexpected member for %q: got %q and %q", key, ma, mb)
+		}
+	}
+}
+
+func TestRingDistribution(t *testing.T) {
+	members := []string{"n1", "n2", "n3", "n4"}
+	r := New(160, members...)
+
+	const keys = 40000
+	counts := map[string]int{}
+	for i := range keys {
+		m, ok := r.Get(fmt.Sprintf("user-%d", i))
+		if !ok {
+			t.Fatal("expected lookup to succeed")
+		}
+		counts[m]++
+	}
+
+	expected := keys / len(members)
+	for _, m := range members {
+		if counts[m] < expected*7/10 || counts[m] > expected*13/10 {
+			t.Fatalf("unbalanced distribution for %s: got %d want about %d (%v)", m, counts[m], expected, counts)
+		}
+	}
+}
+
+func TestRingMinimalRemapping(t *testing.T) {
+	before := New(160, "n1", "n2", "n3", "n4")
+	after := New(160, "n1", "n2", "n3", "n4", "n5")
+
+	const keys = 20000
+	moved := 0
+	for i := range keys {
+		key := fmt.Sprintf("user-%d", i)
+		mb, _ := before.Get(key)
+		ma, _ := after.Get(key)
+		if mb != ma {
+			if ma != "n5" {
+				t.Fatalf("key %q moved between existing members: %q -> %q", key, mb, ma)
+			}
+			moved++
+		}
+	}
+
+	if moved > keys*3/10 {
+		t.Fatalf("too many keys remapped: got %d of %d", moved, keys)
+	}
+}
+
+func TestRingGetFuncSkipsRejected(t *testing.T) {
+	r := New(160, "n1", "n2", "n3")
+
+	for i := range 1000 {
+		key := fmt.Sprintf("user-%d", i)
+		orig, _ := r.Get(key)
+
+		got, ok := r.GetFunc(key, func(member string) bool { return member != "n2" })
+		if !ok || got == "n2" {
+			t.Fatalf("unexpected member for %q: got %q ok %v", key, got, ok)
+		}
+		if orig != "n2" && got != orig {
+			t.Fatalf("key %q on accepted member moved: %q -> %q", key, orig, got)
+		}
+	}
+
+	if _, ok := r.GetFunc("key", func(string) bool { return false }); ok {
+		t.Fatal("expected lookup to fail when every member is rejected")
+	}
+}
@@ -0,0 +1,26 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+// Package metrics 提供不依赖外部库的 Prometheus 风格指标。
+//
+// Registry 按名称管理 Counter、Gauge、Histogram 指标族，每个指标族按标签值区分序列，并以 Prometheus 文本格式（0.0.4）输出，
+// 可直接挂载到 HTTP 服务供 Prometheus 抓取。框架内置的 RPC、dsvc、网关和 broker 指标注册在 Default 返回的默认 Registry 中。
+//
+// 同名指标重复注册时返回已有指标族，类型或标签名不一致时 panic。
+package metrics
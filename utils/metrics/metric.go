@@ -0,0 +1,286 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package metrics
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Type 是指标类型。
+type Type int32
+
+const (
+	Type_Counter   Type = iota // 单调递增计数
+	Type_Gauge                 // 可增可减的瞬时值
+	Type_Histogram             // 分桶统计
+)
+
+// String 返回 Prometheus 文本格式中的类型名。
+func (t Type) String() string {
+	switch t {
+	case Type_Counter:
+		return "counter"
+	case Type_Gauge:
+		return "gauge"
+	case Type_Histogram:
+		return "histogram"
+	default:
+		return "untyped"
+	}
+}
+
+// DefBuckets 是以秒为单位的默认延迟分桶。
+var DefBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type _AtomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *_AtomicFloat) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+func (f *_AtomicFloat) Store(v float64) {
+	f.bits.Store(math.Float64bits(v))
+}
+
+func (f *_AtomicFloat) Add(delta float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// Counter 是单调递增的计数器。
+type Counter struct {
+	value _AtomicFloat
+}
+
+// Inc 计数加 1。
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add 计数增加 delta；delta 为负时忽略。
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	c.value.Add(delta)
+}
+
+// Value 返回当前计数。
+func (c *Counter) Value() float64 {
+	return c.value.Load()
+}
+
+// Gauge 是可增可减的瞬时值，也可由函数在采集时提供。
+type Gauge struct {
+	value _AtomicFloat
+	fn    atomic.Pointer[func() float64]
+}
+
+// Set 设置当前值。
+func (g *Gauge) Set(v float64) {
+	g.value.Store(v)
+}
+
+// Add 当前值增加 delta。
+func (g *Gauge) Add(delta float64) {
+	g.value.Add(delta)
+}
+
+// Inc 当前值加 1。
+func (g *Gauge) Inc() {
+	g.value.Add(1)
+}
+
+// Dec 当前值减 1。
+func (g *Gauge) Dec() {
+	g.value.Add(-1)
+}
+
+// SetFunc 设置采集时调用的取值函数，设置后 Set/Add 写入的值被忽略；传入 nil 恢复为直接取值。
+func (g *Gauge) SetFunc(fn func() float64) {
+	if fn == nil {
+		g.fn.Store(nil)
+		return
+	}
+	g.fn.Store(&fn)
+}
+
+// Value 返回当前值。
+func (g *Gauge) Value() float64 {
+	if fn := g.fn.Load(); fn != nil {
+		return (*fn)()
+	}
+	return g.value.Load()
+}
+
+// Histogram 统计观测值的分桶计数、总和与次数。
+type Histogram struct {
+	buckets []float64
+	counts  []atomic.Uint64
+	sum     _AtomicFloat
+	count   atomic.Uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		buckets: buckets,
+		counts:  make([]atomic.Uint64, len(buckets)),
+	}
+}
+
+// Observe 记录一次观测值。
+func (h *Histogram) Observe(v float64) {
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[i].Add(1)
+	}
+	h.sum.Add(v)
+	h.count.Add(1)
+}
+
+// Count 返回观测次数。
+func (h *Histogram) Count() uint64 {
+	return h.count.Load()
+}
+
+// Sum 返回观测值总和。
+func (h *Histogram) Sum() float64 {
+	return h.sum.Load()
+}
+
+// family 是同名指标的全部序列。
+type family struct {
+	name       string
+	help       string
+	typ        Type
+	labelNames []string
+	buckets    []float64
+	mu         sync.RWMutex
+	series     map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	metric      any
+}
+
+const labelSep = "\xff"
+
+func (f *family) get(labelValues []string) any {
+	if len(labelValues) != len(f.labelNames) {
+		panic("metrics: label values count mismatch for " + f.name)
+	}
+
+	key := strings.Join(labelValues, labelSep)
+
+	f.mu.RLock()
+	s, ok := f.series[key]
+	f.mu.RUnlock()
+	if ok {
+		return s.metric
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if s, ok := f.series[key]; ok {
+		return s.metric
+	}
+
+	s = &series{labelValues: slices.Clone(labelValues)}
+	switch f.typ {
+	case Type_Counter:
+		s.metric = &Counter{}
+	case Type_Gauge:
+		s.metric = &Gauge{}
+	case Type_Histogram:
+		s.metric = newHistogram(f.buckets)
+	}
+	f.series[key] = s
+	return s.metric
+}
+
+func (f *family) delete(labelValues []string) bool {
+	key := strings.Join(labelValues, labelSep)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.series[key]; !ok {
+		return false
+	}
+	delete(f.series, key)
+	return true
+}
+
+// CounterVec 是按标签区分的计数器族。
+type CounterVec struct {
+	f *family
+}
+
+// With 返回标签值对应的计数器，不存在时创建。
+func (v CounterVec) With(labelValues ...string) *Counter {
+	return v.f.get(labelValues).(*Counter)
+}
+
+// Delete 删除标签值对应的序列。
+func (v CounterVec) Delete(labelValues ...string) bool {
+	return v.f.delete(labelValues)
+}
+
+// GaugeVec 是按标签区分的瞬时值族。
+type GaugeVec struct {
+	f *family
+}
+
+// With 返回标签值对应的瞬时值，不存在时创建。
+func (v GaugeVec) With(labelValues ...string) *Gauge {
+	return v.f.get(labelValues).(*Gauge)
+}
+
+// Delete 删除标签值对应的序列。
+func (v GaugeVec) Delete(labelValues ...string) bool {
+	return v.f.delete(labelValues)
+}
+
+// HistogramVec 是按标签区分的分桶统计族。
+type HistogramVec struct {
+	f *family
+}
+
+// With 返回标签值对应的分桶统计，不存在时创建。
+func (v HistogramVec) With(labelValues ...string) *Histogram {
+	return v.f.get(labelValues).(*Histogram)
+}
+
+// Delete 删除标签值对应的序列。
+func (v HistogramVec) Delete(labelValues ...string) bool {
+	return v.f.delete(labelValues)
+}
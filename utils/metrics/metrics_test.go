@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.Counter("rpc_requests_total", "Total RPC requests.", "service", "code")
+	requests.With("svc", "ok").Add(2)
+	requests.With("svc", "500").Inc()
+	requests.With("svc", "ok").Add(-1)
+
+	sessions := r.Gauge("gate_sessions", "Live sessions.", "node")
+	sessions.With("n1").Set(3)
+	sessions.With("n2").SetFunc(func() float64 { return 7 })
+
+	latency := r.Histogram("rpc_duration_seconds", "RPC latency.", []float64{0.5, 0.1}, "service")
+	latency.With("svc").Observe(0.05)
+	latency.With("svc").Observe(0.3)
+	latency.With("svc").Observe(2)
+
+	var sb strings.Builder
+	if err := r.WriteText(&sb); err != nil {
+		t.Fatalf("write text: %v", err)
+	}
+
+	expected := `# HELP gate_sessions Live sessions.
+# TYPE gate_sessions gauge
+gate_sessions{node="n1"} 3
+gate_sessions{node="n2"} 7
+# HELP rpc_duration_seconds RPC latency.
+# TYPE rpc_duration_seconds histogram
+rpc_duration_seconds_bucket{service="svc",le="0.1"} 1
+rpc_duration_seconds_bucket{service="svc",le="0.5"} 2
+rpc_duration_seconds_bucket{service="svc",le="+Inf"} 3
+rpc_duration_seconds_sum{service="svc"} 2.35
+rpc_duration_seconds_count{service="svc"} 3
+# HELP rpc_requests_total Total RPC requests.
+# TYPE rpc_requests_total counter
+rpc_requests_total{service="svc",code="500"} 1
+rpc_requests_total{service="svc",code="ok"} 2
+`
+	if sb.String() != expected {
+		t.Fatalf("unexpected exposition:\n%s", sb.String())
+	}
+}
+
+func TestRegistryReuseAndConflict(t *testing.T) {
+	r := NewRegistry()
+
+	a := r.Counter("c", "", "l")
+	b := r.Counter("c", "", "l")
+	a.With("x").Inc()
+	if b.With("x").Value() != 1 {
+		t.Fatal("expected re-registration to return the same family")
+	}
+
+	if !b.Delete("x") || b.Delete("x") {
+		t.Fatal("unexpected delete result")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected conflicting registration to panic")
+		}
+	}()
+	r.Gauge("c", "", "l")
+}
+
+func TestLabelEscapingAndHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Gauge("g", "line1\nline2", "l").With("a\"b\\c\nd").Set(1)
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `# HELP g line1\nline2`) || !strings.Contains(body, `g{l="a\"b\\c\nd"} 1`) {
+		t.Fatalf("unexpected escaping:\n%s", body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry 管理指标族，并以 Prometheus 文本格式输出。
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry 创建空的 Registry。
+func NewRegistry() *Registry {
+	return &Registry{
+		families: map[string]*family{},
+	}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default 返回框架内置指标使用的默认 Registry。
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Counter 注册或返回计数器族。
+func (r *Registry) Counter(name, help string, labelNames ...string) CounterVec {
+	return CounterVec{f: r.register(name, help, Type_Counter, nil, labelNames)}
+}
+
+// Gauge 注册或返回瞬时值族。
+func (r *Registry) Gauge(name, help string, labelNames ...string) GaugeVec {
+	return GaugeVec{f: r.register(name, help, Type_Gauge, nil, labelNames)}
+}
+
+// Histogram 注册或返回分桶统计族；buckets 为空时使用 DefBuckets。
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) HistogramVec {
+	if len(buckets) <= 0 {
+		buckets = DefBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return HistogramVec{f: r.register(name, help, Type_Histogram, buckets, labelNames)}
+}
+
+func (r *Registry) register(name, help string, typ Type, buckets []float64, labelNames []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if f.typ != typ || !slices.Equal(f.labelNames, labelNames) {
+			panic(fmt.Sprintf("metrics: %q already registered with a different type or labels", name))
+		}
+		return f
+	}
+
+	f := &family{
+		name:       name,
+		help:       help,
+		typ:        typ,
+		labelNames: slices.Clone(labelNames),
+		buckets:    buckets,
+		series:     map[string]*series{},
+	}
+	r.families[name] = f
+	return f
+}
+
+// WriteText 以 Prometheus 文本格式输出全部指标，指标族与序列按名称排序。
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		writeFamily(bw, f)
+	}
+	return bw.Flush()
+}
+
+// Handler 返回输出全部指标的 HTTP 处理器。
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+func writeFamily(w *bufio.Writer, f *family) {
+	f.mu.RLock()
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	seriesList := make([]*series, len(keys))
+	for i, key := range keys {
+		seriesList[i] = f.series[key]
+	}
+	f.mu.RUnlock()
+
+	if f.help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+
+	for _, s := range seriesList {
+		switch m := s.metric.(type) {
+		case *Counter:
+			writeSample(w, f.name, f.labelNames, s.labelValues, "", "", m.Value())
+		case *Gauge:
+			writeSample(w, f.name, f.labelNames, s.labelValues, "", "", m.Value())
+		case *Histogram:
+			var cumulative uint64
+			for i, bound := range m.buckets {
+				cumulative += m.counts[i].Load()
+				writeSample(w, f.name+"_bucket", f.labelNames, s.labelValues, "le", formatFloat(bound), float64(cumulative))
+			}
+			count := m.Count()
+			writeSample(w, f.name+"_bucket", f.labelNames, s.labelValues, "le", "+Inf", float64(count))
+			writeSample(w, f.name+"_sum", f.labelNames, s.labelValues, "", "", m.Sum())
+			writeSample(w, f.name+"_count", f.labelNames, s.labelValues, "", "", float64(count))
+		}
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labelNames, labelValues []string, extraName, extraValue string, value float64) {
+	w.WriteString(name)
+	if len(labelNames) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, labelName := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(labelName)
+			w.WriteString(`="`)
+			w.WriteString(escapeLabel(labelValues[i]))
+			w.WriteByte('"')
+		}
+		if extraName != "" {
+			if len(labelNames) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraName)
+			w.WriteString(`="`)
+			w.WriteString(extraValue)
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
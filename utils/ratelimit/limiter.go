@@ -0,0 +1,181 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limit 定义令牌桶参数。
+type Limit struct {
+	Rate  float64 // Rate 是每秒补充的令牌数；小于等于 0 表示不限流。
+	Burst int     // Burst 是令牌桶容量，即允许的突发请求数；小于 1 时视为 1。
+}
+
+// Unlimited 报告是否不限流。
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+func (l Limit) burst() float64 {
+	return float64(max(l.Burst, 1))
+}
+
+// refill 返回令牌桶从空到满所需的时间。
+func (l Limit) refill() time.Duration {
+	return time.Duration(l.burst() / l.Rate * float64(time.Second))
+}
+
+// Bucket 是单个令牌桶，不可并发使用。
+type Bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket 创建装满令牌的令牌桶。
+func NewBucket(limit Limit, now time.Time) *Bucket {
+	return &Bucket{
+		limit:  limit,
+		tokens: limit.burst(),
+		last:   now,
+	}
+}
+
+// AllowAt 按 now 补充令牌，并尝试取走一个令牌。
+func (b *Bucket) AllowAt(now time.Time) bool {
+	if b.limit.Unlimited() {
+		return true
+	}
+
+	b.advance(now)
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Refund 归还一个之前取走的令牌，令牌数不超过桶容量。
+func (b *Bucket) Refund() {
+	if b.limit.Unlimited() {
+		return
+	}
+	b.tokens = min(b.tokens+1, b.limit.burst())
+}
+
+// FullAt 报告令牌桶在 now 时是否已经回满。
+func (b *Bucket) FullAt(now time.Time) bool {
+	if b.limit.Unlimited() {
+		return true
+	}
+	b.advance(now)
+	return b.tokens >= b.limit.burst()
+}
+
+func (b *Bucket) advance(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed.Seconds()*b.limit.Rate, b.limit.burst())
+		b.last = now
+	}
+}
+
+// Limiter 按键管理一组共享参数的令牌桶，可并发使用。
+type Limiter[K comparable] struct {
+	mutex     sync.Mutex
+	limit     Limit
+	now       func() time.Time
+	buckets   map[K]*Bucket
+	nextSweep time.Time
+}
+
+// NewLimiter 创建按键限流器。
+func NewLimiter[K comparable](limit Limit) *Limiter[K] {
+	return &Limiter[K]{
+		limit:   limit,
+		now:     time.Now,
+		buckets: map[K]*Bucket{},
+	}
+}
+
+// Limit 返回限流参数。
+func (l *Limiter[K]) Limit() Limit {
+	return l.limit
+}
+
+// Allow 尝试从键对应的令牌桶取走一个令牌。
+func (l *Limiter[K]) Allow(key K) bool {
+	if l.limit.Unlimited() {
+		return true
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = NewBucket(l.limit, now)
+		l.buckets[key] = bucket
+	}
+
+	return bucket.AllowAt(now)
+}
+
+// Refund 向键对应的令牌桶归还一个之前由 Allow 取走的令牌，用于组合多个限流器时撤销未生效的放行；
+// 令牌桶已被回收时忽略。
+func (l *Limiter[K]) Refund(key K) {
+	if l.limit.Unlimited() {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if bucket, ok := l.buckets[key]; ok {
+		bucket.Refund()
+	}
+}
+
+// Len 返回当前持有的令牌桶数量。
+func (l *Limiter[K]) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return len(l.buckets)
+}
+
+// sweep 每隔一个回满周期回收已经回满的令牌桶，回满的令牌桶与新建的等价。
+func (l *Limiter[K]) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	l.nextSweep = now.Add(max(l.limit.refill(), time.Second))
+
+	for key, bucket := range l.buckets {
+		if bucket.FullAt(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketBurstAndRefill(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := NewBucket(Limit{Rate: 2, Burst: 3}, now)
+
+	for i := range 3 {
+		if !b.AllowAt(now) {
+			t.Fatalf("expected burst request %d to be allowed", i)
+		}
+	}
+	if b.AllowAt(now) {
+		t.Fatal("expected request beyond burst to be rejected")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if !b.AllowAt(now) {
+		t.Fatal("expected refilled token to be allowed")
+	}
+	if b.AllowAt(now) {
+		t.Fatal("expected only one token to be refilled")
+	}
+
+	now = now.Add(time.Hour)
+	if !b.FullAt(now) {
+		t.Fatal("expected bucket to be full")
+	}
+	for range 3 {
+		b.AllowAt(now)
+	}
+	if b.AllowAt(now) {
+		t.Fatal("expected refill to be capped at burst")
+	}
+}
+
+func TestBucketUnlimited(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := NewBucket(Limit{}, now)
+
+	for range 1000 {
+		if !b.AllowAt(now) {
+			t.Fatal("expected unlimited bucket to allow requests")
+		}
+	}
+}
+
+func TestLimiterKeys(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewLimiter[string](Limit{Rate: 1, Burst: 1})
+	l.now = func() time.Time { return now }
+
+	if !l.Allow("a") || l.Allow("a") {
+		t.Fatal("unexpected limit for key a")
+	}
+	if !l.Allow("b") {
+		t.Fatal("expected key b to have its own bucket")
+	}
+	if got := l.Len(); got != 2 {
+		t.Fatalf("unexpected bucket count: got %d want 2", got)
+	}
+
+	now = now.Add(2 * time.Second)
+	if !l.Allow("a") {
+		t.Fatal("expected key a to be refilled")
+	}
+	if got := l.Len(); got != 1 {
+		t.Fatalf("unexpected bucket count after sweep: got %d want 1", got)
+	}
+}
+
+func TestLimiterRefund(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewLimiter[string](Limit{Rate: 1, Burst: 2})
+	l.now = func() time.Time { return now }
+
+	if !l.Allow("a") || !l.Allow("a") || l.Allow("a") {
+		t.Fatal("unexpected limit for key a")
+	}
+
+	l.Refund("a")
+	if !l.Allow("a") {
+		t.Fatal("expected refunded token to be allowed")
+	}
+	if l.Allow("a") {
+		t.Fatal("expected only one token to be refunded")
+	}
+
+	l.Refund("a")
+	l.Refund("a")
+	l.Refund("a")
+	if !l.Allow("a") || !l.Allow("a") || l.Allow("a") {
+		t.Fatal("expected refunds to be capped at burst")
+	}
+
+	l.Refund("missing")
+	if got := l.Len(); got != 1 {
+		t.Fatalf("refunding an unknown key created a bucket: got %d buckets", got)
+	}
+}
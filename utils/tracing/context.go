@@ -0,0 +1,160 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrInvalidTraceParent 表示 traceparent 格式非法。
+var ErrInvalidTraceParent = errors.New("tracing: invalid traceparent")
+
+// TraceID 是 16 字节的链路 ID。
+type TraceID [16]byte
+
+// IsValid 报告链路 ID 是否非零。
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String 返回小写十六进制形式的链路 ID。
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID 是 8 字节的 Span ID。
+type SpanID [8]byte
+
+// IsValid 报告 Span ID 是否非零。
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String 返回小写十六进制形式的 Span ID。
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// NewTraceID 生成随机链路 ID。
+func NewTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+// NewSpanID 生成随机 Span ID。
+func NewSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+const (
+	traceParentVersion = "00"
+	traceParentLen     = 55
+	flagSampled        = 0x01
+)
+
+// SpanContext 是跨进程传播的追踪上下文。
+type SpanContext struct {
+	TraceID TraceID // 链路 ID。
+	SpanID  SpanID  // 当前 Span ID，作为下游 Span 的父 ID。
+	Sampled bool    // 是否采样；未采样的链路仍会传播，但不导出。
+}
+
+// IsValid 报告追踪上下文是否有效。
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// TraceParent 返回 W3C traceparent 格式的追踪上下文；上下文无效时返回空字符串。
+func (sc SpanContext) TraceParent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+
+	var buf [traceParentLen]byte
+	copy(buf[0:2], traceParentVersion)
+	buf[2] = '-'
+	hex.Encode(buf[3:35], sc.TraceID[:])
+	buf[35] = '-'
+	hex.Encode(buf[36:52], sc.SpanID[:])
+	buf[52] = '-'
+	flags := byte(0)
+	if sc.Sampled {
+		flags |= flagSampled
+	}
+	hex.Encode(buf[53:55], []byte{flags})
+	return string(buf[:])
+}
+
+// String 返回 traceparent 格式的追踪上下文。
+func (sc SpanContext) String() string {
+	return sc.TraceParent()
+}
+
+// ParseTraceParent 解析 W3C traceparent；空字符串返回零值且不报错。
+func ParseTraceParent(s string) (SpanContext, error) {
+	if s == "" {
+		return SpanContext{}, nil
+	}
+
+	// 未来版本允许在尾部追加字段，只解析已知部分
+	if len(s) < traceParentLen || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	if s[:2] == traceParentVersion && len(s) != traceParentLen {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	if s[:2] == "ff" || (len(s) > traceParentLen && s[traceParentLen] != '-') {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	var version [1]byte
+	if _, err := hex.Decode(version[:], []byte(s[0:2])); err != nil {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(s[3:35])); err != nil {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(s[36:52])); err != nil {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(s[53:55])); err != nil {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	sc.Sampled = flags[0]&flagSampled != 0
+
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	return sc, nil
+}
@@ -0,0 +1,27 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+// Package tracing 提供兼容 W3C Trace Context 的轻量分布式追踪工具。
+//
+// SpanContext 以 traceparent 格式（00-<trace-id>-<span-id>-<flags>）在进程间传播；Tracer 基于父上下文创建 Span，
+// Span 结束后交给可插拔的 Exporter 导出。包内提供不依赖外部收集器的导出器：
+// WriterExporter 按行输出 JSON，OTLPFileExporter 按 OTLP/JSON 文件格式输出，可直接被 OpenTelemetry Collector 的文件接收器读取。
+//
+// 未设置默认 Tracer 时，创建的 Span 不记录也不导出，但仍会原样传播父上下文。
+package tracing
@@ -0,0 +1,256 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package tracing
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Exporter 导出已结束的 Span，需支持并发调用。
+type Exporter interface {
+	// Export 导出一批 Span。
+	Export(spans []Span) error
+	// Shutdown 刷新缓冲并释放资源。
+	Shutdown() error
+}
+
+// NewWriterExporter 创建按行向 w 输出 JSON 的导出器，每行一个 Span。
+func NewWriterExporter(w io.Writer) Exporter {
+	return &_LineExporter{
+		w:      bufio.NewWriter(w),
+		encode: encodeSpanJSON,
+	}
+}
+
+// NewStdoutExporter 创建向标准输出打印 Span 的导出器。
+func NewStdoutExporter() Exporter {
+	return NewWriterExporter(os.Stdout)
+}
+
+// NewOTLPFileExporter 创建以 OTLP/JSON 文件格式追加写入 path 的导出器。
+// 每行是一个 ExportTraceServiceRequest，可被 OpenTelemetry Collector 的 otlpjsonfile 接收器读取。
+func NewOTLPFileExporter(path string) (Exporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &_LineExporter{
+		w:      bufio.NewWriter(f),
+		closer: f,
+		encode: encodeOTLPJSON,
+	}, nil
+}
+
+// _LineExporter 将每批 Span 编码为一行写入缓冲区，每批写完后刷新。
+type _LineExporter struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	encode func(spans []Span) ([]byte, error)
+	closed bool
+}
+
+func (e *_LineExporter) Export(spans []Span) error {
+	if len(spans) <= 0 {
+		return nil
+	}
+
+	line, err := e.encode(spans)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return errors.New("tracing: exporter is shutdown")
+	}
+
+	if _, err := e.w.Write(line); err != nil {
+		return err
+	}
+	if err := e.w.WriteByte('\n'); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *_LineExporter) Shutdown() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	err := e.w.Flush()
+	if e.closer != nil {
+		err = errors.Join(err, e.closer.Close())
+	}
+	return err
+}
+
+type _SpanJSON struct {
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	ParentID   string            `json:"parent_id,omitempty"`
+	Service    string            `json:"service,omitempty"`
+	Name       string            `json:"name"`
+	Kind       SpanKind          `json:"kind"`
+	Start      string            `json:"start"`
+	DurationUS int64             `json:"duration_us"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+func encodeSpanJSON(spans []Span) ([]byte, error) {
+	var lines []byte
+	for i, span := range spans {
+		s := _SpanJSON{
+			TraceID:    span.Context.TraceID.String(),
+			SpanID:     span.Context.SpanID.String(),
+			Service:    span.Service,
+			Name:       span.Name,
+			Kind:       span.Kind,
+			Start:      span.Start.Format("2006-01-02T15:04:05.000000Z07:00"),
+			DurationUS: span.End.Sub(span.Start).Microseconds(),
+			Error:      span.Error,
+		}
+		if span.Parent.IsValid() {
+			s.ParentID = span.Parent.String()
+		}
+		if len(span.Attributes) > 0 {
+			s.Attributes = make(map[string]string, len(span.Attributes))
+			for _, attr := range span.Attributes {
+				s.Attributes[attr.Key] = attr.Value
+			}
+		}
+
+		line, err := json.Marshal(s)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			lines = append(lines, '\n')
+		}
+		lines = append(lines, line...)
+	}
+	return lines, nil
+}
+
+// OTLP/JSON 结构，字段命名遵循 opentelemetry-proto 的 JSON 映射。
+type (
+	_OTLPRequest struct {
+		ResourceSpans []_OTLPResourceSpans `json:"resourceSpans"`
+	}
+	_OTLPResourceSpans struct {
+		Resource   _OTLPResource     `json:"resource"`
+		ScopeSpans []_OTLPScopeSpans `json:"scopeSpans"`
+	}
+	_OTLPResource struct {
+		Attributes []_OTLPKeyValue `json:"attributes"`
+	}
+	_OTLPScopeSpans struct {
+		Scope _OTLPScope  `json:"scope"`
+		Spans []_OTLPSpan `json:"spans"`
+	}
+	_OTLPScope struct {
+		Name string `json:"name"`
+	}
+	_OTLPSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              SpanKind        `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []_OTLPKeyValue `json:"attributes,omitempty"`
+		Status            _OTLPStatus     `json:"status"`
+	}
+	_OTLPKeyValue struct {
+		Key   string        `json:"key"`
+		Value _OTLPAnyValue `json:"value"`
+	}
+	_OTLPAnyValue struct {
+		StringValue string `json:"stringValue"`
+	}
+	_OTLPStatus struct {
+		Code    int32  `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+const (
+	otlpScopeName       = "git.golaxy.org/framework"
+	otlpStatusCodeOK    = 1
+	otlpStatusCodeError = 2
+)
+
+func encodeOTLPJSON(spans []Span) ([]byte, error) {
+	var req _OTLPRequest
+	resources := map[string]int{}
+
+	for _, span := range spans {
+		idx, ok := resources[span.Service]
+		if !ok {
+			idx = len(req.ResourceSpans)
+			resources[span.Service] = idx
+			req.ResourceSpans = append(req.ResourceSpans, _OTLPResourceSpans{
+				Resource: _OTLPResource{
+					Attributes: []_OTLPKeyValue{{Key: "service.name", Value: _OTLPAnyValue{StringValue: span.Service}}},
+				},
+				ScopeSpans: []_OTLPScopeSpans{{Scope: _OTLPScope{Name: otlpScopeName}}},
+			})
+		}
+
+		s := _OTLPSpan{
+			TraceID:           span.Context.TraceID.String(),
+			SpanID:            span.Context.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Status:            _OTLPStatus{Code: otlpStatusCodeOK},
+		}
+		if span.Parent.IsValid() {
+			s.ParentSpanID = span.Parent.String()
+		}
+		for _, attr := range span.Attributes {
+			s.Attributes = append(s.Attributes, _OTLPKeyValue{Key: attr.Key, Value: _OTLPAnyValue{StringValue: attr.Value}})
+		}
+		if span.Error != "" {
+			s.Status = _OTLPStatus{Code: otlpStatusCodeError, Message: span.Error}
+		}
+
+		scope := &req.ResourceSpans[idx].ScopeSpans[0]
+		scope.Spans = append(scope.Spans, s)
+	}
+
+	return json.Marshal(req)
+}
@@ -0,0 +1,203 @@
+/*
+ * This file is part of Golaxy Distributed Service Development Framework.
+ *
+ * Golaxy Distributed Service Development Framework is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 2.1 of the License, or
+ * (at your option) any later version.
+ *
+ * Golaxy Distributed Service Development Framework is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with Golaxy Distributed Service Development Framework. If not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (c) 2024 pangdogs.
+ */
+
+package tracing
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SpanKind 是 Span 类型，取值与 OTLP 一致。
+type SpanKind int32
+
+const (
+	SpanKind_Internal SpanKind = iota + 1 // 进程内部操作
+	SpanKind_Server                       // 处理远端请求
+	SpanKind_Client                       // 发起需要响应的远端请求
+	SpanKind_Producer                     // 发送无需响应的远端通知
+	SpanKind_Consumer                     // 处理无需响应的远端通知
+)
+
+// Attribute 是 Span 属性。
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Attr 创建 Span 属性。
+func Attr(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span 是已结束的 Span 数据，交给 Exporter 导出。
+type Span struct {
+	Context    SpanContext // 当前 Span 的追踪上下文。
+	Parent     SpanID      // 父 Span ID；根 Span 为零值。
+	Service    string      // 产生 Span 的服务名。
+	Name       string      // Span 名称，通常为调用路径。
+	Kind       SpanKind    // Span 类型。
+	Start      time.Time   // 开始时间。
+	End        time.Time   // 结束时间。
+	Attributes []Attribute // 属性。
+	Error      string      // 错误信息；为空表示成功。
+}
+
+// Sampler 决定是否采样新的根链路。
+type Sampler func(traceID TraceID) bool
+
+// AlwaysSample 采样所有链路。
+func AlwaysSample(TraceID) bool {
+	return true
+}
+
+// RatioSample 按比例采样链路。
+func RatioSample(ratio float64) Sampler {
+	return func(TraceID) bool {
+		return rand.Float64() < ratio
+	}
+}
+
+// Tracer 创建 Span，并在 Span 结束时将已采样的 Span 交给 Exporter。
+type Tracer struct {
+	exporter Exporter
+	sampler  Sampler
+}
+
+// NewTracer 创建 Tracer；sampler 为 nil 时采样所有链路。
+func NewTracer(exporter Exporter, sampler Sampler) *Tracer {
+	if sampler == nil {
+		sampler = AlwaysSample
+	}
+	return &Tracer{
+		exporter: exporter,
+		sampler:  sampler,
+	}
+}
+
+// Start 以 parent 为父上下文创建并开始 Span；parent 无效时创建新链路。
+// Tracer 为 nil 时返回不记录的 Span，其上下文与 parent 相同。
+func (t *Tracer) Start(parent SpanContext, service, name string, kind SpanKind, attrs ...Attribute) *ActiveSpan {
+	if t == nil {
+		return &ActiveSpan{span: Span{Context: parent}}
+	}
+
+	as := &ActiveSpan{
+		tracer: t,
+		span: Span{
+			Service:    service,
+			Name:       name,
+			Kind:       kind,
+			Start:      time.Now(),
+			Attributes: attrs,
+		},
+	}
+
+	if parent.IsValid() {
+		as.span.Context = SpanContext{
+			TraceID: parent.TraceID,
+			SpanID:  NewSpanID(),
+			Sampled: parent.Sampled,
+		}
+		as.span.Parent = parent.SpanID
+	} else {
+		traceID := NewTraceID()
+		as.span.Context = SpanContext{
+			TraceID: traceID,
+			SpanID:  NewSpanID(),
+			Sampled: t.sampler(traceID),
+		}
+	}
+
+	as.recording = as.span.Context.Sampled && t.exporter != nil
+	return as
+}
+
+// Shutdown 关闭 Exporter。
+func (t *Tracer) Shutdown() error {
+	if t == nil || t.exporter == nil {
+		return nil
+	}
+	return t.exporter.Shutdown()
+}
+
+// ActiveSpan 是进行中的 Span。
+type ActiveSpan struct {
+	mu        sync.Mutex
+	tracer    *Tracer
+	span      Span
+	recording bool
+	ended     bool
+}
+
+// Context 返回 Span 的追踪上下文，用于传播给下游。
+func (as *ActiveSpan) Context() SpanContext {
+	return as.span.Context
+}
+
+// IsRecording 报告 Span 结束后是否会被导出。
+func (as *ActiveSpan) IsRecording() bool {
+	return as.recording
+}
+
+// SetAttributes 追加 Span 属性。
+func (as *ActiveSpan) SetAttributes(attrs ...Attribute) {
+	if !as.recording {
+		return
+	}
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.span.Attributes = append(as.span.Attributes, attrs...)
+}
+
+// End 结束 Span 并导出；err 非空时记为失败。重复调用无效。
+func (as *ActiveSpan) End(err error) {
+	if !as.recording {
+		return
+	}
+
+	as.mu.Lock()
+	if as.ended {
+		as.mu.Unlock()
+		return
+	}
+	as.ended = true
+	as.span.End = time.Now()
+	if err != nil {
+		as.span.Error = err.Error()
+	}
+	span := as.span
+	as.mu.Unlock()
+
+	as.tracer.exporter.Export([]Span{span})
+}
+
+var defaultTracer atomic.Pointer[Tracer]
+
+// SetDefault 设置框架使用的默认 Tracer；传入 nil 关闭追踪。
+func SetDefault(t *Tracer) {
+	defaultTracer.Store(t)
+}
+
+// Default 返回默认 Tracer；未设置时返回 nil，此时创建的 Span 只传播上下文。
+func Default() *Tracer {
+	return defaultTracer.Load()
+}
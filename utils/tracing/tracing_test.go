@@ -0,0 +1,166 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTraceParentRoundTrip(t *testing.T) {
+	const tp = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	sc, err := ParseTraceParent(tp)
+	if err != nil {
+		t.Fatalf("parse traceparent: %v", err)
+	}
+	if !sc.Sampled {
+		t.Fatal("expected sampled flag")
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+		t.Fatalf("unexpected ids: %s %s", sc.TraceID, sc.SpanID)
+	}
+	if got := sc.TraceParent(); got != tp {
+		t.Fatalf("expected %q, got %q", tp, got)
+	}
+}
+
+func TestParseTraceParentRejectsInvalid(t *testing.T) {
+	for _, tp := range []string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceParent(tp); !errors.Is(err, ErrInvalidTraceParent) {
+			t.Fatalf("expected %q to be rejected, got %v", tp, err)
+		}
+	}
+
+	sc, err := ParseTraceParent("")
+	if err != nil || sc.IsValid() {
+		t.Fatalf("expected empty traceparent to yield zero context, got %v %v", sc, err)
+	}
+
+	if _, err := ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future"); err != nil {
+		t.Fatalf("expected future version with extra fields to parse, got %v", err)
+	}
+}
+
+type recordingExporter struct {
+	spans []Span
+}
+
+func (e *recordingExporter) Export(spans []Span) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown() error {
+	return nil
+}
+
+func TestTracerParentChild(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter, nil)
+
+	root := tracer.Start(SpanContext{}, "svc", "root", SpanKind_Client)
+	child := tracer.Start(root.Context(), "svc", "child", SpanKind_Server, Attr("k", "v"))
+	child.End(errors.New("boom"))
+	child.End(nil)
+	root.End(nil)
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(exporter.spans))
+	}
+
+	c, r := exporter.spans[0], exporter.spans[1]
+	if c.Context.TraceID != r.Context.TraceID {
+		t.Fatal("expected child to share trace id with root")
+	}
+	if c.Parent != r.Context.SpanID || r.Parent.IsValid() {
+		t.Fatal("unexpected parent links")
+	}
+	if c.Error != "boom" || r.Error != "" {
+		t.Fatalf("unexpected errors: %q %q", c.Error, r.Error)
+	}
+}
+
+func TestTracerUnsampledAndNil(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter, func(TraceID) bool { return false })
+
+	root := tracer.Start(SpanContext{}, "svc", "root", SpanKind_Client)
+	if !root.Context().IsValid() || root.Context().Sampled || root.IsRecording() {
+		t.Fatal("expected unsampled span to propagate without recording")
+	}
+	root.End(nil)
+	if len(exporter.spans) != 0 {
+		t.Fatal("expected unsampled span not to be exported")
+	}
+
+	parent := SpanContext{TraceID: NewTraceID(), SpanID: NewSpanID(), Sampled: true}
+	span := (*Tracer)(nil).Start(parent, "svc", "noop", SpanKind_Server)
+	if span.Context() != parent || span.IsRecording() {
+		t.Fatal("expected nil tracer to pass parent through")
+	}
+	span.End(nil)
+}
+
+func TestWriterExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer(NewWriterExporter(&buf), nil)
+	tracer.Start(SpanContext{}, "svc", "Comp.Method", SpanKind_Client).End(nil)
+
+	var s map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &s); err != nil {
+		t.Fatalf("unmarshal span: %v", err)
+	}
+	if s["name"] != "Comp.Method" || s["service"] != "svc" {
+		t.Fatalf("unexpected span: %v", s)
+	}
+}
+
+func TestOTLPFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	exporter, err := NewOTLPFileExporter(path)
+	if err != nil {
+		t.Fatalf("create exporter: %v", err)
+	}
+	tracer := NewTracer(exporter, nil)
+
+	root := tracer.Start(SpanContext{}, "a", "root", SpanKind_Client)
+	tracer.Start(root.Context(), "b", "child", SpanKind_Server).End(errors.New("failed"))
+	root.End(nil)
+
+	if err := tracer.Shutdown(); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	var req _OTLPRequest
+	if err := json.Unmarshal([]byte(lines[0]), &req); err != nil {
+		t.Fatalf("unmarshal otlp: %v", err)
+	}
+	rs := req.ResourceSpans[0]
+	if rs.Resource.Attributes[0].Value.StringValue != "b" {
+		t.Fatalf("unexpected resource: %+v", rs.Resource)
+	}
+	span := rs.ScopeSpans[0].Spans[0]
+	if span.ParentSpanID != root.Context().SpanID.String() || span.Status.Code != otlpStatusCodeError || span.Kind != SpanKind_Server {
+		t.Fatalf("unexpected span: %+v", span)
+	}
+}